@@ -0,0 +1,31 @@
+package cli
+
+// An Option configures an Application as it is created, see New.
+type Option func(a *Application)
+
+// Description is an Option setting a one-line description of the
+// Application, shown below the usage line of the top-level usage and in the
+// name of its documentation.
+func Description(description string) Option {
+	return func(a *Application) {
+		a.description = description
+	}
+}
+
+// About is an Option setting an extended description of the Application,
+// such as a paragraph explaining what it is for, shown before the commands
+// in the top-level usage and its documentation.
+func About(about string) Option {
+	return func(a *Application) {
+		a.about = about
+	}
+}
+
+// Footer is an Option setting text shown after the commands in the top-level
+// usage, such as "Run 'myapp help <command>' for details." or a homepage and
+// license.
+func Footer(footer string) Option {
+	return func(a *Application) {
+		a.footer = footer
+	}
+}
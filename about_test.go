@@ -0,0 +1,47 @@
+package cli
+
+import (
+	"bytes"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestAbout(t *testing.T) {
+	app := New("myapp", "0.0.1",
+		Description("Manage widgets."),
+		About("Widgets are stored in the cloud."),
+		Footer("Run 'myapp help <command>' for details."),
+	)
+	app.RuleFunc("build", "Build it.", "", func() {})
+
+	var buf bytes.Buffer
+	app.PrintUsage(&buf)
+	want := "Usage: myapp <cmd> [options] [<args>]\n" +
+		"\nManage widgets.\n\nWidgets are stored in the cloud.\n\n" +
+		"  help [<command>...]   Output this usage information.\n"
+	if !strings.HasPrefix(buf.String(), want) {
+		t.Errorf("header\nhave %q\nwant prefix %q", buf.String(), want)
+	}
+
+	want = "    -short              Print only the version number.\n" +
+		"  build                 Build it.\n\n" +
+		"Run 'myapp help <command>' for details.\n\n"
+	if !strings.HasSuffix(buf.String(), want) {
+		t.Errorf("footer\nhave %q\nwant suffix %q", buf.String(), want)
+	}
+
+	dir := t.TempDir()
+	err := app.GenerateDocs("man", dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data, _ := ioutil.ReadFile(filepath.Join(dir, "myapp.1"))
+	for _, want := range []string{".SH NAME\nmyapp \\- Manage widgets.\n", ".SH DESCRIPTION\nWidgets are stored in the cloud.\n"} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("myapp.1 does not contain %q\n%s", want, data)
+		}
+	}
+}
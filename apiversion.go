@@ -0,0 +1,120 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+)
+
+// apiVersionFlag is the global flag selecting the version of versioned
+// commands, see APIVersion.
+const apiVersionFlag = "api-version"
+
+// APIVersion is a RuleOption registering the command as version v of the
+// commands with its name, such that the same name may be registered once for
+// each version, such as for commands supporting both an old and a new
+// backend. The global -api-version flag, which may also be set from the
+// environment or the configuration file, selects the version to run,
+// defaulting to that given to DefaultAPIVersion or else the version
+// registered first. The help of the version registered first is shown for
+// the command, noting the versions that exist.
+func APIVersion(v string) RuleOption {
+	return func(r *rule) {
+		r.apiVersion = v
+	}
+}
+
+// DefaultAPIVersion is an Option setting the default of the -api-version
+// flag, see APIVersion.
+func DefaultAPIVersion(v string) Option {
+	return func(a *Application) {
+		a.apiVersion = v
+	}
+}
+
+// addVersion records the rule as a version of its command, returning whether
+// another version was registered before it. The caller must hold the lock
+// of the Application.
+func (a *Application) addVersion(r *rule) (bool, error) {
+	prev, ok := a.rules[r.name]
+	if ok && prev.apiVersion == "" || !ok && r.apiVersion == "" {
+		return false, nil
+	}
+
+	if r.apiVersion == "" {
+		return false, fmt.Errorf("rule: command %q requires an API version", r.name)
+	}
+
+	for _, v := range a.versions[r.name] {
+		if v.apiVersion == r.apiVersion {
+			return false, fmt.Errorf("rule: duplicate version %q of command %q", r.apiVersion, r.name)
+		}
+	}
+
+	if a.versions == nil {
+		a.versions = make(map[string][]*rule)
+	}
+
+	a.versions[r.name] = append(a.versions[r.name], r)
+	return ok, nil
+}
+
+// defineAPIVersion defines the -api-version flag if any command is
+// versioned.
+func (a *Application) defineAPIVersion(flags *flag.FlagSet) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	if len(a.versions) > 0 {
+		flags.String(apiVersionFlag, a.apiVersion, "Select the API version of versioned commands.")
+	}
+}
+
+// selectVersion returns the version of the command of the rule selected by
+// the -api-version flag, if it is versioned.
+func (a *Application) selectVersion(r *rule, flags *flag.FlagSet) (*rule, error) {
+	f := flags.Lookup(apiVersionFlag)
+	if f == nil || r.apiVersion == "" {
+		return r, nil
+	}
+
+	v := f.Value.String()
+	if v == "" {
+		return r, nil
+	}
+
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	for _, version := range a.versions[r.name] {
+		if version.apiVersion == v {
+			return version, nil
+		}
+	}
+
+	return nil, fmt.Errorf("unsupported API version %q, available: %s", v, strings.Join(a.apiVersions(r.name), ", "))
+}
+
+// apiVersions returns the versions of the named command in the order they
+// were registered. The caller must hold the lock of the Application.
+func (a *Application) apiVersions(name string) []string {
+	var versions []string
+	for _, r := range a.versions[name] {
+		versions = append(versions, r.apiVersion)
+	}
+
+	return versions
+}
+
+// versionNote returns the note documenting the versions of the command of
+// the rule, if it is versioned.
+func (a *Application) versionNote(r *rule) string {
+	if r.apiVersion == "" {
+		return ""
+	}
+
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	return fmt.Sprintf("API versions: %s, see -%s.", strings.Join(a.apiVersions(r.name), ", "), apiVersionFlag)
+}
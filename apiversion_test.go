@@ -0,0 +1,80 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+type runDeploy struct {
+	version string
+}
+
+func (c *runDeploy) Run(ctx context.Context, target string) {
+	fmt.Fprintf(Stdout(ctx), "%s %s\n", c.version, target)
+}
+
+func (c *runDeploy) String() string {
+	return "Deploy the target."
+}
+
+func TestAPIVersion(t *testing.T) {
+	app := New("myapp", "0.0.1")
+	for _, v := range []string{"v1", "v2"} {
+		err := app.Rule(&runDeploy{version: v}, "deploy", "<target>", APIVersion(v))
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		args   string
+		code   int
+		stdout string
+		stderr string
+	}{
+		{"deploy prod", 0, "v1 prod\n", ""},
+		{"-api-version v2 deploy prod", 0, "v2 prod\n", ""},
+		{"-api-version=v1 deploy prod", 0, "v1 prod\n", ""},
+		{"-api-version v3 deploy prod", ExitUsage, "", "Error: deploy: unsupported API version \"v3\", available: v1, v2\n"},
+	}
+
+	for _, tt := range tests {
+		var stdout, stderr bytes.Buffer
+		code := app.RunWithArgs(strings.Fields(tt.args), &stdout, &stderr)
+		if code != tt.code || stdout.String() != tt.stdout || stderr.String() != tt.stderr {
+			t.Errorf("%s\nhave %d %q %q\nwant %d %q %q", tt.args, code, stdout.String(), stderr.String(), tt.code, tt.stdout, tt.stderr)
+		}
+	}
+
+	var stdout, stderr bytes.Buffer
+	app.RunWithArgs([]string{"help", "deploy"}, &stdout, &stderr)
+	if want := "API versions: v1, v2, see -api-version."; !strings.Contains(stderr.String(), want) {
+		t.Errorf("help\nhave %s\nwant %s", stderr.String(), want)
+	}
+
+	errs := map[string]error{
+		"duplicate":   app.Rule(&runDeploy{}, "deploy", "<target>", APIVersion("v2")),
+		"unversioned": app.Rule(&runDeploy{}, "deploy", "<target>"),
+	}
+	want := map[string]string{
+		"duplicate":   `rule: duplicate version "v2" of command "deploy"`,
+		"unversioned": `rule: command "deploy" requires an API version`,
+	}
+	for name, err := range errs {
+		if fmt.Sprint(err) != want[name] {
+			t.Errorf("%s\nhave %v\nwant %v", name, err, want[name])
+		}
+	}
+
+	app = New("myapp", "0.0.1", DefaultAPIVersion("v2"))
+	app.Rule(&runDeploy{version: "v1"}, "deploy", "<target>", APIVersion("v1"))
+	app.Rule(&runDeploy{version: "v2"}, "deploy", "<target>", APIVersion("v2"))
+	stdout.Reset()
+	app.RunWithArgs([]string{"deploy", "prod"}, &stdout, &stderr)
+	if have, want := stdout.String(), "v2 prod\n"; have != want {
+		t.Errorf("default\nhave %q\nwant %q", have, want)
+	}
+}
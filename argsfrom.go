@@ -0,0 +1,167 @@
+package cli
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+)
+
+// argsFromFlag is the framework flag naming a source of extra arguments.
+const argsFromFlag = "args-from"
+
+var errArgsFromValue = fmt.Errorf("args-from: missing file name, use - for stdin")
+
+// ArgFiles is a RuleOption expanding the positional arguments of the command
+// that name files. An argument @path is replaced by the lines of the file at
+// path, one argument per line, and an argument - by the lines of stdin.
+// Blank lines are ignored. An argument beginning with @@ is passed on with
+// the first @ removed, such that @@me is the argument @me.
+func ArgFiles() RuleOption {
+	return func(r *rule) {
+		r.argFiles = true
+	}
+}
+
+// argsFrom removes the -args-from flag from the command arguments and returns
+// the remaining arguments along with those read from the named source. The
+// flag is ignored for commands that define a flag of the same name.
+//
+// This sidesteps the operating system limits on the length of the argument
+// list for commands that accept many arguments, in the manner of xargs.
+func argsFrom(ctx context.Context, r *rule, args []string) ([]string, []string, error) {
+	if r.options.Lookup(argsFromFlag) != nil {
+		return args, nil, nil
+	}
+
+	for i, arg := range args {
+		if arg == "--" {
+			break
+		}
+
+		name := strings.TrimLeft(arg, "-")
+		if name == arg || len(arg)-len(name) > 2 {
+			continue
+		}
+
+		var path string
+		rest := append([]string{}, args[:i]...)
+		if name == argsFromFlag {
+			if i+1 >= len(args) {
+				return nil, nil, errArgsFromValue
+			}
+
+			path = args[i+1]
+			rest = append(rest, args[i+2:]...)
+		} else if strings.HasPrefix(name, argsFromFlag+"=") {
+			path = name[len(argsFromFlag)+1:]
+			rest = append(rest, args[i+1:]...)
+		} else {
+			continue
+		}
+
+		extra, err := readArgsFrom(ctx, path)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		return rest, extra, nil
+	}
+
+	return args, nil, nil
+}
+
+// expandArgFiles expands the arguments naming files, see ArgFiles.
+func expandArgFiles(ctx context.Context, args []string) ([]string, error) {
+	var expanded []string
+	for _, arg := range args {
+		var path string
+		switch {
+		case arg == "-":
+			path = "-"
+		case strings.HasPrefix(arg, "@@"):
+			expanded = append(expanded, arg[1:])
+			continue
+		case strings.HasPrefix(arg, "@") && len(arg) > 1:
+			path = arg[1:]
+		default:
+			expanded = append(expanded, arg)
+			continue
+		}
+
+		lines, err := readArgLines(ctx, path)
+		if err != nil {
+			return nil, err
+		}
+
+		expanded = append(expanded, lines...)
+	}
+
+	return expanded, nil
+}
+
+// readArgLines reads the non-blank lines of path, or stdin if path is "-".
+func readArgLines(ctx context.Context, path string) ([]string, error) {
+	src := Stdin(ctx)
+	if path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		src = f
+	}
+
+	var lines []string
+	scanner := bufio.NewScanner(src)
+	scanner.Buffer(nil, 1<<20)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if strings.TrimSpace(line) != "" {
+			lines = append(lines, line)
+		}
+	}
+
+	return lines, scanner.Err()
+}
+
+// readArgsFrom reads the arguments stored in path, or stdin if path is "-".
+func readArgsFrom(ctx context.Context, path string) ([]string, error) {
+	src := Stdin(ctx)
+	if path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		src = f
+	}
+
+	data, err := ioutil.ReadAll(src)
+	if err != nil {
+		return nil, err
+	}
+
+	return splitArgsFrom(data), nil
+}
+
+// splitArgsFrom splits data into arguments. Arguments are NUL delimited if
+// data contains a NUL byte, as produced by find -print0, otherwise they are
+// delimited by whitespace.
+func splitArgsFrom(data []byte) []string {
+	if bytes.IndexByte(data, 0) < 0 {
+		return strings.Fields(string(data))
+	}
+
+	var args []string
+	for _, arg := range strings.Split(string(data), "\x00") {
+		if arg != "" {
+			args = append(args, arg)
+		}
+	}
+
+	return args
+}
@@ -0,0 +1,72 @@
+package cli
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestArgsFrom(t *testing.T) {
+	app := New("myapp", "0.0.1")
+	cmd := &runRecord{}
+	app.Rule(cmd, "record", "<a> <b>")
+
+	app.stdin = strings.NewReader("two\n")
+	code := app.Dispatch([]string{"record", "--args-from", "-", "-number", "3", "one"})
+	if code != 3 {
+		t.Errorf("exit code\nhave %d\nwant %d", code, 3)
+	}
+
+	if want := []string{"one", "two"}; !reflect.DeepEqual(cmd.have, want) {
+		t.Errorf("arguments\nhave %q\nwant %q", cmd.have, want)
+	}
+}
+
+func TestSplitArgsFrom(t *testing.T) {
+	tests := []struct {
+		data string
+		want []string
+	}{
+		{"a b\n\tc\n", []string{"a", "b", "c"}},
+		{"a b\x00c\n\x00\x00", []string{"a b", "c\n"}},
+	}
+
+	for _, tt := range tests {
+		have := splitArgsFrom([]byte(tt.data))
+		if !reflect.DeepEqual(have, tt.want) {
+			t.Errorf("%q\nhave %q\nwant %q", tt.data, have, tt.want)
+		}
+	}
+}
+
+func TestArgFiles(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "args")
+	err := ioutil.WriteFile(path, []byte("b c\n\n  d\r\n"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var have []string
+	app := New("myapp", "0.0.1")
+	app.RuleFunc("list", "List arguments.", "[<arg>...]", func(args ...string) {
+		have = args
+	}, ArgFiles())
+
+	app.stdin = strings.NewReader("e\nf\n")
+	code := app.Dispatch([]string{"list", "a", "@" + path, "@@g", "-"})
+	want := []string{"a", "b c", "  d", "@g", "e", "f"}
+	if code != 0 || !reflect.DeepEqual(have, want) {
+		t.Errorf("expanded\nhave %d %q\nwant %d %q", code, have, 0, want)
+	}
+
+	app.RuleFunc("plain", "List arguments.", "[<arg>...]", func(args ...string) {
+		have = args
+	})
+	app.Dispatch([]string{"plain", "@" + path, "-"})
+	want = []string{"@" + path, "-"}
+	if !reflect.DeepEqual(have, want) {
+		t.Errorf("unexpanded\nhave %q\nwant %q", have, want)
+	}
+}
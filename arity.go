@@ -0,0 +1,309 @@
+package cli
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// An Arg describes a positional argument of a command, see Arguments.
+type Arg struct {
+	// Name is the name of the argument, without angle brackets.
+	Name string
+	// Usage describes the argument in the help of the command.
+	Usage string
+	// Required is true if the argument must be given.
+	Required bool
+	// Variadic is true if the argument may be repeated.
+	Variadic bool
+	// Complete returns the values of the argument beginning with prefix for
+	// shell completion, if not nil.
+	Complete func(prefix string) []string
+	// Validate checks the values of the argument before the command runs,
+	// such as with IsFile or OneOf, failing with the first error.
+	Validate []Validator
+}
+
+// An argCount bounds the number of positional arguments of a command, see
+// MinArgs, MaxArgs and ExactArgs. A max of -1 is unbounded.
+type argCount struct {
+	min int
+	max int
+}
+
+// An argSpec is the structure of the arguments string of a rule, such as
+// "<src> <dst> [<extra>...]".
+type argSpec struct {
+	required []string
+	optional int
+	variadic bool
+}
+
+// Arguments is a RuleOption declaring the positional arguments of the
+// command, replacing the arguments string given to Rule, which is a shortcut
+// for arguments without descriptions. Fields may be bound to the arguments
+// by name with arg tags, such as `arg:"src"`.
+func Arguments(args ...Arg) RuleOption {
+	return func(r *rule) {
+		r.args = args
+		r.arguments = formatArgs(args)
+	}
+}
+
+// String formats the argument as in an arguments string, such as
+// "[<extra>...]".
+func (a Arg) String() string {
+	s := "<" + a.Name + ">"
+	if a.Variadic {
+		s += "..."
+	}
+
+	if !a.Required {
+		s = "[" + s + "]"
+	}
+
+	return s
+}
+
+// parseArgs parses an arguments string. Arguments within square brackets are
+// optional and an argument followed by an ellipsis may be repeated.
+func parseArgs(arguments string) []Arg {
+	var args []Arg
+	depth := 0
+	for _, token := range strings.Fields(arguments) {
+		name := strings.TrimSuffix(strings.Trim(token, "[]"), "...")
+		args = append(args, Arg{
+			Name:     strings.TrimSuffix(strings.TrimPrefix(name, "<"), ">"),
+			Required: depth == 0 && !strings.HasPrefix(token, "["),
+			Variadic: strings.Contains(token, "..."),
+		})
+
+		depth += strings.Count(token, "[") - strings.Count(token, "]")
+	}
+
+	return args
+}
+
+// formatArgs formats args as an arguments string.
+func formatArgs(args []Arg) string {
+	tokens := make([]string, len(args))
+	for i, arg := range args {
+		tokens[i] = arg.String()
+	}
+
+	return strings.Join(tokens, " ")
+}
+
+// parseArguments parses an arguments string into its structure.
+func parseArguments(arguments string) argSpec {
+	return specOf(parseArgs(arguments))
+}
+
+// specOf returns the structure of the arguments.
+func specOf(args []Arg) argSpec {
+	var spec argSpec
+	for _, arg := range args {
+		if arg.Required {
+			spec.required = append(spec.required, "<"+arg.Name+">")
+		} else {
+			spec.optional++
+		}
+
+		if arg.Variadic {
+			spec.variadic = true
+		}
+	}
+
+	return spec
+}
+
+// positional returns the positional arguments of the rule, as declared with
+// Arguments or parsed from its arguments string.
+func (r *rule) positional() []Arg {
+	if r.args != nil {
+		return r.args
+	}
+
+	return parseArgs(r.arguments)
+}
+
+// argumentUsages describes the arguments.
+func argumentUsages(args []Arg) []ArgumentUsage {
+	var usages []ArgumentUsage
+	for _, arg := range args {
+		usages = append(usages, ArgumentUsage{
+			Name:     "<" + arg.Name + ">",
+			Usage:    arg.Usage,
+			Optional: !arg.Required,
+			Repeated: arg.Variadic,
+		})
+	}
+
+	return usages
+}
+
+// MinArgs is a RuleOption requiring at least n positional arguments, failing
+// with an error such as "expected at least 1 argument: <files>..." rather
+// than running the command with zero values for the missing arguments.
+func MinArgs(n int) RuleOption {
+	return func(r *rule) {
+		if r.counts == nil {
+			r.counts = &argCount{max: -1}
+		}
+
+		r.counts.min = n
+	}
+}
+
+// MaxArgs is a RuleOption allowing at most n positional arguments, failing
+// with an error such as "expected at most 2 arguments: <src> [<dst>]". The
+// command fails to register if its Run method and arg fields accept fewer.
+func MaxArgs(n int) RuleOption {
+	return func(r *rule) {
+		if r.counts == nil {
+			r.counts = &argCount{}
+		}
+
+		r.counts.max = n
+	}
+}
+
+// ExactArgs is a RuleOption requiring exactly n positional arguments, failing
+// with an error such as "expected exactly 2 arguments: <src> <dst>".
+func ExactArgs(n int) RuleOption {
+	return func(r *rule) {
+		r.counts = &argCount{min: n, max: n}
+	}
+}
+
+// check returns an error if the number of args is out of bounds, naming the
+// arguments of the synopsis.
+func (c *argCount) check(args []string, synopsis string) error {
+	var msg string
+	switch {
+	case c.min == c.max && len(args) != c.min:
+		msg = "expected exactly " + plural(c.min, "argument")
+	case len(args) < c.min:
+		msg = "expected at least " + plural(c.min, "argument")
+	case c.max >= 0 && len(args) > c.max:
+		msg = "expected at most " + plural(c.max, "argument")
+	default:
+		return nil
+	}
+
+	if synopsis != "" {
+		msg += ": " + synopsis
+	}
+
+	return fmt.Errorf("%s", msg)
+}
+
+// validate returns an error if the bounds are inconsistent or exceed the n
+// arguments the command accepts, if limited.
+func (c *argCount) validate(n int, limited bool) error {
+	if c.max >= 0 && c.min > c.max {
+		return fmt.Errorf("rule: MinArgs %d exceeds MaxArgs %d", c.min, c.max)
+	}
+
+	bound := c.max
+	if bound < 0 {
+		bound = c.min
+	}
+
+	if limited && bound > n {
+		return fmt.Errorf("rule: argument count %d exceeds the %d arguments accepted by Run", bound, n)
+	}
+
+	return nil
+}
+
+// plural returns n and the noun, pluralized unless n is one.
+func plural(n int, noun string) string {
+	if n == 1 {
+		return "1 " + noun
+	}
+
+	return fmt.Sprintf("%d %ss", n, noun)
+}
+
+// StrictArgs is a RuleOption overriding whether the command fails when given
+// more positional arguments than it accepts, see Application.StrictArgs.
+func StrictArgs(enabled bool) RuleOption {
+	return func(r *rule) {
+		r.strict = &enabled
+	}
+}
+
+// StrictArgs makes commands fail when given more positional arguments than
+// their Run method and arg fields accept, such as for a synopsis of
+// "[<files>...]" with a Run method taking a single string, rather than
+// ignore the extra arguments. Commands may override it, see the StrictArgs
+// RuleOption.
+func (a *Application) StrictArgs(enabled bool) {
+	a.strict = enabled
+}
+
+// arity returns an error if args are too few or too many for the positional
+// arguments of the rule. Commands whose Run method accepts a slice of the
+// remaining arguments may be given any number beyond those required. If
+// strict is set, or the rule is strict, args must also not exceed the
+// arguments the command accepts. Bounds set by MinArgs, MaxArgs or ExactArgs
+// are checked first.
+func (r *rule) arity(args []string, strict bool) error {
+	if r.counts != nil {
+		err := r.counts.check(args, formatArgs(r.positional()))
+		if err != nil {
+			return err
+		}
+	}
+
+	spec := specOf(r.positional())
+	if len(args) < len(spec.required) {
+		return fmt.Errorf("missing argument %s", spec.required[len(args)])
+	}
+
+	max := len(spec.required) + spec.optional
+	if !spec.variadic && !r.slice && len(args) > max {
+		return fmt.Errorf("unexpected argument %s", args[max])
+	}
+
+	if r.strict != nil {
+		strict = *r.strict
+	}
+
+	if n, ok := r.accepted(); strict && ok && len(args) > n {
+		return fmt.Errorf("unexpected argument %s", args[n])
+	}
+
+	return nil
+}
+
+// accepted returns the number of positional arguments the command of the
+// rule accepts as parameters of its Run method or arg fields, or false if it
+// accepts any number.
+func (r *rule) accepted() (int, bool) {
+	return accepted(r.slice, len(r.converters), r.fields)
+}
+
+// accepted returns the number of positional arguments accepted by params
+// parameters and the arg fields, or false if a final slice parameter or
+// field accepts any number.
+func accepted(slice bool, params int, fields []field) (int, bool) {
+	if slice {
+		return 0, false
+	}
+
+	n := params
+	for _, f := range fields {
+		t := f.value.Type()
+		if t.Kind() == reflect.Slice && !convertible(t) {
+			return 0, false
+		}
+
+		if f.index >= n {
+			n = f.index + 1
+		}
+	}
+
+	return n, true
+}
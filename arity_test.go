@@ -0,0 +1,201 @@
+package cli
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type runCopy struct {
+	Src  string   `arg:"src"`
+	Dst  []string `arg:"dst"`
+	have []string
+}
+
+func TestParseArguments(t *testing.T) {
+	tests := map[string]argSpec{
+		"":                         {},
+		"<src> <dst>":              {required: []string{"<src>", "<dst>"}},
+		"<src> [<dst>]":            {required: []string{"<src>"}, optional: 1},
+		"[<a> <b>]":                {optional: 2},
+		"<file>...":                {required: []string{"<file>"}, variadic: true},
+		"<cmd> [<args>...]":        {required: []string{"<cmd>"}, optional: 1, variadic: true},
+		"<src> <dst> [<extra>]...": {required: []string{"<src>", "<dst>"}, optional: 1, variadic: true},
+	}
+
+	for arguments, want := range tests {
+		have := parseArguments(arguments)
+		if !reflect.DeepEqual(have, want) {
+			t.Errorf("parseArguments(%q)\nhave %+v\nwant %+v", arguments, have, want)
+		}
+	}
+}
+
+func TestArity(t *testing.T) {
+	app := New("myapp", "0.0.1")
+	app.Rule(&runRecord{}, "record", "<a> [<b>]")
+
+	tests := []struct {
+		args []string
+		code int
+	}{
+		{[]string{"record"}, ExitUsage},
+		{[]string{"record", "x"}, 0},
+		{[]string{"record", "x", "y"}, 0},
+		{[]string{"record", "x", "y", "z"}, ExitUsage},
+		{[]string{"version", "x"}, ExitUsage},
+	}
+
+	for _, tt := range tests {
+		var stdout, stderr bytes.Buffer
+		code := app.RunWithArgs(tt.args, &stdout, &stderr)
+		if code != tt.code {
+			t.Errorf("%q exit code\nhave %d\nwant %d\n%s", tt.args, code, tt.code, stderr.String())
+		}
+	}
+}
+
+func TestStrictArgs(t *testing.T) {
+	app := New("myapp", "0.0.1")
+	app.RuleFunc("one", "Take one.", "[<args>...]", func(arg string) {})
+	app.RuleFunc("lax", "Take one.", "[<args>...]", func(arg string) {}, StrictArgs(false))
+	app.RuleFunc("all", "Take all.", "[<args>...]", func(args []string) {})
+	app.Rule(&runCopy{}, "copy", "<src> <dst>...")
+
+	tests := []struct {
+		args   []string
+		strict bool
+		code   int
+	}{
+		{[]string{"one", "x", "y"}, false, 0},
+		{[]string{"one", "x", "y"}, true, ExitUsage},
+		{[]string{"one", "x"}, true, 0},
+		{[]string{"lax", "x", "y"}, true, 0},
+		{[]string{"all", "x", "y"}, true, 0},
+		{[]string{"copy", "x", "y", "z"}, true, 0},
+	}
+
+	for _, tt := range tests {
+		app.StrictArgs(tt.strict)
+		var stdout, stderr bytes.Buffer
+		code := app.RunWithArgs(tt.args, &stdout, &stderr)
+		if code != tt.code {
+			t.Errorf("%q strict=%t exit code\nhave %d\nwant %d\n%s", tt.args, tt.strict, code, tt.code, stderr.String())
+		}
+	}
+
+	var stdout, stderr bytes.Buffer
+	app.RunWithArgs([]string{"one", "x", "y"}, &stdout, &stderr)
+	want := "Error: one: unexpected argument y\n"
+	if !strings.HasPrefix(stderr.String(), want) {
+		t.Errorf("error\nhave %q\nwant prefix %q", stderr.String(), want)
+	}
+}
+
+func (r *runCopy) String() string {
+	return "Copy files."
+}
+
+func (r *runCopy) Run() {
+	r.have = append([]string{r.Src}, r.Dst...)
+}
+
+func TestArguments(t *testing.T) {
+	app := New("myapp", "0.0.1")
+	app.Completion("completion")
+	cmd := &runCopy{}
+	err := app.Rule(cmd, "copy", "", Arguments(
+		Arg{Name: "src", Usage: "The file to copy.", Required: true, Complete: func(prefix string) []string {
+			return []string{"a.txt", "b.txt"}
+		}},
+		Arg{Name: "dst", Usage: "The copies.", Variadic: true},
+	))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if have, want := app.rules["copy"].arguments, "<src> [<dst>...]"; have != want {
+		t.Errorf("arguments\nhave %q\nwant %q", have, want)
+	}
+
+	var stdout, stderr bytes.Buffer
+	code := app.RunWithArgs([]string{"copy", "x", "y", "z"}, &stdout, &stderr)
+	if want := []string{"x", "y", "z"}; code != 0 || !reflect.DeepEqual(cmd.have, want) {
+		t.Errorf("bind\nhave %d %q\nwant %d %q", code, cmd.have, 0, want)
+	}
+
+	code = app.RunWithArgs([]string{"copy"}, &stdout, &stderr)
+	if code != ExitUsage || !strings.HasPrefix(stderr.String(), "Error: copy: missing argument <src>\n") {
+		t.Errorf("arity\nhave %d %q", code, stderr.String())
+	}
+
+	stderr.Reset()
+	app.RunWithArgs([]string{"help", "copy"}, &stdout, &stderr)
+	want := "Arguments:\n  <src>   The file to copy.\n  <dst>   The copies.\n"
+	if !strings.Contains(stderr.String(), want) {
+		t.Errorf("help\nhave %q\nwant %q", stderr.String(), want)
+	}
+
+	stdout.Reset()
+	app.RunWithArgs([]string{completeCommand, "copy", "a"}, &stdout, &stderr)
+	if have := stdout.String(); have != "a.txt\n" {
+		t.Errorf("completion\nhave %q\nwant %q", have, "a.txt\n")
+	}
+
+	err = app.Rule(&runCopy{}, "move", "<from> <to>")
+	if err == nil {
+		t.Errorf("unknown argument\nhave %v", err)
+	}
+}
+
+func TestParseArgs(t *testing.T) {
+	have := parseArgs("<src> [<a> <b>] [<extra>...]")
+	want := []Arg{{Name: "src", Required: true}, {Name: "a"}, {Name: "b"}, {Name: "extra", Variadic: true}}
+	if !reflect.DeepEqual(have, want) {
+		t.Errorf("parseArgs\nhave %+v\nwant %+v", have, want)
+	}
+
+	if s := formatArgs(want); s != "<src> [<a>] [<b>] [<extra>...]" {
+		t.Errorf("formatArgs\nhave %q", s)
+	}
+}
+
+func TestArgCounts(t *testing.T) {
+	app := New("myapp", "0.0.1")
+	app.RuleFunc("copy", "Copy it.", "<src> <dst>", func(src, dst string) {}, ExactArgs(2))
+	app.RuleFunc("add", "Add them.", "<files>...", func(files []string) {}, MinArgs(1))
+	app.RuleFunc("pick", "Pick one.", "[<a>] [<b>]", func(a, b string) {}, MaxArgs(1))
+
+	tests := []struct {
+		args []string
+		code int
+		want string
+	}{
+		{[]string{"copy", "x"}, ExitUsage, "Error: copy: expected exactly 2 arguments: <src> <dst>\n"},
+		{[]string{"copy", "x", "y"}, 0, ""},
+		{[]string{"add"}, ExitUsage, "Error: add: expected at least 1 argument: <files>...\n"},
+		{[]string{"add", "x", "y", "z"}, 0, ""},
+		{[]string{"pick", "x", "y"}, ExitUsage, "Error: pick: expected at most 1 argument: [<a>] [<b>]\n"},
+		{[]string{"pick"}, 0, ""},
+	}
+
+	for _, tt := range tests {
+		var stdout, stderr bytes.Buffer
+		code := app.RunWithArgs(tt.args, &stdout, &stderr)
+		have := strings.SplitAfter(stderr.String(), "\n")[0]
+		if code != tt.code || have != tt.want {
+			t.Errorf("%q\nhave %d %q\nwant %d %q", tt.args, code, have, tt.code, tt.want)
+		}
+	}
+
+	err := app.RuleFunc("one", "Take one.", "<a> <b>", func(a string) {}, ExactArgs(2))
+	if err == nil {
+		t.Errorf("ExactArgs beyond Run\nhave %v\nwant error", err)
+	}
+
+	err = app.RuleFunc("none", "Take none.", "", func() {}, MinArgs(2), MaxArgs(1))
+	if err == nil {
+		t.Errorf("MinArgs beyond MaxArgs\nhave %v\nwant error", err)
+	}
+}
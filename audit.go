@@ -0,0 +1,98 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"os/user"
+	"time"
+)
+
+// An AuditRecord is an entry of the audit log of an Application, see
+// WithAuditLog.
+type AuditRecord struct {
+	Time    time.Time `json:"time"`
+	User    string    `json:"user"`
+	Command string    `json:"command"`
+	// Args are the arguments following the command name, with the values of
+	// secret flags redacted.
+	Args []string `json:"args"`
+	Code int      `json:"code"`
+	// Duration is the duration of the invocation in milliseconds.
+	Duration int64  `json:"duration_ms"`
+	Error    string `json:"error,omitempty"`
+}
+
+// An auditLog is a Recorder writing an AuditRecord of each invocation as a
+// line of JSON with write.
+type auditLog struct {
+	write func(line []byte) error
+}
+
+// WithAuditLog is an Option appending an AuditRecord of each invocation of
+// a command to the file at path as a line of JSON, creating the file if
+// necessary. The records are written once each command completes, as for
+// Instrument, so invocations failing before the command runs, such as with
+// invalid flags, are not recorded. Failures to write a record are printed
+// but do not change the exit code.
+func WithAuditLog(path string) Option {
+	return func(a *Application) {
+		a.Instrument(auditLog{func(line []byte) error {
+			f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+			if err != nil {
+				return err
+			}
+
+			_, err = f.Write(line)
+			if cerr := f.Close(); err == nil {
+				err = cerr
+			}
+
+			return err
+		}})
+	}
+}
+
+// WithAuditSyslog is an Option sending an AuditRecord of each invocation to
+// the system log with the given tag, as for WithAuditLog. The system log is
+// not supported on Windows and Plan 9.
+func WithAuditSyslog(tag string) Option {
+	return func(a *Application) {
+		a.Instrument(auditLog{func(line []byte) error {
+			return writeSyslog(tag, line)
+		}})
+	}
+}
+
+// Record implements the Recorder interface.
+func (l auditLog) Record(ctx context.Context, e *Event) {
+	record := AuditRecord{
+		Time:     e.Start,
+		User:     currentUser(),
+		Command:  e.Command,
+		Args:     e.Args,
+		Code:     e.Code,
+		Duration: e.Duration.Milliseconds(),
+	}
+	if e.Err != nil {
+		record.Error = e.Err.Error()
+	}
+
+	line, err := json.Marshal(record)
+	if err == nil {
+		err = l.write(append(line, '\n'))
+	}
+	if err != nil {
+		ctxInvocation(ctx).app.errorf(Stderr(ctx), "audit: %v", err)
+	}
+}
+
+// currentUser returns the name of the user running the process.
+func currentUser() string {
+	u, err := user.Current()
+	if err != nil {
+		return os.Getenv("USER")
+	}
+
+	return u.Username
+}
@@ -0,0 +1,10 @@
+//go:build windows || plan9 || js || wasip1
+
+package cli
+
+import "errors"
+
+// writeSyslog is not supported on this platform.
+func writeSyslog(tag string, line []byte) error {
+	return errors.New("system log not supported on this platform")
+}
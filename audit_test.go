@@ -0,0 +1,54 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestAuditLog(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	app := New("myapp", "0.0.1", WithAuditLog(path))
+	app.Rule(&runTrace{}, "copy", "<src> <dst>...")
+	app.Rule(&runRecord{}, "record", "[<a>] [<b>]")
+
+	var stderr bytes.Buffer
+	app.RunWithArgs([]string{"copy", "-token", "hunter2", "a", "b"}, &stderr, &stderr)
+	app.RunWithArgs([]string{"record", "-number", "3"}, &stderr, &stderr)
+	app.RunWithArgs([]string{"record", "-bogus"}, &stderr, &stderr)
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("records\nhave %d\nwant %d\n%s", len(lines), 2, data)
+	}
+
+	want := []AuditRecord{
+		{User: currentUser(), Command: "copy", Args: []string{"-token", "********", "a", "b"}},
+		{User: currentUser(), Command: "record", Args: []string{"-number", "3"}, Code: 3},
+	}
+	for i, line := range lines {
+		var have AuditRecord
+		err := json.Unmarshal([]byte(line), &have)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if have.Time.IsZero() {
+			t.Errorf("record %d has no time", i)
+		}
+
+		have.Time, have.Duration = want[i].Time, 0
+		if !reflect.DeepEqual(have, want[i]) {
+			t.Errorf("record %d\nhave %+v\nwant %+v", i, have, want[i])
+		}
+	}
+}
@@ -0,0 +1,17 @@
+//go:build !windows && !plan9 && !js && !wasip1
+
+package cli
+
+import "log/syslog"
+
+// writeSyslog writes the line to the system log with the tag.
+func writeSyslog(tag string, line []byte) error {
+	w, err := syslog.New(syslog.LOG_NOTICE|syslog.LOG_AUTH, tag)
+	if err != nil {
+		return err
+	}
+	defer w.Close()
+
+	_, err = w.Write(line)
+	return err
+}
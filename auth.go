@@ -0,0 +1,68 @@
+package cli
+
+import (
+	"context"
+	"time"
+)
+
+// refreshSkew is how long before expiry Credentials are refreshed.
+const refreshSkew = time.Minute
+
+type credentialsContextKey struct{}
+
+// RequireAuth is a RuleOption marking the command as requiring the user to
+// be logged in, see Login. Before the command runs, expired Credentials are
+// refreshed with the function given to RefreshCredentials and the fresh
+// Credentials are made available through Identity.
+func RequireAuth() RuleOption {
+	return func(r *rule) {
+		r.auth = true
+	}
+}
+
+// RefreshCredentials sets the function called to refresh Credentials that
+// have expired, or are about to, before running commands that RequireAuth.
+// The refreshed Credentials are stored for later invocations.
+func (a *Application) RefreshCredentials(fn func(ctx context.Context, c *Credentials) (*Credentials, error)) {
+	a.refresh = fn
+}
+
+// expired reports whether the Credentials expire within d of now.
+func (c *Credentials) expired(now time.Time, d time.Duration) bool {
+	return !c.Expiry.IsZero() && now.Add(d).After(c.Expiry)
+}
+
+// authenticate loads the Credentials for a rule that requires them,
+// refreshing them if needed, and returns a context carrying them.
+func (a *Application) authenticate(ctx context.Context, r *rule) (context.Context, error) {
+	if !r.auth {
+		return ctx, nil
+	}
+
+	if a.store == nil {
+		return ctx, ErrNoCredentials
+	}
+
+	creds, err := a.store.Load()
+	if err != nil {
+		return ctx, err
+	}
+
+	if creds.expired(time.Now(), refreshSkew) {
+		if a.refresh == nil {
+			return ctx, ErrCredentialsExpired
+		}
+
+		creds, err = a.refresh(ctx, creds)
+		if err != nil {
+			return ctx, err
+		}
+
+		err = a.store.Save(creds)
+		if err != nil {
+			return ctx, err
+		}
+	}
+
+	return context.WithValue(ctx, credentialsContextKey{}, creds), nil
+}
@@ -0,0 +1,52 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestRequireAuthRefresh(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cli-auth")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	app := New("myapp", "0.0.1")
+	store := FileStore(filepath.Join(dir, "credentials.json"))
+	app.Login(store, nil)
+	app.stderr = &bytes.Buffer{}
+
+	whoami := &runWhoami{}
+	app.Rule(whoami, "whoami", "", RequireAuth())
+
+	code := app.Dispatch([]string{"whoami"})
+	if code != 1 {
+		t.Errorf("logged out\nhave %d\nwant %d", code, 1)
+	}
+
+	store.Save(&Credentials{Username: "alice", Secret: "old", Expiry: time.Now()})
+	code = app.Dispatch([]string{"whoami"})
+	if code != 1 {
+		t.Errorf("expired\nhave %d\nwant %d", code, 1)
+	}
+
+	app.RefreshCredentials(func(ctx context.Context, c *Credentials) (*Credentials, error) {
+		return &Credentials{Username: c.Username, Secret: "new", Expiry: time.Now().Add(time.Hour)}, nil
+	})
+
+	code = app.Dispatch([]string{"whoami"})
+	if code != 0 || whoami.have == nil || whoami.have.Secret != "new" {
+		t.Errorf("refreshed\nhave %d %v\nwant %d %s", code, whoami.have, 0, "new")
+	}
+
+	stored, _ := store.Load()
+	if stored.Secret != "new" {
+		t.Errorf("stored\nhave %s\nwant %s", stored.Secret, "new")
+	}
+}
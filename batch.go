@@ -0,0 +1,248 @@
+package cli
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+type commandBatch struct {
+	app       *Application
+	name      string
+	parallel  *int
+	keepGoing *bool
+}
+
+// Batch registers a command with the given name that reads invocations of
+// other commands from a file, or stdin, one per line, and dispatches to each
+// of them in turn. Lines are split into arguments with SplitArgs. Empty lines
+// and lines beginning with # are ignored.
+//
+// The -parallel flag runs up to the given number of invocations concurrently.
+// The output of concurrent invocations written to the streams returned by
+// Stdout and Stderr is buffered and written in input order once each
+// invocation completes. Each concurrent invocation runs on a copy of its
+// command, with its own flags, so the command must not share mutable state
+// between copies. Commands registered with RuleFunc or defining flags with
+// options such as FanOut cannot be copied, and their invocations are run
+// one at a time.
+//
+// The batch stops at the first invocation exiting with a non-zero exit code
+// unless the -keep-going flag is set. The exit code of the batch is that of
+// the first invocation to fail, or zero.
+func (a *Application) Batch(name string) error {
+	return a.Rule(&commandBatch{app: a, name: name}, name, "[<file>]", Local())
+}
+
+func (c *commandBatch) Flags(flags *flag.FlagSet) {
+	c.parallel = flags.Int("parallel", 1, "Number of invocations to run concurrently.")
+	c.keepGoing = flags.Bool("keep-going", false, "Continue after an invocation fails.")
+}
+
+func (c *commandBatch) Run(ctx context.Context, file string) int {
+	invocations, err := c.read(ctx, file)
+	if err != nil {
+		c.app.errorf(Stderr(ctx), "%s: %v", c.name, err)
+		return 1
+	}
+
+	if *c.parallel > 1 {
+		return c.runParallel(ctx, invocations)
+	}
+
+	code := 0
+	for _, args := range invocations {
+		if ctx.Err() != nil {
+			break
+		}
+
+		rv := c.app.dispatch(ctx, args)
+		if rv != 0 && code == 0 {
+			code = rv
+			if !*c.keepGoing {
+				break
+			}
+		}
+	}
+
+	return code
+}
+
+func (c *commandBatch) String() string {
+	return "Run the commands read from a file, one per line."
+}
+
+// runParallel dispatches to the invocations with bounded concurrency.
+func (c *commandBatch) runParallel(ctx context.Context, invocations [][]string) int {
+	type result struct {
+		stdout bytes.Buffer
+		stderr bytes.Buffer
+		code   int
+		done   chan struct{}
+	}
+
+	results := make([]*result, len(invocations))
+	for i := range results {
+		results[i] = &result{done: make(chan struct{})}
+	}
+
+	// Launch the invocations, stopping early after a failure.
+	var mu sync.Mutex
+	failed := false
+	go func() {
+		sem := make(chan struct{}, *c.parallel)
+		for i, args := range invocations {
+			sem <- struct{}{}
+
+			mu.Lock()
+			skip := failed && !*c.keepGoing || ctx.Err() != nil
+			mu.Unlock()
+
+			r := results[i]
+			if skip {
+				<-sem
+				close(r.done)
+				continue
+			}
+
+			go func(args []string) {
+				defer close(r.done)
+				defer func() { <-sem }()
+
+				s := streams{Stdin(ctx), &r.stdout, &r.stderr}
+				r.code = c.app.dispatch(withStreams(withForking(ctx), s), args)
+				if r.code != 0 {
+					mu.Lock()
+					failed = true
+					mu.Unlock()
+				}
+			}(args)
+		}
+	}()
+
+	// Write the output in input order as the invocations complete.
+	code := 0
+	for _, r := range results {
+		<-r.done
+		io.Copy(Stdout(ctx), &r.stdout)
+		io.Copy(Stderr(ctx), &r.stderr)
+		if r.code != 0 && code == 0 {
+			code = r.code
+		}
+	}
+
+	return code
+}
+
+type forkingContextKey struct{}
+
+// withForking returns a copy of ctx under which dispatch runs each command
+// on a copy of its rule rather than waiting for the rule's lock.
+func withForking(ctx context.Context) context.Context {
+	return context.WithValue(ctx, forkingContextKey{}, true)
+}
+
+// forking reports whether ctx was returned by withForking.
+func forking(ctx context.Context) bool {
+	ok, _ := ctx.Value(forkingContextKey{}).(bool)
+	return ok
+}
+
+// fork returns a copy of the rule bound to a copy of its command, or the rule
+// itself if the command cannot be copied. The command must be loaded.
+func (r *rule) fork() *rule {
+	v := reflect.ValueOf(r.command)
+	if r.direct != nil || len(r.define) > 0 || v.Kind() != reflect.Ptr || v.Elem().Kind() != reflect.Struct {
+		return r
+	}
+	if _, ok := r.command.(*funcCommand); ok {
+		return r
+	}
+
+	clone := reflect.New(v.Elem().Type())
+	clone.Elem().Set(v.Elem())
+
+	f := &rule{
+		name:       r.name,
+		arguments:  r.arguments,
+		args:       r.args,
+		summary:    r.summary,
+		limit:      r.limit,
+		grace:      r.grace,
+		timeout:    r.timeout,
+		auth:       r.auth,
+		privilege:  r.privilege,
+		hidden:     r.hidden,
+		category:   r.category,
+		parseMode:  r.parseMode,
+		strict:     r.strict,
+		counts:     r.counts,
+		examples:   r.examples,
+		argFiles:   r.argFiles,
+		local:      r.local,
+		cache:      r.cache,
+		resources:  r.resources,
+		apiVersion: r.apiVersion,
+		guards:     r.guards,
+		gate:       r.gate,
+		completers: r.completers,
+		deprecated: r.deprecated,
+		middleware: r.middleware,
+		env:        r.env,
+		groups:     r.groups,
+		original:   r,
+	}
+	if r.retry != nil {
+		p := *r.retry
+		f.retry = &p
+	}
+
+	if f.bind(clone.Interface().(command)) != nil {
+		return r
+	}
+
+	return f
+}
+
+// read returns the arguments of each invocation listed in file, or stdin if
+// file is empty or "-".
+func (c *commandBatch) read(ctx context.Context, file string) ([][]string, error) {
+	src := Stdin(ctx)
+	if file != "" && file != "-" {
+		f, err := os.Open(file)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		src = f
+	}
+
+	var invocations [][]string
+	scanner := bufio.NewScanner(src)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		args, err := SplitArgs(line)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", n, err)
+		}
+
+		if args[0] == c.name {
+			return nil, fmt.Errorf("line %d: %s may not be nested", n, c.name)
+		}
+
+		invocations = append(invocations, args)
+	}
+
+	return invocations, scanner.Err()
+}
@@ -0,0 +1,118 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type runEcho struct {
+	*NullFlags
+}
+
+func TestBatchParallel(t *testing.T) {
+	app := New("myapp", "0.0.1")
+	app.Rule(&runEcho{}, "echo", "<word>")
+	app.Rule(&runEcho{}, "echo2", "<word>")
+	err := app.Batch("run")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var stdout bytes.Buffer
+	app.stdin = strings.NewReader("echo a\n# comment\n\necho2 'b c'\nversion\necho fail\necho d\n")
+	app.stdout = &stdout
+	code := app.Dispatch([]string{"run", "-parallel", "3"})
+	if code != 3 {
+		t.Errorf("exit code\nhave %d\nwant %d", code, 3)
+	}
+
+	have := stdout.String()
+	if !strings.HasPrefix(have, "a\nb c\nmyapp v0.0.1\nfail\n") {
+		t.Errorf("output\nhave %q", have)
+	}
+}
+
+type runRendezvous struct {
+	n    int
+	wait *sync.WaitGroup
+}
+
+func TestBatchParallelSameCommand(t *testing.T) {
+	var wg sync.WaitGroup
+	wg.Add(2)
+	app := New("myapp", "0.0.1")
+	app.Rule(&runRendezvous{wait: &wg}, "meet", "")
+	app.Batch("run")
+
+	var stdout bytes.Buffer
+	app.stdin = strings.NewReader("meet -n 1\nmeet -n 2\n")
+	app.stdout = &stdout
+	code := app.Dispatch([]string{"run", "-parallel", "2"})
+	if code != 0 {
+		t.Errorf("exit code\nhave %d\nwant %d", code, 0)
+	}
+
+	have := stdout.String()
+	want := "1\n2\n"
+	if have != want {
+		t.Errorf("output\nhave %q\nwant %q", have, want)
+	}
+}
+
+func TestBatchNested(t *testing.T) {
+	app := New("myapp", "0.0.1")
+	app.Batch("run")
+	app.stdin = strings.NewReader("run\n")
+	app.stderr = &bytes.Buffer{}
+	code := app.Dispatch([]string{"run"})
+	if code != 1 {
+		t.Errorf("exit code\nhave %d\nwant %d", code, 1)
+	}
+}
+
+func (c *runEcho) Run(ctx context.Context, word string) int {
+	fmt.Fprintln(Stdout(ctx), word)
+	if word == "fail" {
+		return 3
+	}
+
+	return 0
+}
+
+func (c *runEcho) String() string {
+	return "echo a word"
+}
+
+func (c *runRendezvous) Flags(flags *flag.FlagSet) {
+	flags.IntVar(&c.n, "n", 0, "number to print")
+}
+
+// Run waits for every other invocation to start, which it never would if
+// the invocations were run one at a time.
+func (c *runRendezvous) Run(ctx context.Context) int {
+	c.wait.Done()
+	done := make(chan struct{})
+	go func() {
+		c.wait.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		return 1
+	}
+
+	fmt.Fprintln(Stdout(ctx), c.n)
+	return 0
+}
+
+func (c *runRendezvous) String() string {
+	return "meet the other invocations"
+}
@@ -0,0 +1,86 @@
+package cli
+
+import (
+	"encoding"
+	"flag"
+	"fmt"
+	"reflect"
+	"time"
+)
+
+// Bind defines a flag on flags for each exported field of the struct pointed
+// to by v, such that parsing flags sets the fields, for options structs
+// shared with configuration files or other interfaces. The current values of
+// the fields are the defaults of the flags. A field with a cli tag is defined
+// as it would be on a command, see Rule, while other fields are named after
+// the field in kebab case, such that DryRun is -dry-run, without usage.
+// Fields tagged cli:"-" and untagged fields of unsupported types are
+// skipped. Fields of embedded structs are included.
+func Bind(flags *flag.FlagSet, v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("bind: %T is not a pointer to a struct", v)
+	}
+
+	return bindStruct(flags, rv.Elem())
+}
+
+// bindStruct implements Bind for the struct v.
+func bindStruct(flags *flag.FlagSet, v reflect.Value) error {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		sf, fv := t.Field(i), v.Field(i)
+		tag, tagged := sf.Tag.Lookup("cli")
+		if tag == "-" {
+			continue
+		}
+
+		if sf.Anonymous && !tagged {
+			for fv.Kind() == reflect.Ptr && !fv.IsNil() {
+				fv = fv.Elem()
+			}
+
+			if fv.Kind() == reflect.Struct {
+				err := bindStruct(flags, fv)
+				if err != nil {
+					return err
+				}
+			}
+
+			continue
+		}
+
+		if !fv.CanSet() {
+			continue
+		}
+
+		if !tagged {
+			if !bindable(fv.Addr().Interface()) {
+				continue
+			}
+
+			tag = kebab(sf.Name)
+		}
+
+		err := declareFlag(flags, fv, tag)
+		if err != nil {
+			return fmt.Errorf("bind: field %s: %v", sf.Name, err)
+		}
+	}
+
+	return nil
+}
+
+// bindable reports whether p is a pointer to a value of a type supported by
+// declareFlag.
+func bindable(p interface{}) bool {
+	switch p := p.(type) {
+	case *string, *bool, *time.Duration, *int, *int64, *uint, *uint64, *float64, flag.Value:
+		return true
+	case encoding.TextUnmarshaler:
+		_, ok := p.(encoding.TextMarshaler)
+		return ok
+	}
+
+	return false
+}
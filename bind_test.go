@@ -0,0 +1,56 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+)
+
+type bindCommon struct {
+	Verbose bool
+}
+
+type bindOptions struct {
+	bindCommon
+	Name     string        `json:"name" cli:"name,Name of the server."`
+	Port     int           `json:"port"`
+	DryRun   bool          `json:"dry_run"`
+	Timeout  time.Duration `json:"timeout"`
+	Addr     net.IP        `json:"addr"`
+	Secret   string        `cli:"-"`
+	Tags     []string      `json:"tags"`
+	internal int
+}
+
+func TestBind(t *testing.T) {
+	opts := &bindOptions{Port: 8080, Timeout: time.Second}
+	flags := flag.NewFlagSet("serve", flag.ContinueOnError)
+	err := Bind(flags, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var names []string
+	flags.VisitAll(func(f *flag.Flag) { names = append(names, f.Name+"="+f.DefValue) })
+	want := "[addr= dry-run=false name= port=8080 timeout=1s verbose=false]"
+	if fmt.Sprint(names) != want {
+		t.Errorf("flags\nhave %v\nwant %v", names, want)
+	}
+
+	err = flags.Parse([]string{"-name", "api", "-port", "9000", "-dry-run", "-verbose", "-addr", "127.0.0.1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	have := fmt.Sprintf("%s %d %t %t %v %v", opts.Name, opts.Port, opts.DryRun, opts.Verbose, opts.Timeout, opts.Addr)
+	if want := "api 9000 true true 1s 127.0.0.1"; have != want {
+		t.Errorf("options\nhave %s\nwant %s", have, want)
+	}
+
+	err = Bind(flags, *opts)
+	if err == nil || err.Error() != "bind: cli.bindOptions is not a pointer to a struct" {
+		t.Errorf("error\nhave %v\nwant %v", err, "bind: cli.bindOptions is not a pointer to a struct")
+	}
+}
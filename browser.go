@@ -0,0 +1,59 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+)
+
+type commandDocs struct {
+	*NullFlags
+	url string
+}
+
+// OpenURL opens url in the web browser of the user, such as during a login
+// flow or to show documentation. The browser named by the BROWSER environment
+// variable is preferred over the default of the platform. If the -no-browser
+// flag was given before the command name, see WithNoBrowserFlag, or no
+// browser can be started, the URL is printed to the standard error of the
+// invocation for the user to open themselves instead.
+func OpenURL(ctx context.Context, url string) error {
+	inv, ok := ctx.Value(invocationContextKey{}).(*invocation)
+	if !ok || !inv.noBrowser {
+		argv := browserCommand(url)
+		if browser := os.Getenv("BROWSER"); browser != "" {
+			argv = []string{browser, url}
+		}
+
+		if argv != nil {
+			cmd := exec.Command(argv[0], argv[1:]...)
+			if cmd.Start() == nil {
+				go cmd.Wait()
+				return nil
+			}
+		}
+	}
+
+	_, err := fmt.Fprintf(Stderr(ctx), "%s\n\n  %s\n\n", ctxMessages(ctx).OpenInBrowser, url)
+	return err
+}
+
+// Docs registers a command with the given name that opens the documentation
+// of the Application at url in the web browser, see OpenURL.
+func (a *Application) Docs(name, url string) error {
+	return a.Rule(&commandDocs{url: url}, name, "", Local())
+}
+
+func (c *commandDocs) Run(ctx context.Context) int {
+	err := OpenURL(ctx, c.url)
+	if err != nil {
+		return 1
+	}
+
+	return 0
+}
+
+func (c *commandDocs) String() string {
+	return "Open the documentation in a web browser."
+}
@@ -0,0 +1,15 @@
+//go:build !darwin && !windows
+
+package cli
+
+import "os"
+
+// browserCommand returns the command opening url in the default browser, or
+// nil if there is no graphical session, such as over SSH.
+func browserCommand(url string) []string {
+	if os.Getenv("DISPLAY") == "" && os.Getenv("WAYLAND_DISPLAY") == "" {
+		return nil
+	}
+
+	return []string{"xdg-open", url}
+}
@@ -0,0 +1,23 @@
+package cli
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestDocsNoBrowser(t *testing.T) {
+	var stderr bytes.Buffer
+	app := New("myapp", "0.0.1", WithNoBrowserFlag())
+	app.stderr = &stderr
+	app.Docs("docs", "https://example.com/docs")
+
+	code := app.Dispatch([]string{"-no-browser", "docs"})
+	if code != 0 {
+		t.Fatalf("exit code\nhave %d\nwant %d", code, 0)
+	}
+
+	if !strings.Contains(stderr.String(), "  https://example.com/docs\n") {
+		t.Errorf("stderr\nhave %q\nwant the URL", stderr.String())
+	}
+}
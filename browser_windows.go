@@ -0,0 +1,6 @@
+package cli
+
+// browserCommand returns the command opening url in the default browser.
+func browserCommand(url string) []string {
+	return []string{"rundll32", "url.dll,FileProtocolHandler", url}
+}
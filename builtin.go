@@ -0,0 +1,88 @@
+package cli
+
+// A BuiltinPlacement places the built-in commands in the usage, see
+// SetBuiltinPlacement.
+type BuiltinPlacement int
+
+const (
+	// BuiltinInline lists the built-in commands with the others, in the
+	// order in which they were registered or alphabetically, see SortUsage.
+	BuiltinInline BuiltinPlacement = iota
+	// BuiltinFirst lists the built-in commands before the others.
+	BuiltinFirst
+	// BuiltinLast lists the built-in commands after the others.
+	BuiltinLast
+	// BuiltinHidden omits the built-in commands from the usage. They may
+	// still be run.
+	BuiltinHidden
+)
+
+// OverrideHelp replaces the built-in help command with cmd, which is given
+// the names of the command to describe as its arguments. The -h and -help
+// flags still print the usage or help of a command.
+func (a *Application) OverrideHelp(cmd command, options ...RuleOption) error {
+	return a.override("help", "[<command>...]", cmd, options)
+}
+
+// OverrideVersion replaces the built-in version command, which the -version
+// flag is short for, with cmd.
+func (a *Application) OverrideVersion(cmd command, options ...RuleOption) error {
+	return a.override("version", "", cmd, options)
+}
+
+// SetBuiltinPlacement sets where the built-in commands, such as help and
+// version, are listed in the usage. By default they are listed inline.
+func (a *Application) SetBuiltinPlacement(p BuiltinPlacement) {
+	a.placement = p
+}
+
+// override registers the command under the name of a built-in command,
+// replacing it.
+func (a *Application) override(name, arguments string, cmd command, options []RuleOption) error {
+	r := &rule{name: name, arguments: arguments}
+	for _, option := range options {
+		option(r)
+	}
+
+	err := r.bind(cmd)
+	if err != nil {
+		return err
+	}
+
+	return a.add(r, true)
+}
+
+// builtin reports whether the named command is built in.
+func (a *Application) builtin(name string) bool {
+	switch name {
+	case "help", "version":
+		return !a.noDefaults
+	case "update":
+		return a.update != nil
+	}
+
+	return name == a.settings
+}
+
+// place moves the built-in commands among the names according to the
+// BuiltinPlacement, preserving the order of the others.
+func (a *Application) place(names []string) []string {
+	if a.placement != BuiltinFirst && a.placement != BuiltinLast {
+		return names
+	}
+
+	var builtin, other []string
+	for _, name := range names {
+		if a.builtin(name) {
+			builtin = append(builtin, name)
+		} else {
+			other = append(other, name)
+		}
+	}
+
+	if a.placement == BuiltinFirst {
+		return append(builtin, other...)
+	}
+
+	return append(other, builtin...)
+}
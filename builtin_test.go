@@ -0,0 +1,80 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+type commandBrief struct {
+	NullFlags
+}
+
+func (c *commandBrief) Run(ctx context.Context, names []string) {
+	fmt.Fprintf(Stdout(ctx), "brief help for %s\n", strings.Join(names, " "))
+}
+
+func (c *commandBrief) String() string {
+	return "Show brief help."
+}
+
+func TestOverrideHelp(t *testing.T) {
+	app := New("myapp", "0.0.1")
+	err := app.OverrideHelp(&commandBrief{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	code := app.RunWithArgs([]string{"help", "version"}, &stdout, &stderr)
+	if want := "brief help for version\n"; code != 0 || stdout.String() != want {
+		t.Errorf("help\nhave %d %q\nwant %d %q", code, stdout.String(), 0, want)
+	}
+
+	if have := app.category("help"); have != app.messages.BuiltinCommands {
+		t.Errorf("category\nhave %q\nwant %q", have, app.messages.BuiltinCommands)
+	}
+}
+
+func TestBuiltinPlacement(t *testing.T) {
+	app := New("myapp", "0.0.1")
+	app.RuleFunc("build", "Build it.", "", func() {})
+	app.RuleFunc("test", "Test it.", "", func() {})
+
+	tests := []struct {
+		placement BuiltinPlacement
+		want      []string
+	}{
+		{BuiltinInline, []string{"help", "version", "build", "test"}},
+		{BuiltinFirst, []string{"help", "version", "build", "test"}},
+		{BuiltinLast, []string{"build", "test", "help", "version"}},
+		{BuiltinHidden, []string{"build", "test"}},
+	}
+
+	for _, tt := range tests {
+		app.SetBuiltinPlacement(tt.placement)
+		var buf bytes.Buffer
+		app.PrintUsage(&buf)
+		var have []string
+		for _, line := range strings.Split(buf.String(), "\n") {
+			if fields := strings.Fields(line); strings.HasPrefix(line, "  ") && !strings.HasPrefix(fields[0], "-") {
+				have = append(have, fields[0])
+			}
+		}
+
+		if strings.Join(have, " ") != strings.Join(tt.want, " ") {
+			t.Errorf("placement %d\nhave %q\nwant %q", tt.placement, have, tt.want)
+		}
+	}
+
+	app.SortUsage(true)
+	app.SetBuiltinPlacement(BuiltinFirst)
+	app.RuleFunc("audit", "Audit it.", "", func() {}, Category("Security"))
+	var buf bytes.Buffer
+	app.PrintUsage(&buf)
+	if i, j := strings.Index(buf.String(), "help"), strings.Index(buf.String(), "audit"); i < 0 || i > j {
+		t.Errorf("built-in commands not first\n%s", buf.String())
+	}
+}
@@ -0,0 +1,133 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// A resultCache caches the output and exit code of a command, see Cache.
+type resultCache struct {
+	ttl   time.Duration
+	flags []string
+	dir   string
+}
+
+// A cachedResult is a cached run of a command.
+type cachedResult struct {
+	Code   int    `json:"code"`
+	Stdout []byte `json:"stdout"`
+}
+
+// Cache is a RuleOption caching the standard output and exit code of the
+// command for ttl, for expensive commands that are idempotent. Runs are keyed
+// by the positional arguments and the values of the named flags, and a
+// cached run younger than ttl is replayed without running the command unless
+// the -no-cache flag was given before the command name, see
+// WithNoCacheFlag. Results are kept under the CacheDir of the Application
+// unless another directory is given with CacheIn.
+//
+// Only output written to Stdout, or given to the command by SetIO, is
+// cached. Runs that fail with an error, are interrupted or are dry runs are
+// not cached. Failures to read or write the cache are ignored.
+func Cache(ttl time.Duration, flags ...string) RuleOption {
+	return func(r *rule) {
+		if r.cache == nil {
+			r.cache = &resultCache{}
+			r.middleware = append(r.middleware, r.cache.middleware)
+		}
+
+		r.cache.ttl = ttl
+		r.cache.flags = flags
+	}
+}
+
+// CacheIn is a RuleOption keeping the results cached by Cache in dir.
+func CacheIn(dir string) RuleOption {
+	return func(r *rule) {
+		if r.cache == nil {
+			Cache(0)(r)
+		}
+
+		r.cache.dir = dir
+	}
+}
+
+// middleware replays the cached result of a run, or runs the command and
+// caches its result.
+func (c *resultCache) middleware(next RunFunc) RunFunc {
+	return func(ctx context.Context, args []string) (int, error) {
+		inv := ctxInvocation(ctx)
+		path, err := c.path(inv, args)
+		if err != nil || inv.dryRun {
+			return next(ctx, args)
+		}
+
+		s, _ := ctxStreams(ctx)
+		if !inv.noCache {
+			result, ok := c.load(path)
+			if ok {
+				_, err := s.stdout.Write(result.Stdout)
+				return result.Code, err
+			}
+		}
+
+		var buf bytes.Buffer
+		s.stdout = io.MultiWriter(s.stdout, &buf)
+		if cmd, ok := inv.rule.command.(streamer); ok {
+			cmd.SetIO(s.stdin, s.stdout, s.stderr)
+		}
+
+		code, err := next(withStreams(ctx, s), args)
+		inv.mu.Lock()
+		failed := inv.err != nil
+		inv.mu.Unlock()
+		if err != nil || failed || ctx.Err() != nil {
+			return code, err
+		}
+
+		data, err := json.Marshal(cachedResult{Code: code, Stdout: buf.Bytes()})
+		if err == nil {
+			writeFile(path, data)
+		}
+
+		return code, nil
+	}
+}
+
+// path returns the path of the cached result of the invocation with the
+// positional arguments args.
+func (c *resultCache) path(inv *invocation, args []string) (string, error) {
+	dir := c.dir
+	if dir == "" {
+		cache, err := inv.app.CacheDir()
+		if err != nil {
+			return "", err
+		}
+
+		dir = filepath.Join(cache, "results")
+	}
+
+	return filepath.Join(dir, inv.key(args, c.flags...)), nil
+}
+
+// load returns the cached result at path if it is younger than the ttl.
+func (c *resultCache) load(path string) (cachedResult, bool) {
+	var result cachedResult
+	fi, err := os.Stat(path)
+	if err != nil || time.Since(fi.ModTime()) >= c.ttl {
+		return result, false
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return result, false
+	}
+
+	return result, json.Unmarshal(data, &result) == nil
+}
@@ -0,0 +1,121 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"fmt"
+	"testing"
+	"time"
+)
+
+type runCached struct {
+	format *string
+	limit  *int
+	calls  int
+}
+
+func TestCache(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	app := New("myapp", "0.0.1", WithNoCacheFlag(), WithDryRunFlag())
+	cmd := &runCached{}
+	app.Rule(cmd, "query", "<q>", Cache(time.Hour, "format"))
+	other := &runCached{}
+	app.Rule(other, "fresh", "<q>", Cache(0), CacheIn(t.TempDir()))
+
+	tests := []struct {
+		args  []string
+		out   string
+		code  int
+		calls int
+	}{
+		{[]string{"query", "a"}, "1 a json\n", 0, 1},
+		{[]string{"query", "a"}, "1 a json\n", 0, 1},
+		{[]string{"query", "-limit", "5", "a"}, "1 a json\n", 0, 1},
+		{[]string{"query", "-format", "text", "a"}, "2 a text\n", 0, 2},
+		{[]string{"query", "fail"}, "3 fail json\n", 3, 3},
+		{[]string{"query", "fail"}, "3 fail json\n", 3, 3},
+		{[]string{"-no-cache", "query", "a"}, "4 a json\n", 0, 4},
+		{[]string{"query", "a"}, "4 a json\n", 0, 4},
+		{[]string{"-dry-run", "query", "a"}, "5 a json\n", 0, 5},
+		{[]string{"query", "a"}, "4 a json\n", 0, 5},
+	}
+
+	for _, tt := range tests {
+		var stdout bytes.Buffer
+		code := app.RunWithArgs(tt.args, &stdout, &stdout)
+		if code != tt.code || stdout.String() != tt.out || cmd.calls != tt.calls {
+			t.Errorf("%v\nhave %d %q %d\nwant %d %q %d", tt.args, code, stdout.String(), cmd.calls, tt.code, tt.out, tt.calls)
+		}
+	}
+
+	for i := 0; i < 2; i++ {
+		app.RunWithArgs([]string{"fresh", "a"}, &bytes.Buffer{}, &bytes.Buffer{})
+	}
+
+	if other.calls != 2 {
+		t.Errorf("expired calls\nhave %d\nwant %d", other.calls, 2)
+	}
+}
+
+func (c *runCached) Flags(flags *flag.FlagSet) {
+	c.format = flags.String("format", "json", "format")
+	c.limit = flags.Int("limit", 10, "limit")
+}
+
+func (c *runCached) Run(ctx context.Context, q string) int {
+	c.calls++
+	fmt.Fprintln(Stdout(ctx), c.calls, q, *c.format)
+	if q == "fail" {
+		return 3
+	}
+
+	return 0
+}
+
+func (c *runCached) String() string {
+	return "run a query"
+}
+
+type runCachedSecret struct {
+	token *Secret
+	calls int
+}
+
+func TestCacheSecret(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	app := New("myapp", "0.0.1")
+	cmd := &runCachedSecret{}
+	app.Rule(cmd, "whoami", "", Cache(time.Hour, "token"))
+
+	tests := []struct {
+		token string
+		out   string
+		calls int
+	}{
+		{"alice", "alice\n", 1},
+		{"bob", "bob\n", 2},
+		{"alice", "alice\n", 2},
+	}
+
+	for _, tt := range tests {
+		var stdout bytes.Buffer
+		app.RunWithArgs([]string{"whoami", "-token", tt.token}, &stdout, &stdout)
+		if stdout.String() != tt.out || cmd.calls != tt.calls {
+			t.Errorf("%s\nhave %q %d\nwant %q %d", tt.token, stdout.String(), cmd.calls, tt.out, tt.calls)
+		}
+	}
+}
+
+func (c *runCachedSecret) Flags(flags *flag.FlagSet) {
+	c.token = SecretVar(flags, "token", "", "access token")
+}
+
+func (c *runCachedSecret) Run(ctx context.Context) {
+	c.calls++
+	fmt.Fprintln(Stdout(ctx), c.token.Value())
+}
+
+func (c *runCachedSecret) String() string {
+	return "print the token"
+}
@@ -0,0 +1,82 @@
+package cli
+
+import "strings"
+
+// Category is a RuleOption listing the command under a category, such as
+// "Build commands", in the usage of the Application. Commands without a
+// category are listed first, followed by each category in the order it was
+// first used and finally the help and version commands. Commands of a group
+// are listed under the category of the group unless they have their own,
+// see CommandGroup.Category.
+func Category(name string) RuleOption {
+	return func(r *rule) {
+		r.category = name
+	}
+}
+
+// Category lists the group and its commands under a category in the usage of
+// the Application, see the Category RuleOption.
+func (g *CommandGroup) Category(name string) *CommandGroup {
+	g.app.mu.Lock()
+	g.app.rules[g.name].category = name
+	g.app.mu.Unlock()
+	return g
+}
+
+// category returns the category of the named command, or of the nearest
+// group containing it if it has none.
+func (a *Application) category(name string) string {
+	for {
+		if r, ok := a.lookup(name); ok && r.category != "" {
+			return r.category
+		}
+
+		i := strings.LastIndex(name, " ")
+		if i < 0 {
+			break
+		}
+
+		name = name[:i]
+	}
+
+	if a.builtin(name) {
+		return a.messages.BuiltinCommands
+	}
+
+	return ""
+}
+
+// categorize orders the names by their category and returns true if any of
+// them has a category other than that of the built-in commands, and
+// otherwise returns them unchanged. The order of the names within each category is preserved.
+func (a *Application) categorize(names []string) ([]string, bool) {
+	var (
+		order      []string
+		categories = make(map[string][]string)
+	)
+	for _, name := range names {
+		c := a.category(name)
+		if _, ok := categories[c]; !ok && c != "" && c != a.messages.BuiltinCommands {
+			order = append(order, c)
+		}
+
+		categories[c] = append(categories[c], name)
+	}
+
+	if len(order) == 0 {
+		return names, false
+	}
+
+	sorted := categories[""]
+	order = append(order, a.messages.BuiltinCommands)
+	if a.placement == BuiltinFirst {
+		sorted = categories[a.messages.BuiltinCommands]
+		order = append([]string{""}, order[:len(order)-1]...)
+	}
+
+	for _, c := range order {
+		sorted = append(sorted, categories[c]...)
+	}
+
+	return sorted, true
+}
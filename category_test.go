@@ -0,0 +1,53 @@
+package cli
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestCategory(t *testing.T) {
+	app := New("myapp", "0.0.1")
+	app.RuleFunc("build", "Build it.", "", func() {}, Category("Build commands"))
+	app.RuleFunc("users", "List users.", "", func() {}, Category("Admin commands"))
+	app.RuleFunc("test", "Test it.", "", func() {}, Category("Build commands"))
+	app.RuleFunc("misc", "Do other things.", "", func() {})
+	remote := app.Group("remote", "Manage remotes.").Category("Admin commands")
+	remote.Rule(&runFull{}, "add", "<name>")
+
+	var buf bytes.Buffer
+	app.PrintUsage(&buf)
+	want := "Usage: myapp <cmd> [options] [<args>]\n" +
+		"Commands:\n" +
+		"  misc                     Do other things.\n" +
+		"\nBuild commands:\n" +
+		"  build                    Build it.\n" +
+		"  test                     Test it.\n" +
+		"\nAdmin commands:\n" +
+		"  users                    List users.\n" +
+		"  remote <cmd>             Manage remotes.\n" +
+		"    add [options] <name>   runFull help\n" +
+		"      -number=<n>          some number\n" +
+		"\nBuilt-in commands:\n" +
+		"  help [<command>...]      Output this usage information.\n" +
+		"  version [options]        Output the application version.\n" +
+		"    -json                  Print the version and build information as JSON.\n" +
+		"    -short                 Print only the version number.\n\n"
+	if buf.String() != want {
+		t.Errorf("usage\nhave %q\nwant %q", buf.String(), want)
+	}
+
+	if have := app.category("remote add"); have != "Admin commands" {
+		t.Errorf("inherited category\nhave %q\nwant %q", have, "Admin commands")
+	}
+}
+
+func TestCategoryNone(t *testing.T) {
+	app := New("myapp", "0.0.1")
+	app.RuleFunc("build", "Build it.", "", func() {})
+
+	names := []string{"build", "help", "version"}
+	have, ok := app.categorize(names)
+	if ok || len(have) != len(names) {
+		t.Errorf("categorize\nhave %q %t\nwant %q %t", have, ok, names, false)
+	}
+}
@@ -0,0 +1,67 @@
+package cli
+
+import "context"
+
+// Chain enables running several commands in one invocation, in the manner
+// of build tools, separated by separator, such as "," in "app fmt , vet ,
+// test". The commands run in turn, each with the global flags given before
+// the first, until one exits with a non-zero exit code, which is then the
+// exit code of the invocation. The -keep-going flag runs the remaining
+// commands regardless, exiting with the first non-zero exit code. The
+// separator cannot be passed as an argument to a command, except in requests
+// to the Handler, which are not chained. An empty separator disables
+// chaining, the default.
+func (a *Application) Chain(separator string) {
+	a.separator = separator
+}
+
+// chained splits words into the commands separated by the chain separator,
+// omitting empty commands, or returns nil if there is no separator.
+func (a *Application) chained(words []string) [][]string {
+	if a.separator == "" {
+		return nil
+	}
+
+	var commands [][]string
+	found := false
+	start := 0
+	for i := 0; i <= len(words); i++ {
+		if i < len(words) && words[i] != a.separator {
+			continue
+		}
+
+		if i > start {
+			commands = append(commands, words[start:i])
+		}
+		found = found || i < len(words)
+		start = i + 1
+	}
+
+	if !found {
+		return nil
+	}
+
+	return commands
+}
+
+// runChain dispatches to each of the commands in turn with the global
+// flags, stopping at the first to fail unless keepGoing is set.
+func (a *Application) runChain(ctx context.Context, global []string, commands [][]string, keepGoing bool) int {
+	code := 0
+	for _, command := range commands {
+		if ctx.Err() != nil {
+			break
+		}
+
+		c := a.dispatch(ctx, append(append([]string{}, global...), command...))
+		if c != 0 && code == 0 {
+			code = c
+		}
+
+		if c != 0 && !keepGoing {
+			break
+		}
+	}
+
+	return code
+}
@@ -0,0 +1,59 @@
+package cli
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestChain(t *testing.T) {
+	var ran []string
+	app := New("myapp", "0.0.1")
+	app.Chain(",")
+	app.StandardFlags()
+	for _, name := range []string{"fmt", "vet", "test"} {
+		name := name
+		app.RuleFunc(name, "Run "+name+".", "[<arg>]", func(arg string) int {
+			ran = append(ran, name+arg)
+			if arg == "fail" {
+				return 3
+			}
+			return 0
+		})
+	}
+
+	tests := []struct {
+		args []string
+		code int
+		ran  []string
+	}{
+		{[]string{"-q", "fmt", "a", ",", "vet", ",", "test"}, 0, []string{"fmta", "vet", "test"}},
+		{[]string{"fmt", ",", "vet", "fail", ",", "test"}, 3, []string{"fmt", "vetfail"}},
+		{[]string{"-keep-going", "fmt", "fail", ",", "vet", ",", "test"}, 3, []string{"fmtfail", "vet", "test"}},
+		{[]string{"fmt", ","}, 0, []string{"fmt"}},
+	}
+
+	for _, tt := range tests {
+		ran = nil
+		var stdout, stderr bytes.Buffer
+		code := app.RunWithArgs(tt.args, &stdout, &stderr)
+		if code != tt.code || !reflect.DeepEqual(ran, tt.ran) {
+			t.Errorf("%q\nhave %d %q\nwant %d %q", tt.args, code, ran, tt.code, tt.ran)
+		}
+	}
+}
+
+func TestChained(t *testing.T) {
+	app := New("myapp", "0.0.1")
+	words := []string{"a", "+", "b", "c", "+", "+"}
+	if have := app.chained(words); have != nil {
+		t.Errorf("disabled\nhave %q\nwant nil", have)
+	}
+
+	app.Chain("+")
+	have := fmt.Sprint(app.chained(words))
+	if want := "[[a] [b c]]"; have != want {
+		t.Errorf("chained\nhave %s\nwant %s", have, want)
+	}
+}
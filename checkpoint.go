@@ -0,0 +1,108 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+)
+
+// A Checkpoint stores the progress of a long running invocation so that it
+// may resume where it left off if interrupted. Checkpoints are stored as JSON
+// under the DataDir of the Application, keyed by the command name and its
+// arguments, including flags.
+type Checkpoint struct {
+	path string
+}
+
+// DataDir returns the directory in which the Application may store data for
+// the current user that should persist, unlike the contents of CacheDir. The
+// directory is not created.
+func (a *Application) DataDir() (string, error) {
+	dir := os.Getenv("XDG_DATA_HOME")
+	if dir == "" {
+		switch runtime.GOOS {
+		case "windows":
+			dir = os.Getenv("LocalAppData")
+			if dir == "" {
+				return "", errors.New("%LocalAppData% is not defined")
+			}
+		case "darwin", "ios":
+			home, err := os.UserHomeDir()
+			if err != nil {
+				return "", err
+			}
+
+			dir = filepath.Join(home, "Library", "Application Support")
+		default:
+			home, err := os.UserHomeDir()
+			if err != nil {
+				return "", err
+			}
+
+			dir = filepath.Join(home, ".local", "share")
+		}
+	}
+
+	return filepath.Join(dir, a.name), nil
+}
+
+// NewCheckpoint returns the Checkpoint of the invocation of the command that
+// received ctx.
+func NewCheckpoint(ctx context.Context) (*Checkpoint, error) {
+	inv := ctxInvocation(ctx)
+	dir, err := inv.app.DataDir()
+	if err != nil {
+		return nil, err
+	}
+
+	return &Checkpoint{path: filepath.Join(dir, "checkpoint", inv.key(inv.args)+".json")}, nil
+}
+
+// Load decodes the saved progress into v, reporting whether there was any.
+func (c *Checkpoint) Load(v interface{}) (bool, error) {
+	data, err := ioutil.ReadFile(c.path)
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	return true, json.Unmarshal(data, v)
+}
+
+// Save replaces the saved progress with v.
+func (c *Checkpoint) Save(v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	return writeFile(c.path, data)
+}
+
+// Clear removes the saved progress, typically once the operation completes.
+func (c *Checkpoint) Clear() error {
+	err := os.Remove(c.path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+
+	return err
+}
+
+// SaveOnCancel arranges for the value returned by fn to be saved when the
+// invocation of the command that received ctx completes after being
+// interrupted by a signal or timing out, so that commands need not save their
+// progress on every step.
+func (c *Checkpoint) SaveOnCancel(ctx context.Context, fn func() interface{}) {
+	ctxInvocation(ctx).onCleanup(func() {
+		if _, ok := cancelCode(ctx); ok {
+			c.Save(fn())
+		}
+	})
+}
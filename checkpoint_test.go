@@ -0,0 +1,75 @@
+package cli
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+)
+
+type runResume struct {
+	*NullFlags
+	interrupt bool
+	start     int
+}
+
+func TestCheckpoint(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cli-data")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	t.Setenv("XDG_DATA_HOME", dir)
+
+	app := New("myapp", "0.0.1")
+	cmd := &runResume{interrupt: true}
+	app.Rule(cmd, "copy", "<src>")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	code := app.dispatch(ctx, []string{"copy", "a"})
+	if code != ExitTimeout || cmd.start != 0 {
+		t.Fatalf("interrupted\nhave %d from %d\nwant %d from %d", code, cmd.start, ExitTimeout, 0)
+	}
+
+	cmd.interrupt = false
+	code = app.Dispatch([]string{"copy", "a"})
+	if code != 0 || cmd.start != 3 {
+		t.Errorf("resumed\nhave %d from %d\nwant %d from %d", code, cmd.start, 0, 3)
+	}
+
+	code = app.Dispatch([]string{"copy", "a"})
+	if code != 0 || cmd.start != 0 {
+		t.Errorf("restarted\nhave %d from %d\nwant %d from %d", code, cmd.start, 0, 0)
+	}
+}
+
+func (c *runResume) Run(ctx context.Context, src string) int {
+	cp, err := NewCheckpoint(ctx)
+	if err != nil {
+		return 1
+	}
+
+	var done int
+	_, err = cp.Load(&done)
+	if err != nil {
+		return 1
+	}
+
+	c.start = done
+	cp.SaveOnCancel(ctx, func() interface{} { return done })
+	for ; done < 5; done++ {
+		if done == 3 && c.interrupt {
+			<-ctx.Done()
+			return 1
+		}
+	}
+
+	cp.Clear()
+	return 0
+}
+
+func (c *runResume) String() string {
+	return "copy resumably"
+}
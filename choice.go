@@ -0,0 +1,125 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+)
+
+// Choice is a flag.Value accepting one of a set of values, see ChoiceVar.
+type Choice struct {
+	value   string
+	choices []string
+}
+
+// validated is a flag.Value checking values with a function before setting
+// them, see Validate.
+type validated struct {
+	flag.Value
+	fn func(value string) error
+}
+
+// A chooser is a flag.Value accepting one of a set of values, offered by
+// completion.
+type chooser interface {
+	Choices() []string
+}
+
+// ChoiceVar defines a flag with the specified name, default value and usage
+// string accepting only one of choices, such as json, yaml or table. Other
+// values are rejected with an error listing the choices, which are also
+// appended to the usage string and offered by completion.
+func ChoiceVar(flags *flag.FlagSet, name, value string, choices []string, usage string) *Choice {
+	c := &Choice{value: value, choices: choices}
+	flags.Var(c, name, fmt.Sprintf("%s One of %s.", usage, strings.Join(choices, ", ")))
+	return c
+}
+
+// Value returns the chosen value.
+func (c *Choice) Value() string {
+	return c.value
+}
+
+// Choices returns the accepted values.
+func (c *Choice) Choices() []string {
+	return c.choices
+}
+
+// String implements the flag.Value interface.
+func (c *Choice) String() string {
+	if c == nil {
+		return ""
+	}
+
+	return c.value
+}
+
+// Set implements the flag.Value interface.
+func (c *Choice) Set(value string) error {
+	for _, choice := range c.choices {
+		if value == choice {
+			c.value = value
+			return nil
+		}
+	}
+
+	return fmt.Errorf("must be one of %s", strings.Join(c.choices, ", "))
+}
+
+// Validate wraps the flag with the given name, which must already be
+// defined, to check values with fn before they are set. The error returned
+// by fn is reported like any other invalid flag value.
+func Validate(flags *flag.FlagSet, name string, fn func(value string) error) {
+	f := flags.Lookup(name)
+	if f == nil {
+		panic("cli: validate of undefined flag -" + name)
+	}
+
+	f.Value = &validated{Value: f.Value, fn: fn}
+}
+
+// Set implements the flag.Value interface.
+func (v *validated) Set(value string) error {
+	err := v.fn(value)
+	if err != nil {
+		return err
+	}
+
+	return v.Value.Set(value)
+}
+
+// IsBoolFlag reports whether the wrapped flag is a boolean flag.
+func (v *validated) IsBoolFlag() bool {
+	b, ok := v.Value.(interface{ IsBoolFlag() bool })
+	return ok && b.IsBoolFlag()
+}
+
+// Choices returns the values accepted by the wrapped flag, if it is a
+// Choice.
+func (v *validated) Choices() []string {
+	if c, ok := v.Value.(chooser); ok {
+		return c.Choices()
+	}
+
+	return nil
+}
+
+// Repeatable reports whether the wrapped flag may be repeated.
+func (v *validated) Repeatable() bool {
+	r, ok := v.Value.(repeatable)
+	return ok && r.Repeatable()
+}
+
+// choices returns the values accepted by the flag, if it has a set.
+func choices(f *flag.Flag) []string {
+	v := f.Value
+	if s, ok := v.(*shorthand); ok {
+		v = s.Value
+	}
+
+	if c, ok := v.(chooser); ok {
+		return c.Choices()
+	}
+
+	return nil
+}
@@ -0,0 +1,93 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"flag"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+type runFormat struct {
+	format *Choice
+	port   *int
+}
+
+func TestChoiceVar(t *testing.T) {
+	app := New("myapp", "0.0.1")
+	cmd := &runFormat{}
+	app.Rule(cmd, "show", "")
+
+	tests := []struct {
+		args   []string
+		code   int
+		stderr string
+	}{
+		{[]string{"show"}, 0, ""},
+		{[]string{"show", "-format", "yaml"}, 0, ""},
+		{[]string{"show", "-format", "xml"}, ExitUsage, "invalid value \"xml\" for flag -format: must be one of json, yaml, table\n"},
+		{[]string{"show", "-port", "80"}, ExitUsage, "invalid value \"80\" for flag -port: port must be above 1023\n"},
+		{[]string{"show", "-port", "8080"}, 0, ""},
+	}
+
+	for _, tt := range tests {
+		var stdout, stderr bytes.Buffer
+		code := app.RunWithArgs(tt.args, &stdout, &stderr)
+		if code != tt.code {
+			t.Errorf("%q exit code\nhave %d\nwant %d", tt.args, code, tt.code)
+		}
+
+		if !strings.HasPrefix(stderr.String(), tt.stderr) {
+			t.Errorf("%q stderr\nhave %q\nwant %q", tt.args, stderr.String(), tt.stderr)
+		}
+	}
+
+	var buf bytes.Buffer
+	app.PrintUsage(&buf)
+	if !strings.Contains(buf.String(), "Output format. One of json, yaml, table.") {
+		t.Errorf("usage does not list the choices\n%s", buf.String())
+	}
+}
+
+func TestCompleteChoice(t *testing.T) {
+	app := New("myapp", "0.0.1")
+	app.Rule(&runFormat{}, "show", "")
+
+	tests := []struct {
+		words []string
+		want  string
+	}{
+		{[]string{"show", "-format", ""}, "json\nyaml\ntable\n"},
+		{[]string{"show", "-format", "t"}, "table\n"},
+		{[]string{"show", "--format=j"}, "--format=json\n"},
+		{[]string{"show", "-port", ""}, ""},
+	}
+
+	for _, tt := range tests {
+		var buf bytes.Buffer
+		app.complete(&buf, tt.words)
+		if buf.String() != tt.want {
+			t.Errorf("%q\nhave %q\nwant %q", tt.words, buf.String(), tt.want)
+		}
+	}
+}
+
+func (c *runFormat) Flags(flags *flag.FlagSet) {
+	c.format = ChoiceVar(flags, "format", "table", []string{"json", "yaml", "table"}, "Output format.")
+	c.port = flags.Int("port", 8080, "Port to listen on.")
+	Validate(flags, "port", func(value string) error {
+		if n, err := strconv.Atoi(value); err == nil && n < 1024 {
+			return errors.New("port must be above 1023")
+		}
+
+		return nil
+	})
+}
+
+func (c *runFormat) Run(ctx context.Context) {}
+
+func (c *runFormat) String() string {
+	return "show a value"
+}
@@ -6,251 +6,1272 @@ named arguments. Commands help and version are implemented by default. The
 usage information is pretty printed in an opinionated format. That said, this
 package still attempts to embrace the standard library flag package.
 
-This package assumes that any arguments will remain strings. Any non-string
-arguments are likely to be passed as optional flags in practice.
+Positional arguments are converted to the types of the parameters of the Run
+method: strings, booleans, numbers, durations and types implementing
+encoding.TextUnmarshaler. An argument that cannot be converted is reported as
+a usage error naming the argument and the expected type.
+
+Every command accepts an -args-from flag naming a file, or - for stdin, from
+which additional whitespace or NUL delimited arguments are read. This avoids
+the operating system limits on the length of the argument list.
 
 See the documentation of Rule for details and restrictions.
 */
 package cli
 
 import (
+	"context"
 	"flag"
 	"fmt"
 	"io"
 	"os"
+	"path/filepath"
 	"reflect"
-	"strconv"
+	"sort"
 	"strings"
+	"sync"
+	"time"
+	"unicode"
 )
 
-// An Application represents a command line application.
+// An Application represents a command line application. Create one with New.
+// Wrapper libraries may accept an *Application to register their own
+// commands alongside those of the program.
 type Application struct {
 	name    string
 	version string
+	mu      sync.RWMutex
 	rules   map[string]*rule
+	names   []string
+	order   []string
+	stdin   io.Reader
+	stdout  io.Writer
+	stderr  io.Writer
+	store   CredentialStore
+	refresh func(ctx context.Context, c *Credentials) (*Credentials, error)
+	policy  *string
+
+	renderer    HelpRenderer
+	formatter   FlagValueFormatter
+	color       ColorMode
+	description string
+	about       string
+	footer      string
+	update      *selfUpdate
+	messages    Messages
+	signals     []os.Signal
+	grace       time.Duration
+	parseMode   ParseMode
+	flagErrors  flag.ErrorHandling
+	noDefaults  bool
+	settings    string
+	placement   BuiltinPlacement
+	setup       *setup
+	noPager     bool
+	completion  bool
+	sorted      bool
+	suggestRun  bool
+	strict      bool
+	separator   string
+	interpolate bool
+	pluginDir   *pluginDir
+	container   container
+	plugins     bool
+	verify      bool
+	elevate     bool
+	standard    bool
+	config      Config
+	middleware  []Middleware
+	recorders   []Recorder
+	global      []func(flags *flag.FlagSet)
+	builtins    map[string]bool
+	envPrefix   string
+	env         map[string]string
+	build       map[string]string
+	onExit      []func(code int)
+	onPanic     []func(ctx context.Context, report *PanicReport)
+	errorCodes  []errorCode
+	exit        func(code int)
+	onFlagSet   []func(cmd string, flags *flag.FlagSet)
+	onFlagErr   []func(cmd string, err error)
+	apiVersion  string
+	versions    map[string][]*rule
 }
 
 type rule struct {
-	command   command
-	method    reflect.Method
-	slice     bool
-	name      string
-	options   *flag.FlagSet
-	arguments string
+	command    command
+	factory    func() command
+	run        reflect.Value
+	context    bool
+	slice      bool
+	variadic   bool
+	numIn      int
+	converters []converter
+	zeros      []reflect.Value
+	sliceType  reflect.Type
+	structured bool
+	name       string
+	options    *flag.FlagSet
+	arguments  string
+	args       []Arg
+	summary    string
+	retry      *retryPolicy
+	limit      *rateLimit
+	grace      time.Duration
+	timeout    time.Duration
+	auth       bool
+	privilege  bool
+	hidden     bool
+	category   string
+	parseMode  ParseMode
+	strict     *bool
+	counts     *argCount
+	examples   []string
+	argFiles   bool
+	local      bool
+	cache      *resultCache
+	fanOut     *fanOut
+	resources  *limits
+	apiVersion string
+	direct     func(ctx context.Context, args Args) error
+	guards     []func(ctx context.Context) error
+	gate       *gate
+	completers map[string]func(prefix string) []string
+	deprecated *deprecation
+	fields     []field
+	defaults   []reflect.Value
+	middleware []Middleware
+	env        map[string]string
+	groups     []flagGroup
+	define     []func(flags *flag.FlagSet)
+	original   *rule
+	mu         sync.Mutex
+	loading    sync.Mutex
 }
 
+// A RuleOption configures a rule as it is registered.
+type RuleOption func(r *rule)
+
 type command interface {
 	fmt.Stringer
+}
+
+// flagger is implemented by commands defining their own flags.
+type flagger interface {
 	Flags(flags *flag.FlagSet)
 }
 
 // NullFlags is an embeddable struct providing an empty FlagSet.
 type NullFlags struct{}
 
+// compactThreshold is the number of commands beyond which the usage lists
+// commands compactly, without their options.
+const compactThreshold = 40
+
+var (
+	contextType = reflect.TypeOf((*context.Context)(nil)).Elem()
+	errorType   = reflect.TypeOf((*error)(nil)).Elem()
+)
+
 var (
 	errRunMissing     = fmt.Errorf("rule: missing Run method")
-	errRunString      = fmt.Errorf("rule: parameters for Run must be strings")
-	errRunReturnValue = fmt.Errorf("rule: first return value for Run must be int")
+	errRunString      = fmt.Errorf("rule: unsupported parameter type for Run")
+	errRunReturnValue = fmt.Errorf("rule: first return value for Run must be int or error")
+	errRunResult      = fmt.Errorf("rule: RunStructured must return a result")
+	errNameEmpty      = fmt.Errorf("rule: empty command name")
+	errFactoryNil     = fmt.Errorf("rule: factory returned no command")
 )
 
-// New creates a basic Application with help and version commands.
-func New(name, version string) *Application {
+// New creates a basic Application with help and version commands, configured
+// by the options.
+//
+// If name is empty, the base name of the executable is used. If version is
+// empty, the module version recorded in the binary is used, such that tools
+// distributed with go install report useful versions without ldflags. Builds
+// from a working copy report the version control revision instead.
+func New(name, version string, options ...Option) *Application {
+	if name == "" {
+		name = inferName()
+	}
+	if version == "" {
+		version = inferVersion()
+	}
+
 	app := &Application{
-		name:    name,
-		version: version,
-		rules:   make(map[string]*rule),
+		name:     name,
+		version:  version,
+		rules:    make(map[string]*rule),
+		stdin:    os.Stdin,
+		stdout:   os.Stdout,
+		stderr:   os.Stderr,
+		exit:     os.Exit,
+		messages: DefaultMessages,
+	}
+
+	for _, option := range options {
+		option(app)
 	}
 
-	app.Rule(&commandHelp{usage: app.usage}, "help", "")
-	app.Rule(&commandVersion{name: name, version: version}, "version", "")
+	// The built-in commands are instantiated on first use so that they are
+	// wired to the application as it is at that time rather than at New.
+	if !app.noDefaults {
+		app.lazy("help", "[<command>...]", func() command {
+			return &commandHelp{app: app}
+		})
+		app.lazy("version", "", func() command {
+			return &commandVersion{app: app}
+		})
+	}
+	if app.update != nil {
+		app.lazy("update", "", func() command {
+			return &commandUpdate{app: app, executable: executable}
+		}, Local())
+	}
 
 	return app
 }
 
+// Name returns the name of the Application.
+func (a *Application) Name() string {
+	return a.name
+}
+
+// Version returns the version of the Application.
+func (a *Application) Version() string {
+	return a.version
+}
+
 // Rule registers a command with the Application.
 //
 // The command being registered must meet the requirements of the fmt.Stringer
-// interface. The command must also have a method Flags that accepts a new
+// interface. The command may also have a method Flags that accepts a new
 // *flag.FlagSet. The Flags method is where you would define flags for this
 // particular sub-command.
 //
+// Alternatively, flags and positional arguments may be declared with tags on
+// the fields of the command, including those of embedded structs, which are
+// populated before Run is called:
+//
+//	type build struct {
+//		Output  string        `cli:"o,Write the binary to the file.,a.out"`
+//		Timeout time.Duration `cli:"timeout,Stop after the duration.,1m"`
+//		Package string        `arg:"0,required"`
+//		Tags    []string      `arg:"1"`
+//	}
+//
+// A cli tag has the form "name,usage,default", where the usage may not
+// contain commas. An arg tag gives the index of the positional argument and
+// may be followed by ",required". A slice field receives the argument at the
+// index and all those following it. Field types are as for Run parameters.
+//
+// The command may also have a method Help returning a long description of
+// the command, shown by "app help <command>" and "app <command> -h" along
+// with the synopsis and options, and a method Examples returning example
+// command lines shown after the options, see Examples.
+//
+// The command may also have a method SetIO, called with the standard streams
+// of the invocation before it is run, for commands without a context to pass
+// to Stdin, Stdout and Stderr, a method SetLogger, called with the Logger of
+// the invocation, a method SetOut, called with its Out, and a method Inject,
+// called with the values provided to the Application, see Provide.
+//
+// The command may also have a method Init(ctx context.Context) error, called
+// before it is run, and a method Cleanup() error, called once it has run even
+// if Init failed, Run panicked or the invocation was cancelled. An error
+// returned by either is printed to stderr and results in an exit code of 1 if
+// the exit code would otherwise be 0.
+//
+// The command may also have a method Confirm returning a destructive action,
+// such as "Delete the cluster", which the user must confirm before the
+// command is run, see Confirmer.
+//
 // Additionally, the command must have a Run method. If the Run method has no
 // return value, the program will end with a successful exit code. If the Run
-// method has one or more return values, only the first is considered and must
-// be of type int. The first return value will be used as the exit code.
+// method has one or more return values, the first must be of type int or
+// error. An int return value will be used as the exit code. An error return
+// value, either first or following the int, is printed to stderr as
+// "app: command: message" and results in an exit code of 1 if the exit code
+// would otherwise be 0, or the code of an ExitCoder or mapped with MapError.
+// Each of the errors joined with errors.Join is printed on its own line.
 //
-// The Run method may accept parameters of type string. If the Run method has
-// more parameters than there are arguments, the extra parameters will just be
-// empty strings. If the Run method has less parameters than there are
-// arguments, they will silently be ignored. Optionally, the last parameter of
-// the Run method can be of type []string. In this case, any extra parameters
-// will be passed to the final argument.
-func (a *Application) Rule(command command, name, arguments string) error {
-	// Find the Run method dynamically.
-	method, ok := reflect.TypeOf(command).MethodByName("Run")
-	if !ok {
+// Instead of a Run method, the command may have a RunStructured method taking
+// the same parameters whose first return value is a result to print with a
+// Printer, in the format selected by the -output flag, followed by the return
+// values of a Run method. Commands implementing Runner are instead called
+// through their RunE method without reflection, see Runner.
+//
+// The first parameter of the Run method may be a context.Context. The context
+// is cancelled when the invocation completes, when a goroutine started with
+// Go fails, when the command times out, see Timeout, or, under Run, when the
+// process is interrupted.
+//
+// The arguments string documents the positional arguments, such as
+// "<src> <dst> [<extra>...]", and is validated before dispatch. Arguments in
+// square brackets are optional and an argument followed by an ellipsis may be
+// repeated. The arguments may instead be declared with descriptions using the
+// Arguments option. Invocations with too few arguments, or too many for a
+// command whose Run method does not accept a final slice, fail with the usage
+// of the command and exit code 2.
+//
+// The Run method may accept parameters of type string, bool, any integer or
+// floating point type, time.Duration, or any type implementing
+// encoding.TextUnmarshaler. Arguments are converted to the type of their
+// parameter and the command fails with exit code 2 if they cannot be. If the
+// Run method has more parameters than there are arguments, the extra
+// parameters will just be zero values. Arguments beyond those of the
+// arguments string fail as above, while those it allows beyond the parameters
+// of the Run method are ignored unless StrictArgs is enabled. Optionally, the
+// last parameter of the Run method can be a slice of one of these types, such
+// as []string, or variadic, such as Run(first string, rest ...string). In this
+// case, any extra parameters will be passed to the final argument. Flags end
+// at the first positional argument, unless the command parses interspersed
+// flags, see ParseMode, or at a -- argument, after which all arguments, even
+// those beginning with a dash, are passed verbatim, such as
+// "app exec -- ls -l".
+//
+// The behaviour of the rule may be configured with options such as Retry.
+//
+// Rule fails if the name is empty, is taken by another command, including
+// help and version, see OverrideHelp, or contains white space other than the
+// single spaces separating the names of a group and its commands, see Group.
+func (a *Application) Rule(command command, name, arguments string, options ...RuleOption) error {
+	err := a.checkName(name)
+	if err != nil {
+		return err
+	}
+
+	r := &rule{name: name, arguments: arguments}
+	for _, option := range options {
+		option(r)
+	}
+
+	err = r.bind(command)
+	if err != nil {
+		return err
+	}
+
+	// Add the rule.
+	return a.add(r, false)
+}
+
+// MustRule is like Rule but panics if the command cannot be registered, for
+// registering commands as the program is initialized.
+func (a *Application) MustRule(command command, name, arguments string, options ...RuleOption) {
+	err := a.Rule(command, name, arguments, options...)
+	if err != nil {
+		panic(err)
+	}
+}
+
+// checkName returns an error if name is not a valid name for a new command.
+func (a *Application) checkName(name string) error {
+	if name == "" {
+		return errNameEmpty
+	}
+
+	words := strings.Split(name, " ")
+	for _, word := range words {
+		if word == "" || strings.IndexFunc(word, unicode.IsSpace) >= 0 || strings.HasPrefix(word, "-") {
+			return fmt.Errorf("rule: invalid command name %q", name)
+		}
+	}
+
+	// Versions of a command share its name, see APIVersion.
+	if r, ok := a.lookup(name); ok && r.apiVersion == "" {
+		return fmt.Errorf("rule: duplicate command %q", name)
+	}
+
+	if len(words) > 1 {
+		if _, ok := a.lookup(strings.Join(words[:len(words)-1], " ")); !ok {
+			return fmt.Errorf("rule: invalid command name %q, register subcommands with Group", name)
+		}
+	}
+
+	return nil
+}
+
+// lazy registers a rule whose command is created by factory on first use.
+func (a *Application) lazy(name, arguments string, factory func() command, options ...RuleOption) {
+	r := &rule{
+		factory:   factory,
+		name:      name,
+		arguments: arguments,
+	}
+	for _, option := range options {
+		option(r)
+	}
+
+	a.add(r, true)
+}
+
+// add adds the rule to the Application, failing if the name is taken unless
+// replace is set.
+func (a *Application) add(r *rule, replace bool) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	versioned, err := a.addVersion(r)
+	if err != nil || versioned {
+		return err
+	}
+
+	if _, ok := a.rules[r.name]; ok && !replace {
+		return fmt.Errorf("rule: duplicate command %q", r.name)
+	}
+
+	a.rules[r.name] = r
+	a.index(r.name)
+	return nil
+}
+
+// lookup returns the rule with the given name, if there is one.
+func (a *Application) lookup(name string) (*rule, bool) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	r, ok := a.rules[name]
+	return r, ok
+}
+
+// bind validates the command and prepares the rule to dispatch to it.
+func (r *rule) bind(command command) error {
+	if d, ok := command.(Runner); ok {
+		return r.bindDirect(d)
+	}
+
+	// Find the Run method dynamically, or else the RunStructured method.
+	v := reflect.ValueOf(command)
+	run := v.MethodByName("Run")
+	structured := false
+	if f, ok := command.(*funcCommand); ok {
+		run = f.fn
+	} else if !run.IsValid() {
+		run = v.MethodByName("RunStructured")
+		structured = run.IsValid()
+	}
+	if !run.IsValid() {
 		return errRunMissing
 	}
 
-	// Ensure that the parameters are all strings.
-	in := method.Type.NumIn()
-	for i := 1; i < in-1; i++ {
-		kind := method.Type.In(i).Kind()
-		if kind != reflect.String {
+	t := run.Type()
+
+	// The first parameter may optionally be a context.Context.
+	in := t.NumIn()
+	first := 0
+	if in > 0 && t.In(0) == contextType {
+		first = 1
+	}
+
+	// Ensure that the arguments can be converted to the parameters.
+	for i := first; i < in-1; i++ {
+		if !convertible(t.In(i)) {
 			return errRunString
 		}
 	}
 
-	// The last parameter may optionally be a string slice.
+	// The last parameter may optionally be a slice of the remaining arguments.
 	slice := false
-	if in > 1 {
-		final := method.Type.In(in - 1)
-		if final.Kind() == reflect.Slice && final.Elem().Kind() == reflect.String {
+	if in > first {
+		final := t.In(in - 1)
+		if final.Kind() == reflect.Slice && !convertible(final) && convertible(final.Elem()) {
 			slice = true
-		} else if final.Kind() != reflect.String {
+		} else if !convertible(final) {
 			return errRunString
 		}
 	}
 
-	// Ensure that the first return value, if any, is an int.
-	if method.Type.NumOut() >= 1 && method.Type.Out(0).Kind() != reflect.Int {
+	// Ensure that the first return value, if any, is an int or error, after
+	// the result of RunStructured.
+	out := 0
+	if structured {
+		if t.NumOut() < 1 {
+			return errRunResult
+		}
+
+		out = 1
+	}
+
+	if t.NumOut() > out && t.Out(out).Kind() != reflect.Int && t.Out(out) != errorType {
 		return errRunReturnValue
 	}
 
-	// Register a new FlagSet and define the flags provided by the command.
-	options := flag.NewFlagSet(name, flag.ExitOnError)
-	command.Flags(options)
+	// Ensure that any declared flags and arguments are valid. The values of
+	// the flag fields are kept as their defaults.
+	defaults := flagDefaults(command)
+	fields, err := declare(command, flag.NewFlagSet(r.name, flag.ContinueOnError))
+	if err == nil {
+		err = bindNames(fields, r.positional())
+	}
+	if err == nil && r.fanOut != nil && (!slice || len(fields) > 0) {
+		err = errFanOut
+	}
+	if err == nil && r.counts != nil {
+		err = r.counts.validate(accepted(slice, in-first, fields))
+	}
+	if err != nil {
+		return err
+	}
 
-	// Add the rule.
-	a.rules[name] = &rule{
-		command:   command,
-		method:    method,
-		slice:     slice,
-		name:      name,
-		options:   options,
-		arguments: arguments,
+	r.command = command
+	r.defaults = defaults
+	r.run = run
+	r.numIn = in
+	r.variadic = t.IsVariadic()
+	r.context = first == 1
+	r.slice = slice
+	r.structured = structured
+	r.converters = converters(t, first, slice)
+	r.zeros = r.zeros[:0]
+	for i := first; i < in; i++ {
+		r.zeros = append(r.zeros, reflect.Zero(t.In(i)))
 	}
+	if slice {
+		r.sliceType = t.In(in - 1)
+	}
+	r.reset()
 
 	return nil
 }
 
-// Run will parse flags and dispatch to the command.
+// reset registers a new FlagSet and defines the flags provided by the
+// command, restoring any previously parsed values to their defaults.
+func (r *rule) reset() {
+	r.options = flag.NewFlagSet(r.name, flag.ContinueOnError)
+	if f, ok := r.command.(flagger); ok {
+		f.Flags(r.options)
+	}
+
+	for _, fn := range r.define {
+		fn(r.options)
+	}
+
+	if r.direct == nil {
+		restore(r.command, r.defaults)
+	}
+
+	if d, ok := r.command.(defaulter); ok {
+		d.Defaults()
+	}
+
+	// The declarations were validated when the command was bound.
+	if r.direct == nil {
+		r.fields, _ = declare(r.command, r.options)
+		bindNames(r.fields, r.positional())
+	}
+
+	r.retry.define(r.options)
+}
+
+// load instantiates a lazily registered command if it has not been already.
+// It may be called concurrently, such as by the usage while the command is
+// dispatched.
+func (r *rule) load() error {
+	r.loading.Lock()
+	defer r.loading.Unlock()
+
+	if r.command != nil || r.factory == nil {
+		return nil
+	}
+
+	c := r.factory()
+	if c == nil {
+		return errFactoryNil
+	}
+
+	return r.bind(c)
+}
+
+// Run will parse the process arguments, dispatch to the command and exit
+// with its exit code. The global flag.CommandLine FlagSet is left untouched.
+//
+// The context passed to the command is cancelled when the process receives
+// SIGINT or SIGTERM so that long running commands may shut down cleanly. The
+// exit code is then 128 plus the number of the signal. A second signal exits
+// the process immediately with the same code, see ShutdownSignals and
+// ShutdownGrace.
+//
+// Run exits through the function set with SetExit, os.Exit by default, so
+// deferred functions of the caller do not run. Use Main to return the exit
+// code instead.
 func (a *Application) Run() {
-	flag.Usage = a.usage
-	flag.Parse()
+	a.exit(a.Main())
+}
+
+// RunMultiCall is Run for binaries installed under several names. If the
+// executable was invoked by the name of a registered command, such as
+// through a symlink, that command is run directly with the process
+// arguments. Otherwise the process arguments are dispatched as by Run. This
+// allows one binary to ship as several tools, in the manner of busybox.
+func (a *Application) RunMultiCall() {
+	a.exit(a.main(a.multiCall(os.Args)))
+}
+
+// Main is Run, returning the exit code instead of exiting once the functions
+// registered with OnExit have been called, so that the caller may run its
+// deferred functions before exiting itself. See Execute to run a command
+// without the arguments, streams and signals of the process.
+func (a *Application) Main() int {
+	return a.main(os.Args[1:])
+}
+
+// main is Main with the arguments args.
+func (a *Application) main(args []string) int {
+	// The hooks are called once, even if a forced exit returns.
+	var once sync.Once
+	onExit := func(code int) {
+		once.Do(func() {
+			for i := len(a.onExit) - 1; i >= 0; i-- {
+				a.onExit[i](code)
+			}
+		})
+	}
+
+	s := a.shutdown()
+	s.onExit = onExit
+	ctx, stop := notifyContext(context.Background(), s)
+	code := a.dispatch(ctx, args)
+	stop()
+	onExit(code)
+
+	return code
+}
+
+// OnExit registers fn to be called with the exit code once Run or Main
+// has dispatched to the command, such as to flush logs or close tracing
+// spans. The functions are called in the reverse order of registration, and
+// also before the process is exited by a second signal or once the grace
+// period elapses, see ShutdownGrace.
+func (a *Application) OnExit(fn func(code int)) {
+	a.onExit = append(a.onExit, fn)
+}
+
+// SetExit sets the function Run calls to exit the process, os.Exit by
+// default.
+func (a *Application) SetExit(fn func(code int)) {
+	a.exit = fn
+}
+
+// RunWithArgs parses args, excluding the program name, and dispatches to the
+// command with the given standard output and error streams, returning its exit
+// code rather than exiting. It allows the whole dispatch pipeline to be driven
+// from tests.
+func (a *Application) RunWithArgs(args []string, stdout, stderr io.Writer) int {
+	return a.dispatch(withStreams(context.Background(), streams{a.stdin, stdout, stderr}), args)
+}
+
+// multiCall returns the arguments to dispatch for the process arguments argv,
+// prepending the command named by argv[0] if there is one.
+func (a *Application) multiCall(argv []string) []string {
+	name := filepath.Base(argv[0])
+	name = strings.TrimSuffix(name, filepath.Ext(name))
+	if _, ok := a.lookup(name); ok && name != a.name {
+		return append([]string{name}, argv[1:]...)
+	}
+
+	return argv[1:]
+}
+
+// Dispatch parses args, excluding the program name, and dispatches to the
+// command, returning its exit code. Dispatch may be called any number of times
+// for the same Application. Flags are reset to their defaults on each call.
+// Invocations of the same command are serialized.
+func (a *Application) Dispatch(args []string) int {
+	return a.dispatch(context.Background(), args)
+}
+
+// dispatch is Dispatch using the standard streams of parent, if any.
+func (a *Application) dispatch(parent context.Context, args []string) int {
+	s, ok := ctxStreams(parent)
+	if !ok {
+		s = streams{a.stdin, a.stdout, a.stderr}
+		parent = withStreams(parent, s)
+	}
+
+	// The arguments are given again as they are if the command is elevated.
+	argv := args
+	parent, args, err := a.interpolateArgs(parent, args)
+	if err != nil {
+		a.errorf(s.stderr, "%v", err)
+		return ExitUsage
+	}
+
+	a.scanPlugins(s.stderr)
+
+	flags := flag.NewFlagSet(a.name, flag.ContinueOnError)
+	flags.SetOutput(s.stderr)
+	var g globals
+	a.defineGlobal(flags, &g)
+	if !a.noDefaults && flags.Lookup("version") == nil {
+		flags.Var(&g.version, "version", "Print the version and exit.")
+	}
+	if a.separator != "" && flags.Lookup("keep-going") == nil {
+		flags.BoolVar(&g.keepGoing, "keep-going", false, "Run the remaining chained commands after one fails.")
+	}
+	flags.Usage = func() { a.printMatching(s.stderr, "", g.out.noColor) }
+	a.prepareFlags("", flags)
+	global, deferred := a.deferFlags(flags, args)
+	err = flags.Parse(global)
+	if err != nil {
+		return a.flagError(parent, "", err)
+	}
+
+	globalSources := flagSources{}
+	globalSources.parsed(flags)
+	err = a.applyEnv(flags, a.env)
+	if err != nil {
+		a.errorf(s.stderr, "%v", err)
+		return ExitUsage
+	}
+	globalSources.env(a, flags, a.env)
+
+	// Layer the configuration file under the environment and the flags.
+	config, err := a.loadConfig(g.config)
+	if err != nil {
+		a.errorf(s.stderr, "%v", err)
+		return 1
+	}
 
-	// Run requires a command to dispatch to.
-	if flag.NArg() < 1 {
-		flag.Usage()
-		os.Exit(1)
+	config = g.set.apply(config)
+	err = applyConfig(flags, config, "")
+	if err != nil {
+		a.errorf(s.stderr, "%v", err)
+		return ExitUsage
+	}
+	globalSources.config(flags, "")
+
+	// The -version flag is short for the version command.
+	words := flags.Args()
+	if g.version {
+		words = []string{"version"}
+	}
+
+	// Run each of a chain of commands in turn, unless requested through the
+	// Handler.
+	if commands := a.chained(words); commands != nil && !remote(parent) {
+		return a.runChain(parent, args[:len(args)-len(words)], commands, g.keepGoing)
+	}
+
+	// Dispatch requires a command to dispatch to, which deferred flags must
+	// belong to.
+	if len(words) < 1 && len(deferred) > 0 {
+		return a.flagError(parent, "", flags.Parse(deferred))
+	}
+
+	if len(words) < 1 {
+		flags.Usage()
+		return 1
 	}
 
 	// Dispatch or error if the command was not registered.
-	name := flag.Arg(0)
-	rule, ok := a.rules[name]
+	name, rest := a.resolve(words)
+	if name == completeCommand && a.completion && !remote(parent) {
+		a.complete(s.stdout, rest)
+		return 0
+	}
+
+	if name == metaCommand && !remote(parent) {
+		err = a.meta(s.stdout)
+		if err != nil {
+			a.errorf(s.stderr, "%s: %v", name, err)
+			return 1
+		}
+
+		return 0
+	}
+
+	rule, ok := a.lookup(name)
+	if !ok {
+		rule, err = a.plugin(words[0])
+		if err != nil {
+			a.errorf(s.stderr, "%s: %v", words[0], err)
+			return 1
+		}
+
+		if rule != nil {
+			name, rest = words[0], words[1:]
+			ok = true
+		}
+	}
+
 	if !ok {
-		fmt.Fprintf(os.Stderr, "Error: invalid command %s\n", name)
-		flag.Usage()
-		os.Exit(1)
+		suggestions, distance := a.suggest(name)
+		if len(suggestions) == 0 {
+			a.errorf(s.stderr, a.messages.InvalidCommand, name)
+			flags.Usage()
+			return 1
+		}
+
+		if !a.suggestRun || distance != 1 || len(suggestions) != 1 {
+			a.errorf(s.stderr, a.messages.UnknownCommand, name, suggestions[0])
+			flags.Usage()
+			return 1
+		}
+
+		fmt.Fprintf(s.stderr, a.messages.RunningSuggestion+"\n", name, suggestions[0])
+		name = suggestions[0]
+		rule, _ = a.lookup(name)
+	}
+
+	rule, err = a.selectVersion(rule, flags)
+	if err != nil {
+		a.errorf(s.stderr, "%s: %v", name, err)
+		return ExitUsage
+	}
+
+	// Local commands are not found through the Handler, including those
+	// dispatched by the commands it runs.
+	if remote(parent) && rule.local {
+		a.errorf(s.stderr, a.messages.InvalidCommand, name)
+		return 1
+	}
+
+	// Flags given before the command name that are not global are its own.
+	if len(deferred) > 0 {
+		rest = append(append([]string{}, deferred...), rest...)
+	}
+
+	// A command dispatching to itself would wait for itself to complete.
+	if inv, ok := parent.Value(invocationContextKey{}).(*invocation); ok && (inv.rule == rule || inv.rule.original == rule) {
+		a.errorf(s.stderr, "%s: "+a.messages.RunsItself, name)
+		return 1
+	}
+
+	if gated, g := a.gated(name); g != nil {
+		a.errorf(s.stderr, "%s", g.message(a, gated))
+		return 1
+	}
+
+	// Requests to the Handler cannot be answered with input.
+	noInput := g.noInput || remote(parent)
+
+	// Run the first-run setup before any other command.
+	if a.needsSetup(name) {
+		if code, ok := a.runSetup(parent, s, noInput); !ok {
+			return code
+		}
+	}
+
+	// Parallel batch invocations run on their own copy of the command.
+	if forking(parent) && rule.load() == nil {
+		rule = rule.fork()
+	}
+
+	rule.mu.Lock()
+	defer rule.mu.Unlock()
+
+	if !rule.deprecated.check(s.stderr, &a.messages, name, a.version) {
+		return 1
+	}
+
+	// Instantiate the command if it was registered lazily.
+	err = rule.load()
+	if err != nil {
+		a.errorf(s.stderr, "%s: %v", name, err)
+		return 1
+	}
+
+	// Parse the remaining arguments for the command with fresh flags.
+	rule.reset()
+	rule.options.SetOutput(s.stderr)
+	rule.options.Usage = func() { a.printHelp(s.stderr, rule, g.out.noColor) }
+	a.prepareFlags(name, rule.options)
+	if rule.wantsHelp(rest) {
+		rule.options.Usage()
+		return 0
+	}
+
+	args, extra, err := argsFrom(parent, rule, rest)
+	if err != nil {
+		a.errorf(s.stderr, "%s: %v", name, err)
+		return 1
+	}
+
+	inv := &invocation{
+		app:       a,
+		rule:      rule,
+		args:      append(append([]string{}, args...), extra...),
+		noCache:   g.noCache,
+		dryRun:    g.dryRun,
+		yes:       g.yes,
+		noInput:   noInput,
+		output:    g.out,
+		locale:    g.locale,
+		noBrowser: g.noBrowser,
+		noPager:   g.noPager,
+		config:    config,
+		global:    flags,
+		sources:   globalSources,
+	}
+	defer inv.close()
+	ctx := withInvocation(parent, inv)
+
+	// External commands parse their own flags.
+	if _, ok := rule.command.(*External); ok {
+		args = append([]string{"--"}, args...)
+	}
+
+	args, err = rule.parseFlags(args, a.parseMode)
+	if err != nil {
+		return a.flagError(parent, name, err)
+	}
+
+	// Note where each flag was set from for -trace.
+	sources := flagSources{}
+	sources.parsed(rule.options)
+	err = a.applyEnv(rule.options, rule.env)
+	if err == nil {
+		sources.env(a, rule.options, rule.env)
+		err = applyConfig(rule.options, config, configPrefix(name))
+	}
+	if err == nil {
+		sources.config(rule.options, configPrefix(name))
+		err = rule.checkGroups()
+	}
+	if err != nil {
+		a.errorf(s.stderr, "%s: %v", name, err)
+		return ExitUsage
+	}
+
+	if rule.argFiles {
+		args, err = expandArgFiles(ctx, args)
+		if err != nil {
+			a.errorf(s.stderr, "%s: %v", name, err)
+			return 1
+		}
+	}
+
+	args = append(args, extra...)
+
+	policy, err := a.loadPolicy()
+	if err != nil {
+		a.errorf(s.stderr, "%s: %v", name, err)
+		return 1
+	}
+
+	if policy != nil {
+		err = policy.check(name, rule.options, flags)
+		if err != nil {
+			a.errorf(s.stderr, "%s: %v", name, err)
+			return ExitPolicy
+		}
+	}
+
+	err = rule.arity(args, a.strict)
+	if err == nil {
+		err = rule.validateArgs(args)
+	}
+	if err != nil {
+		a.errorf(s.stderr, "%s: %v", name, err)
+		a.printMatching(s.stderr, name, g.out.noColor)
+		return ExitUsage
+	}
+
+	if rule.privilege && !isPrivileged() {
+		return a.elevated(ctx, name, argv)
+	}
+
+	err = resolveSecrets(ctx, rule.options)
+	if err == nil {
+		ctx, err = a.authenticate(ctx, rule)
+	}
+	if err != nil {
+		a.errorf(s.stderr, "%s: %v", name, err)
+		return 1
+	}
+
+	err = rule.authorize(ctx)
+	if err != nil {
+		a.errorf(s.stderr, "%s: "+a.messages.PermissionDenied, name, err)
+		return ExitPolicy
 	}
 
-	// Parse the remaining arguments for the command.
-	args := flag.Args()
-	rule.options.Parse(args[1:])
+	// Runs are only recorded once confirmed, but need not be confirmed if
+	// they would be refused.
+	if a.rateLimited(rule, s, false) {
+		return 1
+	}
+
+	err = a.confirm(ctx, rule)
+	if err != nil {
+		a.errorf(s.stderr, "%s: %v", name, err)
+		return 1
+	}
+
+	if a.rateLimited(rule, s, true) {
+		return 1
+	}
+
+	limit := rule.timeLimit(g.timeout)
+	if limit > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, limit)
+		defer cancel()
+	}
+
+	if g.stats {
+		defer sampleUsage().report(s.stderr, &a.messages)
+	}
+
+	if g.trace {
+		a.trace(s.stderr, rule, flags, globalSources, sources, args)
+	}
+
+	// Call the command through any middleware.
+	start := time.Now()
+	code := a.execute(ctx, rule, args)
+
+	// Report why the invocation stopped early, if it did.
+	if c, ok := cancelCode(ctx); ok {
+		code = c
+		if code == ExitTimeout && limit > 0 {
+			a.errorf(s.stderr, "%s: "+a.messages.TimedOut, name, limit)
+		}
+	}
+
+	a.record(ctx, rule, start, code)
+	if a.setup != nil && name == a.setup.name && code == 0 {
+		err = a.completeSetup()
+		if err != nil {
+			a.errorf(s.stderr, "%s: %v", name, err)
+		}
+	}
+
+	return code
+}
 
+// call calls the Run method of the command with the positional arguments and
+// waits for any goroutines it started, returning the exit code.
+func (r *rule) call(parent context.Context, args []string) (code int) {
 	// Prepare the calling parameters.
-	params := make([]reflect.Value, rule.method.Type.NumIn())
-
-	// Method expressions take the receiver as the first argument.
-	params[0] = reflect.ValueOf(rule.command)
-
-	// Set all but the last parameter.
-	args = rule.options.Args()
-	for i := 1; i < len(params)-1; i++ {
-		if i < len(args)+1 {
-			params[i] = reflect.ValueOf(args[i-1])
-		} else {
-			params[i] = reflect.ValueOf("")
+	params := make([]reflect.Value, r.numIn)
+
+	// Provide the invocation context if requested.
+	group, ctx := NewTaskGroup(parent)
+	first := 0
+	if r.context {
+		params[0] = reflect.ValueOf(ctx)
+		first = 1
+	}
+
+	// Convert the positional arguments for the declared fields and the
+	// remaining parameters.
+	err := bindFields(r.fields, args)
+	if err == nil {
+		err = r.bindArgs(params, first, args)
+	}
+	if err != nil {
+		ctxInvocation(ctx).app.printError(Stderr(ctx), r, err)
+		ctxInvocation(ctx).fail(err)
+		group.Wait()
+		return ExitUsage
+	}
+
+	// Provide the standard streams of the invocation if requested.
+	if s, ok := r.command.(streamer); ok {
+		s.SetIO(Stdin(ctx), Stdout(ctx), Stderr(ctx))
+	}
+
+	// Provide the logger of the invocation if requested.
+	if l, ok := r.command.(loggerSetter); ok {
+		l.SetLogger(Logger(ctx))
+	}
+
+	// Provide the leveled output of the invocation if requested.
+	if o, ok := r.command.(outSetter); ok {
+		o.SetOut(Out(ctx))
+	}
+
+	// Clean up after the command even if it fails to initialize or panics.
+	if c, ok := r.command.(cleaner); ok {
+		defer r.cleanup(ctx, c, &code)
+	}
+
+	// Inject the values provided to the Application if requested.
+	err = ctxInvocation(ctx).app.container.inject(ctx, r.command)
+	if err != nil {
+		ctxInvocation(ctx).app.printError(Stderr(ctx), r, err)
+		ctxInvocation(ctx).fail(err)
+		group.Wait()
+		return 1
+	}
+
+	if i, ok := r.command.(initializer); ok {
+		err = i.Init(ctx)
+		if err != nil {
+			ctxInvocation(ctx).app.printError(Stderr(ctx), r, err)
+			ctxInvocation(ctx).fail(err)
+			group.Wait()
+			return 1
 		}
 	}
 
-	// Set the final parameter. May be a slice of the remaining args.
-	i := len(params) - 1
-	if rule.slice {
-		params[i] = reflect.Zero(reflect.SliceOf(reflect.TypeOf("")))
-		for j := i - 1; j < len(args); j++ {
-			params[i] = reflect.Append(params[i], reflect.ValueOf(args[j]))
+	// Call the command RunE method directly, or its Run method through
+	// reflection.
+	var result, failure error
+	if r.direct != nil {
+		failure = r.direct(ctx, Args(args))
+	} else {
+		code, result, failure = r.invoke(ctx, params)
+	}
+
+	// Report an error returned by the command.
+	if failure != nil {
+		app := ctxInvocation(ctx).app
+		err := limitError(ctx, failure)
+		app.printError(Stderr(ctx), r, err)
+		ctxInvocation(ctx).fail(err)
+		if code == 0 {
+			code = app.errorExitCode(err)
 		}
-	} else if i > 1 {
-		if i < len(args)+1 {
-			params[i] = reflect.ValueOf(args[i-1])
-		} else {
-			params[i] = reflect.ValueOf("")
+	}
+
+	// Wait for any goroutines started by the command.
+	err = group.Wait()
+	if err == nil {
+		err = result
+	}
+	if err != nil {
+		ctxInvocation(ctx).app.printError(Stderr(ctx), r, err)
+		ctxInvocation(ctx).fail(err)
+		if code == 0 {
+			code = ctxInvocation(ctx).app.errorExitCode(err)
 		}
 	}
 
-	// Call the command Run method.
-	rv := rule.method.Func.Call(params)
+	return code
+}
+
+// invoke calls the Run method of the command with params, returning its
+// exit code and error, and any error printing the result of RunStructured.
+func (r *rule) invoke(ctx context.Context, params []reflect.Value) (code int, result, err error) {
+	// Pass the final slice of a variadic Run method as its variadic
+	// arguments.
+	var rv []reflect.Value
+	if r.variadic {
+		rv = r.run.CallSlice(params)
+	} else {
+		rv = r.run.Call(params)
+	}
+
+	// Print the result of RunStructured in the negotiated format.
+	if r.structured {
+		if !isNil(rv[0]) {
+			result = NewPrinter(ctx).Print(rv[0].Interface())
+		}
 
-	// Exit with an appropriate error code.
-	code := 0
-	if len(rv) > 0 {
+		rv = rv[1:]
+	}
+
+	if len(rv) > 0 && rv[0].Kind() == reflect.Int {
 		code = int(rv[0].Int())
+		rv = rv[1:]
+	}
+
+	if len(rv) > 0 && rv[0].Type() == errorType && !rv[0].IsNil() {
+		err = rv[0].Interface().(error)
 	}
 
-	os.Exit(code)
+	return code, result, err
 }
 
-// Find the longest rule and return its length.
-func (a *Application) getRuleLength() int {
-	max := 0
-	for _, rule := range a.rules {
-		length := len(rule.String())
-		if length > max {
-			max = length
-		}
+// index records name in the sorted list of rule names. The caller must hold
+// the lock of the Application.
+func (a *Application) index(name string) {
+	i := sort.SearchStrings(a.names, name)
+	if i < len(a.names) && a.names[i] == name {
+		return
 	}
 
-	// Add some padding for distinction.
-	return max + 3
-}
-
-// PrintUsage pretty prints the application usage across all commands.
-func (a *Application) printUsage(w io.Writer) {
-	length := a.getRuleLength()
-	fmt.Fprintf(w, "Usage: %s <cmd> [options] [<args>]\n", a.name)
-	for _, rule := range a.rules {
-		spaces := strings.Repeat(" ", length-len(rule.String()))
-		fmt.Fprintf(w, "  %s%s%s\n", rule, spaces, rule.command)
-
-		rule.options.VisitAll(func(flag *flag.Flag) {
-			value := flag.DefValue
-			if value == "" {
-				value = "<value>"
-			} else if value == "false" {
-				value = ""
-			} else if _, err := strconv.Atoi(value); err == nil {
-				value = "<n>"
-			} else {
-				value = "\"" + value + "\""
-			}
+	a.names = append(a.names, "")
+	copy(a.names[i+1:], a.names[i:])
+	a.names[i] = name
+	a.order = append(a.order, name)
+}
+
+// match returns the sorted names of the rules beginning with prefix.
+func (a *Application) match(prefix string) []string {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
 
-			option := "-" + flag.Name
-			if value != "" {
-				option += "=" + value
+	i := sort.SearchStrings(a.names, prefix)
+	j := i
+	for j < len(a.names) && strings.HasPrefix(a.names[j], prefix) {
+		j++
+	}
+
+	return append([]string{}, a.names[i:j]...)
+}
+
+// SortUsage lists the commands in the usage in alphabetical order rather
+// than in the order in which they were registered.
+func (a *Application) SortUsage(sorted bool) {
+	a.sorted = sorted
+}
+
+// ordered returns the sorted names in the order in which they are listed in
+// the usage. Commands in a group follow their group.
+func (a *Application) ordered(names []string) []string {
+	if a.sorted {
+		return a.place(names)
+	}
+
+	a.mu.RLock()
+	seq := make(map[string]int, len(a.order))
+	for i, name := range a.order {
+		seq[name] = i
+	}
+	a.mu.RUnlock()
+
+	keys := make(map[string][]int, len(names))
+	for _, name := range names {
+		parts := strings.Split(name, " ")
+		key := make([]int, len(parts))
+		for i := range parts {
+			n, ok := seq[strings.Join(parts[:i+1], " ")]
+			if !ok {
+				n = seq[name]
 			}
 
-			spaces := strings.Repeat(" ", length-len(option)-2)
-			fmt.Fprintf(w, "    %s%s%s\n", option, spaces, flag.Usage)
-		})
+			key[i] = n
+		}
+
+		keys[name] = key
 	}
 
-	fmt.Fprintf(w, "\n")
+	out := append([]string{}, names...)
+	sort.SliceStable(out, func(i, j int) bool {
+		a, b := keys[out[i]], keys[out[j]]
+		for k := 0; k < len(a) && k < len(b); k++ {
+			if a[k] != b[k] {
+				return a[k] < b[k]
+			}
+		}
+
+		return len(a) < len(b)
+	})
+
+	return a.place(out)
+}
+
+// PrintUsage pretty prints the application usage across all commands to w.
+func (a *Application) PrintUsage(w io.Writer) {
+	a.printMatching(w, "", false)
 }
 
-// Usage is called on flag parsing errors.
-func (a *Application) usage() {
-	a.printUsage(os.Stderr)
+// printMatching renders the usage of the commands beginning with prefix.
+func (a *Application) printMatching(w io.Writer, prefix string, noColor bool) {
+	a.render(w, noColor).Usage(w, a.usage(prefix))
+}
+
+// groupKey returns the leading segment of a command name used to group
+// related commands, including its separator.
+func groupKey(name string) string {
+	i := strings.IndexAny(name, "-:.")
+	if i < 0 {
+		return name
+	}
+
+	return name[:i+1]
 }
 
 // String formats the rule for usage printing.
@@ -1,7 +1,14 @@
 package cli
 
 import (
+	"bytes"
+	"errors"
 	"flag"
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
 	"testing"
 )
 
@@ -9,6 +16,17 @@ type runFull struct {
 	number *int
 }
 
+type runRecord struct {
+	number *int
+	have   []string
+	seen   int
+}
+
+type runPassThrough struct {
+	dir  *string
+	have []string
+}
+
 type runErrMissing struct {
 	*NullFlags
 }
@@ -21,6 +39,14 @@ type runErrReturnValue struct {
 	*NullFlags
 }
 
+type runError struct {
+	*NullFlags
+}
+
+type runCodeError struct {
+	*NullFlags
+}
+
 func TestNew(t *testing.T) {
 	app := New("myapp", "0.0.1")
 	if len(app.rules) != 2 {
@@ -28,6 +54,24 @@ func TestNew(t *testing.T) {
 	}
 }
 
+func TestNewLazy(t *testing.T) {
+	app := New("myapp", "0.0.1")
+	for _, name := range []string{"help", "version"} {
+		if app.rules[name].command != nil {
+			t.Errorf("%s instantiated before use", name)
+		}
+	}
+
+	err := app.rules["help"].load()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if app.rules["help"].command == nil {
+		t.Errorf("help not instantiated after load")
+	}
+}
+
 func TestRuleRunFull(t *testing.T) {
 	app := New("myapp", "0.0.1")
 	err := app.Rule(&runFull{}, "full", "<arg1> <arg2> [<extra>]")
@@ -64,6 +108,260 @@ func TestRuleRunReturnValue(t *testing.T) {
 	}
 }
 
+func TestRuleName(t *testing.T) {
+	app := New("myapp", "0.0.1")
+	app.Group("remote", "Manage remotes.")
+	app.Rule(&runFull{}, "build", "")
+
+	tests := map[string]string{
+		"":              "rule: empty command name",
+		"build":         `rule: duplicate command "build"`,
+		"help":          `rule: duplicate command "help"`,
+		"two  spaces":   `rule: invalid command name "two  spaces"`,
+		"tab\there":     `rule: invalid command name "tab\there"`,
+		"-flag":         `rule: invalid command name "-flag"`,
+		"missing child": `rule: invalid command name "missing child", register subcommands with Group`,
+		"remote add":    "",
+	}
+
+	for name, want := range tests {
+		err := app.Rule(&runFull{}, name, "")
+		if have := fmt.Sprint(err); want != "" && have != want || want == "" && err != nil {
+			t.Errorf("%q\nhave %v\nwant %s", name, err, want)
+		}
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("MustRule\nhave no panic\nwant panic")
+		}
+	}()
+	app.MustRule(&runFull{}, "build", "")
+}
+
+func TestRunError(t *testing.T) {
+	app := New("myapp", "0.0.1")
+	app.Rule(&runError{}, "error", "[<fail>]")
+	app.Rule(&runCodeError{}, "code", "<code>")
+
+	tests := []struct {
+		args   []string
+		code   int
+		stderr string
+	}{
+		{[]string{"error"}, 0, ""},
+		{[]string{"error", "yes"}, 1, "myapp: error: failed\n"},
+		{[]string{"code", "3"}, 3, "myapp: code: failed with 3\n"},
+	}
+
+	for _, tt := range tests {
+		var stdout, stderr bytes.Buffer
+		code := app.RunWithArgs(tt.args, &stdout, &stderr)
+		if code != tt.code {
+			t.Errorf("%q exit code\nhave %d\nwant %d", tt.args, code, tt.code)
+		}
+
+		if stderr.String() != tt.stderr {
+			t.Errorf("%q stderr\nhave %q\nwant %q", tt.args, stderr.String(), tt.stderr)
+		}
+	}
+}
+
+func TestPrintUsageCompact(t *testing.T) {
+	app := New("myapp", "0.0.1")
+	for i := 0; i < compactThreshold; i++ {
+		app.Rule(&runFull{}, fmt.Sprintf("gen-%03d", i), "<arg1>")
+	}
+
+	var buf bytes.Buffer
+	app.PrintUsage(&buf)
+	have := buf.String()
+	if !strings.Contains(have, "  gen-*   ") || strings.Contains(have, "gen-000") {
+		t.Errorf("compact usage did not group commands\n%s", have)
+	}
+
+	buf.Reset()
+	app.printMatching(&buf, "gen-01", false)
+	have = buf.String()
+	if strings.Count(have, "-number") != 10 || strings.Contains(have, "gen-020") {
+		t.Errorf("filtered usage\n%s", have)
+	}
+}
+
+func TestPrintUsageOrder(t *testing.T) {
+	app := New("myapp", "0.0.1")
+	app.Rule(&runRecord{}, "zebra", "")
+	remote := app.Group("remote", "Manage remotes.")
+	app.Rule(&runRecord{}, "apple", "")
+	remote.Rule(&runRecord{}, "add", "")
+
+	order := func() []string {
+		var buf bytes.Buffer
+		app.PrintUsage(&buf)
+		var names []string
+		for _, line := range strings.Split(buf.String(), "\n") {
+			fields := strings.Fields(line)
+			if strings.HasPrefix(line, "  ") && !strings.HasPrefix(fields[0], "-") {
+				names = append(names, fields[0])
+			}
+		}
+
+		return names
+	}
+
+	want := []string{"help", "version", "zebra", "remote", "add", "apple"}
+	if have := order(); !reflect.DeepEqual(have, want) {
+		t.Errorf("registration order\nhave %q\nwant %q", have, want)
+	}
+
+	app.SortUsage(true)
+	want = []string{"apple", "help", "remote", "add", "version", "zebra"}
+	if have := order(); !reflect.DeepEqual(have, want) {
+		t.Errorf("sorted order\nhave %q\nwant %q", have, want)
+	}
+}
+
+func TestDispatchReentrant(t *testing.T) {
+	app := New("myapp", "0.0.1")
+	cmd := &runRecord{}
+	app.Rule(cmd, "record", "<a> [<b>]")
+
+	code := app.Dispatch([]string{"record", "-number", "5", "x", "y"})
+	if code != 5 || cmd.seen != 5 {
+		t.Errorf("first dispatch\nhave %d %d\nwant %d %d", code, cmd.seen, 5, 5)
+	}
+
+	code = app.Dispatch([]string{"record", "z"})
+	if code != 0 || cmd.seen != 0 {
+		t.Errorf("second dispatch\nhave %d %d\nwant %d %d", code, cmd.seen, 0, 0)
+	}
+
+	if want := []string{"z", ""}; !reflect.DeepEqual(cmd.have, want) {
+		t.Errorf("arguments\nhave %q\nwant %q", cmd.have, want)
+	}
+}
+
+func TestDispatchTerminator(t *testing.T) {
+	app := New("myapp", "0.0.1")
+	cmd := &runPassThrough{}
+	app.Rule(cmd, "exec", "<cmd> [<args>...]")
+
+	code := app.Dispatch([]string{"exec", "-dir", "/tmp", "--", "ls", "-l", "--", "--all"})
+	if code != 0 {
+		t.Fatalf("exit code\nhave %d\nwant %d", code, 0)
+	}
+
+	if want := []string{"ls", "-l", "--", "--all"}; *cmd.dir != "/tmp" || !reflect.DeepEqual(cmd.have, want) {
+		t.Errorf("arguments\nhave %q %q\nwant %q %q", *cmd.dir, cmd.have, "/tmp", want)
+	}
+}
+
+func TestRuleRunVariadic(t *testing.T) {
+	app := New("myapp", "0.0.1")
+
+	var have []string
+	err := app.RuleFunc("join", "Join words.", "<first> [<rest>...]", func(first string, rest ...string) int {
+		have = append([]string{first}, rest...)
+		return len(rest)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		args []string
+		code int
+	}{
+		{[]string{"join", "a"}, 0},
+		{[]string{"join", "a", "b", "c"}, 2},
+	}
+
+	for _, tt := range tests {
+		code := app.Dispatch(tt.args)
+		if code != tt.code || !reflect.DeepEqual(have, tt.args[1:]) {
+			t.Errorf("%q\nhave %d %q\nwant %d %q", tt.args, code, have, tt.code, tt.args[1:])
+		}
+	}
+}
+
+func TestNameVersion(t *testing.T) {
+	app := New("myapp", "0.0.1")
+	if app.Name() != "myapp" || app.Version() != "0.0.1" {
+		t.Errorf("name and version\nhave %q %q\nwant %q %q", app.Name(), app.Version(), "myapp", "0.0.1")
+	}
+}
+
+func TestRunWithArgs(t *testing.T) {
+	app := New("myapp", "0.0.1")
+	app.Rule(&runRecord{}, "record", "<a> <b>")
+
+	tests := []struct {
+		args   []string
+		code   int
+		stdout string
+	}{
+		{[]string{"version"}, 0, "myapp v0.0.1\n"},
+		{[]string{"-undefined", "version"}, ExitUsage, ""},
+		{[]string{"record", "-number", "x"}, ExitUsage, ""},
+		{[]string{"record", "-h"}, 0, ""},
+	}
+
+	for _, tt := range tests {
+		var stdout, stderr bytes.Buffer
+		code := app.RunWithArgs(tt.args, &stdout, &stderr)
+		if code != tt.code {
+			t.Errorf("%q exit code\nhave %d\nwant %d", tt.args, code, tt.code)
+		}
+
+		if stdout.String() != tt.stdout {
+			t.Errorf("%q stdout\nhave %q\nwant %q", tt.args, stdout.String(), tt.stdout)
+		}
+	}
+}
+
+func TestMultiCall(t *testing.T) {
+	app := New("myapp", "0.0.1")
+	app.Rule(&runRecord{}, "record", "<a> <b>")
+
+	tests := []struct {
+		argv []string
+		want []string
+	}{
+		{[]string{"/usr/bin/myapp", "record", "x"}, []string{"record", "x"}},
+		{[]string{"/usr/bin/record", "x"}, []string{"record", "x"}},
+		{[]string{"record.exe", "x"}, []string{"record", "x"}},
+		{[]string{"other", "help"}, []string{"help"}},
+	}
+
+	for _, tt := range tests {
+		have := app.multiCall(tt.argv)
+		if !reflect.DeepEqual(have, tt.want) {
+			t.Errorf("multiCall(%q)\nhave %q\nwant %q", tt.argv, have, tt.want)
+		}
+	}
+}
+
+func TestRunMultiCall(t *testing.T) {
+	defer func(args []string) { os.Args = args }(os.Args)
+
+	code := -1
+	app := New("myapp", "0.0.1", WithExitFunc(func(c int) { code = c }))
+	cmd := &runRecord{}
+	app.Rule(cmd, "record", "<a> <b>")
+
+	os.Args = []string{"/usr/bin/record", "-number", "3", "x", "y"}
+	app.RunMultiCall()
+	if code != 3 || !reflect.DeepEqual(cmd.have, []string{"x", "y"}) {
+		t.Errorf("symlink\nhave %d %q\nwant %d %q", code, cmd.have, 3, []string{"x", "y"})
+	}
+
+	os.Args = []string{"/usr/bin/myapp", "record", "-number", "4", "z", "w"}
+	app.RunMultiCall()
+	if code != 4 || !reflect.DeepEqual(cmd.have, []string{"z", "w"}) {
+		t.Errorf("fallback\nhave %d %q\nwant %d %q", code, cmd.have, 4, []string{"z", "w"})
+	}
+}
+
 func (c *runFull) Flags(flags *flag.FlagSet) {
 	c.number = flags.Int("number", 0, "some number")
 }
@@ -76,9 +374,56 @@ func (c *runFull) String() string {
 	return "runFull help"
 }
 
-func (c *runErrString) Run(n int)        {}
+func (c *runRecord) Flags(flags *flag.FlagSet) {
+	c.number = flags.Int("number", 0, "exit code")
+}
+
+func (c *runRecord) Run(a, b string) int {
+	c.have = []string{a, b}
+	c.seen = *c.number
+	return *c.number
+}
+
+func (c *runRecord) String() string {
+	return "record arguments"
+}
+
+func (c *runPassThrough) Flags(flags *flag.FlagSet) {
+	c.dir = flags.String("dir", "", "working directory")
+}
+
+func (c *runPassThrough) Run(name string, args []string) {
+	c.have = append([]string{name}, args...)
+}
+
+func (c *runPassThrough) String() string {
+	return "run a program"
+}
+
+func (c *runErrString) Run(n chan int)   {}
 func (c *runErrReturnValue) Run() string { return "fail" }
 
+func (c *runError) Run(fail string) error {
+	if fail != "" {
+		return errors.New("failed")
+	}
+
+	return nil
+}
+
+func (c *runError) String() string {
+	return "fail with an error"
+}
+
+func (c *runCodeError) Run(code string) (int, error) {
+	n, _ := strconv.Atoi(code)
+	return n, fmt.Errorf("failed with %d", n)
+}
+
+func (c *runCodeError) String() string {
+	return "fail with a code and an error"
+}
+
 func (c *runErrMissing) String() string     { return "missing run method" }
 func (c *runErrString) String() string      { return "invalid param type" }
 func (c *runErrReturnValue) String() string { return "invalid return value" }
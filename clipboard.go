@@ -0,0 +1,96 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+var errClipboard = errors.New("clipboard: no clipboard utility found")
+
+// A Copy offers to place the output of a command, such as a token, URL or ID,
+// on the system clipboard. Define one with CopyVar.
+type Copy struct {
+	enabled bool
+}
+
+// CopyVar defines a -copy flag that places the output given to Write on the
+// system clipboard.
+func CopyVar(flags *flag.FlagSet) *Copy {
+	c := &Copy{}
+	flags.BoolVar(&c.enabled, "copy", false, "Copy the result to the clipboard.")
+	return c
+}
+
+// Write prints text to the standard output of the invocation of the command
+// that received ctx and, if the -copy flag was given, also writes it to the
+// system clipboard.
+func (c *Copy) Write(ctx context.Context, text string) error {
+	fmt.Fprintln(Stdout(ctx), text)
+	if !c.enabled {
+		return nil
+	}
+
+	err := WriteClipboard(ctx, text)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintln(Stderr(ctx), ctxMessages(ctx).CopiedToClipboard)
+	return nil
+}
+
+// WriteClipboard places text on the system clipboard using the utility
+// provided by the platform: pbcopy on macOS, clip on Windows, and wl-copy,
+// xclip or xsel on Linux and other Unix systems depending on whether the
+// session uses Wayland or X11.
+func WriteClipboard(ctx context.Context, text string) error {
+	for _, argv := range clipboardCommands(runtime.GOOS, os.Getenv) {
+		path, err := exec.LookPath(argv[0])
+		if err != nil {
+			continue
+		}
+
+		cmd := exec.CommandContext(ctx, path, argv[1:]...)
+		cmd.Stdin = strings.NewReader(text)
+		cmd.Stderr = Stderr(ctx)
+		err = cmd.Run()
+		if err != nil {
+			return fmt.Errorf("clipboard: %s: %v", argv[0], err)
+		}
+
+		return nil
+	}
+
+	return errClipboard
+}
+
+// clipboardCommands returns the candidate commands for writing stdin to the
+// clipboard, in order of preference.
+func clipboardCommands(goos string, getenv func(string) string) [][]string {
+	switch goos {
+	case "darwin":
+		return [][]string{{"pbcopy"}}
+	case "windows":
+		return [][]string{{"clip"}}
+	}
+
+	var commands [][]string
+	if getenv("WAYLAND_DISPLAY") != "" {
+		commands = append(commands, []string{"wl-copy"})
+	}
+
+	if getenv("DISPLAY") != "" {
+		commands = append(commands,
+			[]string{"xclip", "-selection", "clipboard"},
+			[]string{"xsel", "--clipboard", "--input"},
+		)
+	}
+
+	return commands
+}
@@ -0,0 +1,32 @@
+package cli
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestClipboardCommands(t *testing.T) {
+	tests := []struct {
+		goos string
+		env  map[string]string
+		want []string
+	}{
+		{"darwin", nil, []string{"pbcopy"}},
+		{"windows", nil, []string{"clip"}},
+		{"linux", map[string]string{"WAYLAND_DISPLAY": "wayland-0", "DISPLAY": ":0"}, []string{"wl-copy", "xclip", "xsel"}},
+		{"linux", map[string]string{"DISPLAY": ":0"}, []string{"xclip", "xsel"}},
+		{"linux", nil, nil},
+	}
+
+	for _, tt := range tests {
+		var have []string
+		getenv := func(key string) string { return tt.env[key] }
+		for _, argv := range clipboardCommands(tt.goos, getenv) {
+			have = append(have, argv[0])
+		}
+
+		if !reflect.DeepEqual(have, tt.want) {
+			t.Errorf("clipboardCommands(%s, %v)\nhave %q\nwant %q", tt.goos, tt.env, have, tt.want)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+// Package clitest provides helpers for testing the commands of a
+// cli.Application: running them with captured output and comparing their
+// output with golden files.
+package clitest
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	cli "github.com/pnelson/cli-reflection"
+)
+
+// UpdateEnv is the environment variable that, when set to a non-empty value,
+// makes Golden write the output to the golden files rather than compare it,
+// such as with UPDATE_GOLDEN=1 go test ./...
+const UpdateEnv = "UPDATE_GOLDEN"
+
+// A Result is the outcome of running a command.
+type Result struct {
+	Stdout string
+	Stderr string
+	Code   int
+}
+
+// Run runs the command with the given name, such as "remote add", and
+// arguments, with cli.Application.Execute, returning its output and exit
+// code. Global flags may be given before the command name in name, such as
+// "-json list".
+func Run(t testing.TB, app *cli.Application, name string, args ...string) Result {
+	t.Helper()
+
+	argv := append(strings.Fields(name), args...)
+	stdout, stderr, code := app.Execute(argv)
+	return Result{Stdout: stdout, Stderr: stderr, Code: code}
+}
+
+// Golden compares have with the contents of the file with the given name in
+// the testdata directory, failing the test if they differ, or writes have to
+// the file if the UpdateEnv environment variable is set.
+func Golden(t testing.TB, name, have string) {
+	t.Helper()
+
+	path := filepath.Join("testdata", name)
+	if os.Getenv(UpdateEnv) != "" {
+		err := os.MkdirAll(filepath.Dir(path), 0755)
+		if err == nil {
+			err = ioutil.WriteFile(path, []byte(have), 0644)
+		}
+		if err != nil {
+			t.Fatalf("clitest: %v", err)
+		}
+
+		return
+	}
+
+	want, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatalf("clitest: %v, set %s=1 to create it", err, UpdateEnv)
+	}
+
+	if !bytes.Equal([]byte(have), want) {
+		t.Errorf("%s\nhave %q\nwant %q", path, have, want)
+	}
+}
+
+// Golden compares the standard output of the command with the golden file
+// with the given name, see Golden.
+func (r Result) Golden(t testing.TB, name string) {
+	t.Helper()
+	Golden(t, name, r.Stdout)
+}
@@ -0,0 +1,47 @@
+package clitest
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	cli "github.com/pnelson/cli-reflection"
+)
+
+type recorder struct {
+	testing.TB
+	failed bool
+}
+
+func TestRun(t *testing.T) {
+	app := cli.New("myapp", "0.0.1")
+	app.RuleFunc("greet", "Greet them.", "<name>", func(ctx context.Context, name string) error {
+		fmt.Fprintf(cli.Stdout(ctx), "Hello, %s!\n", name)
+		if name == "nobody" {
+			return fmt.Errorf("nobody to greet")
+		}
+
+		return nil
+	})
+
+	r := Run(t, app, "greet", "world")
+	if r.Code != 0 || r.Stderr != "" {
+		t.Errorf("greet\nhave %d %q\nwant %d %q", r.Code, r.Stderr, 0, "")
+	}
+	r.Golden(t, "greet.golden")
+
+	r = Run(t, app, "greet", "nobody")
+	if want := "myapp: greet: nobody to greet\n"; r.Code != 1 || r.Stderr != want {
+		t.Errorf("greet nobody\nhave %d %q\nwant %d %q", r.Code, r.Stderr, 1, want)
+	}
+
+	rec := &recorder{TB: t}
+	Golden(rec, "greet.golden", "Goodbye, world!\n")
+	if !rec.failed {
+		t.Errorf("golden mismatch not reported")
+	}
+}
+
+func (r *recorder) Errorf(format string, args ...interface{}) {
+	r.failed = true
+}
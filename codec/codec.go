@@ -0,0 +1,280 @@
+// Package codec reads and writes the structured input and output of the
+// commands of a cli.Application as JSON, YAML or TOML, for commands
+// converting between them or editing documents. Values are converted through
+// encoding/json, such that the json tags of structs apply to every format.
+// YAML and TOML are limited to the subsets read by cli.LoadConfig: nested
+// mappings, scalars and lists of scalars.
+package codec
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	cli "github.com/pnelson/cli-reflection"
+)
+
+// The supported formats.
+const (
+	JSON = "json"
+	YAML = "yaml"
+	TOML = "toml"
+)
+
+// Formats are the supported formats, in the order offered by FormatFlag.
+var Formats = []string{JSON, YAML, TOML}
+
+// FormatFlag defines a flag with the specified name choosing one of Formats,
+// empty by default to detect the format, see Read and Write.
+func FormatFlag(flags *flag.FlagSet, name, usage string) *cli.Choice {
+	return cli.ChoiceVar(flags, name, "", Formats, usage)
+}
+
+// Detect returns the format of the file at path by its extension, or the
+// empty string if it has none of .json, .yaml, .yml or .toml.
+func Detect(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		return JSON
+	case ".yaml", ".yml":
+		return YAML
+	case ".toml":
+		return TOML
+	}
+
+	return ""
+}
+
+// Read decodes the file at path, or the standard input of the invocation of
+// the command that received ctx if path is empty or -, into v. The format is
+// detected from the extension of path if it is empty, defaulting to JSON.
+func Read(ctx context.Context, path, format string, v interface{}) error {
+	r := cli.Stdin(ctx)
+	if path != "" && path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		r = f
+	}
+
+	if format == "" {
+		format = Detect(path)
+	}
+
+	return Decode(r, format, v)
+}
+
+// Write encodes v to the file at path, or the standard output of the
+// invocation of the command that received ctx if path is empty or -. The
+// format is detected from the extension of path if it is empty, defaulting
+// to JSON. The file is written with cli.WriteFileAtomic, so it is only
+// described during a dry run.
+func Write(ctx context.Context, path, format string, v interface{}) error {
+	if format == "" {
+		format = Detect(path)
+	}
+
+	if path == "" || path == "-" {
+		return Encode(cli.Stdout(ctx), format, v)
+	}
+
+	var buf bytes.Buffer
+	err := Encode(&buf, format, v)
+	if err != nil {
+		return err
+	}
+
+	return cli.WriteFileAtomic(ctx, path, buf.Bytes(), 0644)
+}
+
+// Decode decodes the data read from r in format, JSON if empty, into v.
+func Decode(r io.Reader, format string, v interface{}) error {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return err
+	}
+
+	if format == "" || format == JSON {
+		return json.Unmarshal(data, v)
+	}
+
+	c, err := cli.ParseConfig(data, format)
+	if err != nil {
+		return fmt.Errorf("codec: %v", err)
+	}
+
+	data, err = json.Marshal(c)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(data, v)
+}
+
+// Encode writes v to w in format, JSON if empty. Values encoded as YAML or
+// TOML must be objects.
+func Encode(w io.Writer, format string, v interface{}) error {
+	if format == "" || format == JSON {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(v)
+	}
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	var m map[string]interface{}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	err = dec.Decode(&m)
+	if err != nil {
+		return fmt.Errorf("codec: %s requires an object, not %T", format, v)
+	}
+
+	var buf bytes.Buffer
+	switch format {
+	case YAML, "yml":
+		err = encodeYAML(&buf, m, 0)
+	case TOML:
+		err = encodeTOML(&buf, m, "")
+	default:
+		err = fmt.Errorf("codec: unsupported format %q", format)
+	}
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(buf.Bytes())
+	return err
+}
+
+// encodeYAML writes the mapping m as YAML indented by indent spaces.
+func encodeYAML(w io.Writer, m map[string]interface{}, indent int) error {
+	pad := strings.Repeat(" ", indent)
+	for _, key := range sortedKeys(m) {
+		if nested, ok := m[key].(map[string]interface{}); ok {
+			fmt.Fprintf(w, "%s%s:\n", pad, quoteKey(key))
+			err := encodeYAML(w, nested, indent+2)
+			if err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		s, err := scalar(m[key], true)
+		if err != nil {
+			return fmt.Errorf("codec: yaml: %s: %v", key, err)
+		}
+
+		fmt.Fprintf(w, "%s%s: %s\n", pad, quoteKey(key), s)
+	}
+
+	return nil
+}
+
+// encodeTOML writes the mapping m as TOML, its scalars in the table named
+// table followed by its nested mappings as tables of their own. Null values
+// are omitted.
+func encodeTOML(w io.Writer, m map[string]interface{}, table string) error {
+	var tables []string
+	for _, key := range sortedKeys(m) {
+		switch v := m[key].(type) {
+		case nil:
+		case map[string]interface{}:
+			tables = append(tables, key)
+		default:
+			s, err := scalar(v, false)
+			if err != nil {
+				return fmt.Errorf("codec: toml: %s: %v", key, err)
+			}
+
+			fmt.Fprintf(w, "%s = %s\n", quoteKey(key), s)
+		}
+	}
+
+	for _, key := range tables {
+		name := quoteKey(key)
+		if table != "" {
+			name = table + "." + name
+		}
+
+		fmt.Fprintf(w, "\n[%s]\n", name)
+		err := encodeTOML(w, m[key].(map[string]interface{}), name)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// scalar formats a scalar or a list of scalars. Null is formatted as null if
+// nullable is set.
+func scalar(v interface{}, nullable bool) (string, error) {
+	switch v := v.(type) {
+	case nil:
+		if nullable {
+			return "null", nil
+		}
+	case string:
+		return strconv.Quote(v), nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	case json.Number:
+		return v.String(), nil
+	case []interface{}:
+		elems := make([]string, len(v))
+		for i, e := range v {
+			s, err := scalar(e, nullable)
+			if err != nil {
+				return "", err
+			}
+
+			elems[i] = s
+		}
+
+		return "[" + strings.Join(elems, ", ") + "]", nil
+	}
+
+	return "", fmt.Errorf("unsupported value %v", v)
+}
+
+// quoteKey quotes key unless it consists only of letters, digits, dashes and
+// underscores, such that keys containing colons, dots or spaces survive
+// being read back.
+func quoteKey(key string) string {
+	bare := func(r rune) bool {
+		return r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_'
+	}
+	if key == "" || strings.TrimFunc(key, bare) != "" {
+		return strconv.Quote(key)
+	}
+
+	return key
+}
+
+// sortedKeys returns the keys of m in order.
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
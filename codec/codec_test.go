@@ -0,0 +1,185 @@
+package codec
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	cli "github.com/pnelson/cli-reflection"
+)
+
+type server struct {
+	Name  string   `json:"name"`
+	Port  int      `json:"port"`
+	Debug bool     `json:"debug"`
+	Tags  []string `json:"tags"`
+	TLS   struct {
+		Cert string `json:"cert"`
+	} `json:"tls"`
+}
+
+func TestEncodeDecode(t *testing.T) {
+	var want server
+	want.Name = "api"
+	want.Port = 8080
+	want.Debug = true
+	want.Tags = []string{"a", "b c"}
+	want.TLS.Cert = "cert.pem"
+
+	tests := map[string]string{
+		JSON: "{\n  \"name\": \"api\",\n  \"port\": 8080,\n  \"debug\": true,\n  \"tags\": [\n    \"a\",\n    \"b c\"\n  ],\n  \"tls\": {\n    \"cert\": \"cert.pem\"\n  }\n}\n",
+		YAML: "debug: true\nname: \"api\"\nport: 8080\ntags: [\"a\", \"b c\"]\ntls:\n  cert: \"cert.pem\"\n",
+		TOML: "debug = true\nname = \"api\"\nport = 8080\ntags = [\"a\", \"b c\"]\n\n[tls]\ncert = \"cert.pem\"\n",
+	}
+	for format, text := range tests {
+		var buf bytes.Buffer
+		err := Encode(&buf, format, want)
+		if err != nil {
+			t.Fatalf("Encode %s: %v", format, err)
+		}
+
+		if buf.String() != text {
+			t.Errorf("Encode %s\nhave %q\nwant %q", format, buf.String(), text)
+		}
+
+		var have server
+		err = Decode(&buf, format, &have)
+		if err != nil {
+			t.Fatalf("Decode %s: %v", format, err)
+		}
+
+		if fmt.Sprint(have) != fmt.Sprint(want) {
+			t.Errorf("Decode %s\nhave %v\nwant %v", format, have, want)
+		}
+	}
+}
+
+func TestEncodeDecodeKeys(t *testing.T) {
+	want := map[string]interface{}{
+		"host:port": "localhost:80",
+		"a.b":       true,
+		"two words": map[string]interface{}{"x.y": "z", "plain": "p"},
+		"plain":     "p",
+	}
+
+	tests := map[string]string{
+		YAML: "\"a.b\": true\n\"host:port\": \"localhost:80\"\nplain: \"p\"\n\"two words\":\n  plain: \"p\"\n  \"x.y\": \"z\"\n",
+		TOML: "\"a.b\" = true\n\"host:port\" = \"localhost:80\"\nplain = \"p\"\n\n[\"two words\"]\nplain = \"p\"\n\"x.y\" = \"z\"\n",
+	}
+	for format, text := range tests {
+		var buf bytes.Buffer
+		err := Encode(&buf, format, want)
+		if err != nil {
+			t.Fatalf("Encode %s: %v", format, err)
+		}
+
+		if buf.String() != text {
+			t.Errorf("Encode %s\nhave %q\nwant %q", format, buf.String(), text)
+		}
+
+		var have map[string]interface{}
+		err = Decode(&buf, format, &have)
+		if err != nil {
+			t.Fatalf("Decode %s: %v", format, err)
+		}
+
+		if fmt.Sprint(have) != fmt.Sprint(want) {
+			t.Errorf("Decode %s\nhave %v\nwant %v", format, have, want)
+		}
+	}
+}
+
+func TestEncodeErrors(t *testing.T) {
+	tests := []struct {
+		format string
+		v      interface{}
+		want   string
+	}{
+		{YAML, []string{"a"}, "codec: yaml requires an object, not []string"},
+		{TOML, map[string]interface{}{"a": []interface{}{map[string]interface{}{}}}, "codec: toml: a: unsupported value map[]"},
+		{"xml", map[string]string{}, `codec: unsupported format "xml"`},
+	}
+	for _, tt := range tests {
+		err := Encode(ioutil.Discard, tt.format, tt.v)
+		if err == nil || err.Error() != tt.want {
+			t.Errorf("Encode %s %v\nhave %v\nwant %s", tt.format, tt.v, err, tt.want)
+		}
+	}
+}
+
+func TestDetect(t *testing.T) {
+	tests := map[string]string{
+		"a.json":      JSON,
+		"a.YAML":      YAML,
+		"dir/a.yml":   YAML,
+		"a.toml":      TOML,
+		"a.txt":       "",
+		"-":           "",
+		"":            "",
+		"noext.json/": "",
+	}
+	for path, want := range tests {
+		have := Detect(path)
+		if have != want {
+			t.Errorf("Detect %q\nhave %q\nwant %q", path, have, want)
+		}
+	}
+}
+
+func TestReadWrite(t *testing.T) {
+	dir, err := ioutil.TempDir("", "codec")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "server.yaml")
+	err = ioutil.WriteFile(path, []byte("name: api\nport: 8080\n"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var format *cli.Choice
+	app := cli.New("myapp", "0.0.1", cli.WithDryRunFlag())
+	app.RuleFunc("convert", "Convert a server.", "<in> <out>", func(ctx context.Context, in, out string) error {
+		var s server
+		err := Read(ctx, in, "", &s)
+		if err != nil {
+			return err
+		}
+
+		s.Port++
+		return Write(ctx, out, format.Value(), s)
+	})
+	app.Flags(func(flags *flag.FlagSet) {
+		format = FormatFlag(flags, "format", "Output format.")
+	})
+
+	var stdout, stderr bytes.Buffer
+	code := app.RunWithArgs([]string{"-format", "toml", "convert", path, "-"}, &stdout, &stderr)
+	want := "debug = false\nname = \"api\"\nport = 8081\n\n[tls]\ncert = \"\"\n"
+	if code != 0 || stdout.String() != want {
+		t.Errorf("convert\nhave %d %q %q\nwant %d %q", code, stdout.String(), stderr.String(), 0, want)
+	}
+
+	out := filepath.Join(dir, "server.json")
+	code = app.RunWithArgs([]string{"convert", path, out}, &stdout, &stderr)
+	data, _ := ioutil.ReadFile(out)
+	if code != 0 || !strings.Contains(string(data), `"port": 8081`) {
+		t.Errorf("convert json\nhave %d %q %q\nwant %d %q", code, data, stderr.String(), 0, `"port": 8081`)
+	}
+
+	out = filepath.Join(dir, "dry.json")
+	stderr.Reset()
+	code = app.RunWithArgs([]string{"-dry-run", "convert", path, out}, &stdout, &stderr)
+	_, err = os.Stat(out)
+	if code != 0 || !os.IsNotExist(err) || !strings.HasPrefix(stderr.String(), "dry run: would write "+out) {
+		t.Errorf("convert dry run\nhave %d %v %q", code, err, stderr.String())
+	}
+}
@@ -0,0 +1,66 @@
+package cli
+
+import (
+	"io"
+	"os"
+	"strconv"
+)
+
+// A ColorMode controls whether output is colored, see SetColorMode.
+type ColorMode int
+
+// Color modes.
+const (
+	ColorAuto   ColorMode = iota // Color terminals unless NO_COLOR is set.
+	ColorAlways                  // Color regardless of the output.
+	ColorNever                   // Never color.
+)
+
+// ANSI escape sequences for styling text on terminals.
+const (
+	ansiBold  = "\x1b[1m"
+	ansiDim   = "\x1b[2m"
+	ansiReset = "\x1b[0m"
+)
+
+// SetColorMode sets whether the usage, help and the output of commands, see
+// Color, are colored. The default is ColorAuto, coloring terminals unless
+// the NO_COLOR environment variable is set or TERM is dumb. The -no-color
+// flag disables color in any mode, see StandardFlags.
+func (a *Application) SetColorMode(mode ColorMode) {
+	a.color = mode
+}
+
+// colorize reports whether output to w may be colored in the mode.
+func colorize(w io.Writer, mode ColorMode, noColor bool) bool {
+	switch {
+	case noColor || mode == ColorNever:
+		return false
+	case mode == ColorAlways:
+		return true
+	case os.Getenv("NO_COLOR") != "" || os.Getenv("TERM") == "dumb":
+		return false
+	}
+
+	return isTerminal(w)
+}
+
+// width returns the number of columns of w if it is a terminal, or a Pager
+// displaying on one, overridden by the COLUMNS environment variable, or zero
+// if it is not.
+func width(w io.Writer) int {
+	if p, ok := w.(*pager); ok {
+		w = p.term
+	}
+
+	f, ok := w.(*os.File)
+	if !ok || !terminal(f.Fd()) {
+		return 0
+	}
+
+	if n, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && n > 0 {
+		return n
+	}
+
+	return terminalWidth(f.Fd())
+}
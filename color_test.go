@@ -0,0 +1,84 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+)
+
+type runColor struct {
+	*NullFlags
+	color bool
+}
+
+func (r *runColor) String() string {
+	return "Report color."
+}
+
+func (r *runColor) Run(ctx context.Context) {
+	r.color = Color(ctx)
+}
+
+func TestColorize(t *testing.T) {
+	t.Setenv("NO_COLOR", "")
+	tests := []struct {
+		mode    ColorMode
+		noColor bool
+		want    bool
+	}{
+		{ColorAuto, false, false},
+		{ColorAlways, false, true},
+		{ColorAlways, true, false},
+		{ColorNever, false, false},
+	}
+
+	for _, tt := range tests {
+		if have := colorize(&bytes.Buffer{}, tt.mode, tt.noColor); have != tt.want {
+			t.Errorf("colorize(%d, %t)\nhave %t\nwant %t", tt.mode, tt.noColor, have, tt.want)
+		}
+	}
+
+	app := New("myapp", "0.0.1")
+	app.StandardFlags()
+	app.SetColorMode(ColorAlways)
+	cmd := &runColor{}
+	app.Rule(cmd, "color", "")
+
+	app.Dispatch([]string{"color"})
+	if !cmd.color {
+		t.Errorf("Color with ColorAlways\nhave %t\nwant %t", cmd.color, true)
+	}
+
+	app.Dispatch([]string{"-no-color", "color"})
+	if cmd.color {
+		t.Errorf("Color with -no-color\nhave %t\nwant %t", cmd.color, false)
+	}
+}
+
+func TestTextRendererColor(t *testing.T) {
+	app := New("myapp", "0.0.1")
+	app.Rule(&runRecord{}, "record", "[<a>] [<b>]")
+	app.SetHelpRenderer(TextRenderer{Color: true})
+
+	var buf bytes.Buffer
+	app.PrintUsage(&buf)
+	for _, want := range []string{ansiBold + "record [options] [<a>] [<b>]" + ansiReset, ansiDim + "-number=<n>" + ansiReset} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("usage missing %q\n%s", want, buf.String())
+		}
+	}
+}
+
+func TestTextRendererWrap(t *testing.T) {
+	tr := TextRenderer{Width: 30}
+	have := tr.wrap("the quick brown fox jumps over the lazy dog", 4)
+	want := "the quick brown fox jumps\n    over the lazy dog"
+	if have != want {
+		t.Errorf("wrap\nhave %q\nwant %q", have, want)
+	}
+
+	if have := tr.wrap("too narrow to wrap at all", 20); have != "too narrow to wrap at all" {
+		t.Errorf("narrow\nhave %q", have)
+	}
+}
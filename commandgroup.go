@@ -0,0 +1,83 @@
+package cli
+
+import (
+	"context"
+	"io"
+	"strings"
+)
+
+// A CommandGroup registers commands under a common name, such that the
+// commands of a group named remote are invoked as app remote add and app
+// remote remove. Create one with Application.Group.
+type CommandGroup struct {
+	app  *Application
+	name string
+}
+
+type commandGroup struct {
+	*NullFlags
+	name        string
+	description string
+	usage       func(w io.Writer, prefix string, noColor bool)
+}
+
+// Group registers a group of commands with the given name and description
+// and returns it for commands to be registered with. Invoking the group
+// without one of its commands prints the usage of the group. Dispatch walks
+// the arguments to find the deepest matching command.
+func (a *Application) Group(name, description string) *CommandGroup {
+	a.lazy(name, "<cmd>", func() command {
+		return &commandGroup{name: name, description: description, usage: a.printMatching}
+	})
+
+	return &CommandGroup{app: a, name: name}
+}
+
+// Group registers a nested group of commands, see Application.Group.
+func (g *CommandGroup) Group(name, description string) *CommandGroup {
+	return g.app.Group(g.name+" "+name, description)
+}
+
+// Rule registers a command in the group, see Application.Rule.
+func (g *CommandGroup) Rule(command command, name, arguments string, options ...RuleOption) error {
+	return g.app.Rule(command, g.name+" "+name, arguments, options...)
+}
+
+func (c *commandGroup) Run(ctx context.Context) int {
+	c.usage(Stderr(ctx), c.name, ctxOutput(ctx).noColor)
+	return ExitUsage
+}
+
+func (c *commandGroup) String() string {
+	return c.description
+}
+
+// resolve returns the name of the deepest command named by the leading
+// arguments of args, along with the remaining arguments.
+func (a *Application) resolve(args []string) (string, []string) {
+	n := 1
+	for n < len(args) && !strings.HasPrefix(args[n], "-") {
+		n++
+	}
+
+	for i := n; i > 1; i-- {
+		name := strings.Join(args[:i], " ")
+		if _, ok := a.lookup(name); ok {
+			return name, args[i:]
+		}
+	}
+
+	return args[0], args[1:]
+}
+
+// topLevel returns the names of the commands that are not in a group.
+func topLevel(names []string) []string {
+	var top []string
+	for _, name := range names {
+		if !strings.Contains(name, " ") {
+			top = append(top, name)
+		}
+	}
+
+	return top
+}
@@ -0,0 +1,45 @@
+package cli
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func TestGroup(t *testing.T) {
+	app := New("myapp", "0.0.1")
+	remote := app.Group("remote", "Manage remotes.")
+	add := &runRecord{}
+	remote.Rule(add, "add", "<a> <b>")
+	remote.Group("branch", "Manage remote branches.").Rule(&runRecord{}, "list", "")
+
+	code := app.Dispatch([]string{"remote", "add", "-number", "3", "origin", "url"})
+	if code != 3 {
+		t.Errorf("exit code\nhave %d\nwant %d", code, 3)
+	}
+
+	if want := []string{"origin", "url"}; !reflect.DeepEqual(add.have, want) {
+		t.Errorf("arguments\nhave %q\nwant %q", add.have, want)
+	}
+
+	var stderr bytes.Buffer
+	app.stderr = &stderr
+	code = app.Dispatch([]string{"remote"})
+	if code != ExitUsage {
+		t.Errorf("group exit code\nhave %d\nwant %d", code, ExitUsage)
+	}
+
+	want := `Error: remote: missing argument <cmd>
+Usage: myapp <cmd> [options] [<args>]
+  remote <cmd>              Manage remotes.
+    add [options] <a> <b>   record arguments
+      -number=<n>           exit code
+    branch <cmd>            Manage remote branches.
+      list [options]        record arguments
+        -number=<n>         exit code
+
+`
+	if stderr.String() != want {
+		t.Errorf("group usage\nhave\n%s\nwant\n%s", stderr.String(), want)
+	}
+}
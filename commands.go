@@ -0,0 +1,44 @@
+package cli
+
+// CommandInfo describes a registered command, see Commands.
+type CommandInfo struct {
+	// Name is the full name of the command, such as "remote add".
+	Name string
+	// Description is the short description of the command.
+	Description string
+	// Arguments is the synopsis of the positional arguments.
+	Arguments string
+	// Category is the category of the command, if any.
+	Category   string
+	Hidden     bool
+	Deprecated bool
+	Options    []OptionUsage
+}
+
+// Commands returns a description of each registered command, including
+// hidden and deprecated commands, in the order they were registered.
+// Commands that fail to load are omitted.
+//
+// Commands may be registered at any time, including concurrently with Run
+// and from within a running command, such as by a REPL or plugin loader.
+func (a *Application) Commands() []CommandInfo {
+	var commands []CommandInfo
+	for _, name := range a.ordered(a.match("")) {
+		r, _ := a.lookup(name)
+		if r.load() != nil {
+			continue
+		}
+
+		commands = append(commands, CommandInfo{
+			Name:        r.name,
+			Description: r.command.String(),
+			Arguments:   r.arguments,
+			Category:    a.category(r.name),
+			Hidden:      a.hidden(r.name),
+			Deprecated:  r.deprecated != nil,
+			Options:     a.optionUsages(r.options, r.env),
+		})
+	}
+
+	return commands
+}
@@ -0,0 +1,70 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"sync"
+	"testing"
+)
+
+func TestCommands(t *testing.T) {
+	app := New("myapp", "0.0.1")
+	app.RuleFunc("build", "Build it.", "", func() {}, Category("Build commands"))
+	app.RuleFunc("old", "Old build.", "", func() {}, Hidden(), Deprecated("1.0", "2.0", "build"))
+	app.Rule(&runFull{}, "add", "<name>")
+
+	commands := app.Commands()
+	var names []string
+	for _, c := range commands {
+		names = append(names, c.Name)
+	}
+
+	want := "[help version build old add]"
+	if fmt.Sprint(names) != want {
+		t.Fatalf("names\nhave %v\nwant %v", names, want)
+	}
+
+	if c := commands[2]; c.Description != "Build it." || c.Category != "Build commands" {
+		t.Errorf("build\nhave %q %q\nwant %q %q", c.Description, c.Category, "Build it.", "Build commands")
+	}
+
+	if c := commands[3]; !c.Hidden || !c.Deprecated {
+		t.Errorf("old\nhave hidden=%t deprecated=%t\nwant hidden=true deprecated=true", c.Hidden, c.Deprecated)
+	}
+
+	if c := commands[4]; c.Arguments != "<name>" || len(c.Options) != 1 || c.Options[0].Name != "number" {
+		t.Errorf("add\nhave %q %v\nwant %q [number]", c.Arguments, c.Options, "<name>")
+	}
+}
+
+func TestConcurrentRegistration(t *testing.T) {
+	app := New("myapp", "0.0.1")
+	app.RuleFunc("late", "Register more.", "", func() {
+		app.RuleFunc("later", "Registered late.", "", func() {})
+		app.Commands()
+	})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			app.RuleFunc(fmt.Sprintf("cmd%d", i), "Concurrent.", "", func() {})
+			app.RunWithArgs([]string{"help"}, io.Discard, io.Discard)
+			app.Commands()
+		}(i)
+	}
+	wg.Wait()
+
+	if code := app.RunWithArgs([]string{"late"}, io.Discard, io.Discard); code != 0 {
+		t.Fatalf("late\nhave %d\nwant %d", code, 0)
+	}
+
+	if code := app.RunWithArgs([]string{"later"}, io.Discard, io.Discard); code != 0 {
+		t.Errorf("later\nhave %d\nwant %d", code, 0)
+	}
+
+	if have := len(app.Commands()); have != 12 {
+		t.Errorf("commands\nhave %d\nwant %d", have, 12)
+	}
+}
@@ -0,0 +1,355 @@
+package cli
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+)
+
+// completeCommand is the hidden command implementing the dynamic completion
+// protocol used by the generated completion scripts.
+const completeCommand = "__complete"
+
+// A completer is a command completing its positional arguments for shell
+// completion. Complete returns the values of the argument following args,
+// the positional arguments before it, that begin with prefix.
+type completer interface {
+	Complete(args []string, prefix string) []string
+}
+
+type commandCompletion struct {
+	*NullFlags
+	app  *Application
+	name string
+}
+
+// completionScripts are templates of the completion scripts for each shell.
+var completionScripts = map[string]*template.Template{
+	"bash": template.Must(template.New("bash").Parse(`# bash completion for {{.Name}}
+# Add to your ~/.bashrc: source <({{.Name}} completion bash)
+
+_{{.Name}}_complete() {
+  local IFS=$'\n'
+  local candidates
+  candidates=$({{.Name}} {{.Complete}} "${COMP_WORDS[@]:1:COMP_CWORD}" 2>/dev/null | cut -f1)
+  COMPREPLY=($(compgen -W "$candidates" -- "${COMP_WORDS[COMP_CWORD]}"))
+}
+
+complete -o default -F _{{.Name}}_complete {{.Name}}
+`)),
+	"zsh": template.Must(template.New("zsh").Parse(`#compdef {{.Name}}
+# Add to your ~/.zshrc: source <({{.Name}} completion zsh)
+
+_{{.Name}}() {
+  local -a candidates
+  local line
+  for line in "${(@f)$({{.Name}} {{.Complete}} "${(@)words[2,CURRENT]}" 2>/dev/null)}"; do
+    [[ -n $line ]] && candidates+=("${${line%%$'\t'*}//:/\\:}:${line#*$'\t'}")
+  done
+  _describe '{{.Name}}' candidates
+}
+
+compdef _{{.Name}} {{.Name}}
+`)),
+	"fish": template.Must(template.New("fish").Parse(`# fish completion for {{.Name}}
+# Save to ~/.config/fish/completions/{{.Name}}.fish: {{.Name}} completion fish > ~/.config/fish/completions/{{.Name}}.fish
+
+function __{{.Name}}_complete
+    set -l words (commandline -opc)[2..-1] (commandline -ct)
+    {{.Name}} {{.Complete}} $words 2>/dev/null
+end
+
+complete -c {{.Name}} -f -a '(__{{.Name}}_complete)'
+`)),
+	"nushell": template.Must(template.New("nushell").Parse(`# nushell completion for {{.Name}}
+# Add to your config: source {{.Name}}-completion.nu
+
+def "nu-complete {{.Name}}" [context: string] {
+  let words = ($context | split row -r '\s+' | skip 1)
+  ^{{.Name}} {{.Complete}} ...$words | lines | each {|line|
+    let parts = ($line | split row "\t")
+    {value: ($parts | first), description: ($parts | get -i 1 | default "")}
+  }
+}
+
+export extern "{{.Name}}" [...args: string@"nu-complete {{.Name}}"]
+`)),
+	"elvish": template.Must(template.New("elvish").Parse(`# elvish completion for {{.Name}}
+# Add to your rc.elv: eval ({{.Name}} completion elvish | slurp)
+
+use str
+set edit:completion:arg-completer[{{.Name}}] = {|@words|
+  {{.Name}} {{.Complete}} $@words[1..] | each {|line|
+    var parts = [(str:split "\t" $line)]
+    if (> (count $parts) 1) {
+      edit:complex-candidate $parts[0] &display=$parts[0]' ('$parts[1]')'
+    } else {
+      put $parts[0]
+    }
+  }
+}
+`)),
+}
+
+// Completion registers a command with the given name that prints a script
+// enabling completion of the commands and flags of the Application for the
+// named shell: bash, zsh, fish, nushell or elvish. The scripts call the
+// Application to compute the completions, so they remain accurate as
+// commands are added.
+func (a *Application) Completion(name string) error {
+	a.completion = true
+	return a.Rule(&commandCompletion{app: a, name: name}, name, "<shell>")
+}
+
+func (c *commandCompletion) Run(ctx context.Context, shell string) int {
+	tmpl, ok := completionScripts[shell]
+	if !ok {
+		c.app.errorf(Stderr(ctx), "%s: "+c.app.messages.UnsupportedShell,
+			c.name, shell, strings.Join(completionShells(), ", "))
+		return 1
+	}
+
+	data := struct{ Name, Complete string }{c.app.name, completeCommand}
+	err := tmpl.Execute(Stdout(ctx), data)
+	if err != nil {
+		c.app.errorf(Stderr(ctx), "%s: %v", c.name, err)
+		return 1
+	}
+
+	return 0
+}
+
+func (c *commandCompletion) String() string {
+	return "Output a shell completion script."
+}
+
+// completionShells returns the sorted names of the supported shells.
+func completionShells() []string {
+	var shells []string
+	for shell := range completionScripts {
+		shells = append(shells, shell)
+	}
+
+	sort.Strings(shells)
+	return shells
+}
+
+// complete writes the completions of the final word of words, the arguments
+// following the program name, to w. Each completion is written on its own
+// line, optionally followed by a tab and a description.
+func (a *Application) complete(w io.Writer, words []string) {
+	if len(words) == 0 {
+		words = []string{""}
+	}
+
+	partial := words[len(words)-1]
+	path := words[:len(words)-1]
+	previous := ""
+	if len(path) > 1 {
+		previous = path[len(path)-1]
+	}
+
+	// Arguments following -- are passed verbatim and are not completed.
+	if len(path) > 1 && contains(path[1:], "--") {
+		return
+	}
+
+	if !strings.HasPrefix(partial, "-") && !strings.HasPrefix(previous, "-") {
+		if !a.completeArgument(w, path, partial) {
+			a.completeCommands(w, path, partial)
+		}
+		return
+	}
+
+	if len(path) == 0 {
+		return
+	}
+
+	name, _ := a.resolve(path)
+	r, ok := a.lookup(name)
+	if !ok || r.load() != nil {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// Complete the value of a flag accepting one of a set of values, given
+	// as -flag value or -flag=value.
+	flagName, prefix := strings.TrimLeft(previous, "-"), ""
+	if i := strings.Index(partial, "="); i >= 0 && strings.HasPrefix(partial, "-") {
+		flagName, prefix, partial = strings.TrimLeft(partial[:i], "-"), partial[:i+1], partial[i+1:]
+	} else if strings.HasPrefix(partial, "-") || strings.Contains(previous, "=") {
+		flagName = ""
+	}
+
+	if flagName != "" {
+		if f := r.options.Lookup(flagName); f != nil {
+			for _, choice := range choices(f) {
+				if strings.HasPrefix(choice, partial) {
+					fmt.Fprintf(w, "%s%s\n", prefix, choice)
+				}
+			}
+		}
+
+		return
+	}
+
+	r.options.VisitAll(func(f *flag.Flag) {
+		if isShorthand(f) {
+			return
+		}
+
+		option := "-" + f.Name
+		if strings.HasPrefix(option, partial) || strings.HasPrefix("-"+option, partial) {
+			if strings.HasPrefix(partial, "--") {
+				option = "-" + option
+			}
+
+			fmt.Fprintf(w, "%s\t%s\n", option, f.Usage)
+		}
+	})
+}
+
+// completeArgument writes the completions of the positional argument
+// beginning with partial of the command named by path, returning false if
+// the argument has no completions.
+func (a *Application) completeArgument(w io.Writer, path []string, partial string) bool {
+	if len(path) == 0 {
+		return false
+	}
+
+	name, rest := a.resolve(path)
+	r, ok := a.lookup(name)
+	if !ok || r.load() != nil {
+		return false
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// Collect the positional arguments preceding partial, skipping flags and
+	// their values.
+	var preceding []string
+	for i := 0; i < len(rest); i++ {
+		if !strings.HasPrefix(rest[i], "-") {
+			preceding = append(preceding, rest[i])
+			continue
+		}
+
+		f := r.options.Lookup(strings.TrimLeft(rest[i], "-"))
+		if f != nil && !isBoolFlag(f) {
+			i++
+		}
+	}
+
+	args := r.positional()
+	if len(args) == 0 {
+		return false
+	}
+
+	n := len(preceding)
+	if n >= len(args) {
+		if !args[len(args)-1].Variadic {
+			return false
+		}
+
+		n = len(args) - 1
+	}
+
+	var values []string
+	if fn := args[n].Complete; fn != nil {
+		values = fn(partial)
+	} else if fn := r.completers[args[n].Name]; fn != nil {
+		values = fn(partial)
+	} else if c, ok := r.command.(completer); ok {
+		values = c.Complete(preceding, partial)
+	} else {
+		return false
+	}
+
+	for _, value := range values {
+		if strings.HasPrefix(value, partial) {
+			fmt.Fprintf(w, "%s\n", value)
+		}
+	}
+
+	return true
+}
+
+// CompleteArg is a RuleOption completing the positional argument with the
+// given name, as in the arguments string of the command, with the values
+// returned by fn beginning with prefix, such as CompleteFiles. It is
+// equivalent to the Complete field of an Arg declared with Arguments.
+func CompleteArg(name string, fn func(prefix string) []string) RuleOption {
+	return func(r *rule) {
+		if r.completers == nil {
+			r.completers = make(map[string]func(prefix string) []string)
+		}
+
+		r.completers[name] = fn
+	}
+}
+
+// CompleteFiles returns the names of the files and directories beginning with
+// prefix, for completing arguments naming files. Directories end with a
+// slash so that completion may continue within them. Hidden files are only
+// returned if prefix names them.
+func CompleteFiles(prefix string) []string {
+	dir, base := filepath.Split(prefix)
+	entries, err := os.ReadDir(filepath.Join(".", dir))
+	if err != nil {
+		return nil
+	}
+
+	var names []string
+	for _, e := range entries {
+		name := e.Name()
+		if !strings.HasPrefix(name, base) || strings.HasPrefix(name, ".") && !strings.HasPrefix(base, ".") {
+			continue
+		}
+
+		if e.IsDir() {
+			name += "/"
+		}
+
+		names = append(names, dir+name)
+	}
+
+	return names
+}
+
+// completeCommands writes the completions of the commands beginning with
+// partial in the group named by path, or the top level if path is empty.
+func (a *Application) completeCommands(w io.Writer, path []string, partial string) {
+	prefix := strings.Join(path, " ")
+	if prefix != "" {
+		prefix += " "
+	}
+
+	for _, name := range a.visible(a.match(prefix+partial), "") {
+		if strings.Count(name, " ") != len(path) {
+			continue
+		}
+
+		r, _ := a.lookup(name)
+		if !r.listed() && r.load() != nil {
+			continue
+		}
+
+		fmt.Fprintf(w, "%s\t%s\n", name[len(prefix):], r.description())
+	}
+}
+
+// isBoolFlag reports whether the flag is a boolean flag, which takes no
+// value argument.
+func isBoolFlag(f *flag.Flag) bool {
+	b, ok := f.Value.(interface{ IsBoolFlag() bool })
+	return ok && b.IsBoolFlag()
+}
@@ -0,0 +1,121 @@
+package cli
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+type runCheckout struct {
+	*NullFlags
+}
+
+func TestCompleteArguments(t *testing.T) {
+	dir := t.TempDir()
+	os.Mkdir(filepath.Join(dir, "src"), 0755)
+	os.WriteFile(filepath.Join(dir, "main.go"), nil, 0644)
+	os.WriteFile(filepath.Join(dir, "src", "a.go"), nil, 0644)
+	os.WriteFile(filepath.Join(dir, ".hidden"), nil, 0644)
+	wd, _ := os.Getwd()
+	os.Chdir(dir)
+	defer os.Chdir(wd)
+
+	app := New("myapp", "0.0.1")
+	app.Completion("completion")
+	app.RuleFunc("add", "Add files.", "<files>...", func(files []string) {}, CompleteArg("files", CompleteFiles))
+	app.Rule(&runCheckout{}, "checkout", "<remote> <branch>")
+
+	tests := []struct {
+		words []string
+		want  string
+	}{
+		{[]string{"add", ""}, "main.go\nsrc/\n"},
+		{[]string{"add", "x", "s"}, "src/\n"},
+		{[]string{"add", "src/"}, "src/a.go\n"},
+		{[]string{"add", "."}, ".hidden\n"},
+		{[]string{"checkout", ""}, "origin\nupstream\n"},
+		{[]string{"checkout", "origin", "m"}, "main\n"},
+		{[]string{"checkout", "upstream", ""}, "main\nrelease\n"},
+	}
+
+	for _, tt := range tests {
+		var stdout bytes.Buffer
+		app.stdout = &stdout
+		app.Dispatch(append([]string{completeCommand}, tt.words...))
+		if have := stdout.String(); have != tt.want {
+			t.Errorf("%q\nhave %q\nwant %q", tt.words, have, tt.want)
+		}
+	}
+}
+
+func (c *runCheckout) Complete(args []string, prefix string) []string {
+	if len(args) == 0 {
+		return []string{"origin", "upstream"}
+	}
+
+	if args[0] == "upstream" {
+		return []string{"main", "release"}
+	}
+
+	return []string{"main"}
+}
+
+func (c *runCheckout) Run(remote, branch string) {}
+
+func (c *runCheckout) String() string {
+	return "check out a branch"
+}
+
+func TestComplete(t *testing.T) {
+	app := New("myapp", "0.0.1")
+	app.Completion("completion")
+	app.Rule(&runFull{}, "full", "<arg1> <arg2> [<extra>]")
+	app.Group("remote", "Manage remotes.").Rule(&runFull{}, "add", "<name>")
+
+	tests := []struct {
+		words []string
+		want  string
+	}{
+		{[]string{"ver"}, "version\tOutput the application version.\n"},
+		{[]string{"f"}, "full\trunFull help\n"},
+		{[]string{"full", "-"}, "-number\tsome number\n"},
+		{[]string{"full", "--n"}, "--number\tsome number\n"},
+		{[]string{"full", "-x"}, ""},
+		{[]string{"full", "a"}, ""},
+		{[]string{"full", "--", "-"}, ""},
+		{[]string{"r"}, "remote\tManage remotes.\n"},
+		{[]string{"remote", ""}, "add\trunFull help\n"},
+		{[]string{"remote", "add", "x", "-n"}, "-number\tsome number\n"},
+	}
+
+	for _, tt := range tests {
+		var stdout bytes.Buffer
+		app.stdout = &stdout
+		app.Dispatch(append([]string{completeCommand}, tt.words...))
+		if have := stdout.String(); have != tt.want {
+			t.Errorf("%q\nhave %q\nwant %q", tt.words, have, tt.want)
+		}
+	}
+}
+
+func TestCompletionScript(t *testing.T) {
+	app := New("myapp", "0.0.1")
+	app.Completion("completion")
+
+	for _, shell := range completionShells() {
+		var stdout bytes.Buffer
+		app.stdout = &stdout
+		code := app.Dispatch([]string{"completion", shell})
+		if code != 0 || !strings.Contains(stdout.String(), "myapp __complete") {
+			t.Errorf("%s\nhave %d\n%s", shell, code, stdout.String())
+		}
+	}
+
+	app.stderr = &bytes.Buffer{}
+	code := app.Dispatch([]string{"completion", "cmd.exe"})
+	if code != 1 {
+		t.Errorf("unsupported shell\nhave %d\nwant %d", code, 1)
+	}
+}
@@ -0,0 +1,140 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// A Config is a nested configuration, such as one decoded from a JSON object,
+// whose values are addressed by dotted keys such as server.port.
+type Config map[string]interface{}
+
+// SetConfig sets the default configuration of the Application, see
+// ConfigValue. Values from the configuration file, given with the -config
+// flag before the command name, see WithConfigFlag, or found in the
+// ConfigDir, take precedence.
+func (a *Application) SetConfig(c Config) {
+	a.config = c
+}
+
+// ConfigValue returns the configuration value with the dotted key for the
+// invocation of the command that received ctx. Values given with the
+// repeatable -o key=value flag before the command name, see
+// WithOverrideFlag, take precedence over the configuration file and the
+// configuration of the Application. Override values are decoded as JSON
+// where valid, so that -o retries=3 is a number, and are strings otherwise.
+// Flags not given on the command line or by environment variables are set
+// from the configuration: global flags from their name and flags of commands
+// from the command name and their name, such as deploy.force.
+func ConfigValue(ctx context.Context, key string) (interface{}, bool) {
+	inv, ok := ctx.Value(invocationContextKey{}).(*invocation)
+	if !ok {
+		return nil, false
+	}
+
+	return inv.config.Get(key)
+}
+
+// Get returns the value with the dotted key.
+func (c Config) Get(key string) (interface{}, bool) {
+	var v interface{} = map[string]interface{}(c)
+	for _, part := range strings.Split(key, ".") {
+		m, ok := asMap(v)
+		if !ok {
+			return nil, false
+		}
+
+		v, ok = m[part]
+		if !ok {
+			return nil, false
+		}
+	}
+
+	return v, true
+}
+
+// Set sets the value with the dotted key, replacing any values along the way
+// that are not objects.
+func (c Config) Set(key string, value interface{}) {
+	c.set(strings.Split(key, "."), value)
+}
+
+// set sets the value with the key split into parts, see Set.
+func (c Config) set(parts []string, value interface{}) {
+	m := map[string]interface{}(c)
+	for _, part := range parts[:len(parts)-1] {
+		next, ok := asMap(m[part])
+		if !ok {
+			next = make(map[string]interface{})
+			m[part] = next
+		}
+
+		m = next
+	}
+
+	m[parts[len(parts)-1]] = value
+}
+
+// clone returns a deep copy of the nested objects of c, such that Set does
+// not modify c.
+func (c Config) clone() Config {
+	out := make(Config, len(c))
+	for k, v := range c {
+		if m, ok := asMap(v); ok {
+			v = map[string]interface{}(Config(m).clone())
+		}
+
+		out[k] = v
+	}
+
+	return out
+}
+
+// asMap returns v as an object, if it is one.
+func asMap(v interface{}) (map[string]interface{}, bool) {
+	switch m := v.(type) {
+	case map[string]interface{}:
+		return m, true
+	case Config:
+		return m, true
+	}
+
+	return nil, false
+}
+
+// overrides collects -o key=value flags.
+type overrides []string
+
+// String implements the flag.Value interface.
+func (o *overrides) String() string {
+	return strings.Join(*o, ",")
+}
+
+// Set implements the flag.Value interface.
+func (o *overrides) Set(value string) error {
+	i := strings.Index(value, "=")
+	if i < 1 {
+		return fmt.Errorf("expected key=value, got %q", value)
+	}
+
+	*o = append(*o, value)
+	return nil
+}
+
+// apply returns a copy of c with the overrides applied.
+func (o overrides) apply(c Config) Config {
+	c = c.clone()
+	for _, kv := range o {
+		i := strings.Index(kv, "=")
+		var v interface{}
+		if json.Unmarshal([]byte(kv[i+1:]), &v) != nil {
+			v = kv[i+1:]
+		}
+
+		c.Set(kv[:i], v)
+	}
+
+	return c
+}
@@ -0,0 +1,52 @@
+package cli
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+type runConfig struct {
+	*NullFlags
+	values map[string]interface{}
+}
+
+func TestConfigOverrides(t *testing.T) {
+	app := New("myapp", "0.0.1", WithOverrideFlag())
+	base := Config{"server": map[string]interface{}{"host": "localhost", "port": 80.0}}
+	app.SetConfig(base)
+	cmd := &runConfig{}
+	app.Rule(cmd, "config", "")
+
+	code := app.Dispatch([]string{"-o", "server.port=8080", "-o", "log.level=debug", "config"})
+	if code != 0 {
+		t.Fatalf("exit code\nhave %d\nwant %d", code, 0)
+	}
+
+	want := map[string]interface{}{
+		"server.host": "localhost",
+		"server.port": 8080.0,
+		"log.level":   "debug",
+		"missing":     nil,
+	}
+	if !reflect.DeepEqual(cmd.values, want) {
+		t.Errorf("values\nhave %v\nwant %v", cmd.values, want)
+	}
+
+	if v, _ := base.Get("server.port"); v != 80.0 {
+		t.Errorf("base config modified\nhave %v\nwant %v", v, 80.0)
+	}
+}
+
+func (c *runConfig) Run(ctx context.Context) int {
+	c.values = make(map[string]interface{})
+	for _, key := range []string{"server.host", "server.port", "log.level", "missing"} {
+		c.values[key], _ = ConfigValue(ctx, key)
+	}
+
+	return 0
+}
+
+func (c *runConfig) String() string {
+	return "read configuration"
+}
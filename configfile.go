@@ -0,0 +1,498 @@
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// configExts are the extensions of the configuration files searched for in
+// the ConfigDir, in order of preference.
+var configExts = []string{".json", ".toml", ".yaml", ".yml"}
+
+// LoadConfig reads the configuration file at path, decoding it according to
+// its extension as JSON, TOML or YAML. Only the commonly used subsets of TOML
+// and YAML are supported: tables, nested mappings, scalars and lists of
+// scalars. Numbers are decoded as float64, as with JSON.
+func LoadConfig(path string) (Config, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	c, err := ParseConfig(data, filepath.Ext(path))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+
+	return c, nil
+}
+
+// ParseConfig decodes data in format, which is json, toml, yaml or yml, with
+// or without a leading dot, with the same support as LoadConfig.
+func ParseConfig(data []byte, format string) (Config, error) {
+	var (
+		c   Config
+		err error
+	)
+	switch strings.TrimPrefix(strings.ToLower(format), ".") {
+	case "json":
+		err = json.Unmarshal(data, &c)
+	case "toml":
+		c, err = parseTOML(data)
+	case "yaml", "yml":
+		c, err = parseYAML(data)
+	default:
+		err = fmt.Errorf("unsupported configuration format %q", format)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if c == nil {
+		c = make(Config)
+	}
+
+	return c, nil
+}
+
+// loadConfig returns the configuration of the Application merged with the
+// configuration file at path. If path is empty, the first of config.json,
+// config.toml, config.yaml and config.yml found in the ConfigDir is used, if
+// any.
+func (a *Application) loadConfig(path string) (Config, error) {
+	if path == "" {
+		dir, err := a.ConfigDir()
+		if err != nil {
+			return a.config.clone(), nil
+		}
+
+		for _, ext := range configExts {
+			name := filepath.Join(dir, "config"+ext)
+			if _, err := os.Stat(name); err == nil {
+				path = name
+				break
+			}
+		}
+
+		if path == "" {
+			return a.config.clone(), nil
+		}
+	}
+
+	file, err := LoadConfig(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return a.config.merge(file), nil
+}
+
+// merge returns a copy of c with the values of other merged into it,
+// recursively for nested objects.
+func (c Config) merge(other Config) Config {
+	out := c.clone()
+	for k, v := range other {
+		m, ok := asMap(v)
+		if ok {
+			if base, ok := asMap(out[k]); ok {
+				v = map[string]interface{}(Config(base).merge(m))
+			} else {
+				v = map[string]interface{}(Config(m).clone())
+			}
+		}
+
+		out[k] = v
+	}
+
+	return out
+}
+
+// applyConfig sets the flags that were not given on the command line or by
+// environment variables from the configuration values under prefix, where
+// the flag name is the last part of the dotted key.
+func applyConfig(flags *flag.FlagSet, c Config, prefix string) error {
+	set := visited(flags)
+
+	var err error
+	flags.VisitAll(func(f *flag.Flag) {
+		if set[f.Name] || err != nil || isShorthand(f) || isCmdline(f) {
+			return
+		}
+
+		key := f.Name
+		if prefix != "" {
+			key = prefix + "." + key
+		}
+
+		v, ok := c.Get(key)
+		if !ok {
+			return
+		}
+
+		value, ok := configString(v)
+		if !ok {
+			return
+		}
+
+		if serr := flags.Set(f.Name, value); serr != nil {
+			err = fmt.Errorf("invalid value %q for %s in configuration: %v", value, key, serr)
+		}
+	})
+
+	return err
+}
+
+// configPrefix returns the configuration key under which the flags of the
+// command with the given name are found, such as remote.add.
+func configPrefix(name string) string {
+	return strings.Replace(name, " ", ".", -1)
+}
+
+// configString formats a configuration value as a flag value. Lists are
+// joined with commas. Objects and null values have no flag value.
+func configString(v interface{}) (string, bool) {
+	switch v := v.(type) {
+	case string:
+		return v, true
+	case bool:
+		return strconv.FormatBool(v), true
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), true
+	case []interface{}:
+		values := make([]string, 0, len(v))
+		for _, e := range v {
+			s, ok := configString(e)
+			if !ok {
+				return "", false
+			}
+
+			values = append(values, s)
+		}
+
+		return strings.Join(values, ","), true
+	}
+
+	return "", false
+}
+
+// parseTOML decodes the subset of TOML consisting of tables and key/value
+// pairs with string, number, boolean and single line array values. Keys may
+// be dotted and their parts quoted.
+func parseTOML(data []byte) (Config, error) {
+	c := make(Config)
+	var table []string
+	for n, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(stripComment(line))
+		if line == "" {
+			continue
+		}
+
+		if strings.HasPrefix(line, "[") {
+			if strings.HasPrefix(line, "[[") || !strings.HasSuffix(line, "]") {
+				return nil, fmt.Errorf("line %d: unsupported table %s", n+1, line)
+			}
+
+			var err error
+			table, err = splitKey(line[1 : len(line)-1])
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %v", n+1, err)
+			}
+
+			continue
+		}
+
+		i := unquoted(line, func(i int) bool { return line[i] == '=' })
+		if i < 1 {
+			return nil, fmt.Errorf("line %d: expected key = value", n+1)
+		}
+
+		key, err := splitKey(line[:i])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", n+1, err)
+		}
+
+		v, err := parseValue(strings.TrimSpace(line[i+1:]), false)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", n+1, err)
+		}
+
+		c.set(append(append([]string{}, table...), key...), v)
+	}
+
+	return c, nil
+}
+
+// yamlLevel is a mapping being decoded and its indentation.
+type yamlLevel struct {
+	indent int
+	m      map[string]interface{}
+}
+
+// parseYAML decodes the subset of YAML consisting of block mappings nested
+// by indentation with scalar values and block or flow lists of scalars.
+func parseYAML(data []byte) (Config, error) {
+	c := make(Config)
+	stack := []yamlLevel{{0, c}}
+
+	// The last key without a value, which may be followed by a nested
+	// mapping or list.
+	var (
+		open   map[string]interface{}
+		key    string
+		indent int
+	)
+
+	for n, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimRight(stripComment(line), " \t\r")
+		text := strings.TrimLeft(line, " ")
+		if text == "" || text == "---" {
+			continue
+		}
+
+		depth := len(line) - len(text)
+		if text == "-" || strings.HasPrefix(text, "- ") {
+			if open == nil || depth < indent {
+				return nil, fmt.Errorf("line %d: unexpected list item", n+1)
+			}
+
+			list, ok := open[key].([]interface{})
+			if !ok && open[key] != nil {
+				return nil, fmt.Errorf("line %d: unexpected list item", n+1)
+			}
+
+			v, err := parseValue(strings.TrimSpace(text[1:]), true)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %v", n+1, err)
+			}
+
+			open[key] = append(list, v)
+			continue
+		}
+
+		if open != nil && open[key] == nil && depth > indent {
+			m := make(map[string]interface{})
+			open[key] = m
+			stack = append(stack, yamlLevel{depth, m})
+		}
+
+		for len(stack) > 1 && depth < stack[len(stack)-1].indent {
+			stack = stack[:len(stack)-1]
+		}
+
+		top := stack[len(stack)-1]
+		if depth != top.indent {
+			return nil, fmt.Errorf("line %d: unexpected indentation", n+1)
+		}
+
+		i := yamlColon(text)
+		if i < 1 {
+			return nil, fmt.Errorf("line %d: expected key: value", n+1)
+		}
+
+		k, err := parseKey(text[:i])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", n+1, err)
+		}
+
+		value := strings.TrimSpace(text[i+1:])
+		if value == "" {
+			top.m[k] = nil
+			open, key, indent = top.m, k, depth
+			continue
+		}
+
+		v, err := parseValue(value, true)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", n+1, err)
+		}
+
+		top.m[k] = v
+		open = nil
+	}
+
+	return c, nil
+}
+
+// yamlColon returns the index of the colon separating the key of a mapping
+// from its value, or -1 if there is none.
+func yamlColon(text string) int {
+	return unquoted(text, func(i int) bool {
+		return text[i] == ':' && (i == len(text)-1 || text[i+1] == ' ')
+	})
+}
+
+// unquoted returns the index of the first byte of s outside quotes for which
+// match returns true, or -1 if there is none.
+func unquoted(s string, match func(i int) bool) int {
+	var quote byte
+	for i := 0; i < len(s); i++ {
+		switch b := s[i]; {
+		case quote != 0:
+			if b == '\\' && quote == '"' {
+				i++
+			} else if b == quote {
+				quote = 0
+			}
+		case b == '"' || b == '\'':
+			quote = b
+		case match(i):
+			return i
+		}
+	}
+
+	return -1
+}
+
+// splitKey splits a dotted TOML key into its parts, which may be quoted.
+func splitKey(s string) ([]string, error) {
+	var parts []string
+	for {
+		i := unquoted(s, func(i int) bool { return s[i] == '.' })
+		if i < 0 {
+			break
+		}
+
+		part, err := parseKey(s[:i])
+		if err != nil {
+			return nil, err
+		}
+
+		parts = append(parts, part)
+		s = s[i+1:]
+	}
+
+	part, err := parseKey(s)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(parts, part), nil
+}
+
+// parseKey decodes a key of a mapping, which may be quoted.
+func parseKey(s string) (string, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return "", errors.New("empty key")
+	}
+
+	if strings.HasPrefix(s, `"`) || strings.HasPrefix(s, "'") {
+		v, err := parseValue(s, false)
+		if err != nil {
+			return "", err
+		}
+
+		return v.(string), nil
+	}
+
+	return s, nil
+}
+
+// stripComment removes a comment, introduced by a # at the start of the line
+// or after whitespace, that is not within quotes.
+func stripComment(line string) string {
+	var quote byte
+	for i := 0; i < len(line); i++ {
+		switch b := line[i]; {
+		case quote != 0:
+			if b == '\\' && quote == '"' {
+				i++
+			} else if b == quote {
+				quote = 0
+			}
+		case b == '"' || b == '\'':
+			quote = b
+		case b == '#' && (i == 0 || line[i-1] == ' ' || line[i-1] == '\t'):
+			return line[:i]
+		}
+	}
+
+	return line
+}
+
+// parseValue decodes a quoted string, number, boolean or flow list of
+// scalars. If bare is true, as for YAML, null values and unquoted strings are
+// also accepted.
+func parseValue(s string, bare bool) (interface{}, error) {
+	switch {
+	case s == "":
+		if bare {
+			return nil, nil
+		}
+	case strings.HasPrefix(s, `"`):
+		return strconv.Unquote(s)
+	case strings.HasPrefix(s, "'"):
+		if len(s) < 2 || !strings.HasSuffix(s, "'") {
+			return nil, fmt.Errorf("unterminated string %s", s)
+		}
+
+		return s[1 : len(s)-1], nil
+	case strings.HasPrefix(s, "["):
+		if !strings.HasSuffix(s, "]") {
+			return nil, fmt.Errorf("unterminated list %s", s)
+		}
+
+		list := []interface{}{}
+		for _, e := range splitList(s[1 : len(s)-1]) {
+			e = strings.TrimSpace(e)
+			if e == "" {
+				continue
+			}
+
+			v, err := parseValue(e, bare)
+			if err != nil {
+				return nil, err
+			}
+
+			list = append(list, v)
+		}
+
+		return list, nil
+	case s == "true" || s == "false":
+		return s == "true", nil
+	case bare && (s == "null" || s == "~"):
+		return nil, nil
+	case strings.IndexByte("+-.0123456789", s[0]) >= 0:
+		f, err := strconv.ParseFloat(strings.Replace(s, "_", "", -1), 64)
+		if err == nil {
+			return f, nil
+		}
+	}
+
+	if bare {
+		return s, nil
+	}
+
+	return nil, fmt.Errorf("invalid value %s", s)
+}
+
+// splitList splits the elements of a flow list at commas outside quotes.
+func splitList(s string) []string {
+	var elems []string
+	var quote byte
+	start := 0
+	for i := 0; i < len(s); i++ {
+		switch b := s[i]; {
+		case quote != 0:
+			if b == '\\' && quote == '"' {
+				i++
+			} else if b == quote {
+				quote = 0
+			}
+		case b == '"' || b == '\'':
+			quote = b
+		case b == ',':
+			elems = append(elems, s[start:i])
+			start = i + 1
+		}
+	}
+
+	return append(elems, s[start:])
+}
@@ -0,0 +1,95 @@
+package cli
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestLoadConfig(t *testing.T) {
+	files := map[string]string{
+		"config.json": `{"name": "demo", "record": {"number": 5, "tags": ["a", "b"]}, "debug": true}`,
+		"config.toml": `# comment
+name = "demo"
+debug = true
+
+[record]
+number = 5 # comment
+tags = ["a", 'b']
+`,
+		"config.yaml": `# comment
+name: demo
+record:
+  number: 5
+  tags:
+    - a
+    - "b"
+debug: true
+`,
+	}
+
+	want := Config{
+		"name":  "demo",
+		"debug": true,
+		"record": map[string]interface{}{
+			"number": 5.0,
+			"tags":   []interface{}{"a", "b"},
+		},
+	}
+
+	dir := t.TempDir()
+	for name, data := range files {
+		path := filepath.Join(dir, name)
+		ioutil.WriteFile(path, []byte(data), 0600)
+		c, err := LoadConfig(path)
+		if err != nil {
+			t.Errorf("%s: %v", name, err)
+			continue
+		}
+
+		if !reflect.DeepEqual(c, want) {
+			t.Errorf("%s\nhave %v\nwant %v", name, c, want)
+		}
+	}
+}
+
+func TestConfigFlags(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	os.MkdirAll(filepath.Join(dir, "myapp"), 0700)
+	ioutil.WriteFile(filepath.Join(dir, "myapp", "config.yaml"), []byte("record:\n  number: 5\n"), 0600)
+	other := filepath.Join(dir, "other.toml")
+	ioutil.WriteFile(other, []byte("[record]\nnumber = 9\n"), 0600)
+
+	app := New("myapp", "0.0.1", WithConfigFlag(), WithOverrideFlag())
+	app.EnvPrefix("MYAPP")
+	app.Rule(&runRecord{}, "record", "[<a>] [<b>]", EnvVar("number", "RECORD_NUMBER"))
+
+	tests := []struct {
+		args []string
+		env  string
+		code int
+	}{
+		{[]string{"record"}, "", 5},
+		{[]string{"-config", other, "record"}, "", 9},
+		{[]string{"-o", "record.number=4", "record"}, "", 4},
+		{[]string{"record"}, "7", 7},
+		{[]string{"record", "-number", "3"}, "7", 3},
+		{[]string{"-config", filepath.Join(dir, "missing.json"), "record"}, "", 1},
+		{[]string{"-o", "record.number=many", "record"}, "", ExitUsage},
+	}
+
+	for _, tt := range tests {
+		if tt.env != "" {
+			os.Setenv("RECORD_NUMBER", tt.env)
+		}
+
+		code := app.RunWithArgs(tt.args, ioutil.Discard, ioutil.Discard)
+		os.Unsetenv("RECORD_NUMBER")
+		if code != tt.code {
+			t.Errorf("%q\nhave %d\nwant %d", tt.args, code, tt.code)
+		}
+	}
+}
@@ -0,0 +1,126 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// ErrDeclined is returned when the user declines to confirm an action.
+var ErrDeclined = errors.New("declined")
+
+// A Confirmer asks the user to confirm destructive actions. Actions are
+// approved without asking if -yes was given before the command name, see
+// WithYesFlag, and are not performed during a dry run, see DryRun. Otherwise
+// the user is asked on the terminal and actions are declined if there is
+// none.
+type Confirmer struct {
+	r           io.Reader
+	w           io.Writer
+	interactive bool
+	noInput     bool
+	yes         bool
+	dryRun      bool
+	messages    *Messages
+}
+
+// A confirmation is implemented by commands confirming their invocation
+// before they are run, unless during a dry run. Confirm returns the action to
+// confirm, such as "Delete the cluster", or the empty string if there is
+// nothing to confirm.
+type confirmation interface {
+	Confirm() string
+}
+
+// NewConfirmer returns a Confirmer for the invocation of the command that
+// received ctx.
+func NewConfirmer(ctx context.Context) *Confirmer {
+	inv := ctxInvocation(ctx)
+	return &Confirmer{
+		r:           Stdin(ctx),
+		w:           Stderr(ctx),
+		interactive: isTerminal(Stdin(ctx)),
+		noInput:     inv.noInput,
+		yes:         inv.yes,
+		dryRun:      inv.dryRun,
+		messages:    &inv.app.messages,
+	}
+}
+
+// Confirm asks the user to confirm the action, returning nil if it is
+// approved and ErrDeclined if it is not. Actions are declined during a dry
+// run.
+func (c *Confirmer) Confirm(action string) error {
+	if c.dryRun {
+		return ErrDeclined
+	}
+
+	if c.yes {
+		return nil
+	}
+
+	if c.noInput {
+		return fmt.Errorf("%s: cannot confirm with -no-input, use -yes", action)
+	}
+
+	if !c.interactive {
+		return fmt.Errorf("%s: cannot confirm without a terminal, use -yes", action)
+	}
+
+	fmt.Fprintf(c.w, c.messages.AreYouSure+" %s ", action, c.messages.YesNo)
+	line, err := bufio.NewReader(c.r).ReadString('\n')
+	if err != nil && line == "" {
+		return err
+	}
+
+	switch strings.ToLower(strings.TrimSpace(line)) {
+	case "y", "yes":
+		return nil
+	}
+
+	return ErrDeclined
+}
+
+// Do calls fn if the action is confirmed. During a dry run, the action is
+// described rather than performed and Do returns nil.
+func (c *Confirmer) Do(action string, fn func() error) error {
+	if c.dryRun {
+		fmt.Fprintf(c.w, c.messages.WouldDo+"\n", lowerFirst(action))
+		return nil
+	}
+
+	err := c.Confirm(action)
+	if err != nil {
+		return err
+	}
+
+	return fn()
+}
+
+// confirm confirms the invocation of the command of the rule, if it is a
+// confirmation.
+func (a *Application) confirm(ctx context.Context, r *rule) error {
+	cmd, ok := r.command.(confirmation)
+	if !ok || DryRun(ctx) {
+		return nil
+	}
+
+	action := cmd.Confirm()
+	if action == "" {
+		return nil
+	}
+
+	return NewConfirmer(ctx).Confirm(action)
+}
+
+// lowerFirst returns s with its first letter in lower case.
+func lowerFirst(s string) string {
+	if s == "" {
+		return s
+	}
+
+	return strings.ToLower(s[:1]) + s[1:]
+}
@@ -0,0 +1,83 @@
+package cli
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+type runConfirm struct {
+	*NullFlags
+	ran bool
+}
+
+func (r *runConfirm) String() string {
+	return "Delete everything."
+}
+
+func (r *runConfirm) Confirm() string {
+	return "Delete everything"
+}
+
+func (r *runConfirm) Run() {
+	r.ran = true
+}
+
+func TestConfirmer(t *testing.T) {
+	tests := []struct {
+		c     Confirmer
+		input string
+		err   error
+		ran   bool
+		out   string
+	}{
+		{Confirmer{interactive: true, messages: &DefaultMessages}, "y\n", nil, true, "Delete it. Are you sure? [y/N] "},
+		{Confirmer{interactive: true, messages: &DefaultMessages}, "YES\n", nil, true, "Delete it. Are you sure? [y/N] "},
+		{Confirmer{interactive: true, messages: &DefaultMessages}, "\n", ErrDeclined, false, "Delete it. Are you sure? [y/N] "},
+		{Confirmer{yes: true}, "", nil, true, ""},
+		{Confirmer{yes: true, dryRun: true, messages: &DefaultMessages}, "", nil, false, "Would delete it.\n"},
+	}
+
+	for i, tt := range tests {
+		var out bytes.Buffer
+		tt.c.r, tt.c.w = strings.NewReader(tt.input), &out
+		ran := false
+		err := tt.c.Do("Delete it", func() error {
+			ran = true
+			return nil
+		})
+		if err != tt.err || ran != tt.ran || out.String() != tt.out {
+			t.Errorf("%d\nhave %v %t %q\nwant %v %t %q", i, err, ran, out.String(), tt.err, tt.ran, tt.out)
+		}
+	}
+
+	err := (&Confirmer{}).Confirm("Delete it")
+	if err == nil || errors.Is(err, ErrDeclined) {
+		t.Errorf("no terminal\nhave %v", err)
+	}
+}
+
+func TestConfirmation(t *testing.T) {
+	app := New("myapp", "0.0.1", WithYesFlag(), WithDryRunFlag())
+	cmd := &runConfirm{}
+	app.Rule(cmd, "purge", "")
+
+	var stdout, stderr bytes.Buffer
+	code := app.RunWithArgs([]string{"purge"}, &stdout, &stderr)
+	want := "Error: purge: Delete everything: cannot confirm without a terminal, use -yes\n"
+	if code != 1 || cmd.ran || stderr.String() != want {
+		t.Errorf("unconfirmed\nhave %d %t %q\nwant %d %t %q", code, cmd.ran, stderr.String(), 1, false, want)
+	}
+
+	code = app.RunWithArgs([]string{"-yes", "purge"}, &stdout, &stderr)
+	if code != 0 || !cmd.ran {
+		t.Errorf("-yes\nhave %d %t", code, cmd.ran)
+	}
+
+	cmd.ran = false
+	code = app.RunWithArgs([]string{"-dry-run", "purge"}, &stdout, &stderr)
+	if code != 0 || !cmd.ran {
+		t.Errorf("-dry-run\nhave %d %t", code, cmd.ran)
+	}
+}
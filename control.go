@@ -0,0 +1,227 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// A Control serves requests from other processes of the Application, such as
+// the health command, to a long running instance over a unix socket.
+type Control struct {
+	ln     net.Listener
+	path   string
+	mu     sync.Mutex
+	checks []func(ctx context.Context) error
+}
+
+type commandHealth struct {
+	app     *Application
+	name    string
+	timeout *time.Duration
+}
+
+// ControlPath returns the path of the control socket of the Application, in
+// the XDG_RUNTIME_DIR or otherwise a directory of the temporary directory
+// private to the user.
+func (a *Application) ControlPath() string {
+	dir := os.Getenv("XDG_RUNTIME_DIR")
+	if dir == "" {
+		dir = filepath.Join(os.TempDir(), fmt.Sprintf("%s-%d", a.name, os.Getuid()))
+	}
+
+	return filepath.Join(dir, a.name+".sock")
+}
+
+// controlPath returns ControlPath, creating its directory in the temporary
+// directory if necessary and ensuring that no other user may have created
+// it.
+func (a *Application) controlPath() (string, error) {
+	path := a.ControlPath()
+	if os.Getenv("XDG_RUNTIME_DIR") != "" {
+		return path, nil
+	}
+
+	dir := filepath.Dir(path)
+	err := os.Mkdir(dir, 0700)
+	if err != nil && !os.IsExist(err) {
+		return "", err
+	}
+
+	return path, checkPrivate(dir)
+}
+
+// ServeControl listens on the control socket of the Application of the
+// invocation of the command that received ctx and serves requests until ctx
+// is done or the Control is closed. It is an error for another instance to
+// already be serving.
+func ServeControl(ctx context.Context) (*Control, error) {
+	path, err := ctxInvocation(ctx).app.controlPath()
+	if err != nil {
+		return nil, err
+	}
+
+	// Remove a stale socket left behind by an instance that has gone away.
+	conn, err := net.Dial("unix", path)
+	if err == nil {
+		conn.Close()
+		return nil, fmt.Errorf("control: %s is in use", path)
+	}
+	os.Remove(path)
+
+	ln, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+
+	err = os.Chmod(path, 0600)
+	if err != nil {
+		ln.Close()
+		return nil, err
+	}
+
+	c := &Control{ln: ln, path: path}
+	go c.serve(ctx)
+	go func() {
+		<-ctx.Done()
+		c.ln.Close()
+	}()
+
+	return c, nil
+}
+
+// HealthCheck registers fn to be called when the health of the instance is
+// queried. The instance is healthy if all of the functions return nil.
+func (c *Control) HealthCheck(fn func(ctx context.Context) error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.checks = append(c.checks, fn)
+}
+
+// Close stops serving requests and removes the control socket.
+func (c *Control) Close() error {
+	err := c.ln.Close()
+	os.Remove(c.path)
+	return err
+}
+
+// serve accepts connections until the listener is closed.
+func (c *Control) serve(ctx context.Context) {
+	for {
+		conn, err := c.ln.Accept()
+		if err != nil {
+			return
+		}
+
+		go c.handle(ctx, conn)
+	}
+}
+
+// handle responds to a single request. Requests and responses are single
+// lines of text. A reload request runs the reload pipeline, see OnReload.
+func (c *Control) handle(ctx context.Context, conn net.Conn) {
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(30 * time.Second))
+
+	line, err := bufio.NewReader(conn).ReadString('\n')
+	if err != nil {
+		return
+	}
+
+	switch strings.TrimSpace(line) {
+	case "health":
+		err = c.health(ctx)
+	case "reload":
+		err = Reload(ctx)
+	default:
+		err = fmt.Errorf("unknown request %q", strings.TrimSpace(line))
+	}
+
+	if err != nil {
+		fmt.Fprintf(conn, "error: %v\n", err)
+		return
+	}
+
+	fmt.Fprintln(conn, "ok")
+}
+
+// health runs the registered health checks.
+func (c *Control) health(ctx context.Context) error {
+	c.mu.Lock()
+	checks := append([]func(context.Context) error{}, c.checks...)
+	c.mu.Unlock()
+
+	for _, check := range checks {
+		err := check(ctx)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// request sends a request to the instance serving the control socket at path
+// and returns its response.
+func request(path, req string, timeout time.Duration) (string, error) {
+	conn, err := net.DialTimeout("unix", path, timeout)
+	if err != nil {
+		return "", err
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(timeout))
+
+	_, err = fmt.Fprintln(conn, req)
+	if err != nil {
+		return "", err
+	}
+
+	line, err := bufio.NewReader(conn).ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+
+	line = strings.TrimSpace(line)
+	if strings.HasPrefix(line, "error: ") {
+		return "", fmt.Errorf("%s", strings.TrimPrefix(line, "error: "))
+	}
+
+	return line, nil
+}
+
+// Health registers a command with the given name that queries the health of
+// the running instance serving the control socket, see ServeControl. The
+// command exits 0 if the instance is healthy and 1 otherwise, making it
+// suitable for container health checks.
+func (a *Application) Health(name string) error {
+	return a.Rule(&commandHealth{app: a, name: name}, name, "")
+}
+
+func (c *commandHealth) Flags(flags *flag.FlagSet) {
+	c.timeout = flags.Duration("timeout", 5*time.Second, "Time to wait for a response.")
+}
+
+func (c *commandHealth) Run(ctx context.Context) int {
+	path, err := c.app.controlPath()
+	if err == nil {
+		_, err = request(path, "health", *c.timeout)
+	}
+	if err != nil {
+		c.app.errorf(Stderr(ctx), "%s: %v", c.name, err)
+		return 1
+	}
+
+	fmt.Fprintln(Stdout(ctx), "ok")
+	return 0
+}
+
+func (c *commandHealth) String() string {
+	return "Check the health of the running instance."
+}
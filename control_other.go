@@ -0,0 +1,9 @@
+//go:build windows || plan9 || js || wasip1
+
+package cli
+
+// checkPrivate returns nil. The temporary directory is private to the user
+// on Windows.
+func checkPrivate(dir string) error {
+	return nil
+}
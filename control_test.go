@@ -0,0 +1,110 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+type runServe struct {
+	*NullFlags
+	ready   chan struct{}
+	stop    chan struct{}
+	healthy error
+}
+
+func TestHealth(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cli-run")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	t.Setenv("XDG_RUNTIME_DIR", dir)
+
+	app := New("myapp", "0.0.1")
+	app.Health("health")
+	app.stdout = &bytes.Buffer{}
+	app.stderr = &bytes.Buffer{}
+
+	code := app.Dispatch([]string{"health", "-timeout", "1s"})
+	if code != 1 {
+		t.Errorf("not running\nhave %d\nwant %d", code, 1)
+	}
+
+	serve := &runServe{ready: make(chan struct{}), stop: make(chan struct{})}
+	app.Rule(serve, "serve", "")
+	done := make(chan int)
+	go func() { done <- app.Dispatch([]string{"serve"}) }()
+	<-serve.ready
+
+	code = app.Dispatch([]string{"health"})
+	if code != 0 {
+		t.Errorf("healthy\nhave %d\nwant %d", code, 0)
+	}
+
+	serve.healthy = errors.New("database unavailable")
+	code = app.Dispatch([]string{"health"})
+	if code != 1 {
+		t.Errorf("unhealthy\nhave %d\nwant %d", code, 1)
+	}
+
+	close(serve.stop)
+	if code := <-done; code != 0 {
+		t.Errorf("serve\nhave %d\nwant %d", code, 0)
+	}
+}
+
+func (c *runServe) Run(ctx context.Context) int {
+	control, err := ServeControl(ctx)
+	if err != nil {
+		return 1
+	}
+	defer control.Close()
+
+	control.HealthCheck(func(ctx context.Context) error {
+		return c.healthy
+	})
+
+	close(c.ready)
+	<-c.stop
+	return 0
+}
+
+func (c *runServe) String() string {
+	return "serve forever"
+}
+
+func TestControlPathPrivate(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("permissions are not checked on windows")
+	}
+
+	t.Setenv("XDG_RUNTIME_DIR", "")
+	t.Setenv("TMPDIR", t.TempDir())
+
+	app := New("myapp", "0.0.1")
+	path, err := app.controlPath()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	dir := filepath.Dir(path)
+	fi, err := os.Stat(dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if fi.Mode().Perm() != 0700 {
+		t.Errorf("directory\nhave %v\nwant %v", fi.Mode().Perm(), os.FileMode(0700))
+	}
+
+	os.Chmod(dir, 0755)
+	_, err = app.controlPath()
+	if err == nil {
+		t.Errorf("shared directory\nhave no error")
+	}
+}
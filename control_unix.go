@@ -0,0 +1,25 @@
+//go:build !windows && !plan9 && !js && !wasip1
+
+package cli
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+)
+
+// checkPrivate returns an error unless dir is a directory owned by the user
+// and inaccessible to others.
+func checkPrivate(dir string) error {
+	fi, err := os.Lstat(dir)
+	if err != nil {
+		return err
+	}
+
+	st, ok := fi.Sys().(*syscall.Stat_t)
+	if !fi.IsDir() || !ok || int(st.Uid) != os.Getuid() || fi.Mode().Perm()&0077 != 0 {
+		return fmt.Errorf("control: %s is not private to the user", dir)
+	}
+
+	return nil
+}
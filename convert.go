@@ -0,0 +1,204 @@
+package cli
+
+import (
+	"encoding"
+	"fmt"
+	"reflect"
+	"strconv"
+	"time"
+)
+
+var (
+	durationType        = reflect.TypeOf(time.Duration(0))
+	stringType          = reflect.TypeOf("")
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// convertible reports whether positional arguments may be converted to
+// parameters of type t.
+func convertible(t reflect.Type) bool {
+	if t.Implements(textUnmarshalerType) || reflect.PtrTo(t).Implements(textUnmarshalerType) {
+		return true
+	}
+
+	switch t.Kind() {
+	case reflect.String, reflect.Bool, reflect.Float32, reflect.Float64,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return true
+	}
+
+	return false
+}
+
+// A converter converts the positional argument arg at index n to the type it
+// was made for, see converterFor.
+type converter func(arg string, n int) (reflect.Value, error)
+
+// converters returns the converters of the parameters of the Run method from
+// index first, converting the elements of a final slice parameter if slice is
+// set. They are made once, as the command is bound, rather than on every
+// call.
+func converters(t reflect.Type, first int, slice bool) []converter {
+	var convs []converter
+	for i := first; i < t.NumIn(); i++ {
+		in := t.In(i)
+		if i == t.NumIn()-1 && slice {
+			in = in.Elem()
+		}
+
+		convs = append(convs, converterFor(in))
+	}
+
+	return convs
+}
+
+// bindArgs converts the positional arguments to the parameters of the Run
+// method from index first. Missing arguments are zero values.
+func (r *rule) bindArgs(params []reflect.Value, first int, args []string) error {
+	last := len(params) - 1
+	for i := first; i < len(params); i++ {
+		conv := r.converters[i-first]
+		n := i - first
+		if i == last && r.slice {
+			if n >= len(args) {
+				params[i] = reflect.Zero(r.sliceType)
+				continue
+			}
+
+			rest := reflect.MakeSlice(r.sliceType, 0, len(args)-n)
+			for ; n < len(args); n++ {
+				v, err := conv(args[n], n)
+				if err != nil {
+					return err
+				}
+
+				rest = reflect.Append(rest, v)
+			}
+
+			params[i] = rest
+			continue
+		}
+
+		if n >= len(args) {
+			params[i] = r.zeros[n]
+			continue
+		}
+
+		v, err := conv(args[n], n)
+		if err != nil {
+			return err
+		}
+
+		params[i] = v
+	}
+
+	return nil
+}
+
+// argValue converts the positional argument arg at index n to type t.
+func argValue(t reflect.Type, arg string, n int) (reflect.Value, error) {
+	return converterFor(t)(arg, n)
+}
+
+// converterFor returns a converter to type t.
+func converterFor(t reflect.Type) converter {
+	invalid := func(arg string, n int, expected string) error {
+		return fmt.Errorf("invalid value %q for argument %d: %s", arg, n+1, expected)
+	}
+
+	switch {
+	case t.Implements(textUnmarshalerType) && t.Kind() == reflect.Ptr:
+		return func(arg string, n int) (reflect.Value, error) {
+			v := reflect.New(t.Elem())
+			err := v.Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(arg))
+			if err != nil {
+				return v, invalid(arg, n, err.Error())
+			}
+
+			return v, nil
+		}
+	case reflect.PtrTo(t).Implements(textUnmarshalerType):
+		return func(arg string, n int) (reflect.Value, error) {
+			v := reflect.New(t)
+			err := v.Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(arg))
+			if err != nil {
+				return v, invalid(arg, n, err.Error())
+			}
+
+			return v.Elem(), nil
+		}
+	case t == durationType:
+		return func(arg string, n int) (reflect.Value, error) {
+			d, err := time.ParseDuration(arg)
+			if err != nil {
+				return reflect.Value{}, invalid(arg, n, "expected a duration such as 1m30s")
+			}
+
+			return reflect.ValueOf(d), nil
+		}
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		if t == stringType {
+			return func(arg string, n int) (reflect.Value, error) {
+				return reflect.ValueOf(arg), nil
+			}
+		}
+
+		return func(arg string, n int) (reflect.Value, error) {
+			v := reflect.New(t).Elem()
+			v.SetString(arg)
+			return v, nil
+		}
+	case reflect.Bool:
+		return func(arg string, n int) (reflect.Value, error) {
+			v := reflect.New(t).Elem()
+			b, err := strconv.ParseBool(arg)
+			if err != nil {
+				return v, invalid(arg, n, "expected true or false")
+			}
+
+			v.SetBool(b)
+			return v, nil
+		}
+	case reflect.Float32, reflect.Float64:
+		return func(arg string, n int) (reflect.Value, error) {
+			v := reflect.New(t).Elem()
+			f, err := strconv.ParseFloat(arg, t.Bits())
+			if err != nil {
+				return v, invalid(arg, n, "expected a number")
+			}
+
+			v.SetFloat(f)
+			return v, nil
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return func(arg string, n int) (reflect.Value, error) {
+			v := reflect.New(t).Elem()
+			i, err := strconv.ParseInt(arg, 0, t.Bits())
+			if err != nil {
+				return v, invalid(arg, n, "expected an integer")
+			}
+
+			v.SetInt(i)
+			return v, nil
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return func(arg string, n int) (reflect.Value, error) {
+			v := reflect.New(t).Elem()
+			u, err := strconv.ParseUint(arg, 0, t.Bits())
+			if err != nil {
+				return v, invalid(arg, n, "expected a non-negative integer")
+			}
+
+			v.SetUint(u)
+			return v, nil
+		}
+	}
+
+	return func(arg string, n int) (reflect.Value, error) {
+		return reflect.New(t).Elem(), nil
+	}
+}
@@ -0,0 +1,92 @@
+package cli
+
+import (
+	"bytes"
+	"io"
+	"net"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type runTyped struct {
+	*NullFlags
+	n    int
+	d    time.Duration
+	ok   bool
+	ip   net.IP
+	rest []float64
+}
+
+func TestRunTypedArguments(t *testing.T) {
+	app := New("myapp", "0.0.1")
+	cmd := &runTyped{}
+	app.Rule(cmd, "typed", "<n> <d> <ok> <ip> [<f>...]")
+
+	code := app.Dispatch([]string{"typed", "0x10", "1m30s", "true", "10.0.0.1", "1.5", "-2"})
+	if code != 0 {
+		t.Fatalf("exit code\nhave %d\nwant %d", code, 0)
+	}
+
+	have := []interface{}{cmd.n, cmd.d, cmd.ok, cmd.ip.String(), cmd.rest}
+	want := []interface{}{16, 90 * time.Second, true, "10.0.0.1", []float64{1.5, -2}}
+	if !reflect.DeepEqual(have, want) {
+		t.Errorf("arguments\nhave %v\nwant %v", have, want)
+	}
+
+	tests := map[string][]string{
+		"myapp: typed: invalid value \"x\" for argument 1: expected an integer\n":               {"typed", "x", "1s", "true", "::1"},
+		"myapp: typed: invalid value \"1\" for argument 2: expected a duration such as 1m30s\n": {"typed", "1", "1", "true", "::1"},
+		"myapp: typed: invalid value \"a\" for argument 5: expected a number\n":                 {"typed", "1", "1s", "0", "::1", "a"},
+	}
+
+	for want, args := range tests {
+		var stdout, stderr bytes.Buffer
+		code := app.RunWithArgs(args, &stdout, &stderr)
+		if code != ExitUsage {
+			t.Errorf("%q exit code\nhave %d\nwant %d", args, code, ExitUsage)
+		}
+
+		if stderr.String() != want {
+			t.Errorf("%q stderr\nhave %q\nwant %q", args, stderr.String(), want)
+		}
+	}
+}
+
+func (c *runTyped) Run(n int, d time.Duration, ok bool, ip net.IP, rest []float64) {
+	c.n, c.d, c.ok, c.ip, c.rest = n, d, ok, ip, rest
+}
+
+func (c *runTyped) String() string {
+	return "convert arguments"
+}
+
+func BenchmarkBindArgs(b *testing.B) {
+	app := New("myapp", "0.0.1")
+	app.Rule(&runTyped{}, "typed", "<n> <d> <ok> <ip> [<rest>...]")
+	r, _ := app.lookup("typed")
+	args := []string{"1", "1s", "true", "::1", "1.5", "2.5"}
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		params := make([]reflect.Value, r.numIn)
+		err := r.bindArgs(params, 0, args)
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkDispatch(b *testing.B) {
+	app := New("myapp", "0.0.1")
+	app.Rule(&runTyped{}, "typed", "<n> <d> <ok> <ip> [<rest>...]")
+	app.stdout, app.stderr = io.Discard, io.Discard
+	args := []string{"typed", "1", "1s", "true", "::1", "1.5", "2.5"}
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if code := app.Dispatch(args); code != 0 {
+			b.Fatalf("exit code\nhave %d\nwant %d", code, 0)
+		}
+	}
+}
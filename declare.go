@@ -0,0 +1,288 @@
+package cli
+
+import (
+	"encoding"
+	"flag"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// A field is a struct field of a command declared as a positional argument
+// with the arg tag.
+type field struct {
+	name     string
+	arg      string
+	index    int
+	required bool
+	value    reflect.Value
+}
+
+// A defaulter is a command setting the defaults of its declared flags. The
+// Defaults method is called before the flags are defined on each dispatch,
+// after the fields are restored to the values they held when the command was
+// registered.
+type defaulter interface {
+	Defaults()
+}
+
+// declare defines the flags declared by the cli tags of the fields of the
+// command and returns the fields declared as positional arguments by arg
+// tags. Fields of embedded structs are included.
+func declare(command interface{}, flags *flag.FlagSet) ([]field, error) {
+	return declareValue(reflect.ValueOf(command), flags)
+}
+
+// declareValue implements declare for the struct v or a pointer to it.
+func declareValue(v reflect.Value, flags *flag.FlagSet) ([]field, error) {
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return nil, nil
+		}
+
+		v = v.Elem()
+	}
+
+	if v.Kind() != reflect.Struct {
+		return nil, nil
+	}
+
+	var fields []field
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		sf, fv := t.Field(i), v.Field(i)
+		if sf.Anonymous {
+			embedded, err := declareValue(fv, flags)
+			if err != nil {
+				return nil, err
+			}
+
+			fields = append(fields, embedded...)
+			continue
+		}
+
+		if !fv.CanSet() {
+			continue
+		}
+
+		if tag, ok := sf.Tag.Lookup("cli"); ok {
+			err := declareFlag(flags, fv, tag)
+			if err != nil {
+				return nil, fmt.Errorf("rule: field %s: %v", sf.Name, err)
+			}
+		}
+
+		if tag, ok := sf.Tag.Lookup("arg"); ok {
+			f, err := declareArg(sf, fv, tag)
+			if err != nil {
+				return nil, fmt.Errorf("rule: field %s: %v", sf.Name, err)
+			}
+
+			fields = append(fields, f)
+		}
+	}
+
+	return fields, nil
+}
+
+// declareFlag defines a flag for the field v with a tag of the form
+// "name,usage,default". The usage may not contain commas. The value of the
+// field is the default of the flag, unless it is zero and the tag has one.
+func declareFlag(flags *flag.FlagSet, v reflect.Value, tag string) error {
+	parts := strings.SplitN(tag, ",", 3)
+	name, usage := parts[0], ""
+	if len(parts) > 1 {
+		usage = parts[1]
+	}
+
+	if len(parts) > 2 && parts[2] != "" && v.IsZero() {
+		def, err := argValue(v.Type(), parts[2], 0)
+		if err != nil {
+			return fmt.Errorf("invalid default %q", parts[2])
+		}
+
+		v.Set(def)
+	}
+
+	switch p := v.Addr().Interface().(type) {
+	case *string:
+		flags.StringVar(p, name, *p, usage)
+	case *bool:
+		flags.BoolVar(p, name, *p, usage)
+	case *time.Duration:
+		flags.DurationVar(p, name, *p, usage)
+	case *int:
+		flags.IntVar(p, name, *p, usage)
+	case *int64:
+		flags.Int64Var(p, name, *p, usage)
+	case *uint:
+		flags.UintVar(p, name, *p, usage)
+	case *uint64:
+		flags.Uint64Var(p, name, *p, usage)
+	case *float64:
+		flags.Float64Var(p, name, *p, usage)
+	case flag.Value:
+		flags.Var(p, name, usage)
+	case encoding.TextUnmarshaler:
+		m, ok := p.(encoding.TextMarshaler)
+		if !ok {
+			return fmt.Errorf("unsupported flag type %v", v.Type())
+		}
+
+		flags.TextVar(p, name, m, usage)
+	default:
+		return fmt.Errorf("unsupported flag type %v", v.Type())
+	}
+
+	return nil
+}
+
+// flagFields returns the fields of the command, or the struct v, declared as
+// flags with cli tags, including those of embedded structs.
+func flagFields(v reflect.Value) []reflect.Value {
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return nil
+		}
+
+		v = v.Elem()
+	}
+
+	if v.Kind() != reflect.Struct {
+		return nil
+	}
+
+	var fields []reflect.Value
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		sf, fv := t.Field(i), v.Field(i)
+		if sf.Anonymous {
+			fields = append(fields, flagFields(fv)...)
+			continue
+		}
+
+		if _, ok := sf.Tag.Lookup("cli"); ok && fv.CanSet() {
+			fields = append(fields, fv)
+		}
+	}
+
+	return fields
+}
+
+// flagDefaults returns copies of the values of the flag fields of the
+// command.
+func flagDefaults(command interface{}) []reflect.Value {
+	fields := flagFields(reflect.ValueOf(command))
+	values := make([]reflect.Value, len(fields))
+	for i, fv := range fields {
+		values[i] = reflect.New(fv.Type()).Elem()
+		values[i].Set(fv)
+	}
+
+	return values
+}
+
+// restore sets the flag fields of the command to the values returned by
+// flagDefaults.
+func restore(command interface{}, values []reflect.Value) {
+	for i, fv := range flagFields(reflect.ValueOf(command)) {
+		if i < len(values) {
+			fv.Set(values[i])
+		}
+	}
+}
+
+// declareArg returns the positional argument for the field v with a tag of
+// the form "index" or "index,required", or naming an argument of the rule,
+// see Arguments. A slice field receives the argument at the index and all of
+// those following it.
+func declareArg(sf reflect.StructField, v reflect.Value, tag string) (field, error) {
+	parts := strings.Split(tag, ",")
+	f := field{name: strings.ToLower(sf.Name), value: v}
+	if index, err := strconv.Atoi(parts[0]); err == nil && index >= 0 {
+		f.index = index
+	} else if err != nil && parts[0] != "" && !strings.HasPrefix(parts[0], "-") {
+		f.name, f.arg = parts[0], parts[0]
+	} else {
+		return field{}, fmt.Errorf("invalid argument index %q", parts[0])
+	}
+
+	t := v.Type()
+	if !convertible(t) && !(t.Kind() == reflect.Slice && convertible(t.Elem())) {
+		return field{}, fmt.Errorf("unsupported argument type %v", t)
+	}
+
+	for _, option := range parts[1:] {
+		if option != "required" {
+			return field{}, fmt.Errorf("unknown argument option %q", option)
+		}
+
+		f.required = true
+	}
+
+	return f, nil
+}
+
+// bindNames sets the index of the fields bound to arguments by name.
+func bindNames(fields []field, args []Arg) error {
+	for i := range fields {
+		if fields[i].arg == "" {
+			continue
+		}
+
+		found := false
+		for j, arg := range args {
+			if arg.Name == fields[i].arg {
+				fields[i].index, found = j, true
+				break
+			}
+		}
+
+		if !found {
+			return fmt.Errorf("rule: unknown argument <%s>", fields[i].arg)
+		}
+	}
+
+	return nil
+}
+
+// bindFields sets the fields declared as positional arguments from args.
+func bindFields(fields []field, args []string) error {
+	for _, f := range fields {
+		t := f.value.Type()
+		if f.index >= len(args) {
+			if f.required {
+				return fmt.Errorf("missing required argument <%s>", f.name)
+			}
+
+			f.value.Set(reflect.Zero(t))
+			continue
+		}
+
+		if t.Kind() == reflect.Slice && !convertible(t) {
+			rest := reflect.MakeSlice(t, 0, len(args)-f.index)
+			for n := f.index; n < len(args); n++ {
+				v, err := argValue(t.Elem(), args[n], n)
+				if err != nil {
+					return err
+				}
+
+				rest = reflect.Append(rest, v)
+			}
+
+			f.value.Set(rest)
+			continue
+		}
+
+		v, err := argValue(t, args[f.index], f.index)
+		if err != nil {
+			return err
+		}
+
+		f.value.Set(v)
+	}
+
+	return nil
+}
@@ -0,0 +1,133 @@
+package cli
+
+import (
+	"bytes"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+type buildOptions struct {
+	Output  string        `cli:"o,Write the binary to the file.,a.out"`
+	Timeout time.Duration `cli:"timeout,Stop after the duration.,1m"`
+}
+
+type runDeclared struct {
+	buildOptions
+	Verbose bool     `cli:"v,Print more."`
+	Package string   `arg:"0,required"`
+	Tags    []string `arg:"1"`
+	have    []interface{}
+}
+
+type runDefaulted struct {
+	Output  string `cli:"o,Write the binary to the file.,a.out"`
+	Jobs    int    `cli:"j,Number of jobs."`
+	Race    bool   `cli:"race,Enable the race detector."`
+	options []string
+}
+
+type runErrDeclared struct {
+	Count int `cli:"count,Number.,many"`
+}
+
+func TestDeclared(t *testing.T) {
+	app := New("myapp", "0.0.1")
+	cmd := &runDeclared{}
+	err := app.Rule(cmd, "build", "<pkg> [<tags>...]")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	code := app.Dispatch([]string{"build", "-v", "-timeout", "5s", "./cmd", "a", "b"})
+	if code != 0 {
+		t.Fatalf("exit code\nhave %d\nwant %d", code, 0)
+	}
+
+	want := []interface{}{"a.out", 5 * time.Second, true, "./cmd", []string{"a", "b"}}
+	if !reflect.DeepEqual(cmd.have, want) {
+		t.Errorf("fields\nhave %v\nwant %v", cmd.have, want)
+	}
+
+	code = app.Dispatch([]string{"build", "./cmd"})
+	want = []interface{}{"a.out", time.Minute, false, "./cmd", []string(nil)}
+	if code != 0 || !reflect.DeepEqual(cmd.have, want) {
+		t.Errorf("fields reset\nhave %d %v\nwant %d %v", code, cmd.have, 0, want)
+	}
+
+	var stdout, stderr bytes.Buffer
+	code = app.RunWithArgs([]string{"build"}, &stdout, &stderr)
+	if code != ExitUsage {
+		t.Errorf("missing argument exit code\nhave %d\nwant %d", code, ExitUsage)
+	}
+
+	if want := "Error: build: missing argument <pkg>\n"; !strings.HasPrefix(stderr.String(), want) {
+		t.Errorf("missing argument\nhave %q\nwant %q", stderr.String(), want)
+	}
+}
+
+func TestDeclaredInvalid(t *testing.T) {
+	app := New("myapp", "0.0.1")
+	err := app.Rule(&runErrDeclared{}, "count", "")
+	if err == nil {
+		t.Errorf("error\nhave %v\nwant invalid default", err)
+	}
+}
+
+func TestDeclaredDefaults(t *testing.T) {
+	app := New("myapp", "0.0.1")
+	cmd := &runDefaulted{Output: "bin/app", Jobs: 4}
+	app.Rule(cmd, "build", "")
+
+	tests := []struct {
+		args []string
+		want []string
+	}{
+		{[]string{"build"}, []string{"bin/app", "4", "true"}},
+		{[]string{"build", "-o", "x", "-j", "8", "-race=false"}, []string{"x", "8", "false"}},
+		{[]string{"build"}, []string{"bin/app", "4", "true"}},
+	}
+
+	for _, tt := range tests {
+		code := app.Dispatch(tt.args)
+		if code != 0 || !reflect.DeepEqual(cmd.options, tt.want) {
+			t.Errorf("%v\nhave %d %v\nwant %d %v", tt.args, code, cmd.options, 0, tt.want)
+		}
+	}
+
+	var buf bytes.Buffer
+	app.printHelp(&buf, app.rules["build"], false)
+	for _, want := range []string{`-o="bin/app"`, `-race="true"`} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("help\nhave %q\nwant %q", buf.String(), want)
+		}
+	}
+}
+
+func (c *runDeclared) Run() {
+	c.have = []interface{}{c.Output, c.Timeout, c.Verbose, c.Package, c.Tags}
+}
+
+func (c *runDeclared) String() string {
+	return "build a package"
+}
+
+func (c *runDefaulted) Defaults() {
+	c.Race = true
+}
+
+func (c *runDefaulted) Run() {
+	c.options = []string{c.Output, strconv.Itoa(c.Jobs), strconv.FormatBool(c.Race)}
+}
+
+func (c *runDefaulted) String() string {
+	return "build a package"
+}
+
+func (c *runErrDeclared) Run() {}
+
+func (c *runErrDeclared) String() string {
+	return "count things"
+}
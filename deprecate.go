@@ -0,0 +1,177 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// deprecation describes the lifecycle of a deprecated rule.
+type deprecation struct {
+	since       string
+	removal     string
+	replacement string
+	message     string
+}
+
+// Deprecated is a RuleOption marking the command as deprecated since the
+// given version of the Application, to be removed in the removal version, and
+// optionally replaced by another command. Invocations print a warning with
+// the migration guidance and the usage of the command is annotated. Once the
+// version of the Application reaches the removal version, invocations fail
+// instead. Either version may be empty if it is not known.
+func Deprecated(since, removal, replacement string) RuleOption {
+	return func(r *rule) {
+		d := r.deprecation()
+		d.since, d.removal, d.replacement = since, removal, replacement
+	}
+}
+
+// DeprecationWarning is a RuleOption marking the command as deprecated with
+// the given warning, printed instead of the warning composed by Deprecated.
+func DeprecationWarning(message string) RuleOption {
+	return func(r *rule) {
+		r.deprecation().message = message
+	}
+}
+
+// Hidden is a RuleOption omitting the command from the usage, completion and
+// suggestions for unknown commands. The command may still be run, so that
+// old names keep working for scripts.
+func Hidden() RuleOption {
+	return func(r *rule) {
+		r.hidden = true
+	}
+}
+
+// deprecation returns the deprecation of the rule, marking it as deprecated.
+func (r *rule) deprecation() *deprecation {
+	if r.deprecated == nil {
+		r.deprecated = &deprecation{}
+	}
+
+	return r.deprecated
+}
+
+// visible returns the names of the commands that are not hidden, themselves
+// or by their group, except for the command named exactly.
+func (a *Application) visible(names []string, exact string) []string {
+	var out []string
+	for _, name := range names {
+		if name == exact || !a.hidden(name) {
+			out = append(out, name)
+		}
+	}
+
+	return out
+}
+
+// hidden reports whether the command with the given name or its group is
+// hidden, or not enabled, see RequireEnv.
+func (a *Application) hidden(name string) bool {
+	if a.placement == BuiltinHidden && a.builtin(name) {
+		return true
+	}
+
+	parts := strings.Split(name, " ")
+	for i := range parts {
+		if r, ok := a.lookup(strings.Join(parts[:i+1], " ")); ok && r.hidden {
+			return true
+		}
+	}
+
+	_, g := a.gated(name)
+	return g != nil
+}
+
+// usage returns the description of the rule for usage printing.
+func (r *rule) usage(m *Messages) string {
+	usage := r.description()
+	if r.deprecated != nil {
+		usage += " (" + m.Deprecated
+		if r.deprecated.replacement != "" {
+			usage += ", " + fmt.Sprintf(m.DeprecatedUse, r.deprecated.replacement)
+		}
+
+		usage += ")"
+	}
+
+	return usage
+}
+
+// check warns that the rule named name is deprecated, returning false if it
+// has been removed as of version.
+func (d *deprecation) check(w io.Writer, m *Messages, name, version string) bool {
+	if d == nil {
+		return true
+	}
+
+	guidance := ""
+	if d.replacement != "" {
+		guidance = fmt.Sprintf(m.DeprecatedInstead, d.replacement)
+	}
+
+	if d.removal != "" && compareVersions(version, d.removal) >= 0 {
+		removed := fmt.Sprintf(m.Removed, name, strings.TrimPrefix(d.removal, "v"))
+		fmt.Fprintf(w, "%s: %s%s\n", m.Error, removed, guidance)
+		return false
+	}
+
+	if d.message != "" {
+		fmt.Fprintf(w, "%s: %s\n", m.Warning, d.message)
+		return true
+	}
+
+	msg := fmt.Sprintf(m.DeprecatedWarning, name)
+	if d.since != "" {
+		msg += fmt.Sprintf(m.DeprecatedSince, strings.TrimPrefix(d.since, "v"))
+	}
+	if d.removal != "" {
+		msg += fmt.Sprintf(m.DeprecatedRemoval, strings.TrimPrefix(d.removal, "v"))
+	}
+
+	fmt.Fprintf(w, "%s: %s%s\n", m.Warning, msg, guidance)
+	return true
+}
+
+// compareVersions compares dotted numeric versions, ignoring a leading v and
+// any pre-release or build suffix, returning -1, 0 or 1.
+func compareVersions(a, b string) int {
+	pa, pb := versionParts(a), versionParts(b)
+	for i := 0; i < len(pa) || i < len(pb); i++ {
+		var x, y int
+		if i < len(pa) {
+			x = pa[i]
+		}
+		if i < len(pb) {
+			y = pb[i]
+		}
+
+		if x != y {
+			if x < y {
+				return -1
+			}
+
+			return 1
+		}
+	}
+
+	return 0
+}
+
+// versionParts returns the numeric components of version.
+func versionParts(version string) []int {
+	version = strings.TrimPrefix(version, "v")
+	if i := strings.IndexAny(version, "-+"); i >= 0 {
+		version = version[:i]
+	}
+
+	var parts []int
+	for _, s := range strings.Split(version, ".") {
+		n, _ := strconv.Atoi(s)
+		parts = append(parts, n)
+	}
+
+	return parts
+}
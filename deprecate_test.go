@@ -0,0 +1,93 @@
+package cli
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestDeprecated(t *testing.T) {
+	tests := []struct {
+		version string
+		code    int
+		stderr  string
+	}{
+		{"1.9.0", 0, "Warning: old is deprecated since v1.2 and will be removed in v2.0, use new instead\n"},
+		{"2.0.0", 1, "Error: old was removed in v2.0, use new instead\n"},
+		{"v2.1.0-rc.1", 1, "Error: old was removed in v2.0, use new instead\n"},
+	}
+
+	for _, tt := range tests {
+		var stderr bytes.Buffer
+		app := New("myapp", tt.version)
+		app.stderr = &stderr
+		app.Rule(&runRecord{}, "old", "<a> <b>", Deprecated("1.2", "2.0", "new"))
+
+		code := app.Dispatch([]string{"old", "x", "y"})
+		if code != tt.code {
+			t.Errorf("%s exit code\nhave %d\nwant %d", tt.version, code, tt.code)
+		}
+
+		if stderr.String() != tt.stderr {
+			t.Errorf("%s stderr\nhave %q\nwant %q", tt.version, stderr.String(), tt.stderr)
+		}
+
+		stderr.Reset()
+		app.PrintUsage(&stderr)
+		if !strings.Contains(stderr.String(), "record arguments (deprecated, use new)") {
+			t.Errorf("%s usage not annotated\n%s", tt.version, stderr.String())
+		}
+	}
+}
+
+func TestHiddenDeprecationWarning(t *testing.T) {
+	app := New("myapp", "0.0.1")
+	app.Completion("completion")
+	app.Rule(&runRecord{}, "legacy", "[<a>] [<b>]", Hidden(), DeprecationWarning("legacy is going away, use record"))
+
+	var stdout, stderr bytes.Buffer
+	code := app.RunWithArgs([]string{"legacy", "-number", "3"}, &stdout, &stderr)
+	if code != 3 {
+		t.Errorf("exit code\nhave %d\nwant %d", code, 3)
+	}
+
+	want := "Warning: legacy is going away, use record\n"
+	if stderr.String() != want {
+		t.Errorf("stderr\nhave %q\nwant %q", stderr.String(), want)
+	}
+
+	stderr.Reset()
+	app.PrintUsage(&stderr)
+	if strings.Contains(stderr.String(), "legacy") {
+		t.Errorf("hidden command in usage\n%s", stderr.String())
+	}
+
+	stdout.Reset()
+	app.RunWithArgs([]string{completeCommand, "leg"}, &stdout, &stderr)
+	if stdout.Len() != 0 {
+		t.Errorf("hidden command completed\n%s", stdout.String())
+	}
+
+	if names, _ := app.suggest("legazy"); len(names) != 0 {
+		t.Errorf("hidden command suggested\nhave %q", names)
+	}
+}
+
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"1.2.3", "1.2.3", 0},
+		{"v1.2", "1.2.0", 0},
+		{"1.10.0", "1.9.0", 1},
+		{"1.2.3-beta", "1.3", -1},
+	}
+
+	for _, tt := range tests {
+		have := compareVersions(tt.a, tt.b)
+		if have != tt.want {
+			t.Errorf("compareVersions(%q, %q)\nhave %d\nwant %d", tt.a, tt.b, have, tt.want)
+		}
+	}
+}
@@ -0,0 +1,239 @@
+package cli
+
+import (
+	"context"
+	"encoding"
+	"strconv"
+	"time"
+)
+
+// Args are the positional arguments given to the RunE method of a Runner.
+type Args []string
+
+// A Runner is a command called through its RunE method with its positional
+// arguments rather than through a Run method found by reflection, for
+// compile-time checking of its signature and lower overhead. It is
+// registered with Rule like any other command, sharing its options, flags,
+// help and the validation of its arguments string, but does not support the
+// cli and arg tags. Command1, Command2 and Command3 make a Runner from a
+// function taking typed arguments.
+type Runner interface {
+	command
+	RunE(ctx context.Context, args Args) error
+}
+
+// A typedCommand is a Runner made from a function taking a fixed number of
+// typed arguments, returning pointers to values of their types.
+type typedCommand interface {
+	params() []interface{}
+}
+
+// command1 is the Runner made by Command1.
+type command1[A any] struct {
+	description string
+	run         func(ctx context.Context, a A) error
+}
+
+// command2 is the Runner made by Command2.
+type command2[A, B any] struct {
+	description string
+	run         func(ctx context.Context, a A, b B) error
+}
+
+// command3 is the Runner made by Command3.
+type command3[A, B, C any] struct {
+	description string
+	run         func(ctx context.Context, a A, b B, c C) error
+}
+
+// errExpected describes the form of an invalid argument, see Args.Scan.
+type errExpected string
+
+// Command1 returns a Runner with the description calling run with its
+// argument converted to type A, which may be any type accepted by the Run
+// method of a command, see Rule. The command requires exactly one argument.
+func Command1[A any](description string, run func(ctx context.Context, a A) error) Runner {
+	return &command1[A]{description, run}
+}
+
+// Command2 is Command1 for commands taking two arguments.
+func Command2[A, B any](description string, run func(ctx context.Context, a A, b B) error) Runner {
+	return &command2[A, B]{description, run}
+}
+
+// Command3 is Command1 for commands taking three arguments.
+func Command3[A, B, C any](description string, run func(ctx context.Context, a A, b B, c C) error) Runner {
+	return &command3[A, B, C]{description, run}
+}
+
+// bindDirect prepares the rule to dispatch to the Runner.
+func (r *rule) bindDirect(d Runner) error {
+	if r.fanOut != nil {
+		return errFanOut
+	}
+
+	if t, ok := d.(typedCommand); ok {
+		params := t.params()
+		for _, p := range params {
+			if scanArg(p, "") == errRunString {
+				return errRunString
+			}
+		}
+
+		if r.counts == nil {
+			r.counts = &argCount{len(params), len(params)}
+		}
+	}
+
+	r.command = d
+	r.direct = d.RunE
+	r.slice = true
+	r.reset()
+	return nil
+}
+
+// Scan converts the arguments to the values pointed to by ptrs, in order,
+// which may be of any type accepted by the Run method of a command, see
+// Rule. Missing arguments leave their values unchanged. An argument that
+// cannot be converted is reported as a UsageError.
+func (args Args) Scan(ptrs ...interface{}) error {
+	for i, p := range ptrs {
+		if i >= len(args) {
+			break
+		}
+
+		err := scanArg(p, args[i])
+		if err == errRunString {
+			return err
+		}
+
+		if err != nil {
+			return UsageErrorf("invalid value %q for argument %d: %v", args[i], i+1, err)
+		}
+	}
+
+	return nil
+}
+
+// scanArg converts arg to the value pointed to by p without reflection,
+// returning errRunString if its type is not supported.
+func scanArg(p interface{}, arg string) error {
+	var err error
+	switch p := p.(type) {
+	case encoding.TextUnmarshaler:
+		return p.UnmarshalText([]byte(arg))
+	case *string:
+		*p = arg
+	case *bool:
+		*p, err = strconv.ParseBool(arg)
+		if err != nil {
+			return errExpected("expected true or false")
+		}
+	case *time.Duration:
+		*p, err = time.ParseDuration(arg)
+		if err != nil {
+			return errExpected("expected a duration such as 1m30s")
+		}
+	case *int:
+		var i int64
+		i, err = strconv.ParseInt(arg, 0, strconv.IntSize)
+		if err != nil {
+			return errExpected("expected an integer")
+		}
+
+		*p = int(i)
+	case *int64:
+		*p, err = strconv.ParseInt(arg, 0, 64)
+		if err != nil {
+			return errExpected("expected an integer")
+		}
+	case *uint:
+		var u uint64
+		u, err = strconv.ParseUint(arg, 0, strconv.IntSize)
+		if err != nil {
+			return errExpected("expected a non-negative integer")
+		}
+
+		*p = uint(u)
+	case *uint64:
+		*p, err = strconv.ParseUint(arg, 0, 64)
+		if err != nil {
+			return errExpected("expected a non-negative integer")
+		}
+	case *float64:
+		*p, err = strconv.ParseFloat(arg, 64)
+		if err != nil {
+			return errExpected("expected a number")
+		}
+	default:
+		return errRunString
+	}
+
+	return nil
+}
+
+// Error implements the error interface.
+func (e errExpected) Error() string {
+	return string(e)
+}
+
+func (cmd *command1[A]) RunE(ctx context.Context, args Args) error {
+	var a A
+	err := args.Scan(&a)
+	if err != nil {
+		return err
+	}
+
+	return cmd.run(ctx, a)
+}
+
+func (cmd *command1[A]) params() []interface{} {
+	return []interface{}{new(A)}
+}
+
+func (cmd *command1[A]) String() string {
+	return cmd.description
+}
+
+func (cmd *command2[A, B]) RunE(ctx context.Context, args Args) error {
+	var (
+		a A
+		b B
+	)
+	err := args.Scan(&a, &b)
+	if err != nil {
+		return err
+	}
+
+	return cmd.run(ctx, a, b)
+}
+
+func (cmd *command2[A, B]) params() []interface{} {
+	return []interface{}{new(A), new(B)}
+}
+
+func (cmd *command2[A, B]) String() string {
+	return cmd.description
+}
+
+func (cmd *command3[A, B, C]) RunE(ctx context.Context, args Args) error {
+	var (
+		a A
+		b B
+		c C
+	)
+	err := args.Scan(&a, &b, &c)
+	if err != nil {
+		return err
+	}
+
+	return cmd.run(ctx, a, b, c)
+}
+
+func (cmd *command3[A, B, C]) params() []interface{} {
+	return []interface{}{new(A), new(B), new(C)}
+}
+
+func (cmd *command3[A, B, C]) String() string {
+	return cmd.description
+}
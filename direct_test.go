@@ -0,0 +1,72 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+type runDirect struct {
+	upper *bool
+}
+
+func (c *runDirect) Flags(flags *flag.FlagSet) {
+	c.upper = flags.Bool("upper", false, "Print in upper case.")
+}
+
+func (c *runDirect) RunE(ctx context.Context, args Args) error {
+	s := strings.Join(args, " ")
+	if *c.upper {
+		s = strings.ToUpper(s)
+	}
+
+	_, err := fmt.Fprintln(Stdout(ctx), s)
+	return err
+}
+
+func (c *runDirect) String() string {
+	return "Echo the words."
+}
+
+func TestRunner(t *testing.T) {
+	app := New("myapp", "0.0.1")
+	app.MustRule(&runDirect{}, "echo", "<words>...")
+	app.MustRule(Command2("Repeat a word.", func(ctx context.Context, word string, n int) error {
+		fmt.Fprintln(Stdout(ctx), strings.Repeat(word, n))
+		return nil
+	}), "repeat", "<word> <n>")
+	app.MustRule(Command1("Wait.", func(ctx context.Context, d time.Duration) error {
+		fmt.Fprintln(Stdout(ctx), d)
+		return nil
+	}), "wait", "<duration>")
+
+	tests := []struct {
+		args   string
+		code   int
+		stdout string
+		stderr string
+	}{
+		{"echo -upper a b", 0, "A B\n", ""},
+		{"repeat ab 3", 0, "ababab\n", ""},
+		{"wait 1m30s", 0, "1m30s\n", ""},
+		{"repeat ab x", ExitUsage, "", "myapp: repeat: invalid value \"x\" for argument 2: expected an integer\nUsage: myapp repeat <word> <n>\n"},
+		{"repeat ab 3 4", ExitUsage, "", "Error: repeat: expected exactly 2 arguments: <word> <n>\nUsage: myapp <cmd> [options] [<args>]\n  repeat <word> <n>   Repeat a word.\n\n"},
+	}
+
+	for _, tt := range tests {
+		var stdout, stderr bytes.Buffer
+		code := app.RunWithArgs(strings.Fields(tt.args), &stdout, &stderr)
+		if code != tt.code || stdout.String() != tt.stdout || stderr.String() != tt.stderr {
+			t.Errorf("%s\nhave %d %q %q\nwant %d %q %q", tt.args, code, stdout.String(), stderr.String(), tt.code, tt.stdout, tt.stderr)
+		}
+	}
+
+	err := app.Rule(Command1("Bad.", func(ctx context.Context, c chan int) error { return nil }), "bad", "<c>")
+	if err != errRunString {
+		t.Errorf("error\nhave %v\nwant %v", err, errRunString)
+	}
+}
@@ -0,0 +1,254 @@
+package cli
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+)
+
+// GenerateDocs writes reference documentation for the Application and each
+// of its commands that is not hidden to dir, in the format "man", for man(1)
+// pages, or "markdown". The documentation of each command includes its
+// synopsis, long description, arguments and options with their defaults.
+// Files are named after the Application and the command, such as
+// myapp-remote-add.1 or myapp-remote-add.md, with an overview in myapp.1 or
+// myapp.md.
+func (a *Application) GenerateDocs(format, dir string) error {
+	var (
+		ext    string
+		render func(w io.Writer, u *Usage, c *CommandUsage)
+	)
+
+	switch format {
+	case "man":
+		ext, render = ".1", renderMan
+	case "markdown", "md":
+		ext, render = ".md", renderMarkdown
+	default:
+		return fmt.Errorf("docs: unsupported format %q", format)
+	}
+
+	u := &Usage{
+		Name:        a.name,
+		Version:     a.version,
+		Description: a.description,
+		About:       a.about,
+		ExitCodes:   a.exitCodeUsages(),
+	}
+	for _, name := range a.ordered(a.visible(a.match(""), "")) {
+		r, _ := a.lookup(name)
+		if r.load() != nil {
+			continue
+		}
+
+		u.Commands = append(u.Commands, *a.commandUsage(r))
+	}
+
+	var buf bytes.Buffer
+	render(&buf, u, nil)
+	err := writeFileAtomic(filepath.Join(dir, a.name+ext), buf.Bytes(), 0644, writeOptions{})
+	if err != nil {
+		return err
+	}
+
+	for i := range u.Commands {
+		c := &u.Commands[i]
+		buf.Reset()
+		render(&buf, u, c)
+		err = writeFileAtomic(filepath.Join(dir, docName(a.name, c.Name)+ext), buf.Bytes(), 0644, writeOptions{})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// docName returns the name of the documentation of a command.
+func docName(app, command string) string {
+	return app + "-" + strings.Replace(command, " ", "-", -1)
+}
+
+// renderMan writes a man(1) page for the command c, or an overview of the
+// Application listing its commands if c is nil.
+func renderMan(w io.Writer, u *Usage, c *CommandUsage) {
+	title, name, description := u.Name, u.Name, "command line interface"
+	if u.Description != "" {
+		description = u.Description
+	}
+
+	if c != nil {
+		title = docName(u.Name, c.Name)
+		name, description = title, c.Description
+	}
+
+	fmt.Fprintf(w, ".TH %s 1 \"\" \"%s %s\"\n", roff(strings.ToUpper(title)), roff(u.Name), roff(u.Version))
+	fmt.Fprintf(w, ".SH NAME\n%s \\- %s\n", roff(name), roff(description))
+	if c == nil {
+		fmt.Fprintf(w, ".SH SYNOPSIS\n.B %s\n<command> [options] [<args>]\n", roff(u.Name))
+		if u.About != "" {
+			fmt.Fprintf(w, ".SH DESCRIPTION\n%s\n", roff(strings.TrimRight(u.About, "\n")))
+		}
+
+		fmt.Fprintf(w, ".SH COMMANDS\n")
+		for _, c := range u.Commands {
+			fmt.Fprintf(w, ".TP\n.B %s\n%s\n", roff(c.Name), roff(c.Description))
+		}
+
+		fmt.Fprintf(w, ".SH SEE ALSO\n")
+		for i, c := range u.Commands {
+			sep := ",\n"
+			if i == len(u.Commands)-1 {
+				sep = "\n"
+			}
+
+			fmt.Fprintf(w, ".BR %s (1)%s", roff(docName(u.Name, c.Name)), sep)
+		}
+
+		return
+	}
+
+	fmt.Fprintf(w, ".SH SYNOPSIS\n.B %s\n%s\n", roff(u.Name), roff(c.Synopsis))
+	fmt.Fprintf(w, ".SH DESCRIPTION\n%s\n", roff(c.Description))
+	if c.Help != "" {
+		fmt.Fprintf(w, ".PP\n%s\n", roff(strings.TrimRight(c.Help, "\n")))
+	}
+
+	if len(c.Arguments) > 0 {
+		fmt.Fprintf(w, ".SH ARGUMENTS\n")
+		for _, arg := range c.Arguments {
+			fmt.Fprintf(w, ".TP\n.B %s\n%s\n", roff(arg.Name), argumentNote(arg))
+		}
+	}
+
+	if len(c.Options) > 0 {
+		fmt.Fprintf(w, ".SH OPTIONS\n")
+		for _, o := range c.Options {
+			fmt.Fprintf(w, ".TP\n.B %s\n%s\n", roff(o.String()), roff(optionNote(o)))
+		}
+
+		for _, note := range c.Notes {
+			fmt.Fprintf(w, ".PP\n%s\n", roff(note))
+		}
+	}
+
+	if len(c.Examples) > 0 {
+		fmt.Fprintf(w, ".SH EXAMPLES\n")
+		for _, example := range c.Examples {
+			fmt.Fprintf(w, ".PP\n.nf\n.RS\n%s\n.RE\n.fi\n", roff(example))
+		}
+	}
+
+	if len(u.ExitCodes) > 0 {
+		fmt.Fprintf(w, ".SH EXIT CODES\n")
+		for _, e := range u.ExitCodes {
+			fmt.Fprintf(w, ".TP\n.B %d\n%s\n", e.Code, roff(e.Description))
+		}
+	}
+
+	fmt.Fprintf(w, ".SH SEE ALSO\n.BR %s (1)\n", roff(u.Name))
+}
+
+// renderMarkdown writes Markdown reference documentation for the command c,
+// or an overview of the Application listing its commands if c is nil.
+func renderMarkdown(w io.Writer, u *Usage, c *CommandUsage) {
+	if c == nil {
+		fmt.Fprintf(w, "# %s\n\n", u.Name)
+		for _, s := range []string{u.Description, u.About} {
+			if s != "" {
+				fmt.Fprintf(w, "%s\n\n", strings.TrimRight(s, "\n"))
+			}
+		}
+
+		fmt.Fprintf(w, "```\n%s <command> [options] [<args>]\n```\n\n## Commands\n\n", u.Name)
+		for _, c := range u.Commands {
+			fmt.Fprintf(w, "- [%s](%s.md): %s\n", c.Name, docName(u.Name, c.Name), c.Description)
+		}
+
+		return
+	}
+
+	fmt.Fprintf(w, "# %s %s\n\n%s\n\n", u.Name, c.Name, c.Description)
+	fmt.Fprintf(w, "```\n%s %s\n```\n", u.Name, c.Synopsis)
+	if c.Help != "" {
+		fmt.Fprintf(w, "\n%s\n", strings.TrimRight(c.Help, "\n"))
+	}
+
+	if len(c.Arguments) > 0 {
+		fmt.Fprintf(w, "\n## Arguments\n\n")
+		for _, arg := range c.Arguments {
+			fmt.Fprintf(w, "- `%s`: %s\n", arg.Name, argumentNote(arg))
+		}
+	}
+
+	if len(c.Options) > 0 {
+		fmt.Fprintf(w, "\n## Options\n\n")
+		for _, o := range c.Options {
+			fmt.Fprintf(w, "- `%s`: %s\n", o.String(), optionNote(o))
+		}
+
+		for _, note := range c.Notes {
+			fmt.Fprintf(w, "\n%s\n", note)
+		}
+	}
+
+	if len(c.Examples) > 0 {
+		fmt.Fprintf(w, "\n## Examples\n\n```\n%s\n```\n", strings.Join(c.Examples, "\n\n"))
+	}
+
+	if len(u.ExitCodes) > 0 {
+		fmt.Fprintf(w, "\n## Exit codes\n\n")
+		for _, e := range u.ExitCodes {
+			fmt.Fprintf(w, "- `%d`: %s\n", e.Code, e.Description)
+		}
+	}
+}
+
+// argumentNote describes an argument and whether it is required and
+// repeatable.
+func argumentNote(arg ArgumentUsage) string {
+	note := "Required"
+	if arg.Optional {
+		note = "Optional"
+	}
+
+	if arg.Usage != "" {
+		note = arg.Usage + " " + note
+	}
+
+	if arg.Repeated {
+		note += ", may be repeated"
+	}
+
+	return note + "."
+}
+
+// optionNote returns the usage of an option with its default and environment
+// variable, if any.
+func optionNote(o OptionUsage) string {
+	note := o.Usage
+	if o.Default != "" && o.Default != "false" {
+		note += fmt.Sprintf(" (default %s)", o.Default)
+	}
+
+	if o.Env != "" {
+		note += fmt.Sprintf(" [$%s]", o.Env)
+	}
+
+	return note
+}
+
+// roff escapes text for a man page.
+func roff(s string) string {
+	s = strings.NewReplacer(`\`, `\e`, "-", `\-`).Replace(s)
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		if strings.HasPrefix(line, ".") || strings.HasPrefix(line, "'") {
+			lines[i] = `\&` + line
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,67 @@
+package cli
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGenerateDocs(t *testing.T) {
+	app := New("myapp", "0.0.1")
+	app.EnvPrefix("MYAPP")
+	remote := app.Group("remote", "Manage remotes.")
+	remote.Rule(&runRecord{}, "add", "<name> [<url>...]")
+	app.Rule(&runRecord{}, "legacy", "", Hidden())
+
+	tests := []struct {
+		format string
+		file   string
+		want   []string
+	}{
+		{"man", "myapp-remote-add.1", []string{
+			".TH MYAPP\\-REMOTE\\-ADD 1",
+			".SH SYNOPSIS\n.B myapp\nremote add [options] <name> [<url>...]\n",
+			".TP\n.B <url>\nOptional, may be repeated.\n",
+			".TP\n.B \\-number=<n>\nexit code (default 0) [$MYAPP_NUMBER]\n",
+		}},
+		{"man", "myapp.1", []string{".TP\n.B remote add\nrecord arguments\n"}},
+		{"markdown", "myapp-remote-add.md", []string{
+			"# myapp remote add\n",
+			"- `<name>`: Required.\n",
+			"- `-number=<n>`: exit code (default 0) [$MYAPP_NUMBER]\n",
+		}},
+		{"markdown", "myapp.md", []string{"- [remote add](myapp-remote-add.md): record arguments\n"}},
+	}
+
+	dir := t.TempDir()
+	for _, format := range []string{"man", "markdown"} {
+		err := app.GenerateDocs(format, dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, tt := range tests {
+		data, err := ioutil.ReadFile(filepath.Join(dir, tt.file))
+		if err != nil {
+			t.Errorf("%s: %v", tt.file, err)
+			continue
+		}
+
+		for _, want := range tt.want {
+			if !strings.Contains(string(data), want) {
+				t.Errorf("%s does not contain %q\n%s", tt.file, want, data)
+			}
+		}
+	}
+
+	files, _ := filepath.Glob(filepath.Join(dir, "myapp-legacy.*"))
+	if len(files) != 0 {
+		t.Errorf("hidden command documented\nhave %q", files)
+	}
+
+	if app.GenerateDocs("html", dir) == nil {
+		t.Errorf("unsupported format\nhave nil\nwant error")
+	}
+}
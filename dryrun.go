@@ -0,0 +1,12 @@
+package cli
+
+import "context"
+
+// DryRun reports whether the invocation of the command that received ctx is a
+// dry run, requested with the -dry-run flag before the command name, see
+// WithDryRunFlag. Commands should describe rather than perform any changes
+// during a dry run.
+func DryRun(ctx context.Context) bool {
+	inv, ok := ctx.Value(invocationContextKey{}).(*invocation)
+	return ok && inv.dryRun
+}
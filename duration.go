@@ -0,0 +1,172 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// A Duration is a flag.Value holding a time.Duration that also accepts days
+// and weeks. Define one with DurationVar.
+type Duration struct {
+	d time.Duration
+}
+
+// DurationVar defines a flag with the specified name, default value and
+// usage string holding a duration parsed with ParseDuration, such as 90s,
+// 1h30m or 2w. Unlike a flag defined with flag.Duration, its default is
+// printed in the usage without zero units, such as 1h rather than 1h0m0s.
+func DurationVar(flags *flag.FlagSet, name string, value time.Duration, usage string) *Duration {
+	d := &Duration{d: value}
+	flags.Var(d, name, usage)
+	return d
+}
+
+// Duration returns the duration.
+func (d *Duration) Duration() time.Duration {
+	return d.d
+}
+
+// String implements the flag.Value interface.
+func (d *Duration) String() string {
+	if d == nil || d.d == 0 {
+		return ""
+	}
+
+	return formatDuration(d.d, 0)
+}
+
+// Set implements the flag.Value interface.
+func (d *Duration) Set(value string) error {
+	v, err := ParseDuration(value)
+	if err != nil {
+		return err
+	}
+
+	d.d = v
+	return nil
+}
+
+// ParseDuration parses a duration like time.ParseDuration, additionally
+// accepting the units d for days of 24 hours and w for weeks of 7 days, such
+// as 1d12h or 2w. It may be used for positional arguments, which are always
+// strings.
+func ParseDuration(s string) (time.Duration, error) {
+	value := strings.Replace(strings.TrimSpace(s), " ", "", -1)
+	sign := time.Duration(1)
+	if strings.HasPrefix(value, "-") {
+		sign, value = -1, value[1:]
+	} else if strings.HasPrefix(value, "+") {
+		value = value[1:]
+	}
+
+	if value == "" {
+		return 0, fmt.Errorf("invalid duration %q", s)
+	}
+
+	// Convert the days and weeks, leaving the other units to
+	// time.ParseDuration.
+	var days time.Duration
+	var rest strings.Builder
+	for value != "" {
+		i := strings.IndexFunc(value, func(r rune) bool { return (r < '0' || r > '9') && r != '.' })
+		if i < 0 {
+			i = len(value)
+		} else if i == 0 {
+			return 0, fmt.Errorf("invalid duration %q", s)
+		}
+
+		j := strings.IndexFunc(value[i:], func(r rune) bool { return r >= '0' && r <= '9' || r == '.' })
+		if j < 0 {
+			j = len(value) - i
+		}
+
+		number, unit := value[:i], value[i:i+j]
+		value = value[i+j:]
+		switch unit {
+		case "d", "w":
+			n, err := strconv.ParseFloat(number, 64)
+			if err != nil {
+				return 0, fmt.Errorf("invalid duration %q", s)
+			}
+
+			size := 24 * time.Hour
+			if unit == "w" {
+				size *= 7
+			}
+
+			days += time.Duration(n * float64(size))
+		default:
+			rest.WriteString(number + unit)
+		}
+	}
+
+	d := time.Duration(0)
+	if rest.Len() > 0 {
+		var err error
+		d, err = time.ParseDuration(rest.String())
+		if err != nil {
+			return 0, fmt.Errorf("invalid duration %q", s)
+		}
+	}
+
+	return sign * (days + d), nil
+}
+
+// durationUnits are the units of formatted durations, largest first.
+var durationUnits = []struct {
+	suffix string
+	size   time.Duration
+}{
+	{"d", 24 * time.Hour},
+	{"h", time.Hour},
+	{"m", time.Minute},
+	{"s", time.Second},
+}
+
+// formatDuration formats d in days, hours, minutes and seconds without zero
+// units, such as 1d 12h or 1h 500ms, such that it may be parsed again by
+// ParseDuration. Given a number of significant units, only those following
+// the most significant unit are formatted, rounded down, such as 3d for 3d 5m
+// with two significant units.
+func formatDuration(d time.Duration, significant int) string {
+	if d < 0 {
+		return "-" + formatDuration(-d, significant)
+	}
+
+	if d < time.Second {
+		if significant > 0 {
+			d = d.Round(time.Millisecond)
+		}
+
+		return d.String()
+	}
+
+	var parts []string
+	first := -1
+	for i, u := range durationUnits {
+		if significant > 0 && first >= 0 && i >= first+significant {
+			return strings.Join(parts, " ")
+		}
+
+		n := d / u.size
+		d -= n * u.size
+		if n == 0 {
+			continue
+		}
+
+		if first < 0 {
+			first = i
+		}
+
+		parts = append(parts, fmt.Sprintf("%d%s", n, u.suffix))
+	}
+
+	if significant == 0 && d > 0 {
+		parts = append(parts, d.String())
+	}
+
+	return strings.Join(parts, " ")
+}
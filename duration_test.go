@@ -0,0 +1,64 @@
+package cli
+
+import (
+	"flag"
+	"testing"
+	"time"
+)
+
+func TestParseDuration(t *testing.T) {
+	tests := []struct {
+		s    string
+		want time.Duration
+	}{
+		{"0", 0},
+		{"90s", 90 * time.Second},
+		{"1h30m", 90 * time.Minute},
+		{"1d12h", 36 * time.Hour},
+		{"1.5d", 36 * time.Hour},
+		{"2w", 14 * 24 * time.Hour},
+		{"1d 2h", 26 * time.Hour},
+		{"-1d", -24 * time.Hour},
+		{"250ms", 250 * time.Millisecond},
+	}
+
+	for _, tt := range tests {
+		have, err := ParseDuration(tt.s)
+		if err != nil || have != tt.want {
+			t.Errorf("ParseDuration(%q)\nhave %v %v\nwant %v", tt.s, have, err, tt.want)
+		}
+	}
+
+	for _, s := range []string{"", "-", "d", "1x", "10", "1..5d"} {
+		_, err := ParseDuration(s)
+		if err == nil {
+			t.Errorf("ParseDuration(%q)\nhave nil\nwant error", s)
+		}
+	}
+}
+
+func TestDurationVar(t *testing.T) {
+	flags := flag.NewFlagSet("test", flag.ContinueOnError)
+	ttl := DurationVar(flags, "ttl", 36*time.Hour+90*time.Second, "Time to live.")
+	timeout := DurationVar(flags, "timeout", 0, "Timeout.")
+	if have, want := flags.Lookup("ttl").DefValue, "1d 12h 1m 30s"; have != want {
+		t.Errorf("default\nhave %q\nwant %q", have, want)
+	}
+
+	if have := (StandardFormatter{}).Placeholder(flags.Lookup("timeout")); have != "<duration>" {
+		t.Errorf("hint\nhave %q\nwant %q", have, "<duration>")
+	}
+
+	err := flags.Parse([]string{"-timeout", "1w", "-ttl", "1h0.5s"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if timeout.Duration() != 7*24*time.Hour || ttl.Duration() != time.Hour+500*time.Millisecond {
+		t.Errorf("durations\nhave %v %v\nwant %v %v", timeout.Duration(), ttl.Duration(), 7*24*time.Hour, time.Hour+500*time.Millisecond)
+	}
+
+	if have, want := ttl.String(), "1h 500ms"; have != want {
+		t.Errorf("string\nhave %q\nwant %q", have, want)
+	}
+}
@@ -0,0 +1,61 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"sync"
+)
+
+type embeddedContextKey struct{}
+
+// A lockedBuffer is a bytes.Buffer safe for writes from the goroutines of a
+// command.
+type lockedBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+// Execute parses args, excluding the program name, and dispatches to the
+// command like Dispatch, returning what it wrote to its standard output and
+// standard error along with its exit code, so that the Application may be
+// embedded in tests and other programs. The command reads from an empty
+// standard input. Execute neither uses flag.CommandLine and the standard
+// streams of the process nor handles signals, and never exits the process,
+// even for invalid flags with flag.ExitOnError.
+//
+// Execute does not isolate the Application from the rest of the process.
+// The plugin directory, see PluginDir, is scanned and the policy file, see
+// PolicyFile, and the configuration file are read as for Run. Commands
+// scoped with InDir or WithEnv change the working directory or environment
+// of the whole process while they run, and commands remain responsible for
+// any other side effects of their own.
+func (a *Application) Execute(args []string) (stdout, stderr string, code int) {
+	var out, errOut lockedBuffer
+	ctx := context.WithValue(context.Background(), embeddedContextKey{}, true)
+	ctx = withStreams(ctx, streams{strings.NewReader(""), &out, &errOut})
+	code = a.dispatch(ctx, args)
+	return out.String(), errOut.String(), code
+}
+
+// embedded reports whether the command that received ctx was dispatched by
+// Execute.
+func embedded(ctx context.Context) bool {
+	return ctx.Value(embeddedContextKey{}) != nil
+}
+
+// Write implements the io.Writer interface.
+func (b *lockedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.buf.Write(p)
+}
+
+// String returns the contents of the buffer.
+func (b *lockedBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.buf.String()
+}
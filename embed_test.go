@@ -0,0 +1,27 @@
+package cli
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestExecute(t *testing.T) {
+	exited := false
+	app := New("myapp", "0.0.1", WithFlagErrorHandling(flag.ExitOnError), WithExitFunc(func(int) { exited = true }))
+	app.RuleFunc("echo", "Echo it.", "<word>", func(ctx context.Context, word string) {
+		fmt.Fprintln(Stdout(ctx), word)
+	})
+
+	stdout, stderr, code := app.Execute([]string{"echo", "hi"})
+	if stdout != "hi\n" || stderr != "" || code != 0 {
+		t.Errorf("echo\nhave %q %q %d\nwant %q %q %d", stdout, stderr, code, "hi\n", "", 0)
+	}
+
+	_, stderr, code = app.Execute([]string{"-bogus", "echo"})
+	if !strings.Contains(stderr, "-bogus") || code != ExitUsage || exited {
+		t.Errorf("invalid flag\nhave %q %d %v\nwant %d %v", stderr, code, exited, ExitUsage, false)
+	}
+}
@@ -0,0 +1,78 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// EnvPrefix enables populating flags from environment variables named by the
+// prefix, an underscore and the name of the flag in upper case with dashes
+// replaced by underscores. For example, with the prefix MYAPP, the variable
+// MYAPP_DRY_RUN sets the -dry-run flag. Values given on the command line take
+// precedence. The variables are shown in the usage of the flags.
+func (a *Application) EnvPrefix(prefix string) {
+	a.envPrefix = prefix
+}
+
+// EnvVar sets the environment variable populating the global flag with the
+// given name, overriding the name derived from the EnvPrefix.
+func (a *Application) EnvVar(name, env string) {
+	if a.env == nil {
+		a.env = make(map[string]string)
+	}
+
+	a.env[name] = env
+}
+
+// EnvVar is a RuleOption setting the environment variable populating the flag
+// of the command with the given name, see Application.EnvVar.
+func EnvVar(name, env string) RuleOption {
+	return func(r *rule) {
+		if r.env == nil {
+			r.env = make(map[string]string)
+		}
+
+		r.env[name] = env
+	}
+}
+
+// envName returns the environment variable populating the flag with the
+// given name, or an empty string if there is none.
+func (a *Application) envName(name string, overrides map[string]string) string {
+	if env, ok := overrides[name]; ok {
+		return env
+	}
+
+	if a.envPrefix == "" {
+		return ""
+	}
+
+	return a.envPrefix + "_" + strings.ToUpper(strings.Replace(name, "-", "_", -1))
+}
+
+// applyEnv sets the flags that were not given on the command line from their
+// environment variables, if set.
+func (a *Application) applyEnv(flags *flag.FlagSet, overrides map[string]string) error {
+	set := visited(flags)
+
+	var err error
+	flags.VisitAll(func(f *flag.Flag) {
+		env := a.envName(f.Name, overrides)
+		if set[f.Name] || env == "" || err != nil || isShorthand(f) || isCmdline(f) {
+			return
+		}
+
+		value, ok := os.LookupEnv(env)
+		if !ok {
+			return
+		}
+
+		if serr := flags.Set(f.Name, value); serr != nil {
+			err = fmt.Errorf("invalid value %q for $%s: %v", value, env, serr)
+		}
+	})
+
+	return err
+}
@@ -0,0 +1,50 @@
+package cli
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestEnvPrefix(t *testing.T) {
+	app := New("myapp", "0.0.1", WithDryRunFlag())
+	app.EnvPrefix("MYAPP")
+	cmd := &runRecord{}
+	app.Rule(cmd, "record", "[<a>] [<b>]", EnvVar("number", "RECORD_NUMBER"))
+	app.Rule(&runWrite{path: os.DevNull}, "write", "")
+
+	os.Setenv("RECORD_NUMBER", "7")
+	os.Setenv("MYAPP_DRY_RUN", "true")
+	defer os.Unsetenv("RECORD_NUMBER")
+	defer os.Unsetenv("MYAPP_DRY_RUN")
+
+	code := app.Dispatch([]string{"record"})
+	if code != 7 {
+		t.Errorf("env exit code\nhave %d\nwant %d", code, 7)
+	}
+
+	code = app.Dispatch([]string{"record", "-number", "3"})
+	if code != 3 {
+		t.Errorf("flag precedence exit code\nhave %d\nwant %d", code, 3)
+	}
+
+	var stdout, stderr bytes.Buffer
+	app.RunWithArgs([]string{"write"}, &stdout, &stderr)
+	if !strings.Contains(stderr.String(), "dry run") {
+		t.Errorf("global env\nhave %q\nwant a dry run", stderr.String())
+	}
+
+	os.Setenv("RECORD_NUMBER", "many")
+	stderr.Reset()
+	code = app.RunWithArgs([]string{"record"}, &stdout, &stderr)
+	if code != ExitUsage || !strings.Contains(stderr.String(), "$RECORD_NUMBER") {
+		t.Errorf("invalid env\nhave %d %q\nwant %d", code, stderr.String(), ExitUsage)
+	}
+
+	var buf bytes.Buffer
+	app.PrintUsage(&buf)
+	if !strings.Contains(buf.String(), "exit code [$RECORD_NUMBER]") {
+		t.Errorf("usage does not show the variable\n%s", buf.String())
+	}
+}
@@ -0,0 +1,28 @@
+package cli
+
+// An exampler is implemented by commands illustrating their use, see
+// Examples.
+type exampler interface {
+	Examples() []string
+}
+
+// Examples is a RuleOption adding examples of the use of the command, shown
+// in its help and documentation. Each example is a command line, such as
+// "myapp remote add origin https://example.com/repo.git", optionally preceded
+// by comment lines beginning with "#". Commands may instead supply examples
+// with a method Examples, which are shown first.
+func Examples(examples ...string) RuleOption {
+	return func(r *rule) {
+		r.examples = append(r.examples, examples...)
+	}
+}
+
+// allExamples returns the examples of the command of the rule.
+func (r *rule) allExamples() []string {
+	var examples []string
+	if e, ok := r.command.(exampler); ok {
+		examples = append(examples, e.Examples()...)
+	}
+
+	return append(examples, r.examples...)
+}
@@ -0,0 +1,63 @@
+package cli
+
+import (
+	"bytes"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+type runExamples struct {
+	*NullFlags
+}
+
+func (r *runExamples) String() string {
+	return "Deploy the service."
+}
+
+func (r *runExamples) Examples() []string {
+	return []string{"myapp deploy"}
+}
+
+func (r *runExamples) Run() {}
+
+func TestExamples(t *testing.T) {
+	app := New("myapp", "0.0.1")
+	app.Rule(&runExamples{}, "deploy", "", Examples("# Deploy to staging.\nmyapp deploy -env staging"))
+
+	var buf bytes.Buffer
+	app.RunWithArgs([]string{"help", "deploy"}, &buf, &buf)
+	want := "Usage: myapp deploy\n\nDeploy the service.\n\nExamples:\n" +
+		"  myapp deploy\n  # Deploy to staging.\n  myapp deploy -env staging\n\n"
+	if buf.String() != want {
+		t.Errorf("help\nhave %q\nwant %q", buf.String(), want)
+	}
+
+	dir := t.TempDir()
+	for _, format := range []string{"man", "markdown"} {
+		err := app.GenerateDocs(format, dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		file string
+		want string
+	}{
+		{"myapp-deploy.1", ".SH EXAMPLES\n.PP\n.nf\n.RS\nmyapp deploy\n.RE\n.fi\n.PP\n.nf\n.RS\n# Deploy to staging.\nmyapp deploy \\-env staging\n.RE\n.fi\n"},
+		{"myapp-deploy.md", "## Examples\n\n```\nmyapp deploy\n\n# Deploy to staging.\nmyapp deploy -env staging\n```\n"},
+	}
+
+	for _, tt := range tests {
+		data, err := ioutil.ReadFile(filepath.Join(dir, tt.file))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !strings.Contains(string(data), tt.want) {
+			t.Errorf("%s does not contain %q\n%s", tt.file, tt.want, data)
+		}
+	}
+}
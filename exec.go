@@ -0,0 +1,64 @@
+package cli
+
+import (
+	"context"
+	"os/exec"
+	"path/filepath"
+	"time"
+)
+
+// defaultGrace is how long child processes are given to exit after being
+// asked to terminate before they are killed.
+const defaultGrace = 5 * time.Second
+
+// GracePeriod is a RuleOption setting how long child processes started with
+// Command are given to exit after being asked to terminate before they are
+// killed. The default is five seconds.
+func GracePeriod(d time.Duration) RuleOption {
+	return func(r *rule) {
+		r.grace = d
+	}
+}
+
+// Command returns an exec.Cmd to run the named program with the given
+// arguments as a child of the invocation of the command that received ctx.
+// The standard streams of the child default to those of the invocation.
+//
+// The child is started in its own process group. When ctx is done, such as
+// when the invocation times out or is interrupted, the process group is sent
+// SIGTERM. Any processes remaining after the grace period of the rule are
+// sent SIGKILL and reported on the standard error of the invocation. On
+// platforms without process groups the child is killed immediately.
+func Command(ctx context.Context, name string, args ...string) *exec.Cmd {
+	inv := ctxInvocation(ctx)
+	grace := inv.rule.grace
+	if grace <= 0 {
+		grace = defaultGrace
+	}
+
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Stdin = Stdin(ctx)
+	cmd.Stdout = Stdout(ctx)
+	cmd.Stderr = Stderr(ctx)
+	setProcessGroup(cmd)
+
+	cmd.Cancel = func() error {
+		pid := cmd.Process.Pid
+		err := terminateGroup(cmd)
+		if err != nil {
+			return err
+		}
+
+		go func() {
+			time.Sleep(grace)
+			if killGroup(pid) {
+				inv.app.errorf(Stderr(ctx), "%s: "+inv.app.messages.KilledChild,
+					inv.rule.name, filepath.Base(name), pid, grace)
+			}
+		}()
+
+		return nil
+	}
+
+	return cmd
+}
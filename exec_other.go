@@ -0,0 +1,18 @@
+//go:build windows || plan9 || js || wasip1
+
+package cli
+
+import "os/exec"
+
+// setProcessGroup is not supported on this platform.
+func setProcessGroup(cmd *exec.Cmd) {}
+
+// terminateGroup kills the process of cmd as there is no gentler option.
+func terminateGroup(cmd *exec.Cmd) error {
+	return cmd.Process.Kill()
+}
+
+// killGroup is not supported on this platform.
+func killGroup(pid int) bool {
+	return false
+}
@@ -0,0 +1,48 @@
+//go:build !windows
+
+package cli
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+type runChild struct {
+	*NullFlags
+}
+
+func TestCommandKillsAfterGrace(t *testing.T) {
+	app := New("myapp", "0.0.1")
+	app.Rule(&runChild{}, "child", "", GracePeriod(50*time.Millisecond))
+
+	var stderr lockedBuffer
+	app.stderr = &stderr
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	app.dispatch(ctx, []string{"child"})
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("child not killed after %v", elapsed)
+	}
+
+	for i := 0; i < 100 && !strings.Contains(stderr.String(), "killed child process sh"); i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if have := stderr.String(); !strings.Contains(have, "killed child process sh") {
+		t.Errorf("stderr\nhave %q", have)
+	}
+}
+
+func (c *runChild) Run(ctx context.Context) int {
+	cmd := Command(ctx, "sh", "-c", `trap "" TERM; sleep 10`)
+	cmd.Run()
+	return 0
+}
+
+func (c *runChild) String() string {
+	return "start a stubborn child"
+}
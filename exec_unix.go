@@ -0,0 +1,32 @@
+//go:build !windows && !plan9 && !js && !wasip1
+
+package cli
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// setProcessGroup arranges for cmd to be started in a new process group.
+func setProcessGroup(cmd *exec.Cmd) {
+	if cmd.SysProcAttr == nil {
+		cmd.SysProcAttr = &syscall.SysProcAttr{}
+	}
+
+	cmd.SysProcAttr.Setpgid = true
+}
+
+// terminateGroup asks the process group of cmd to terminate.
+func terminateGroup(cmd *exec.Cmd) error {
+	return syscall.Kill(-cmd.Process.Pid, syscall.SIGTERM)
+}
+
+// killGroup kills any processes remaining in the process group pid,
+// reporting whether there were any.
+func killGroup(pid int) bool {
+	if syscall.Kill(-pid, 0) != nil {
+		return false
+	}
+
+	return syscall.Kill(-pid, syscall.SIGKILL) == nil
+}
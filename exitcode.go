@@ -0,0 +1,181 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+)
+
+// ExitUsage is the exit code of invocations with invalid flags, matching
+// flag.ExitOnError. Flags are parsed with flag.ContinueOnError such that
+// invalid flags print the usage and the code is returned from Main and
+// seen by OnExit hooks rather than exiting the process.
+const ExitUsage = 2
+
+// Conventional exit codes for invocations that were stopped early.
+const (
+	ExitTimeout = 124 // The invocation exceeded its deadline.
+	ExitSignal  = 128 // Added to the number of the signal that stopped it.
+)
+
+// An ExitCoder is an error carrying the exit code of the invocation whose
+// command returned it, such as *exec.ExitError.
+type ExitCoder interface {
+	error
+	ExitCode() int
+}
+
+// A UsageError is returned by a command that was invoked incorrectly, such
+// as with an invalid combination of arguments, rather than failing to
+// perform its operation. The framework prints the error followed by the
+// synopsis of the command and exits with ExitUsage. Create one with
+// UsageErrorf.
+type UsageError struct {
+	Err error
+}
+
+// An errorCode maps the errors matching target to an exit code, see
+// MapError.
+type errorCode struct {
+	target error
+	code   int
+}
+
+// ExitCodeUsage describes an exit code of the Application for a
+// HelpRenderer.
+type ExitCodeUsage struct {
+	Code        int
+	Description string
+}
+
+// MapError sets the exit code of invocations whose command returns an error
+// matching target, such as fs.ErrNotExist, to code rather than 1. Errors are
+// matched with errors.Is in the order they were mapped, after looking for an
+// ExitCoder. The mapped codes are documented in the help of the commands.
+func (a *Application) MapError(target error, code int) {
+	a.errorCodes = append(a.errorCodes, errorCode{target: target, code: code})
+}
+
+// errorExitCode returns the exit code for an error returned by a command.
+func (a *Application) errorExitCode(err error) int {
+	var coder ExitCoder
+	if errors.As(err, &coder) && coder.ExitCode() > 0 {
+		return coder.ExitCode()
+	}
+
+	for _, m := range a.errorCodes {
+		if errors.Is(err, m.target) {
+			return m.code
+		}
+	}
+
+	return 1
+}
+
+// exitCodeUsages describes the exit codes of the Application if any errors
+// were mapped to exit codes, see MapError.
+func (a *Application) exitCodeUsages() []ExitCodeUsage {
+	if len(a.errorCodes) == 0 {
+		return nil
+	}
+
+	codes := []ExitCodeUsage{
+		{0, a.messages.ExitSuccess},
+		{1, a.messages.ExitFailure},
+		{ExitUsage, a.messages.ExitInvalid},
+	}
+	for _, m := range a.errorCodes {
+		codes = append(codes, ExitCodeUsage{m.code, m.target.Error()})
+	}
+
+	return codes
+}
+
+// printError prints an error returned by the command of the rule to w, as
+// "app: command: message", with each of the errors of a joined error, see
+// errors.Join, on its own line.
+func (a *Application) printError(w io.Writer, r *rule, err error) {
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		for _, err := range joined.Unwrap() {
+			a.printError(w, r, err)
+		}
+
+		return
+	}
+
+	fmt.Fprintf(w, "%s: %s: %v\n", a.name, r.name, err)
+
+	var usage *UsageError
+	if errors.As(err, &usage) {
+		fmt.Fprintf(w, "%s: %s %s\n", a.messages.Usage, a.name, r)
+	}
+}
+
+// UsageErrorf returns a UsageError with the message formatted as by
+// fmt.Errorf, such that it may wrap another error with %w.
+func UsageErrorf(format string, args ...interface{}) error {
+	return &UsageError{Err: fmt.Errorf(format, args...)}
+}
+
+// Error implements the error interface.
+func (e *UsageError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the wrapped error.
+func (e *UsageError) Unwrap() error {
+	return e.Err
+}
+
+// ExitCode implements the ExitCoder interface.
+func (e *UsageError) ExitCode() int {
+	return ExitUsage
+}
+
+// parseCode returns the exit code for an error parsing flags. Asking for help
+// is not a failure.
+func parseCode(err error) int {
+	if err == flag.ErrHelp {
+		return 0
+	}
+
+	return ExitUsage
+}
+
+// A SignalError is the cause of the cancellation of an invocation by a signal,
+// see context.Cause.
+type SignalError struct {
+	Signal os.Signal
+}
+
+// Error implements the error interface.
+func (e *SignalError) Error() string {
+	return fmt.Sprintf("received %v", e.Signal)
+}
+
+// cancelCode returns the conventional exit code for an invocation whose
+// context is done: 128 plus the signal number if cancelled by a signal, such
+// as 130 for SIGINT and 143 for SIGTERM, or 124 if it timed out.
+func cancelCode(ctx context.Context) (int, bool) {
+	if ctx.Err() == nil {
+		return 0, false
+	}
+
+	cause := context.Cause(ctx)
+
+	var sig *SignalError
+	if errors.As(cause, &sig) {
+		if n, ok := signalNumber(sig.Signal); ok {
+			return ExitSignal + n, true
+		}
+	}
+
+	if errors.Is(cause, context.DeadlineExceeded) {
+		return ExitTimeout, true
+	}
+
+	return 0, false
+}
@@ -0,0 +1,197 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"reflect"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestCancelCode(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Nanosecond)
+	defer cancel()
+	<-ctx.Done()
+
+	interrupted, cancelInt := context.WithCancelCause(context.Background())
+	cancelInt(&SignalError{syscall.SIGINT})
+
+	terminated, cancelTerm := context.WithCancelCause(context.Background())
+	cancelTerm(&SignalError{syscall.SIGTERM})
+
+	other, cancelOther := context.WithCancelCause(context.Background())
+	cancelOther(errors.New("other"))
+
+	tests := []struct {
+		ctx  context.Context
+		code int
+		ok   bool
+	}{
+		{context.Background(), 0, false},
+		{ctx, 124, true},
+		{interrupted, 130, true},
+		{terminated, 143, true},
+		{other, 0, false},
+	}
+
+	for i, tt := range tests {
+		code, ok := cancelCode(tt.ctx)
+		if code != tt.code || ok != tt.ok {
+			t.Errorf("%d\nhave %d %t\nwant %d %t", i, code, ok, tt.code, tt.ok)
+		}
+	}
+}
+
+func TestDispatchTimeoutCode(t *testing.T) {
+	app := New("myapp", "0.0.1")
+	app.Rule(&runTaskGroup{}, "group", "")
+	app.stderr = &bytes.Buffer{}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Nanosecond)
+	defer cancel()
+	<-ctx.Done()
+
+	code := app.dispatch(ctx, []string{"group"})
+	if code != ExitTimeout {
+		t.Errorf("exit code\nhave %d\nwant %d", code, ExitTimeout)
+	}
+}
+
+func TestOnExit(t *testing.T) {
+	args := os.Args
+	defer func() { os.Args = args }()
+	os.Args = []string{"myapp", "record", "-number", "3"}
+
+	app := New("myapp", "0.0.1")
+	app.Rule(&runRecord{}, "record", "[<a>] [<b>]")
+
+	var calls []int
+	app.OnExit(func(code int) { calls = append(calls, code) })
+	app.OnExit(func(code int) { calls = append(calls, -code) })
+	app.SetExit(func(code int) { calls = append(calls, code*10) })
+	app.Run()
+
+	if want := []int{-3, 3, 30}; !reflect.DeepEqual(calls, want) {
+		t.Errorf("calls\nhave %v\nwant %v", calls, want)
+	}
+}
+
+func TestFlagError(t *testing.T) {
+	args := os.Args
+	defer func() { os.Args = args }()
+
+	tests := []struct {
+		args   []string
+		code   int
+		stderr []string
+	}{
+		{[]string{"myapp", "record", "-bogus"}, ExitUsage, []string{"flag provided but not defined: -bogus\n", "Usage: myapp record [options]"}},
+		{[]string{"myapp", "-bogus", "record"}, ExitUsage, []string{"flag provided but not defined: -bogus\n", "Usage: myapp record [options]"}},
+		{[]string{"myapp", "-bogus"}, ExitUsage, []string{"flag provided but not defined: -bogus\n", "Usage: myapp <cmd>"}},
+		{[]string{"myapp", "record", "-number", "x"}, ExitUsage, []string{"invalid value \"x\" for flag -number"}},
+		{[]string{"myapp", "record", "-h"}, 0, []string{"Usage: myapp record [options]"}},
+	}
+
+	for _, tt := range tests {
+		os.Args = tt.args
+		app := New("myapp", "0.0.1")
+		app.Rule(&runRecord{}, "record", "[<a>] [<b>]")
+
+		var stderr bytes.Buffer
+		app.stderr = &stderr
+		var calls []int
+		app.OnExit(func(code int) { calls = append(calls, code) })
+		app.SetExit(func(code int) { calls = append(calls, code) })
+		app.Run()
+
+		if want := []int{tt.code, tt.code}; !reflect.DeepEqual(calls, want) {
+			t.Errorf("%q calls\nhave %v\nwant %v", tt.args, calls, want)
+		}
+
+		for _, want := range tt.stderr {
+			if !strings.Contains(stderr.String(), want) {
+				t.Errorf("%q stderr\nhave %q\nwant %q", tt.args, stderr.String(), want)
+			}
+		}
+	}
+}
+
+type codeError struct{}
+
+func (codeError) Error() string { return "conflict" }
+
+func (codeError) ExitCode() int { return 9 }
+
+func TestMapError(t *testing.T) {
+	app := New("myapp", "0.0.1")
+	app.MapError(fs.ErrNotExist, 3)
+
+	var returned error
+	app.RuleFunc("fail", "Fail.", "", func() error { return returned })
+
+	tests := []struct {
+		err    error
+		code   int
+		stderr string
+	}{
+		{errors.New("failed"), 1, "myapp: fail: failed\n"},
+		{fmt.Errorf("open x: %w", fs.ErrNotExist), 3, "myapp: fail: open x: file does not exist\n"},
+		{fmt.Errorf("wrapped: %w", codeError{}), 9, "myapp: fail: wrapped: conflict\n"},
+		{errors.Join(errors.New("a"), fs.ErrNotExist), 3, "myapp: fail: a\nmyapp: fail: file does not exist\n"},
+	}
+
+	for _, tt := range tests {
+		returned = tt.err
+		var stdout, stderr bytes.Buffer
+		code := app.RunWithArgs([]string{"fail"}, &stdout, &stderr)
+		if code != tt.code || stderr.String() != tt.stderr {
+			t.Errorf("%v\nhave %d %q\nwant %d %q", tt.err, code, stderr.String(), tt.code, tt.stderr)
+		}
+	}
+
+	var buf bytes.Buffer
+	app.printHelp(&buf, app.rules["fail"], true)
+	want := "Exit codes:\n  0     Success.\n  1     Failure.\n  2     Invalid flags or arguments.\n  3     file does not exist\n"
+	if !strings.HasSuffix(buf.String(), want+"\n") {
+		t.Errorf("help\nhave %q\nwant suffix %q", buf.String(), want)
+	}
+}
+
+func TestUsageError(t *testing.T) {
+	app := New("myapp", "0.0.1")
+	app.RuleFunc("copy", "Copy a file.", "<src> <dst>", func(src, dst string) error {
+		if src == dst {
+			return UsageErrorf("source and destination are both %s", src)
+		}
+
+		return fmt.Errorf("copy %s: %w", src, fs.ErrNotExist)
+	})
+
+	tests := []struct {
+		args []string
+		code int
+		want string
+	}{
+		{[]string{"copy", "a", "a"}, ExitUsage, "myapp: copy: source and destination are both a\nUsage: myapp copy <src> <dst>\n"},
+		{[]string{"copy", "a", "b"}, 1, "myapp: copy: copy a: file does not exist\n"},
+	}
+
+	for _, tt := range tests {
+		var stdout, stderr bytes.Buffer
+		code := app.RunWithArgs(tt.args, &stdout, &stderr)
+		if code != tt.code || stderr.String() != tt.want {
+			t.Errorf("%v\nhave %d %q\nwant %d %q", tt.args, code, stderr.String(), tt.code, tt.want)
+		}
+	}
+
+	err := UsageErrorf("bad %w", fs.ErrInvalid)
+	if !errors.Is(err, fs.ErrInvalid) {
+		t.Errorf("unwrap\nhave %v\nwant %v", err, fs.ErrInvalid)
+	}
+}
@@ -0,0 +1,78 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"os/exec"
+)
+
+// External is a command that runs an executable, such as a plugin, with the
+// arguments of the invocation. Flags are not parsed but passed to the
+// executable verbatim, along with the environment and standard streams of the
+// invocation. The exit code of the executable is the exit code of the
+// command.
+type External struct {
+	// Path is the name or path of the executable, looked up on the PATH if it
+	// contains no path separators.
+	Path string
+	// Description is the short description of the command.
+	Description string
+}
+
+// String implements the fmt.Stringer interface.
+func (e *External) String() string {
+	return e.Description
+}
+
+// Run runs the executable as a child of the invocation, see Command.
+func (e *External) Run(ctx context.Context, args []string) (int, error) {
+	err := Command(ctx, e.Path, args...).Run()
+	var exit *exec.ExitError
+	if errors.As(err, &exit) {
+		return exit.ExitCode(), nil
+	}
+
+	return 0, err
+}
+
+// Plugins enables running executables on the PATH named after the
+// Application and a command, such as myapp-deploy for "myapp deploy", for
+// top-level commands that are not registered, like git. If verify is true,
+// executables must be trusted by the TrustStore of the Application.
+func (a *Application) Plugins(verify bool) {
+	a.plugins = true
+	a.verify = verify
+}
+
+// plugin returns a rule running the plugin named name, or nil if there is
+// none.
+func (a *Application) plugin(name string) (*rule, error) {
+	if !a.plugins {
+		return nil, nil
+	}
+
+	path, err := exec.LookPath(a.name + "-" + name)
+	if err != nil {
+		return nil, nil
+	}
+
+	if a.verify {
+		store, err := a.TrustStore()
+		if err != nil {
+			return nil, err
+		}
+
+		err = store.Verify(path)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	r := &rule{name: name, arguments: "[<args>...]"}
+	err = r.bind(&External{Path: path, Description: "Plugin " + path + "."})
+	if err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
@@ -0,0 +1,71 @@
+//go:build !windows
+
+package cli
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestPlugins(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	plugin := filepath.Join(dir, "myapp-deploy")
+	ioutil.WriteFile(plugin, []byte("#!/bin/sh\necho \"deploy $*\"\nexit 3\n"), 0755)
+
+	app := New("myapp", "0.0.1")
+	var stdout, stderr bytes.Buffer
+	code := app.RunWithArgs([]string{"deploy", "-f", "prod"}, &stdout, &stderr)
+	if code != 1 || !strings.HasPrefix(stderr.String(), "Error: invalid command deploy\n") {
+		t.Errorf("disabled\nhave %d %q", code, stderr.String())
+	}
+
+	app.Plugins(false)
+	stdout.Reset()
+	stderr.Reset()
+	code = app.RunWithArgs([]string{"deploy", "-f", "prod"}, &stdout, &stderr)
+	if code != 3 {
+		t.Errorf("exit code\nhave %d\nwant %d\n%s", code, 3, stderr.String())
+	}
+
+	if want := "deploy -f prod\n"; stdout.String() != want {
+		t.Errorf("output\nhave %q\nwant %q", stdout.String(), want)
+	}
+
+	app.Plugins(true)
+	stderr.Reset()
+	code = app.RunWithArgs([]string{"deploy"}, &stdout, &stderr)
+	if code != 1 || !strings.Contains(stderr.String(), ErrUntrusted.Error()) {
+		t.Errorf("untrusted\nhave %d %q", code, stderr.String())
+	}
+
+	store, _ := app.TrustStore()
+	store.TrustChecksum(plugin)
+	code = app.RunWithArgs([]string{"deploy"}, &stdout, &stderr)
+	if code != 3 {
+		t.Errorf("trusted\nhave %d\nwant %d", code, 3)
+	}
+}
+
+func TestExternal(t *testing.T) {
+	app := New("myapp", "0.0.1")
+	app.Rule(&External{Path: "sh", Description: "Run a shell."}, "sh", "[<args>...]")
+
+	var stdout, stderr bytes.Buffer
+	code := app.RunWithArgs([]string{"sh", "-c", "echo $0", "x"}, &stdout, &stderr)
+	if code != 0 || stdout.String() != "x\n" {
+		t.Errorf("external\nhave %d %q %q", code, stdout.String(), stderr.String())
+	}
+
+	app.Rule(&External{Path: filepath.Join(t.TempDir(), "missing")}, "missing", "")
+	stderr.Reset()
+	code = app.RunWithArgs([]string{"missing"}, &stdout, &stderr)
+	if code != 1 || !strings.HasPrefix(stderr.String(), "myapp: missing: ") {
+		t.Errorf("missing\nhave %d %q", code, stderr.String())
+	}
+}
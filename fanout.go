@@ -0,0 +1,168 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"runtime"
+	"sync"
+)
+
+var errFanOut = fmt.Errorf("rule: FanOut requires a final slice parameter for Run and no declared arguments")
+
+// A fanOut runs a command once for each of its final arguments, see FanOut.
+type fanOut struct {
+	jobs *int
+}
+
+// A prefixWriter writes complete lines to w, each beginning with prefix,
+// holding mu while it does so.
+type prefixWriter struct {
+	mu     *sync.Mutex
+	w      io.Writer
+	prefix string
+	buf    bytes.Buffer
+}
+
+// FanOut is a RuleOption running the command once for each of the arguments
+// of the final slice parameter of its Run method, concurrently, rather than
+// once for all of them. The -jobs flag limits the number of concurrent runs,
+// defaulting to jobs, or the number of CPUs if jobs is zero.
+//
+// Each line written to Stdout and Stderr by a run is prefixed by its argument,
+// such as "a.txt: ", and written once complete, so that the output of
+// concurrent runs interleaves by line. The exit code is that of the first
+// argument whose run fails, in the order given. Runs not yet started when the
+// invocation is cancelled are skipped.
+//
+// The command must not declare arguments with arg tags, and should not keep
+// the state of a run in its fields, as runs share the command.
+func FanOut(jobs int) RuleOption {
+	if jobs < 1 {
+		jobs = runtime.NumCPU()
+	}
+
+	return func(r *rule) {
+		f := &fanOut{}
+		r.fanOut = f
+		r.define = append(r.define, func(flags *flag.FlagSet) {
+			f.jobs = flags.Int("jobs", jobs, "Number of arguments to run concurrently.")
+		})
+		r.middleware = append(r.middleware, f.middleware)
+	}
+}
+
+// middleware runs next once for each of the final arguments with bounded
+// concurrency.
+func (f *fanOut) middleware(next RunFunc) RunFunc {
+	return func(ctx context.Context, args []string) (int, error) {
+		n := len(ctxInvocation(ctx).rule.converters) - 1
+		if len(args) <= n+1 {
+			return next(ctx, args)
+		}
+
+		jobs := *f.jobs
+		if jobs < 1 {
+			jobs = 1
+		}
+
+		fixed, items := args[:n:n], args[n:]
+		codes := make([]int, len(items))
+		errs := make([]error, len(items))
+		s, _ := ctxStreams(ctx)
+
+		var (
+			wg       sync.WaitGroup
+			mu       sync.Mutex
+			panicked interface{}
+		)
+		sem := make(chan struct{}, jobs)
+		for i, item := range items {
+			select {
+			case sem <- struct{}{}:
+			case <-ctx.Done():
+			}
+			if ctx.Err() != nil {
+				break
+			}
+
+			wg.Add(1)
+			go func(i int, item string) {
+				defer wg.Done()
+				defer func() { <-sem }()
+
+				stdout := &prefixWriter{mu: &mu, w: s.stdout, prefix: item + ": "}
+				stderr := &prefixWriter{mu: &mu, w: s.stderr, prefix: item + ": "}
+				defer stdout.Flush()
+				defer stderr.Flush()
+
+				// Panics are raised again once all runs are done, to be
+				// recovered like those of any command.
+				defer func() {
+					if v := recover(); v != nil {
+						mu.Lock()
+						panicked = v
+						mu.Unlock()
+					}
+				}()
+
+				ctx := withStreams(ctx, streams{s.stdin, stdout, stderr})
+				codes[i], errs[i] = next(ctx, append(fixed, item))
+			}(i, item)
+		}
+
+		wg.Wait()
+		if panicked != nil {
+			panic(panicked)
+		}
+
+		for i := range items {
+			if codes[i] != 0 || errs[i] != nil {
+				return codes[i], errs[i]
+			}
+		}
+
+		return 0, nil
+	}
+}
+
+// Write implements the io.Writer interface.
+func (w *prefixWriter) Write(p []byte) (int, error) {
+	w.buf.Write(p)
+	i := bytes.LastIndexByte(w.buf.Bytes(), '\n')
+	if i < 0 {
+		return len(p), nil
+	}
+
+	lines := w.buf.Next(i + 1)
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	for len(lines) > 0 {
+		j := bytes.IndexByte(lines, '\n')
+		_, err := fmt.Fprintf(w.w, "%s%s", w.prefix, lines[:j+1])
+		if err != nil {
+			return 0, err
+		}
+
+		lines = lines[j+1:]
+	}
+
+	return len(p), nil
+}
+
+// Flush writes any incomplete final line.
+func (w *prefixWriter) Flush() error {
+	if w.buf.Len() == 0 {
+		return nil
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	_, err := fmt.Fprintf(w.w, "%s%s\n", w.prefix, w.buf.Bytes())
+	w.buf.Reset()
+	return err
+}
@@ -0,0 +1,90 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type runFanOut struct {
+	running int32
+	peak    int32
+}
+
+type runErrFanOut struct{}
+
+func TestFanOut(t *testing.T) {
+	app := New("myapp", "0.0.1")
+	cmd := &runFanOut{}
+	err := app.Rule(cmd, "check", "<mode> <files>...", FanOut(2))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	code := app.RunWithArgs([]string{"check", "-jobs", "3", "strict", "a", "bad", "c", "d", "e"}, &stdout, &stderr)
+	if code != 1 {
+		t.Errorf("exit code\nhave %d\nwant %d", code, 1)
+	}
+
+	lines := strings.Split(strings.TrimSpace(stdout.String()), "\n")
+	sort.Strings(lines)
+	want := "[a: partial a: strict a ok bad: partial bad: strict bad ok c: partial c: strict c ok d: partial d: strict d ok e: partial e: strict e ok]"
+	if fmt.Sprint(lines) != want {
+		t.Errorf("stdout\nhave %v\nwant %v", lines, want)
+	}
+
+	if have, want := stderr.String(), "bad: myapp: check: bad file\n"; have != want {
+		t.Errorf("stderr\nhave %q\nwant %q", have, want)
+	}
+
+	if peak := atomic.LoadInt32(&cmd.peak); peak < 2 || peak > 3 {
+		t.Errorf("concurrency\nhave %d\nwant 2 to 3", peak)
+	}
+
+	stdout.Reset()
+	code = app.RunWithArgs([]string{"check", "strict", "a"}, &stdout, &stderr)
+	if have, want := stdout.String(), "strict a ok\npartial"; code != 0 || have != want {
+		t.Errorf("single\nhave %d %q\nwant %d %q", code, have, 0, want)
+	}
+
+	err = app.Rule(&runErrFanOut{}, "one", "<file>", FanOut(0))
+	if err != errFanOut {
+		t.Errorf("error\nhave %v\nwant %v", err, errFanOut)
+	}
+}
+
+func (c *runFanOut) Run(ctx context.Context, mode string, files []string) error {
+	n := atomic.AddInt32(&c.running, 1)
+	defer atomic.AddInt32(&c.running, -1)
+	for {
+		peak := atomic.LoadInt32(&c.peak)
+		if n <= peak || atomic.CompareAndSwapInt32(&c.peak, peak, n) {
+			break
+		}
+	}
+
+	time.Sleep(10 * time.Millisecond)
+	fmt.Fprintln(Stdout(ctx), mode, strings.Join(files, " "), "ok")
+	fmt.Fprint(Stdout(ctx), "partial")
+	if files[0] == "bad" {
+		return fmt.Errorf("bad file")
+	}
+
+	return nil
+}
+
+func (c *runFanOut) String() string {
+	return "check files"
+}
+
+func (c *runErrFanOut) Run(file string) {}
+
+func (c *runErrFanOut) String() string {
+	return "check one file"
+}
@@ -0,0 +1,41 @@
+//go:build !linux && !darwin && !dragonfly && !freebsd && !netbsd && !openbsd
+
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// staleLock is the age beyond which a lock file is assumed to have been left
+// behind by a process that exited while holding it.
+const staleLock = 10 * time.Second
+
+// lockFile locks the file at path by creating it exclusively until the
+// returned function is called, which removes it. Processes locking the same
+// file wait for each other.
+func lockFile(path string) (unlock func(), err error) {
+	err = os.MkdirAll(filepath.Dir(path), 0755)
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_EXCL, 0600)
+		if err == nil {
+			f.Close()
+			return func() { os.Remove(path) }, nil
+		}
+		if !os.IsExist(err) {
+			return nil, err
+		}
+
+		if fi, err := os.Stat(path); err == nil && time.Since(fi.ModTime()) > staleLock {
+			os.Remove(path)
+			continue
+		}
+
+		time.Sleep(10 * time.Millisecond)
+	}
+}
@@ -0,0 +1,108 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+)
+
+// A flagGroup is a relationship between flags of a command.
+type flagGroup struct {
+	kind  flagGroupKind
+	flags []string
+	when  string
+}
+
+type flagGroupKind int
+
+const (
+	flagsExclusive flagGroupKind = iota
+	flagsTogether
+	flagRequiredIf
+)
+
+// Exclusive is a RuleOption requiring that at most one of the named flags of
+// the command is set.
+func Exclusive(flags ...string) RuleOption {
+	return func(r *rule) {
+		r.groups = append(r.groups, flagGroup{kind: flagsExclusive, flags: flags})
+	}
+}
+
+// Together is a RuleOption requiring that either all or none of the named
+// flags of the command are set.
+func Together(flags ...string) RuleOption {
+	return func(r *rule) {
+		r.groups = append(r.groups, flagGroup{kind: flagsTogether, flags: flags})
+	}
+}
+
+// RequiredIf is a RuleOption requiring that the flag of the command with the
+// given name is set if the flag named when is set.
+func RequiredIf(name, when string) RuleOption {
+	return func(r *rule) {
+		r.groups = append(r.groups, flagGroup{kind: flagRequiredIf, flags: []string{name}, when: when})
+	}
+}
+
+// checkGroups returns an error naming the flags violating the flag groups of
+// the rule. Flags set by environment variables or configuration count as set.
+func (r *rule) checkGroups() error {
+	set := visited(r.options)
+	for _, g := range r.groups {
+		var have, missing []string
+		for _, name := range g.flags {
+			if set[name] {
+				have = append(have, "-"+name)
+			} else {
+				missing = append(missing, "-"+name)
+			}
+		}
+
+		switch {
+		case g.kind == flagsExclusive && len(have) > 1:
+			return fmt.Errorf("flags %s cannot be used together", joinFlags(have))
+		case g.kind == flagsTogether && len(have) > 0 && len(missing) > 0:
+			noun := "flag"
+			if len(missing) > 1 {
+				noun = "flags"
+			}
+
+			return fmt.Errorf("%s %s must be used with %s", noun, joinFlags(missing), joinFlags(have))
+		case g.kind == flagRequiredIf && set[g.when] && len(missing) > 0:
+			return fmt.Errorf("flag %s is required when -%s is set", missing[0], g.when)
+		}
+	}
+
+	return nil
+}
+
+// notes describes the flag groups of the rule for usage printing.
+func (r *rule) notes(m *Messages) []string {
+	var notes []string
+	for _, g := range r.groups {
+		flags := make([]string, len(g.flags))
+		for i, name := range g.flags {
+			flags[i] = "-" + name
+		}
+
+		switch g.kind {
+		case flagsExclusive:
+			notes = append(notes, fmt.Sprintf(m.FlagsExclusive, joinFlags(flags)))
+		case flagsTogether:
+			notes = append(notes, fmt.Sprintf(m.FlagsTogether, joinFlags(flags)))
+		case flagRequiredIf:
+			notes = append(notes, fmt.Sprintf(m.FlagRequiredIf, flags[0], g.when))
+		}
+	}
+
+	return notes
+}
+
+// joinFlags joins flag names as a list in prose, such as "-a, -b and -c".
+func joinFlags(flags []string) string {
+	if len(flags) < 2 {
+		return strings.Join(flags, "")
+	}
+
+	return strings.Join(flags[:len(flags)-1], ", ") + " and " + flags[len(flags)-1]
+}
@@ -0,0 +1,65 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"strings"
+	"testing"
+)
+
+type runConnect struct{}
+
+func TestFlagGroups(t *testing.T) {
+	app := New("myapp", "0.0.1")
+	app.Rule(&runConnect{}, "connect", "",
+		Exclusive("json", "yaml", "text"),
+		Together("user", "password"),
+		RequiredIf("key", "tls"))
+
+	tests := []struct {
+		args   []string
+		code   int
+		stderr string
+	}{
+		{[]string{"connect", "-json"}, 0, ""},
+		{[]string{"connect", "-json", "-yaml", "-text"}, ExitUsage, "Error: connect: flags -json, -yaml and -text cannot be used together\n"},
+		{[]string{"connect", "-user", "me"}, ExitUsage, "Error: connect: flag -password must be used with -user\n"},
+		{[]string{"connect", "-user", "me", "-password", "pw"}, 0, ""},
+		{[]string{"connect", "-tls"}, ExitUsage, "Error: connect: flag -key is required when -tls is set\n"},
+		{[]string{"connect", "-tls", "-key", "k"}, 0, ""},
+	}
+
+	for _, tt := range tests {
+		var stdout, stderr bytes.Buffer
+		code := app.RunWithArgs(tt.args, &stdout, &stderr)
+		if code != tt.code || stderr.String() != tt.stderr {
+			t.Errorf("%q\nhave %d %q\nwant %d %q", tt.args, code, stderr.String(), tt.code, tt.stderr)
+		}
+	}
+
+	var buf bytes.Buffer
+	app.RunWithArgs([]string{"help", "connect"}, &buf, &buf)
+	want := "\nAt most one of -json, -yaml and -text may be set.\n" +
+		"Flags -user and -password must be set together.\n" +
+		"Flag -key is required when -tls is set.\n"
+	if !strings.Contains(buf.String(), want) {
+		t.Errorf("help does not annotate the groups\n%s", buf.String())
+	}
+}
+
+func (c *runConnect) Flags(flags *flag.FlagSet) {
+	flags.Bool("json", false, "JSON output.")
+	flags.Bool("yaml", false, "YAML output.")
+	flags.Bool("text", false, "Text output.")
+	flags.String("user", "", "User name.")
+	flags.String("password", "", "Password.")
+	flags.Bool("tls", false, "Use TLS.")
+	flags.String("key", "", "TLS key.")
+}
+
+func (c *runConnect) Run(ctx context.Context) {}
+
+func (c *runConnect) String() string {
+	return "connect to a server"
+}
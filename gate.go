@@ -0,0 +1,85 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// A gate keeps a command from running unless an environment variable opts in
+// to it, see RequireEnv and Experimental.
+type gate struct {
+	env          string
+	experimental bool
+}
+
+// RequireEnv is a RuleOption gating the command behind the environment
+// variable env, such as a feature flag. Unless env is set to a true value,
+// such as 1 or true, the command is hidden and refuses to run, printing how
+// to enable it.
+func RequireEnv(env string) RuleOption {
+	return func(r *rule) {
+		r.gate = &gate{env: env}
+	}
+}
+
+// Experimental is a RuleOption gating a preview command like RequireEnv,
+// behind the environment variable named by the EnvPrefix of the Application,
+// or its name in upper case, followed by _EXPERIMENTAL, such as
+// MYAPP_EXPERIMENTAL. The variable enables all experimental commands.
+func Experimental() RuleOption {
+	return func(r *rule) {
+		r.gate = &gate{experimental: true}
+	}
+}
+
+// gated returns the name and gate of the command with the given name, or its
+// group, that is not enabled, if any.
+func (a *Application) gated(name string) (string, *gate) {
+	parts := strings.Split(name, " ")
+	for i := range parts {
+		prefix := strings.Join(parts[:i+1], " ")
+		if r, ok := a.lookup(prefix); ok && !r.gate.enabled(a) {
+			return prefix, r.gate
+		}
+	}
+
+	return "", nil
+}
+
+// variable returns the name of the environment variable enabling the command
+// of the Application a.
+func (g *gate) variable(a *Application) string {
+	if !g.experimental {
+		return g.env
+	}
+
+	prefix := a.envPrefix
+	if prefix == "" {
+		prefix = strings.ToUpper(strings.Replace(a.name, "-", "_", -1))
+	}
+
+	return prefix + "_EXPERIMENTAL"
+}
+
+// enabled reports whether the gated command may run.
+func (g *gate) enabled(a *Application) bool {
+	if g == nil {
+		return true
+	}
+
+	ok, err := strconv.ParseBool(os.Getenv(g.variable(a)))
+	return err == nil && ok
+}
+
+// message returns the guidance printed when the command named name is run
+// without being enabled.
+func (g *gate) message(a *Application, name string) string {
+	format := a.messages.Gated
+	if g.experimental {
+		format = a.messages.Experimental
+	}
+
+	return fmt.Sprintf(format, name, g.variable(a))
+}
@@ -0,0 +1,55 @@
+package cli
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestGate(t *testing.T) {
+	t.Setenv("MYAPP_EXPERIMENTAL", "")
+	t.Setenv("MYAPP_BETA", "")
+
+	ran := ""
+	app := New("myapp", "0.0.1")
+	app.RuleFunc("preview", "Preview it.", "", func() { ran = "preview" }, Experimental())
+	app.RuleFunc("beta", "Beta group.", "", func() { ran = "beta" }, RequireEnv("MYAPP_BETA"))
+	app.RuleFunc("beta run", "Run the beta.", "", func() { ran = "beta run" })
+
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{[]string{"preview"}, "Error: preview is experimental and may change, set MYAPP_EXPERIMENTAL=1 to use it\n"},
+		{[]string{"beta", "run"}, "Error: beta is not enabled, set MYAPP_BETA=1 to use it\n"},
+	}
+
+	for _, tt := range tests {
+		var stdout, stderr bytes.Buffer
+		code := app.RunWithArgs(tt.args, &stdout, &stderr)
+		if code != 1 || stderr.String() != tt.want || ran != "" {
+			t.Errorf("%v\nhave %d %q %q\nwant %d %q", tt.args, code, stderr.String(), ran, 1, tt.want)
+		}
+	}
+
+	var buf bytes.Buffer
+	app.PrintUsage(&buf)
+	if strings.Contains(buf.String(), "preview") || strings.Contains(buf.String(), "beta") {
+		t.Errorf("usage lists gated commands\n%s", buf.String())
+	}
+
+	t.Setenv("MYAPP_EXPERIMENTAL", "1")
+	t.Setenv("MYAPP_BETA", "true")
+	for _, args := range [][]string{{"preview"}, {"beta", "run"}} {
+		code := app.Dispatch(args)
+		if want := strings.Join(args, " "); code != 0 || ran != want {
+			t.Errorf("%v\nhave %d %q\nwant %d %q", args, code, ran, 0, want)
+		}
+	}
+
+	buf.Reset()
+	app.PrintUsage(&buf)
+	if !strings.Contains(buf.String(), "preview") {
+		t.Errorf("usage omits enabled command\n%s", buf.String())
+	}
+}
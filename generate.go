@@ -0,0 +1,124 @@
+package cli
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"go/token"
+	"io"
+	"strings"
+	"text/template"
+	"unicode"
+)
+
+// commandTemplate is the template of the Go file written by Generate.
+var commandTemplate = template.Must(template.New("command").Parse(`package {{.Package}}
+
+import (
+	"context"
+	"flag"
+
+	cli "github.com/pnelson/cli-reflection"
+)
+
+// {{.Type}} is the {{.Name}} command.
+type {{.Type}} struct{}
+
+{{if .Parent}}// add{{.Title}} adds the {{.Name}} command to the {{.Parent}} group.
+func add{{.Title}}(group *cli.CommandGroup) error {
+	return group.Rule(&{{.Type}}{}, {{printf "%q" .Base}}, {{printf "%q" .Arguments}})
+}{{else}}// add{{.Title}} adds the {{.Name}} command to app.
+func add{{.Title}}(app *cli.Application) error {
+	return app.Rule(&{{.Type}}{}, {{printf "%q" .Name}}, {{printf "%q" .Arguments}})
+}{{end}}
+
+// Flags defines the options of the command.
+func (c *{{.Type}}) Flags(flags *flag.FlagSet) {}
+
+// Run runs the command.
+func (c *{{.Type}}) Run(ctx context.Context{{range .Params}}, {{.}}{{end}}) error {
+	return nil
+}
+
+// String returns the description of the command.
+func (c *{{.Type}}) String() string {
+	return "TODO: describe {{.Name}}."
+}
+`))
+
+// Generate writes a Go file in package pkg to w declaring a command named
+// name taking the arguments, in the same form as those given to Rule, with
+// stubs of its Flags, Run and String methods to be filled in, and a function
+// adding it to an Application, or to the CommandGroup of its parent for a
+// subcommand such as "remote add". The command type is named after the command
+// in camel case, such that "remote add" and "remote-add" are remoteAdd, added
+// by addRemoteAdd. Run takes a string for each argument, and a []string for a
+// final repeated argument.
+func Generate(w io.Writer, pkg, name, arguments string) error {
+	words := strings.FieldsFunc(name, func(r rune) bool { return r == ' ' || r == '-' || r == '_' })
+	typ := camel(words)
+	if !token.IsIdentifier(typ) || !token.IsIdentifier(pkg) {
+		return fmt.Errorf("generate: invalid command %q in package %q", name, pkg)
+	}
+
+	var params []string
+	args := parseArgs(arguments)
+	for i, arg := range args {
+		param := camel(strings.FieldsFunc(arg.Name, func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+		}))
+		if token.IsKeyword(param) {
+			param += "Arg"
+		}
+
+		if !token.IsIdentifier(param) {
+			return fmt.Errorf("generate: invalid argument %q", arg.Name)
+		}
+
+		if arg.Variadic && i == len(args)-1 {
+			params = append(params, param+" []string")
+		} else {
+			params = append(params, param+" string")
+		}
+	}
+
+	fields := strings.Fields(name)
+	var buf bytes.Buffer
+	err := commandTemplate.Execute(&buf, map[string]interface{}{
+		"Package":   pkg,
+		"Name":      strings.Join(fields, " "),
+		"Parent":    strings.Join(fields[:len(fields)-1], " "),
+		"Base":      fields[len(fields)-1],
+		"Arguments": arguments,
+		"Type":      typ,
+		"Title":     strings.ToUpper(typ[:1]) + typ[1:],
+		"Params":    params,
+	})
+	if err != nil {
+		return err
+	}
+
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(src)
+	return err
+}
+
+// camel joins the words in camel case, such that remote and add is
+// remoteAdd.
+func camel(words []string) string {
+	var b strings.Builder
+	for i, word := range words {
+		word = strings.ToLower(word)
+		if i > 0 && word != "" {
+			word = strings.ToUpper(word[:1]) + word[1:]
+		}
+
+		b.WriteString(word)
+	}
+
+	return b.String()
+}
@@ -0,0 +1,71 @@
+package cli
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestGenerate(t *testing.T) {
+	var buf bytes.Buffer
+	err := Generate(&buf, "main", "remote  add", "<name> <type> [<refs>...]")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := `package main
+
+import (
+	"context"
+	"flag"
+
+	cli "github.com/pnelson/cli-reflection"
+)
+
+// remoteAdd is the remote add command.
+type remoteAdd struct{}
+
+// addRemoteAdd adds the remote add command to the remote group.
+func addRemoteAdd(group *cli.CommandGroup) error {
+	return group.Rule(&remoteAdd{}, "add", "<name> <type> [<refs>...]")
+}
+
+// Flags defines the options of the command.
+func (c *remoteAdd) Flags(flags *flag.FlagSet) {}
+
+// Run runs the command.
+func (c *remoteAdd) Run(ctx context.Context, name string, typeArg string, refs []string) error {
+	return nil
+}
+
+// String returns the description of the command.
+func (c *remoteAdd) String() string {
+	return "TODO: describe remote add."
+}
+`
+	if have := buf.String(); have != want {
+		t.Errorf("source\nhave %s\nwant %s", have, want)
+	}
+
+	buf.Reset()
+	err = Generate(&buf, "main", "sync-all", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, want := range []string{
+		"func addSyncAll(app *cli.Application) error {\n\treturn app.Rule(&syncAll{}, \"sync-all\", \"\")\n}",
+		"func (c *syncAll) Run(ctx context.Context) error {",
+	} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("source\nhave %s\nwant %s", buf.String(), want)
+		}
+	}
+
+	for _, name := range []string{"", "9lives", "remote.add"} {
+		err = Generate(&buf, "main", name, "")
+		if err == nil {
+			t.Errorf("Generate(%q)\nhave nil\nwant error", name)
+		}
+	}
+}
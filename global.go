@@ -0,0 +1,252 @@
+package cli
+
+import (
+	"context"
+	"flag"
+	"strings"
+	"time"
+)
+
+// globals holds the values of the global flags of a dispatch.
+type globals struct {
+	noCache   bool
+	stats     bool
+	dryRun    bool
+	yes       bool
+	noInput   bool
+	timeout   time.Duration
+	locale    string
+	noBrowser bool
+	noPager   bool
+	config    string
+	trace     bool
+	version   cmdlineBool
+	set       overrides
+	keepGoing bool
+	out       output
+}
+
+// builtinFlags are the names of the global flags of the package that are
+// enabled by WithBuiltinFlags.
+var builtinFlags = []string{
+	"config", "o", "timeout", "yes", "no-input", "locale", "stats", "trace",
+	"no-cache", "no-browser", "no-pager", "dry-run",
+}
+
+// Flags registers fn to define global flags, which are given before the
+// command name and shared by all commands. The flags are defined anew for
+// each dispatch, like those of commands, and are documented in the Global
+// options section of the usage. Commands obtain their values through the
+// variables defined by fn or with GlobalFlag.
+func (a *Application) Flags(fn func(flags *flag.FlagSet)) {
+	a.global = append(a.global, fn)
+}
+
+// GlobalFlag returns the global flag with the given name for the invocation
+// of the command that received ctx, or nil if there is none.
+func GlobalFlag(ctx context.Context, name string) *flag.Flag {
+	return ctxInvocation(ctx).global.Lookup(name)
+}
+
+// WithBuiltinFlags is an Option enabling all of the global flags of the
+// package that are otherwise enabled one at a time, such as with
+// WithConfigFlag.
+func WithBuiltinFlags() Option {
+	return withBuiltin(builtinFlags...)
+}
+
+// WithConfigFlag is an Option enabling the -config global flag, which reads
+// the configuration file from the given path rather than the ConfigDir.
+func WithConfigFlag() Option {
+	return withBuiltin("config")
+}
+
+// WithOverrideFlag is an Option enabling the repeatable -o key=value global
+// flag, which overrides a value of the configuration, see ConfigValue.
+func WithOverrideFlag() Option {
+	return withBuiltin("o")
+}
+
+// WithTimeoutFlag is an Option enabling the -timeout global flag, which
+// stops the command after the given duration, see Timeout.
+func WithTimeoutFlag() Option {
+	return withBuiltin("timeout")
+}
+
+// WithYesFlag is an Option enabling the -yes global flag, which approves
+// confirmations without asking, see Confirm.
+func WithYesFlag() Option {
+	return withBuiltin("yes")
+}
+
+// WithNoInputFlag is an Option enabling the -no-input global flag, which
+// fails prompts instead of asking, see NoInput.
+func WithNoInputFlag() Option {
+	return withBuiltin("no-input")
+}
+
+// WithLocaleFlag is an Option enabling the -locale global flag, which sets
+// the locale used to format numbers and dates, see Locale.
+func WithLocaleFlag() Option {
+	return withBuiltin("locale")
+}
+
+// WithStatsFlag is an Option enabling the -stats global flag, which reports
+// the resource usage of the command once it returns.
+func WithStatsFlag() Option {
+	return withBuiltin("stats")
+}
+
+// WithTraceFlag is an Option enabling the -trace global flag, or -x for
+// short, which prints the resolved invocation before running it.
+func WithTraceFlag() Option {
+	return withBuiltin("trace")
+}
+
+// WithNoCacheFlag is an Option enabling the -no-cache global flag, which
+// ignores the results cached by Cache and Memoize.
+func WithNoCacheFlag() Option {
+	return withBuiltin("no-cache")
+}
+
+// WithNoBrowserFlag is an Option enabling the -no-browser global flag, which
+// prints URLs instead of opening them, see OpenURL.
+func WithNoBrowserFlag() Option {
+	return withBuiltin("no-browser")
+}
+
+// WithNoPagerFlag is an Option enabling the -no-pager global flag, which
+// disables the pager, see Pager.
+func WithNoPagerFlag() Option {
+	return withBuiltin("no-pager")
+}
+
+// WithDryRunFlag is an Option enabling the -dry-run global flag, which
+// requests that commands describe rather than make changes, see DryRun.
+// Applications whose commands do not all check DryRun should not enable it.
+func WithDryRunFlag() Option {
+	return withBuiltin("dry-run")
+}
+
+// withBuiltin returns an Option enabling the named global flags of the
+// package.
+func withBuiltin(names ...string) Option {
+	return func(a *Application) {
+		if a.builtins == nil {
+			a.builtins = make(map[string]bool)
+		}
+
+		for _, name := range names {
+			a.builtins[name] = true
+		}
+	}
+}
+
+// defineGlobal defines the documented global flags on flags, setting g from
+// the flags of the package that are enabled. The flags of the Application
+// take precedence over those of the package with the same name, which are
+// then not defined.
+func (a *Application) defineGlobal(flags *flag.FlagSet, g *globals) {
+	if a.standard {
+		g.out.define(flags)
+	}
+
+	a.defineAPIVersion(flags)
+	for _, fn := range a.global {
+		fn(flags)
+	}
+
+	// free reports whether the named flag is enabled and not yet defined.
+	free := func(name string) bool {
+		return a.builtins[name] && flags.Lookup(name) == nil
+	}
+	if free("config") {
+		flags.StringVar(&g.config, "config", "", "Read configuration from the file.")
+	}
+	if free("o") {
+		flags.Var(&g.set, "o", "Override a configuration value, as key=value. May be repeated.")
+	}
+	if free("timeout") {
+		flags.DurationVar(&g.timeout, "timeout", 0, "Stop the command after the duration.")
+	}
+	if free("yes") {
+		flags.BoolVar(&g.yes, "yes", false, "Approve confirmations without asking.")
+	}
+	if free("no-input") {
+		flags.BoolVar(&g.noInput, "no-input", false, "Fail instead of prompting for input.")
+	}
+	if free("locale") {
+		flags.StringVar(&g.locale, "locale", "", "Locale for formatting numbers and dates.")
+	}
+	if free("stats") {
+		flags.BoolVar(&g.stats, "stats", false, "Report resource usage after the command.")
+	}
+	if free("trace") {
+		flags.BoolVar(&g.trace, "trace", false, "Print the resolved invocation before running it.")
+		if flags.Lookup("x") == nil {
+			Shorthand(flags, "trace", "x")
+		}
+	}
+	if free("no-cache") {
+		flags.BoolVar(&g.noCache, "no-cache", false, "Ignore cached results.")
+	}
+	if free("no-browser") {
+		flags.BoolVar(&g.noBrowser, "no-browser", false, "Print URLs instead of opening a browser.")
+	}
+	if free("no-pager") {
+		flags.BoolVar(&g.noPager, "no-pager", false, "Do not page long output.")
+	}
+	if free("dry-run") {
+		flags.BoolVar(&g.dryRun, "dry-run", false, "Describe changes without making them.")
+	}
+}
+
+// deferFlags splits the arguments before the command name into those for
+// the global flags, followed by the command name and its arguments, and the
+// flags that are not global, which are deferred to the command, so that
+// "app -verbose build" is "app build -verbose". The value of a deferred flag
+// may follow it unless it is another flag or the name of a command.
+func (a *Application) deferFlags(flags *flag.FlagSet, args []string) (global, deferred []string) {
+	i := 0
+	for i < len(args) {
+		arg := args[i]
+		if arg == "--" || arg == "-" || !strings.HasPrefix(arg, "-") {
+			break
+		}
+
+		name := strings.TrimLeft(arg, "-")
+		value := strings.Contains(name, "=")
+		if j := strings.Index(name, "="); j >= 0 {
+			name = name[:j]
+		}
+
+		if repeated := repeatCounter(flags, name); !value && repeated != nil {
+			global = append(global, repeated...)
+			i++
+			continue
+		}
+
+		n := 1
+		f := flags.Lookup(name)
+		if f != nil || name == "h" || name == "help" {
+			if f != nil && !value && !isBoolFlag(f) {
+				n = 2
+			}
+
+			global = append(global, args[i:min(i+n, len(args))]...)
+			i += n
+			continue
+		}
+
+		if next := i + 1; !value && next < len(args) && !strings.HasPrefix(args[next], "-") {
+			if _, ok := a.lookup(args[next]); !ok {
+				n = 2
+			}
+		}
+
+		deferred = append(deferred, args[i:min(i+n, len(args))]...)
+		i += n
+	}
+
+	return append(global, args[min(i, len(args)):]...), deferred
+}
@@ -0,0 +1,126 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"strings"
+	"testing"
+)
+
+type runGlobal struct {
+	*NullFlags
+	profile string
+}
+
+func TestGlobalFlags(t *testing.T) {
+	app := New("myapp", "0.0.1")
+	var region *string
+	app.Flags(func(flags *flag.FlagSet) {
+		region = flags.String("region", "us-east-1", "Region to operate in.")
+		flags.String("profile", "default", "Named profile to use.")
+	})
+	cmd := &runGlobal{}
+	app.Rule(cmd, "global", "")
+
+	code := app.Dispatch([]string{"-region", "eu-west-1", "-profile", "work", "global"})
+	if code != 0 {
+		t.Fatalf("exit code\nhave %d\nwant %d", code, 0)
+	}
+
+	if *region != "eu-west-1" || cmd.profile != "work" {
+		t.Errorf("global flags\nhave %q %q\nwant %q %q", *region, cmd.profile, "eu-west-1", "work")
+	}
+
+	app.Dispatch([]string{"global"})
+	if *region != "us-east-1" || cmd.profile != "default" {
+		t.Errorf("global flags reset\nhave %q %q\nwant %q %q", *region, cmd.profile, "us-east-1", "default")
+	}
+
+	var buf bytes.Buffer
+	app.PrintUsage(&buf)
+	want := `Global options:
+  -profile="default"    Named profile to use.
+  -region="us-east-1"   Region to operate in.
+`
+	if !strings.Contains(buf.String(), want) {
+		t.Errorf("usage\nhave\n%s\nwant\n%s", buf.String(), want)
+	}
+}
+
+func (c *runGlobal) Run(ctx context.Context) {
+	c.profile = GlobalFlag(ctx, "profile").Value.String()
+}
+
+func (c *runGlobal) String() string {
+	return "read global flags"
+}
+
+func TestDeferFlags(t *testing.T) {
+	app := New("myapp", "0.0.1", WithLocaleFlag(), WithYesFlag())
+	cmd := &runRecord{}
+	app.Rule(cmd, "record", "[<a>] [<b>]")
+
+	tests := []struct {
+		args []string
+		code int
+		have []string
+	}{
+		{[]string{"-number", "4", "record", "x"}, 4, []string{"x", ""}},
+		{[]string{"-locale", "fr", "--number=5", "record"}, 5, []string{"", ""}},
+		{[]string{"-number", "6", "-yes", "record", "-number", "3", "y"}, 3, []string{"y", ""}},
+		{[]string{"-bogus", "record"}, ExitUsage, nil},
+	}
+
+	for _, tt := range tests {
+		cmd.have = nil
+		var stderr bytes.Buffer
+		code := app.RunWithArgs(tt.args, &stderr, &stderr)
+		if code != tt.code || strings.Join(cmd.have, ",") != strings.Join(tt.have, ",") {
+			t.Errorf("%q\nhave %d %q\nwant %d %q\n%s", tt.args, code, cmd.have, tt.code, tt.have, stderr.String())
+		}
+	}
+}
+
+func TestBuiltinFlags(t *testing.T) {
+	app := New("myapp", "0.0.1", WithConfigFlag(), WithTraceFlag())
+	var config, version *string
+	app.Flags(func(flags *flag.FlagSet) {
+		config = flags.String("config", "dev", "Named configuration.")
+		version = flags.String("version", "v1", "API version.")
+		flags.String("profile", "default", "Named profile to use.")
+	})
+	cmd := &runGlobal{}
+	app.Rule(cmd, "global", "")
+
+	var stderr bytes.Buffer
+	code := app.RunWithArgs([]string{"-config", "prod", "-version", "v2", "global"}, &stderr, &stderr)
+	if code != 0 {
+		t.Fatalf("exit code\nhave %d\nwant %d\n%s", code, 0, stderr.String())
+	}
+
+	if *config != "prod" || *version != "v2" {
+		t.Errorf("global flags\nhave %q %q\nwant %q %q", *config, *version, "prod", "v2")
+	}
+
+	var buf bytes.Buffer
+	app.PrintUsage(&buf)
+	want := `Global options:
+  -config="dev"        Named configuration.
+  -profile="default"   Named profile to use.
+  -x, -trace           Print the resolved invocation before running it.
+  -version="v1"        API version.
+`
+	if !strings.Contains(buf.String(), want) {
+		t.Errorf("usage\nhave\n%s\nwant\n%s", buf.String(), want)
+	}
+
+	if strings.Contains(buf.String(), "-timeout") {
+		t.Errorf("usage lists a disabled flag\n%s", buf.String())
+	}
+
+	code = app.RunWithArgs([]string{"-dry-run", "global"}, &stderr, &stderr)
+	if code != ExitUsage {
+		t.Errorf("disabled flag exit code\nhave %d\nwant %d", code, ExitUsage)
+	}
+}
@@ -0,0 +1,87 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os/user"
+	"strings"
+)
+
+// An authorizer is a command deciding whether the invocation may run it,
+// see Guard.
+type authorizer interface {
+	Authorize(ctx context.Context) error
+}
+
+// Guard is a RuleOption calling fn before the command runs to decide whether
+// the invocation may run it, such as to restrict dangerous commands of
+// administrative tools to certain users, see AllowUsers and AllowGroups, or
+// to the holders of a token, see Identity. Commands may also decide for
+// themselves with a method Authorize(ctx context.Context) error, called after
+// the functions given to Guard. If any returns an error, the command does
+// not run: the error is printed as permission denied and the invocation
+// exits with ExitPolicy.
+func Guard(fn func(ctx context.Context) error) RuleOption {
+	return func(r *rule) {
+		r.guards = append(r.guards, fn)
+	}
+}
+
+// AllowUsers returns a function for Guard allowing only the named operating
+// system users.
+func AllowUsers(names ...string) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		u, err := user.Current()
+		if err != nil {
+			return err
+		}
+
+		if !contains(names, u.Username) {
+			return fmt.Errorf("user %s is not allowed", u.Username)
+		}
+
+		return nil
+	}
+}
+
+// AllowGroups returns a function for Guard allowing only the operating
+// system users that are members of one of the named groups.
+func AllowGroups(names ...string) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		u, err := user.Current()
+		if err != nil {
+			return err
+		}
+
+		ids, err := u.GroupIds()
+		if err != nil {
+			return err
+		}
+
+		for _, id := range ids {
+			g, err := user.LookupGroupId(id)
+			if err == nil && contains(names, g.Name) {
+				return nil
+			}
+		}
+
+		return fmt.Errorf("user %s is not a member of %s", u.Username, strings.Join(names, " or "))
+	}
+}
+
+// authorize returns an error if a guard of the rule, or the command itself,
+// denies the invocation.
+func (r *rule) authorize(ctx context.Context) error {
+	for _, fn := range r.guards {
+		err := fn(ctx)
+		if err != nil {
+			return err
+		}
+	}
+
+	if a, ok := r.command.(authorizer); ok {
+		return a.Authorize(ctx)
+	}
+
+	return nil
+}
@@ -0,0 +1,63 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os/user"
+	"strings"
+	"testing"
+)
+
+type runGuarded struct {
+	runRecord
+	deny error
+}
+
+func (c *runGuarded) Authorize(ctx context.Context) error {
+	return c.deny
+}
+
+func TestGuard(t *testing.T) {
+	u, err := user.Current()
+	if err != nil {
+		t.Skip(err)
+	}
+
+	token := ""
+	app := New("myapp", "0.0.1")
+	app.Rule(&runRecord{}, "deploy", "[<a>] [<b>]", Guard(func(ctx context.Context) error {
+		if token != "secret" {
+			return errors.New("invalid token")
+		}
+
+		return nil
+	}))
+	app.Rule(&runRecord{}, "mine", "[<a>] [<b>]", Guard(AllowUsers(u.Username)))
+	app.Rule(&runRecord{}, "theirs", "[<a>] [<b>]", Guard(AllowUsers("nobody-at-all")))
+	app.Rule(&runGuarded{}, "self", "[<a>] [<b>]")
+	app.Rule(&runGuarded{deny: errors.New("read-only mode")}, "other", "[<a>] [<b>]")
+
+	tests := []struct {
+		args   string
+		token  string
+		code   int
+		stderr string
+	}{
+		{"deploy", "", ExitPolicy, "Error: deploy: permission denied: invalid token\n"},
+		{"deploy", "secret", 0, ""},
+		{"mine", "", 0, ""},
+		{"theirs", "", ExitPolicy, "Error: theirs: permission denied: user " + u.Username + " is not allowed\n"},
+		{"self", "", 0, ""},
+		{"other", "", ExitPolicy, "Error: other: permission denied: read-only mode\n"},
+	}
+
+	for _, tt := range tests {
+		token = tt.token
+		var stdout, stderr bytes.Buffer
+		code := app.RunWithArgs(strings.Fields(tt.args), &stdout, &stderr)
+		if code != tt.code || stderr.String() != tt.stderr {
+			t.Errorf("%s\nhave %d %q\nwant %d %q", tt.args, code, stderr.String(), tt.code, tt.stderr)
+		}
+	}
+}
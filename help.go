@@ -1,14 +1,55 @@
 package cli
 
+import (
+	"context"
+	"io"
+	"strings"
+)
+
 type commandHelp struct {
 	*NullFlags
-	usage func()
+	app *Application
+}
+
+// A helper is implemented by commands providing a long description for their
+// help, see Rule.
+type helper interface {
+	Help() string
 }
 
-func (c *commandHelp) Run() {
-	c.usage()
+// A usager is implemented by commands writing their own help, such as to
+// include diagrams or an extended grammar, in place of that generated from
+// their description, arguments and options. The help is still framed by the
+// synopsis and the exit codes of the Application.
+type usager interface {
+	Usage(w io.Writer)
+}
+
+func (c *commandHelp) Run(ctx context.Context, words []string) {
+	w, noColor := c.app.pager(Stderr(ctx), ctxInvocation(ctx).noPager), ctxOutput(ctx).noColor
+	defer w.Close()
+
+	if len(words) > 0 {
+		name, rest := c.app.resolve(words)
+		r, ok := c.app.lookup(name)
+		if ok && len(rest) == 0 && r.load() == nil {
+			if _, group := r.command.(*commandGroup); !group {
+				c.app.printHelp(w, r, noColor)
+				return
+			}
+		}
+	}
+
+	c.app.printMatching(w, strings.Join(words, " "), noColor)
 }
 
 func (c *commandHelp) String() string {
 	return "Output this usage information."
 }
+
+// printHelp renders the detailed help of a command: its synopsis,
+// description, long description, if it has a Help method, and its options.
+func (a *Application) printHelp(w io.Writer, r *rule, noColor bool) {
+	u := &Usage{Name: a.name, Version: a.version, ExitCodes: a.exitCodeUsages(), Messages: a.messages}
+	a.render(w, noColor).Help(w, u, a.commandUsage(r))
+}
@@ -0,0 +1,94 @@
+package cli
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+)
+
+type runHelp struct {
+	runRecord
+}
+
+type runCustomHelp struct {
+	runRecord
+}
+
+func TestHelp(t *testing.T) {
+	app := New("myapp", "0.0.1")
+	app.Rule(&runHelp{}, "record", "<a> <b>")
+
+	want := `Usage: myapp record [options] <a> <b>
+
+record arguments
+
+Records the arguments a and b.
+
+Options:
+  -number=<n>   exit code
+
+`
+	tests := [][]string{
+		{"help", "record"},
+		{"record", "-h"},
+		{"record", "--help"},
+	}
+
+	for _, args := range tests {
+		var stdout, stderr bytes.Buffer
+		code := app.RunWithArgs(args, &stdout, &stderr)
+		if code != 0 {
+			t.Errorf("%q exit code\nhave %d\nwant %d", args, code, 0)
+		}
+
+		if stderr.String() != want {
+			t.Errorf("%q\nhave\n%s\nwant\n%s", args, stderr.String(), want)
+		}
+	}
+
+	var stdout, stderr bytes.Buffer
+	app.RunWithArgs([]string{"help", "rec"}, &stdout, &stderr)
+	want = `Usage: myapp <cmd> [options] [<args>]
+  record [options] <a> <b>   record arguments
+    -number=<n>              exit code
+
+`
+	if stderr.String() != want {
+		t.Errorf("prefix\nhave\n%s\nwant\n%s", stderr.String(), want)
+	}
+}
+
+func (c *runHelp) Help() string {
+	return "Records the arguments a and b.\n"
+}
+
+func TestCustomHelp(t *testing.T) {
+	app := New("myapp", "0.0.1")
+	app.Rule(&runCustomHelp{}, "record", "<a> <b>")
+	app.MapError(errors.New("Not found."), 4)
+
+	want := `Usage: myapp record [options] <a> <b>
+
+  a ---> b
+
+Exit codes:
+  0     Success.
+  1     Failure.
+  2     Invalid flags or arguments.
+  4     Not found.
+
+`
+	for _, args := range [][]string{{"help", "record"}, {"record", "-h"}} {
+		var stdout, stderr bytes.Buffer
+		app.RunWithArgs(args, &stdout, &stderr)
+		if stderr.String() != want {
+			t.Errorf("%q\nhave\n%s\nwant\n%s", args, stderr.String(), want)
+		}
+	}
+}
+
+func (c *runCustomHelp) Usage(w io.Writer) {
+	fmt.Fprintf(w, "  a ---> b\n\n")
+}
@@ -0,0 +1,40 @@
+package cli
+
+import "strings"
+
+// wantsHelp reports whether args ask for the help of the command of the rule
+// with -h, -help or --help anywhere before a -- argument, including after
+// positional arguments. Flags the command defines itself, such as -h for a
+// host, and the values of flags are not mistaken for a request for help.
+func (r *rule) wantsHelp(args []string) bool {
+	if _, ok := r.command.(*External); ok {
+		return false
+	}
+
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" {
+			return false
+		}
+
+		if !strings.HasPrefix(arg, "-") || arg == "-" {
+			continue
+		}
+
+		name := strings.TrimLeft(arg, "-")
+		if strings.Contains(name, "=") {
+			continue
+		}
+
+		f := r.options.Lookup(name)
+		if f == nil && (name == "h" || name == "help") {
+			return true
+		}
+
+		if f != nil && !isBoolFlag(f) {
+			i++
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,73 @@
+package cli
+
+import (
+	"bytes"
+	"flag"
+	"strings"
+	"testing"
+)
+
+type runHost struct {
+	host *string
+	ran  bool
+}
+
+func (r *runHost) Flags(flags *flag.FlagSet) {
+	r.host = flags.String("h", "localhost", "host to connect to")
+}
+
+func (r *runHost) String() string {
+	return "Connect to a host."
+}
+
+func (r *runHost) Run() {
+	r.ran = true
+}
+
+func TestHelpFlag(t *testing.T) {
+	tests := []struct {
+		args []string
+		help bool
+	}{
+		{[]string{"record", "-h"}, true},
+		{[]string{"record", "a", "--help"}, true},
+		{[]string{"record", "a", "-help", "b"}, true},
+		{[]string{"record", "--", "-h"}, false},
+		{[]string{"record", "-number", "-h"}, false},
+		{[]string{"record", "a", "b"}, false},
+	}
+
+	for _, tt := range tests {
+		app := New("myapp", "0.0.1")
+		app.Rule(&runRecord{}, "record", "[<a>] [<b>] [<c>]")
+
+		var stdout, stderr bytes.Buffer
+		code := app.RunWithArgs(tt.args, &stdout, &stderr)
+		help := strings.HasPrefix(stderr.String(), "Usage: myapp record")
+		if tt.help && (code != 0 || !help) {
+			t.Errorf("%q\nhave %d %q\nwant 0 and help", tt.args, code, stderr.String())
+		}
+
+		if !tt.help && help {
+			t.Errorf("%q\nhave help\nwant no help", tt.args)
+		}
+	}
+}
+
+func TestHelpFlagDefined(t *testing.T) {
+	app := New("myapp", "0.0.1")
+	cmd := &runHost{}
+	app.Rule(cmd, "connect", "")
+
+	var stdout, stderr bytes.Buffer
+	code := app.RunWithArgs([]string{"connect", "-h", "example.com"}, &stdout, &stderr)
+	if code != 0 || !cmd.ran || *cmd.host != "example.com" {
+		t.Errorf("-h\nhave %d %t %q\nwant %d %t %q", code, cmd.ran, *cmd.host, 0, true, "example.com")
+	}
+
+	cmd.ran = false
+	code = app.RunWithArgs([]string{"connect", "--help"}, &stdout, &stderr)
+	if code != 0 || cmd.ran || !strings.HasPrefix(stderr.String(), "Usage: myapp connect") {
+		t.Errorf("--help\nhave %d %t %q", code, cmd.ran, stderr.String())
+	}
+}
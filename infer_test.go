@@ -0,0 +1,37 @@
+package cli
+
+import (
+	"runtime/debug"
+	"testing"
+)
+
+func TestBuildVersion(t *testing.T) {
+	tests := []struct {
+		info debug.BuildInfo
+		want string
+	}{
+		{debug.BuildInfo{Main: debug.Module{Version: "v1.2.3"}}, "1.2.3"},
+		{debug.BuildInfo{Main: debug.Module{Version: "(devel)"}}, "devel"},
+		{debug.BuildInfo{
+			Main: debug.Module{Version: "(devel)"},
+			Settings: []debug.BuildSetting{
+				{Key: "vcs.revision", Value: "0123456789abcdef"},
+				{Key: "vcs.modified", Value: "true"},
+			},
+		}, "devel-0123456789ab-dirty"},
+	}
+
+	for _, tt := range tests {
+		have := buildVersion(&tt.info)
+		if have != tt.want {
+			t.Errorf("buildVersion(%v)\nhave %q\nwant %q", tt.info.Main.Version, have, tt.want)
+		}
+	}
+}
+
+func TestNewInfer(t *testing.T) {
+	app := New("", "")
+	if app.name == "" || app.version == "" {
+		t.Errorf("New(\"\", \"\")\nhave name %q version %q\nwant both inferred", app.name, app.version)
+	}
+}
@@ -0,0 +1,96 @@
+package cli
+
+import (
+	"context"
+	"time"
+)
+
+// An Event describes a completed invocation of a command, see Instrument.
+type Event struct {
+	// Command is the full name of the command, such as "remote add".
+	Command string
+	// Args are the arguments following the command name, with the values of
+	// secret flags redacted.
+	Args     []string
+	Start    time.Time
+	Duration time.Duration
+	Code     int
+	// Err is the first error reported by the command, if any.
+	Err error
+}
+
+// A Recorder records the invocations of commands, such as to emit
+// OpenTelemetry spans or usage metrics.
+type Recorder interface {
+	Record(ctx context.Context, e *Event)
+}
+
+// A RecorderFunc is a function used as a Recorder.
+type RecorderFunc func(ctx context.Context, e *Event)
+
+// Record implements the Recorder interface.
+func (fn RecorderFunc) Record(ctx context.Context, e *Event) {
+	fn(ctx, e)
+}
+
+// Instrument adds a Recorder called after each command runs, including when
+// it fails, panics or is cancelled. Recorders are called in the order they
+// were added with the context of the invocation, which may already be
+// cancelled. Invocations that fail before the command runs, such as with
+// invalid flags, are not recorded.
+func (a *Application) Instrument(r Recorder) {
+	a.recorders = append(a.recorders, r)
+}
+
+// record calls the recorders with the invocation of the command of the rule
+// that ran from start with the exit code.
+func (a *Application) record(ctx context.Context, r *rule, start time.Time, code int) {
+	if len(a.recorders) == 0 {
+		return
+	}
+
+	inv := ctxInvocation(ctx)
+	inv.mu.Lock()
+	err := inv.err
+	inv.mu.Unlock()
+	if err == nil && ctx.Err() != nil {
+		err = context.Cause(ctx)
+	}
+
+	e := &Event{
+		Command:  r.name,
+		Args:     redactArgs(r, inv.args),
+		Start:    start,
+		Duration: time.Since(start),
+		Code:     code,
+		Err:      err,
+	}
+
+	for _, recorder := range a.recorders {
+		recorder.Record(ctx, e)
+	}
+}
+
+// fail records the first error reported by the command of the invocation,
+// and the last for lastError.
+func (inv *invocation) fail(err error) {
+	inv.mu.Lock()
+	defer inv.mu.Unlock()
+
+	if inv.err == nil {
+		inv.err = err
+	}
+
+	inv.last = err
+}
+
+// lastError returns the last error reported by the command of the invocation
+// since the last call, if any.
+func (inv *invocation) lastError() error {
+	inv.mu.Lock()
+	defer inv.mu.Unlock()
+
+	err := inv.last
+	inv.last = nil
+	return err
+}
@@ -0,0 +1,43 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"io"
+	"reflect"
+	"testing"
+)
+
+func TestInstrument(t *testing.T) {
+	var events []Event
+	app := New("myapp", "0.0.1")
+	app.Instrument(RecorderFunc(func(ctx context.Context, e *Event) {
+		events = append(events, *e)
+	}))
+
+	errFailed := errors.New("failed")
+	app.RuleFunc("ok", "Succeed.", "[<arg>]", func(arg string) {})
+	app.RuleFunc("fail", "Fail.", "", func() error { return errFailed })
+	app.RuleFunc("panic", "Panic.", "", func() { panic("boom") })
+
+	app.RunWithArgs([]string{"ok", "a"}, io.Discard, io.Discard)
+	app.RunWithArgs([]string{"fail"}, io.Discard, io.Discard)
+	app.RunWithArgs([]string{"panic"}, io.Discard, io.Discard)
+	app.RunWithArgs([]string{"ok", "-bad"}, io.Discard, io.Discard)
+
+	if len(events) != 3 {
+		t.Fatalf("events\nhave %d\nwant %d", len(events), 3)
+	}
+
+	if e := events[0]; e.Command != "ok" || !reflect.DeepEqual(e.Args, []string{"a"}) || e.Code != 0 || e.Err != nil || e.Start.IsZero() {
+		t.Errorf("ok\nhave %+v", e)
+	}
+
+	if e := events[1]; e.Command != "fail" || e.Code != 1 || e.Err != errFailed {
+		t.Errorf("fail\nhave %+v", e)
+	}
+
+	if e := events[2]; e.Code != ExitPanic || e.Err == nil || e.Err.Error() != "panic: boom" {
+		t.Errorf("panic\nhave %+v", e)
+	}
+}
@@ -0,0 +1,154 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"strings"
+)
+
+var errInterpolate = fmt.Errorf("interpolate: unterminated ${ or $(")
+
+type interpolatedContextKey struct{}
+
+// Interpolate enables the interpolation of environment variables in the
+// arguments and flag values given to the Application, such that ${HOME} is
+// replaced by the value of $HOME, or nothing if it is unset. $$ is a literal
+// dollar sign, and a dollar sign followed by anything else is kept as is.
+//
+// In applications that enable Chain, and in the commands read by Shell,
+// $(cmd args...) is replaced by the standard output of the command of the
+// Application, without its trailing newlines, such as $(config get region).
+// The output is not split into several arguments. A command substitution
+// that fails fails the command it appears in. Within Shell, text in single
+// quotes or following a backslash is not interpolated, see SplitArgs.
+// Requests to the Handler are not interpolated.
+func (a *Application) Interpolate(enabled bool) {
+	a.interpolate = enabled
+}
+
+// interpolateArgs interpolates the arguments of the dispatch with parent,
+// returning the context to dispatch them with. Arguments dispatched from
+// within an interpolated dispatch have already been interpolated.
+func (a *Application) interpolateArgs(parent context.Context, args []string) (context.Context, []string, error) {
+	if !a.interpolate || remote(parent) || parent.Value(interpolatedContextKey{}) != nil {
+		return parent, args, nil
+	}
+
+	ctx := context.WithValue(parent, interpolatedContextKey{}, true)
+	expand := a.expander(ctx, a.separator != "")
+	interpolated := make([]string, len(args))
+	for i, arg := range args {
+		var err error
+		interpolated[i], err = interpolate(arg, expand)
+		if err != nil {
+			return ctx, nil, err
+		}
+	}
+
+	return ctx, interpolated, nil
+}
+
+// expander returns the function expanding the interpolations found by
+// dollar, substituting commands dispatched with ctx if substitute is set.
+func (a *Application) expander(ctx context.Context, substitute bool) func(token string) (string, error) {
+	return func(token string) (string, error) {
+		switch {
+		case token == "$$":
+			return "$", nil
+		case token[1] == '{':
+			return os.Getenv(token[2 : len(token)-1]), nil
+		case !substitute:
+			return token, nil
+		}
+
+		return a.substitute(ctx, token[2:len(token)-1])
+	}
+}
+
+// substitute dispatches the command line with an empty standard input and
+// returns its standard output without trailing newlines.
+func (a *Application) substitute(ctx context.Context, line string) (string, error) {
+	args, err := splitArgs(line, a.expander(ctx, true))
+	if err != nil {
+		return "", err
+	}
+
+	var stdout bytes.Buffer
+	code := a.dispatch(withStreams(ctx, streams{strings.NewReader(""), &stdout, Stderr(ctx)}), args)
+	if code != 0 {
+		return "", fmt.Errorf("interpolate: $(%s) exited with code %d", line, code)
+	}
+
+	return strings.TrimRight(stdout.String(), "\n"), nil
+}
+
+// interpolate replaces each interpolation in s with its expansion.
+func interpolate(s string, expand func(token string) (string, error)) (string, error) {
+	if !strings.Contains(s, "$") {
+		return s, nil
+	}
+
+	var b strings.Builder
+	runes := []rune(s)
+	for i := 0; i < len(runes); i++ {
+		n, err := expansion(runes, i, expand, &b)
+		if err != nil {
+			return "", err
+		}
+
+		if n > 0 {
+			i += n - 1
+			continue
+		}
+
+		b.WriteRune(runes[i])
+	}
+
+	return b.String(), nil
+}
+
+// dollar returns the length of the interpolation at runes[i], which is
+// ${NAME}, $(...) or $$, or zero if there is none. The parentheses of a
+// command substitution must balance outside of quotes.
+func dollar(runes []rune, i int) (int, error) {
+	if runes[i] != '$' || i+1 >= len(runes) {
+		return 0, nil
+	}
+
+	switch runes[i+1] {
+	case '$':
+		return 2, nil
+	case '{':
+		for j := i + 2; j < len(runes); j++ {
+			if runes[j] == '}' {
+				return j - i + 1, nil
+			}
+		}
+	case '(':
+		depth := 0
+		var quote rune
+		for j := i + 1; j < len(runes); j++ {
+			switch r := runes[j]; {
+			case quote != 0:
+				if r == quote {
+					quote = 0
+				}
+			case r == '\'' || r == '"':
+				quote = r
+			case r == '(':
+				depth++
+			case r == ')':
+				depth--
+				if depth == 0 {
+					return j - i + 1, nil
+				}
+			}
+		}
+	default:
+		return 0, nil
+	}
+
+	return 0, errInterpolate
+}
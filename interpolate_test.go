@@ -0,0 +1,60 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestInterpolate(t *testing.T) {
+	t.Setenv("GREETEE", "alice")
+	app := New("myapp", "0.0.1")
+	app.RuleFunc("greet", "Greet someone.", "<name>", func(ctx context.Context, name string) {
+		fmt.Fprintf(Stdout(ctx), "hello %s\n", name)
+	})
+	app.RuleFunc("whoami", "Print the user.", "", func(ctx context.Context) {
+		fmt.Fprintf(Stdout(ctx), "bob\n\n")
+	})
+	app.Shell("shell")
+
+	tests := []struct {
+		args   []string
+		chain  string
+		stdout string
+		stderr string
+	}{
+		{[]string{"greet", "${GREETEE}"}, "", "hello alice\n", ""},
+		{[]string{"greet", "$${GREETEE} $5"}, "", "hello ${GREETEE} $5\n", ""},
+		{[]string{"greet", "$(whoami)"}, "", "hello $(whoami)\n", ""},
+		{[]string{"greet", "$(whoami)", ",", "greet", "${GREETEE}"}, ",", "hello bob\nhello alice\n", ""},
+		{[]string{"greet", "${GREETEE"}, "", "", "Error: interpolate: unterminated ${ or $(\n"},
+	}
+
+	app.Interpolate(true)
+	for _, tt := range tests {
+		app.Chain(tt.chain)
+		var stdout, stderr bytes.Buffer
+		app.RunWithArgs(tt.args, &stdout, &stderr)
+		if stdout.String() != tt.stdout || stderr.String() != tt.stderr {
+			t.Errorf("%q\nhave %q %q\nwant %q %q", tt.args, stdout.String(), stderr.String(), tt.stdout, tt.stderr)
+		}
+	}
+
+	app.Chain("")
+	app.stdin = strings.NewReader("greet ${GREETEE}\ngreet '${GREETEE}'\ngreet \"$(whoami) and \\$(whoami)\"\ngreet $(greet $(whoami))\n")
+	var stdout, stderr bytes.Buffer
+	app.RunWithArgs([]string{"shell"}, &stdout, &stderr)
+	want := "hello alice\nhello ${GREETEE}\nhello bob and $(whoami)\nhello hello bob\n"
+	if stdout.String() != want || stderr.String() != "" {
+		t.Errorf("shell\nhave %q %q\nwant %q", stdout.String(), stderr.String(), want)
+	}
+
+	app.Interpolate(false)
+	stdout.Reset()
+	app.RunWithArgs([]string{"greet", "${GREETEE}"}, &stdout, &stderr)
+	if have, want := stdout.String(), "hello ${GREETEE}\n"; have != want {
+		t.Errorf("disabled\nhave %q\nwant %q", have, want)
+	}
+}
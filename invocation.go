@@ -0,0 +1,97 @@
+package cli
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"flag"
+	"log/slog"
+	"sync"
+)
+
+// An invocation describes a single dispatch to a command.
+type invocation struct {
+	app       *Application
+	rule      *rule
+	args      []string
+	noCache   bool
+	dryRun    bool
+	yes       bool
+	noInput   bool
+	output    output
+	locale    string
+	noBrowser bool
+	noPager   bool
+	config    Config
+	global    *flag.FlagSet
+	sources   flagSources
+
+	mu      sync.Mutex
+	reload  []*reloadHook
+	stopHUP chan struct{}
+	cleanup []func()
+	tempDir string
+	logger  *slog.Logger
+	err     error
+	last    error
+}
+
+type invocationContextKey struct{}
+
+// withInvocation returns a copy of ctx carrying inv.
+func withInvocation(ctx context.Context, inv *invocation) context.Context {
+	return context.WithValue(ctx, invocationContextKey{}, inv)
+}
+
+// ctxInvocation returns the invocation of ctx or panics if there is none.
+func ctxInvocation(ctx context.Context) *invocation {
+	inv, ok := ctx.Value(invocationContextKey{}).(*invocation)
+	if !ok {
+		panic("cli: context was not passed to Run")
+	}
+
+	return inv
+}
+
+// key returns a file name safe digest of the command name, the arguments
+// args and the values of the named flags of the command. The values of
+// secrets are included unredacted.
+func (inv *invocation) key(args []string, flags ...string) string {
+	h := sha256.New()
+	h.Write([]byte(inv.rule.name))
+	for _, arg := range args {
+		h.Write([]byte{0})
+		h.Write([]byte(arg))
+	}
+
+	for _, name := range flags {
+		h.Write([]byte{1})
+		h.Write([]byte(name))
+		if f := inv.rule.options.Lookup(name); f != nil {
+			h.Write([]byte{0})
+			h.Write([]byte(rawValue(f)))
+		}
+	}
+
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// onCleanup registers fn to be called once the invocation completes, even if
+// it was interrupted.
+func (inv *invocation) onCleanup(fn func()) {
+	inv.mu.Lock()
+	defer inv.mu.Unlock()
+	inv.cleanup = append(inv.cleanup, fn)
+}
+
+// close calls the cleanup functions in the reverse order of registration.
+func (inv *invocation) close() {
+	inv.mu.Lock()
+	cleanup := inv.cleanup
+	inv.cleanup = nil
+	inv.mu.Unlock()
+
+	for i := len(cleanup) - 1; i >= 0; i-- {
+		cleanup[i]()
+	}
+}
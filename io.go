@@ -0,0 +1,59 @@
+package cli
+
+import (
+	"context"
+	"io"
+	"os"
+)
+
+// streams are the standard streams of an invocation.
+type streams struct {
+	stdin  io.Reader
+	stdout io.Writer
+	stderr io.Writer
+}
+
+type streamsContextKey struct{}
+
+// A streamer is implemented by commands receiving the standard streams of
+// their invocation before they are run, see Rule.
+type streamer interface {
+	SetIO(stdin io.Reader, stdout, stderr io.Writer)
+}
+
+// withStreams returns a copy of ctx carrying the standard streams s.
+func withStreams(ctx context.Context, s streams) context.Context {
+	return context.WithValue(ctx, streamsContextKey{}, s)
+}
+
+// ctxStreams returns the standard streams of ctx and whether it had any.
+func ctxStreams(ctx context.Context) (streams, bool) {
+	s, ok := ctx.Value(streamsContextKey{}).(streams)
+	if !ok {
+		return streams{os.Stdin, os.Stdout, os.Stderr}, false
+	}
+
+	return s, true
+}
+
+// Stdin returns the standard input of the invocation that received ctx.
+// Commands should prefer it to os.Stdin so that their input can be provided
+// by the framework, such as when running in batch mode.
+func Stdin(ctx context.Context) io.Reader {
+	s, _ := ctxStreams(ctx)
+	return s.stdin
+}
+
+// Stdout returns the standard output of the invocation that received ctx.
+// Commands should prefer it to os.Stdout so that their output can be
+// captured by the framework, such as when running invocations in parallel.
+func Stdout(ctx context.Context) io.Writer {
+	s, _ := ctxStreams(ctx)
+	return s.stdout
+}
+
+// Stderr returns the standard error of the invocation that received ctx.
+func Stderr(ctx context.Context) io.Writer {
+	s, _ := ctxStreams(ctx)
+	return s.stderr
+}
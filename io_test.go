@@ -0,0 +1,42 @@
+package cli
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+	"testing"
+)
+
+type runStreams struct {
+	*NullFlags
+	stdin          io.Reader
+	stdout, stderr io.Writer
+}
+
+func (r *runStreams) String() string {
+	return "Echo the input."
+}
+
+func (r *runStreams) SetIO(stdin io.Reader, stdout, stderr io.Writer) {
+	r.stdin, r.stdout, r.stderr = stdin, stdout, stderr
+}
+
+func (r *runStreams) Run() {
+	data, _ := ioutil.ReadAll(r.stdin)
+	fmt.Fprintf(r.stdout, "%s", data)
+	fmt.Fprintf(r.stderr, "read %d bytes\n", len(data))
+}
+
+func TestSetIO(t *testing.T) {
+	app := New("myapp", "0.0.1")
+	app.Rule(&runStreams{}, "echo", "")
+	app.stdin = strings.NewReader("hello\n")
+
+	var stdout, stderr bytes.Buffer
+	code := app.RunWithArgs([]string{"echo"}, &stdout, &stderr)
+	if code != 0 || stdout.String() != "hello\n" || stderr.String() != "read 6 bytes\n" {
+		t.Errorf("streams\nhave %d %q %q\nwant %d %q %q", code, stdout.String(), stderr.String(), 0, "hello\n", "read 6 bytes\n")
+	}
+}
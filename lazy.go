@@ -0,0 +1,80 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+)
+
+// RuleLazy registers a command created by factory when it is first needed,
+// otherwise as Rule, for commands whose construction is costly, such as
+// those opening configuration files or building API clients. The command is
+// created once, when it is first dispatched or its help, options or
+// arguments are completed or documented. Given Summary, the command is listed
+// by the usage and completed by name without being created, which keeps the
+// help and completion of large applications fast.
+func (a *Application) RuleLazy(name, arguments string, factory func() fmt.Stringer, options ...RuleOption) error {
+	err := a.checkName(name)
+	if err != nil {
+		return err
+	}
+
+	r := &rule{
+		factory:   func() command { return factory() },
+		name:      name,
+		arguments: arguments,
+	}
+	for _, option := range options {
+		option(r)
+	}
+
+	return a.add(r, false)
+}
+
+// RuleLazy registers a command in the group created by factory when it is
+// first needed, see Application.RuleLazy.
+func (g *CommandGroup) RuleLazy(name, arguments string, factory func() fmt.Stringer, options ...RuleOption) error {
+	return g.app.RuleLazy(g.name+" "+name, arguments, factory, options...)
+}
+
+// Summary is a RuleOption setting the description of the command listed by
+// the usage and completion in place of that returned by its String method,
+// such that commands registered with RuleLazy are listed without being
+// created.
+func Summary(description string) RuleOption {
+	return func(r *rule) {
+		r.summary = description
+	}
+}
+
+// listed reports whether the rule may be listed without being loaded.
+func (r *rule) listed() bool {
+	r.loading.Lock()
+	defer r.loading.Unlock()
+
+	return r.command == nil && r.summary != ""
+}
+
+// description returns the description of the loaded or listed rule.
+func (r *rule) description() string {
+	if r.summary != "" {
+		return r.summary
+	}
+
+	return r.command.String()
+}
+
+// summaryUsage describes the listed rule without loading it.
+func (a *Application) summaryUsage(r *rule) *CommandUsage {
+	synopsis := r.name
+	if r.arguments != "" {
+		synopsis += " " + r.arguments
+	}
+
+	return &CommandUsage{
+		Name:        r.name,
+		Synopsis:    synopsis,
+		Description: r.usage(&a.messages),
+		Category:    a.category(r.name),
+		Depth:       strings.Count(r.name, " "),
+	}
+}
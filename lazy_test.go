@@ -0,0 +1,107 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+type runLazy struct {
+	*NullFlags
+}
+
+func (r *runLazy) Run(ctx context.Context, name string) {
+	fmt.Fprintf(Stdout(ctx), "deployed %s\n", name)
+}
+
+func (r *runLazy) String() string {
+	return "Deploy a service."
+}
+
+func TestRuleLazy(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	created := 0
+	app := New("myapp", "0.0.1")
+	app.stdout = &stdout
+	app.stderr = &stderr
+	app.Completion("completion")
+	err := app.RuleLazy("deploy", "<name>", func() fmt.Stringer {
+		created++
+		return &runLazy{}
+	}, Summary("Deploy a service to production."))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	app.Dispatch([]string{"help"})
+	if want := "Deploy a service to production."; created != 0 || !strings.Contains(stderr.String()+stdout.String(), want) {
+		t.Errorf("help\nhave %d %q\nwant %d %q", created, stderr.String()+stdout.String(), 0, want)
+	}
+
+	stdout.Reset()
+	app.Dispatch([]string{completeCommand, "dep"})
+	if want := "deploy\tDeploy a service to production.\n"; created != 0 || stdout.String() != want {
+		t.Errorf("complete\nhave %d %q\nwant %d %q", created, stdout.String(), 0, want)
+	}
+
+	for i := 0; i < 2; i++ {
+		stdout.Reset()
+		code := app.Dispatch([]string{"deploy", "api"})
+		if want := "deployed api\n"; code != 0 || created != 1 || stdout.String() != want {
+			t.Errorf("deploy\nhave %d %d %q\nwant %d %d %q", code, created, stdout.String(), 0, 1, want)
+		}
+	}
+}
+
+func TestRuleLazyConcurrent(t *testing.T) {
+	app := New("myapp", "0.0.1")
+	app.stdout = ioutil.Discard
+	app.stderr = ioutil.Discard
+	var created int32
+	app.RuleLazy("deploy", "<name>", func() fmt.Stringer {
+		atomic.AddInt32(&created, 1)
+		return &runLazy{}
+	})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			app.PrintUsage(ioutil.Discard)
+		}()
+	}
+	wg.Wait()
+
+	if created != 1 {
+		t.Errorf("created\nhave %d\nwant %d", created, 1)
+	}
+}
+
+func TestRuleLazyNil(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	app := New("myapp", "0.0.1")
+	app.stdout = &stdout
+	app.stderr = &stderr
+	app.RuleLazy("deploy", "", func() fmt.Stringer { return nil })
+
+	code := app.Dispatch([]string{"deploy"})
+	if want := "deploy: rule: factory returned no command"; code != 1 || !strings.Contains(stderr.String(), want) {
+		t.Errorf("deploy\nhave %d %q\nwant %d %q", code, stderr.String(), 1, want)
+	}
+}
+
+func TestRuleLazyDuplicate(t *testing.T) {
+	app := New("myapp", "0.0.1")
+	app.Rule(&runLazy{}, "deploy", "<name>")
+
+	err := app.RuleLazy("deploy", "<name>", func() fmt.Stringer { return &runLazy{} })
+	if err == nil {
+		t.Errorf("RuleLazy duplicate\nhave %v\nwant error", err)
+	}
+}
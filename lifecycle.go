@@ -0,0 +1,31 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+)
+
+// An initializer is implemented by commands preparing to run, such as by
+// opening clients, see Rule.
+type initializer interface {
+	Init(ctx context.Context) error
+}
+
+// A cleaner is implemented by commands releasing resources once they have
+// run, see Rule.
+type cleaner interface {
+	Cleanup() error
+}
+
+// cleanup calls the Cleanup method of the command of the rule, reporting any
+// error and setting code to 1 if it would otherwise be 0.
+func (r *rule) cleanup(ctx context.Context, c cleaner, code *int) {
+	err := c.Cleanup()
+	if err != nil {
+		ctxInvocation(ctx).app.printError(Stderr(ctx), r, fmt.Errorf("cleanup: %w", err))
+		ctxInvocation(ctx).fail(err)
+		if *code == 0 {
+			*code = 1
+		}
+	}
+}
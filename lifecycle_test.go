@@ -0,0 +1,69 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+type runLifecycle struct {
+	*NullFlags
+	calls   []string
+	init    error
+	cleanup error
+}
+
+func (r *runLifecycle) String() string {
+	return "Record the lifecycle."
+}
+
+func (r *runLifecycle) Init(ctx context.Context) error {
+	r.calls = append(r.calls, "init")
+	return r.init
+}
+
+func (r *runLifecycle) Run(mode string) {
+	r.calls = append(r.calls, "run")
+	if mode == "panic" {
+		panic("boom")
+	}
+}
+
+func (r *runLifecycle) Cleanup() error {
+	r.calls = append(r.calls, "cleanup")
+	return r.cleanup
+}
+
+func TestLifecycle(t *testing.T) {
+	tests := []struct {
+		args    []string
+		init    error
+		cleanup error
+		code    int
+		calls   []string
+		stderr  string
+	}{
+		{[]string{"life"}, nil, nil, 0, []string{"init", "run", "cleanup"}, ""},
+		{[]string{"life"}, errors.New("no client"), nil, 1, []string{"init", "cleanup"}, "myapp: life: no client\n"},
+		{[]string{"life"}, nil, errors.New("busy"), 1, []string{"init", "run", "cleanup"}, "myapp: life: cleanup: busy\n"},
+		{[]string{"life", "panic"}, nil, nil, ExitPanic, []string{"init", "run", "cleanup"}, ""},
+	}
+
+	for _, tt := range tests {
+		app := New("myapp", "0.0.1")
+		cmd := &runLifecycle{init: tt.init, cleanup: tt.cleanup}
+		app.Rule(cmd, "life", "[<mode>]")
+
+		var stdout, stderr bytes.Buffer
+		code := app.RunWithArgs(tt.args, &stdout, &stderr)
+		if code != tt.code || !reflect.DeepEqual(cmd.calls, tt.calls) {
+			t.Errorf("%v %v\nhave %d %q\nwant %d %q", tt.init, tt.cleanup, code, cmd.calls, tt.code, tt.calls)
+		}
+
+		if tt.stderr != "" && stderr.String() != tt.stderr {
+			t.Errorf("%v %v stderr\nhave %q\nwant %q", tt.init, tt.cleanup, stderr.String(), tt.stderr)
+		}
+	}
+}
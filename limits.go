@@ -0,0 +1,184 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"runtime"
+	"sync"
+	"time"
+)
+
+// limitInterval is how often the memory and temporary files of a command
+// with limits are checked.
+var limitInterval = 50 * time.Millisecond
+
+// limits are the soft limits on the resources used by a command, see
+// MaxMemory, MaxOutput and MaxTempFiles.
+type limits struct {
+	memory    int64
+	output    int64
+	tempFiles int
+}
+
+// A LimitError is the error of a command cancelled for exceeding one of its
+// limits.
+type LimitError struct {
+	Resource string
+	Limit    string
+}
+
+// A limitWriter writes to w until the bytes written through it exceed the
+// limit.
+type limitWriter struct {
+	mu     sync.Mutex
+	n      int64
+	limit  int64
+	w      io.Writer
+	cancel context.CancelCauseFunc
+}
+
+// MaxMemory is a RuleOption cancelling the command once the heap memory
+// allocated by the process exceeds n bytes, checked periodically while the
+// command runs.
+func MaxMemory(n int64) RuleOption {
+	return func(r *rule) {
+		r.limits().memory = n
+	}
+}
+
+// MaxOutput is a RuleOption cancelling the command once it writes more than
+// n bytes to Stdout. The write exceeding the limit fails, and is not written.
+func MaxOutput(n int64) RuleOption {
+	return func(r *rule) {
+		r.limits().output = n
+	}
+}
+
+// MaxTempFiles is a RuleOption cancelling the command once its TempDir holds
+// more than n files, checked periodically while the command runs.
+func MaxTempFiles(n int) RuleOption {
+	return func(r *rule) {
+		r.limits().tempFiles = n
+	}
+}
+
+// Error implements the error interface.
+func (e *LimitError) Error() string {
+	return fmt.Sprintf("%s limit of %s exceeded", e.Resource, e.Limit)
+}
+
+// limits returns the limits of the rule, adding their middleware on first use.
+func (r *rule) limits() *limits {
+	if r.resources == nil {
+		r.resources = &limits{}
+		r.middleware = append(r.middleware, r.resources.middleware)
+	}
+
+	return r.resources
+}
+
+// middleware runs next with its limits enforced. The limits are soft: the
+// context of the command is cancelled with a LimitError, and the command is
+// expected to return once it is done. The LimitError is reported in place of
+// the cancellation error returned by the command, or returned if the command
+// succeeds regardless.
+func (l *limits) middleware(next RunFunc) RunFunc {
+	return func(ctx context.Context, args []string) (int, error) {
+		ctx, cancel := context.WithCancelCause(ctx)
+		defer cancel(nil)
+
+		if l.output > 0 {
+			s, _ := ctxStreams(ctx)
+			s.stdout = &limitWriter{limit: l.output, w: s.stdout, cancel: cancel}
+			if cmd, ok := ctxInvocation(ctx).rule.command.(streamer); ok {
+				cmd.SetIO(s.stdin, s.stdout, s.stderr)
+			}
+
+			ctx = withStreams(ctx, s)
+		}
+
+		if l.memory > 0 || l.tempFiles > 0 {
+			done := make(chan struct{})
+			defer close(done)
+			go l.monitor(ctx, cancel, done)
+		}
+
+		code, err := next(ctx, args)
+		if e, ok := context.Cause(ctx).(*LimitError); ok && code == 0 && err == nil {
+			return 1, e
+		}
+
+		return code, err
+	}
+}
+
+// monitor cancels the command once its memory or temporary files exceed
+// their limits, until done is closed.
+func (l *limits) monitor(ctx context.Context, cancel context.CancelCauseFunc, done chan struct{}) {
+	inv := ctxInvocation(ctx)
+	t := time.NewTicker(limitInterval)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-done:
+			return
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		}
+
+		if l.memory > 0 {
+			var mem runtime.MemStats
+			runtime.ReadMemStats(&mem)
+			if int64(mem.HeapAlloc) > l.memory {
+				cancel(&LimitError{"memory", formatBytes(l.memory)})
+				return
+			}
+		}
+
+		if l.tempFiles > 0 {
+			inv.mu.Lock()
+			dir := inv.tempDir
+			inv.mu.Unlock()
+			if dir == "" {
+				continue
+			}
+
+			files, err := ioutil.ReadDir(dir)
+			if err == nil && len(files) > l.tempFiles {
+				cancel(&LimitError{"temporary file", plural(l.tempFiles, "file")})
+				return
+			}
+		}
+	}
+}
+
+// limitError returns the LimitError cancelling ctx in place of err if err is
+// the cancellation of ctx.
+func limitError(ctx context.Context, err error) error {
+	e, ok := context.Cause(ctx).(*LimitError)
+	if ok && errors.Is(err, context.Canceled) {
+		return e
+	}
+
+	return err
+}
+
+// Write implements the io.Writer interface.
+func (w *limitWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.n+int64(len(p)) > w.limit {
+		err := &LimitError{"output", formatBytes(w.limit)}
+		w.cancel(err)
+		return 0, err
+	}
+
+	w.n += int64(len(p))
+	return w.w.Write(p)
+}
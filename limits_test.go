@@ -0,0 +1,84 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+type runLimits struct{}
+
+type runTempLimits struct{}
+
+func (cmd *runLimits) Run(ctx context.Context, mode string) error {
+	switch mode {
+	case "print":
+		for i := 0; i < 10; i++ {
+			_, err := fmt.Fprintln(Stdout(ctx), "0123456789")
+			if err != nil {
+				return err
+			}
+		}
+	case "wait":
+		<-ctx.Done()
+		return ctx.Err()
+	}
+
+	return nil
+}
+
+func (cmd *runLimits) String() string {
+	return "Print within limits."
+}
+
+func (cmd *runTempLimits) Run(ctx context.Context) error {
+	dir, err := TempDir(ctx)
+	if err != nil {
+		return err
+	}
+
+	for i := 0; i < 3; i++ {
+		err = ioutil.WriteFile(filepath.Join(dir, fmt.Sprint(i)), nil, 0600)
+		if err != nil {
+			return err
+		}
+	}
+
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func (cmd *runTempLimits) String() string {
+	return "Create temporary files."
+}
+
+func TestLimits(t *testing.T) {
+	app := New("myapp", "0.0.1")
+	app.Rule(&runLimits{}, "output", "<mode>", MaxOutput(25))
+	app.Rule(&runLimits{}, "memory", "<mode>", MaxMemory(1))
+	app.Rule(&runTempLimits{}, "temp", "", MaxTempFiles(2))
+
+	tests := []struct {
+		args   string
+		code   int
+		stdout string
+		stderr string
+	}{
+		{"output none", 0, "", ""},
+		{"output print", 1, "0123456789\n0123456789\n", "myapp: output: output limit of 25 B exceeded\n"},
+		{"memory wait", 1, "", "myapp: memory: memory limit of 1 B exceeded\n"},
+		{"temp", 1, "", "myapp: temp: temporary file limit of 2 files exceeded\n"},
+	}
+
+	for _, tt := range tests {
+		var stdout, stderr bytes.Buffer
+		code := app.RunWithArgs(strings.Fields(tt.args), &stdout, &stderr)
+		if code != tt.code || stdout.String() != tt.stdout || stderr.String() != tt.stderr {
+			t.Errorf("%s\nhave %d %q %q\nwant %d %q %q", tt.args, code, stdout.String(), stderr.String(), tt.code, tt.stdout, tt.stderr)
+		}
+	}
+}
@@ -0,0 +1,159 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// A locale describes the conventions for formatting numbers and dates.
+type locale struct {
+	group   string
+	decimal string
+	date    string
+}
+
+// defaultLocale applies to the C and POSIX locales and any locale that is not
+// known, formatting dates as ISO 8601.
+var defaultLocale = locale{",", ".", "2006-01-02"}
+
+// locales are the known locales, by language and optionally territory.
+var locales = map[string]locale{
+	"de":    {".", ",", "02.01.2006"},
+	"de_CH": {"’", ".", "02.01.2006"},
+	"en":    {",", ".", "Jan 2, 2006"},
+	"en_AU": {",", ".", "2 Jan 2006"},
+	"en_GB": {",", ".", "2 Jan 2006"},
+	"en_IE": {",", ".", "2 Jan 2006"},
+	"en_NZ": {",", ".", "2 Jan 2006"},
+	"es":    {".", ",", "02/01/2006"},
+	"fr":    {" ", ",", "02/01/2006"},
+	"it":    {".", ",", "02/01/2006"},
+	"ja":    {",", ".", "2006/01/02"},
+	"ko":    {",", ".", "2006. 01. 02."},
+	"nl":    {".", ",", "02-01-2006"},
+	"pl":    {" ", ",", "02.01.2006"},
+	"pt":    {".", ",", "02/01/2006"},
+	"ru":    {" ", ",", "02.01.2006"},
+	"sv":    {" ", ",", "2006-01-02"},
+	"zh":    {",", ".", "2006/01/02"},
+}
+
+// Locale returns the name of the locale of the invocation of the command that
+// received ctx, such as en_US. It is given by the -locale flag before the
+// command name, see WithLocaleFlag, or otherwise the LC_ALL, LC_NUMERIC or
+// LANG environment variables, in that order.
+func Locale(ctx context.Context) string {
+	if inv, ok := ctx.Value(invocationContextKey{}).(*invocation); ok && inv.locale != "" {
+		return inv.locale
+	}
+
+	for _, key := range []string{"LC_ALL", "LC_NUMERIC", "LANG"} {
+		if v := os.Getenv(key); v != "" {
+			return v
+		}
+	}
+
+	return "C"
+}
+
+// ctxLocale returns the conventions of the locale of ctx.
+func ctxLocale(ctx context.Context) locale {
+	name := Locale(ctx)
+	if i := strings.IndexAny(name, ".@"); i >= 0 {
+		name = name[:i]
+	}
+
+	name = strings.Replace(name, "-", "_", 1)
+	if l, ok := locales[name]; ok {
+		return l
+	}
+
+	if i := strings.Index(name, "_"); i >= 0 {
+		name = name[:i]
+	}
+
+	if l, ok := locales[strings.ToLower(name)]; ok {
+		return l
+	}
+
+	return defaultLocale
+}
+
+// FormatNumber formats n with the thousands separator of the locale, see
+// Locale.
+func FormatNumber(ctx context.Context, n int64) string {
+	return ctxLocale(ctx).number(strconv.FormatInt(n, 10))
+}
+
+// FormatFloat formats f with prec digits after the decimal separator and the
+// thousands separator of the locale, see Locale.
+func FormatFloat(ctx context.Context, f float64, prec int) string {
+	return ctxLocale(ctx).number(strconv.FormatFloat(f, 'f', prec, 64))
+}
+
+// FormatBytes formats a size in bytes in binary units, such as 1.5 MiB, with
+// the decimal separator of the locale, see Locale.
+func FormatBytes(ctx context.Context, n int64) string {
+	return ctxLocale(ctx).bytes(n)
+}
+
+// FormatDate formats the date of t in the conventional form of the locale,
+// see Locale.
+func FormatDate(ctx context.Context, t time.Time) string {
+	return t.Format(ctxLocale(ctx).date)
+}
+
+// FormatDuration formats d in its two most significant units, such as 3d 4h,
+// 5m 30s or 250ms.
+func FormatDuration(d time.Duration) string {
+	return formatDuration(d, 2)
+}
+
+// number inserts separators into the decimal representation s.
+func (l locale) number(s string) string {
+	sign := ""
+	if strings.HasPrefix(s, "-") {
+		sign, s = "-", s[1:]
+	}
+
+	frac := ""
+	if i := strings.Index(s, "."); i >= 0 {
+		s, frac = s[:i], l.decimal+s[i+1:]
+	}
+
+	var b strings.Builder
+	for i, c := range s {
+		if i > 0 && (len(s)-i)%3 == 0 {
+			b.WriteString(l.group)
+		}
+
+		b.WriteRune(c)
+	}
+
+	return sign + b.String() + frac
+}
+
+// bytes formats a size in bytes.
+func (l locale) bytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+
+	if n%div == 0 {
+		return fmt.Sprintf("%s %ciB", l.number(strconv.FormatInt(n/div, 10)), "KMGTPE"[exp])
+	}
+
+	v := strconv.FormatFloat(float64(n)/float64(div), 'f', 1, 64)
+	return fmt.Sprintf("%s %ciB", l.number(v), "KMGTPE"[exp])
+}
@@ -0,0 +1,59 @@
+package cli
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestFormatLocale(t *testing.T) {
+	date := time.Date(2020, 3, 4, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		locale string
+		number string
+		float  string
+		bytes  string
+		date   string
+	}{
+		{"C", "-1,234,567", "1,234.50", "1.5 KiB", "2020-03-04"},
+		{"en_US.UTF-8", "-1,234,567", "1,234.50", "1.5 KiB", "Mar 4, 2020"},
+		{"en_GB", "-1,234,567", "1,234.50", "1.5 KiB", "4 Mar 2020"},
+		{"de_DE.UTF-8", "-1.234.567", "1.234,50", "1,5 KiB", "04.03.2020"},
+		{"de-CH", "-1’234’567", "1’234.50", "1.5 KiB", "04.03.2020"},
+	}
+
+	for _, tt := range tests {
+		ctx := withInvocation(context.Background(), &invocation{locale: tt.locale})
+		have := []string{
+			FormatNumber(ctx, -1234567),
+			FormatFloat(ctx, 1234.5, 2),
+			FormatBytes(ctx, 1536),
+			FormatDate(ctx, date),
+		}
+
+		want := []string{tt.number, tt.float, tt.bytes, tt.date}
+		for i := range want {
+			if have[i] != want[i] {
+				t.Errorf("%s\nhave %q\nwant %q", tt.locale, have[i], want[i])
+			}
+		}
+	}
+}
+
+func TestFormatDuration(t *testing.T) {
+	tests := map[time.Duration]string{
+		250 * time.Millisecond:         "250ms",
+		45 * time.Second:               "45s",
+		5*time.Minute + 30*time.Second: "5m 30s",
+		3*24*time.Hour + 4*time.Hour:   "3d 4h",
+		3*24*time.Hour + 5*time.Minute: "3d",
+		-2*time.Hour - 15*time.Minute:  "-2h 15m",
+	}
+
+	for d, want := range tests {
+		have := FormatDuration(d)
+		if have != want {
+			t.Errorf("FormatDuration(%v)\nhave %q\nwant %q", d, have, want)
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package cli
+
+import (
+	"context"
+	"io"
+	"log/slog"
+)
+
+// A loggerSetter is implemented by commands receiving the Logger of their
+// invocation before they are run, see Rule.
+type loggerSetter interface {
+	SetLogger(logger *slog.Logger)
+}
+
+// Logger returns a structured logger writing to the standard error of the
+// invocation of the command that received ctx. Messages below the info level
+// are discarded, or below the debug level with -verbose, the warn level with
+// -quiet and the error level with -quiet given twice, unless the level is
+// set with -log-level. Messages are logged as text, or JSON with -log-format
+// json. See StandardFlags.
+func Logger(ctx context.Context) *slog.Logger {
+	inv, ok := ctx.Value(invocationContextKey{}).(*invocation)
+	if !ok {
+		return slog.Default()
+	}
+
+	inv.mu.Lock()
+	defer inv.mu.Unlock()
+	if inv.logger == nil {
+		inv.logger = inv.output.logger(Stderr(ctx))
+	}
+
+	return inv.logger
+}
+
+// logger returns a logger writing to w with the preferences of o.
+func (o output) logger(w io.Writer) *slog.Logger {
+	level := slog.LevelInfo
+	switch {
+	case o.logLevel != nil && o.logLevel.Value() != "":
+		level.UnmarshalText([]byte(o.logLevel.Value()))
+	case o.verbosity() > 0:
+		level = slog.LevelDebug
+	case o.verbosity() == -1:
+		level = slog.LevelWarn
+	case o.verbosity() < -1:
+		level = slog.LevelError
+	}
+
+	opts := &slog.HandlerOptions{Level: level}
+	if o.logFormat != nil && o.logFormat.Value() == "json" {
+		return slog.New(slog.NewJSONHandler(w, opts))
+	}
+
+	// Timestamps are noise in the text output of a command line tool.
+	opts.ReplaceAttr = func(groups []string, a slog.Attr) slog.Attr {
+		if len(groups) == 0 && a.Key == slog.TimeKey {
+			return slog.Attr{}
+		}
+
+		return a
+	}
+
+	return slog.New(slog.NewTextHandler(w, opts))
+}
@@ -0,0 +1,66 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"testing"
+)
+
+type runLog struct {
+	*NullFlags
+	logger *slog.Logger
+}
+
+func (r *runLog) String() string {
+	return "Log messages."
+}
+
+func (r *runLog) SetLogger(logger *slog.Logger) {
+	r.logger = logger
+}
+
+func (r *runLog) Run(ctx context.Context) {
+	if r.logger != Logger(ctx) {
+		panic("different loggers")
+	}
+
+	r.logger.Debug("details")
+	r.logger.Info("working", "n", 1)
+	r.logger.Warn("careful")
+}
+
+func TestLogger(t *testing.T) {
+	tests := []struct {
+		args   []string
+		stderr string
+	}{
+		{[]string{"log"}, "level=INFO msg=working n=1\nlevel=WARN msg=careful\n"},
+		{[]string{"-v", "log"}, "level=DEBUG msg=details\nlevel=INFO msg=working n=1\nlevel=WARN msg=careful\n"},
+		{[]string{"-q", "log"}, "level=WARN msg=careful\n"},
+		{[]string{"-q", "-log-level", "debug", "log"}, "level=DEBUG msg=details\nlevel=INFO msg=working n=1\nlevel=WARN msg=careful\n"},
+		{[]string{"-log-level", "error", "log"}, ""},
+	}
+
+	for _, tt := range tests {
+		app := New("myapp", "0.0.1")
+		app.StandardFlags()
+		app.Rule(&runLog{}, "log", "")
+
+		var stdout, stderr bytes.Buffer
+		code := app.RunWithArgs(tt.args, &stdout, &stderr)
+		if code != 0 || stderr.String() != tt.stderr {
+			t.Errorf("%q\nhave %d %q\nwant %d %q", tt.args, code, stderr.String(), 0, tt.stderr)
+		}
+	}
+
+	app := New("myapp", "0.0.1")
+	app.StandardFlags()
+	app.Rule(&runLog{}, "log", "")
+
+	var stdout, stderr bytes.Buffer
+	app.RunWithArgs([]string{"-log-format", "json", "-quiet", "log"}, &stdout, &stderr)
+	if !bytes.Contains(stderr.Bytes(), []byte(`"level":"WARN","msg":"careful"}`)) {
+		t.Errorf("json\nhave %q", stderr.String())
+	}
+}
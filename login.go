@@ -0,0 +1,210 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Credentials identify the user of the Application to a remote service.
+// Credentials with a zero Expiry do not expire.
+type Credentials struct {
+	Username string    `json:"username"`
+	Secret   string    `json:"secret"`
+	Refresh  string    `json:"refresh,omitempty"`
+	Expiry   time.Time `json:"expiry,omitempty"`
+}
+
+// A CredentialStore persists the Credentials of the user. Implementations
+// may wrap the keyring of the operating system.
+type CredentialStore interface {
+	// Load returns the stored Credentials or ErrNoCredentials.
+	Load() (*Credentials, error)
+	// Save replaces the stored Credentials.
+	Save(c *Credentials) error
+	// Delete removes the stored Credentials, if any.
+	Delete() error
+}
+
+// FileStore is a CredentialStore keeping Credentials in a JSON file readable
+// only by the current user.
+type FileStore string
+
+type commandLogin struct {
+	app      *Application
+	store    CredentialStore
+	verify   func(ctx context.Context, c *Credentials) error
+	password *Secret
+}
+
+type commandLogout struct {
+	*NullFlags
+	store CredentialStore
+}
+
+var (
+	// ErrNoCredentials is returned when the user has not logged in.
+	ErrNoCredentials = errors.New("not logged in")
+
+	// ErrCredentialsExpired is returned when the Credentials have expired and
+	// cannot be refreshed.
+	ErrCredentialsExpired = errors.New("credentials expired, log in again")
+)
+
+// ConfigDir returns the directory in which the Application may store
+// configuration for the current user. The directory is not created.
+func (a *Application) ConfigDir() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(dir, a.name), nil
+}
+
+// Login registers login and logout commands storing Credentials in store.
+// If store is nil, the Credentials are kept in credentials.json under the
+// ConfigDir. The login command accepts the username as an argument and the
+// password as a secret flag, see SecretVar, prompting for either if missing.
+// If verify is not nil, it is called to check the Credentials before they are
+// stored. Other commands obtain the Credentials with Identity.
+func (a *Application) Login(store CredentialStore, verify func(ctx context.Context, c *Credentials) error) error {
+	if store == nil {
+		dir, err := a.ConfigDir()
+		if err != nil {
+			return err
+		}
+
+		store = FileStore(filepath.Join(dir, "credentials.json"))
+	}
+
+	a.store = store
+	err := a.Rule(&commandLogin{app: a, store: store, verify: verify}, "login", "[<username>]", Local())
+	if err != nil {
+		return err
+	}
+
+	return a.Rule(&commandLogout{store: store}, "logout", "", Local())
+}
+
+// Identity returns the Credentials stored by the login command for the
+// Application of the invocation of the command that received ctx. Commands
+// that RequireAuth receive the Credentials as refreshed before they ran.
+func Identity(ctx context.Context) (*Credentials, error) {
+	if creds, ok := ctx.Value(credentialsContextKey{}).(*Credentials); ok {
+		return creds, nil
+	}
+
+	store := ctxInvocation(ctx).app.store
+	if store == nil {
+		return nil, ErrNoCredentials
+	}
+
+	return store.Load()
+}
+
+func (c *commandLogin) Flags(flags *flag.FlagSet) {
+	env := strings.ToUpper(strings.NewReplacer("-", "_", ".", "_").Replace(c.app.name)) + "_PASSWORD"
+	c.password = SecretVar(flags, "password", env, "Password or access token.")
+}
+
+func (c *commandLogin) Run(ctx context.Context, username string) int {
+	var err error
+	if username == "" {
+		username, err = Input(ctx, "username")
+	}
+
+	if err == nil && username == "" {
+		err = errors.New("missing username")
+	}
+
+	creds := &Credentials{Username: username, Secret: c.password.Value()}
+	if err == nil && creds.Secret == "" {
+		creds.Secret, err = Password(ctx, "password")
+	}
+
+	if err == nil && c.verify != nil {
+		err = c.verify(ctx, creds)
+	}
+
+	if err == nil {
+		err = c.store.Save(creds)
+	}
+
+	if err != nil {
+		c.app.errorf(Stderr(ctx), "login: %v", err)
+		return 1
+	}
+
+	fmt.Fprintf(Stderr(ctx), c.app.messages.LoggedIn+"\n", username)
+	return 0
+}
+
+func (c *commandLogin) String() string {
+	return "Log in and store your credentials."
+}
+
+func (c *commandLogout) Run(ctx context.Context) int {
+	err := c.store.Delete()
+	if err != nil {
+		ctxInvocation(ctx).app.errorf(Stderr(ctx), "logout: %v", err)
+		return 1
+	}
+
+	return 0
+}
+
+func (c *commandLogout) String() string {
+	return "Remove your stored credentials."
+}
+
+// Load implements the CredentialStore interface.
+func (f FileStore) Load() (*Credentials, error) {
+	data, err := ioutil.ReadFile(string(f))
+	if os.IsNotExist(err) {
+		return nil, ErrNoCredentials
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var c Credentials
+	err = json.Unmarshal(data, &c)
+	if err != nil {
+		return nil, err
+	}
+
+	return &c, nil
+}
+
+// Save implements the CredentialStore interface.
+func (f FileStore) Save(c *Credentials) error {
+	data, err := json.Marshal(c)
+	if err != nil {
+		return err
+	}
+
+	err = os.MkdirAll(filepath.Dir(string(f)), 0700)
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(string(f), data, 0600)
+}
+
+// Delete implements the CredentialStore interface.
+func (f FileStore) Delete() error {
+	err := os.Remove(string(f))
+	if os.IsNotExist(err) {
+		return nil
+	}
+
+	return err
+}
@@ -0,0 +1,70 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+type runWhoami struct {
+	*NullFlags
+	have *Credentials
+	err  error
+}
+
+func TestLogin(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cli-login")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	app := New("myapp", "0.0.1")
+	store := FileStore(filepath.Join(dir, "credentials.json"))
+	err = app.Login(store, func(ctx context.Context, c *Credentials) error {
+		if c.Secret != "hunter2" {
+			return errors.New("invalid password")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	whoami := &runWhoami{}
+	app.Rule(whoami, "whoami", "")
+	app.stderr = &bytes.Buffer{}
+
+	code := app.Dispatch([]string{"login", "-password", "wrong", "alice"})
+	if code != 1 {
+		t.Errorf("invalid login\nhave %d\nwant %d", code, 1)
+	}
+
+	code = app.Dispatch([]string{"login", "-password", "hunter2", "alice"})
+	if code != 0 {
+		t.Errorf("login\nhave %d\nwant %d", code, 0)
+	}
+
+	app.Dispatch([]string{"whoami"})
+	if whoami.err != nil || whoami.have.Username != "alice" {
+		t.Errorf("identity\nhave %v %v\nwant %s", whoami.have, whoami.err, "alice")
+	}
+
+	app.Dispatch([]string{"logout"})
+	app.Dispatch([]string{"whoami"})
+	if whoami.err != ErrNoCredentials {
+		t.Errorf("logout\nhave %v\nwant %v", whoami.err, ErrNoCredentials)
+	}
+}
+
+func (c *runWhoami) Run(ctx context.Context) {
+	c.have, c.err = Identity(ctx)
+}
+
+func (c *runWhoami) String() string {
+	return "print the current user"
+}
@@ -0,0 +1,62 @@
+package cli
+
+import (
+	"context"
+	"flag"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Memoize returns the result of fn for the invocation of the command that
+// received ctx. Results are cached under the CacheDir of the Application,
+// keyed by the command name, its arguments and the values of its flags,
+// including those set from the environment or configuration. A cached
+// result is returned without calling fn if it is younger than ttl, unless the
+// -no-cache flag was given before the command name, see WithNoCacheFlag.
+// Errors from fn are not cached. Failures to read or write the cache are
+// ignored.
+//
+// Memoize is intended for read-only commands that query slow backends.
+func Memoize(ctx context.Context, ttl time.Duration, fn func() ([]byte, error)) ([]byte, error) {
+	inv := ctxInvocation(ctx)
+	path, err := inv.memoPath()
+	if err != nil {
+		return fn()
+	}
+
+	if !inv.noCache {
+		fi, err := os.Stat(path)
+		if err == nil && time.Since(fi.ModTime()) < ttl {
+			data, err := ioutil.ReadFile(path)
+			if err == nil {
+				return data, nil
+			}
+		}
+	}
+
+	data, err := fn()
+	if err != nil {
+		return nil, err
+	}
+
+	writeFile(path, data)
+
+	return data, nil
+}
+
+// memoPath returns the path of the cached result of the invocation.
+func (inv *invocation) memoPath() (string, error) {
+	dir, err := inv.app.CacheDir()
+	if err != nil {
+		return "", err
+	}
+
+	var flags []string
+	inv.rule.options.VisitAll(func(f *flag.Flag) {
+		flags = append(flags, f.Name)
+	})
+
+	return filepath.Join(dir, "memo", inv.key(inv.args, flags...)), nil
+}
@@ -0,0 +1,117 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+)
+
+type runMemo struct {
+	*NullFlags
+	calls int
+}
+
+func TestMemoize(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cli-cache")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	t.Setenv("XDG_CACHE_HOME", dir)
+
+	app := New("myapp", "0.0.1", WithNoCacheFlag())
+	cmd := &runMemo{}
+	app.Rule(cmd, "query", "<q>")
+
+	var stdout bytes.Buffer
+	app.stdout = &stdout
+	runs := [][]string{
+		{"query", "a"},
+		{"query", "a"},
+		{"query", "b"},
+		{"-no-cache", "query", "a"},
+	}
+
+	for _, args := range runs {
+		app.Dispatch(args)
+	}
+
+	if cmd.calls != 3 {
+		t.Errorf("calls\nhave %d\nwant %d", cmd.calls, 3)
+	}
+
+	if have, want := stdout.String(), "a1a1b2a3"; have != want {
+		t.Errorf("output\nhave %q\nwant %q", have, want)
+	}
+}
+
+type runMemoRegion struct {
+	region string
+	calls  int
+}
+
+func (c *runMemoRegion) Flags(flags *flag.FlagSet) {
+	flags.StringVar(&c.region, "region", "eu", "Region to query.")
+}
+
+func (c *runMemoRegion) Run(ctx context.Context) error {
+	data, err := Memoize(ctx, time.Hour, func() ([]byte, error) {
+		c.calls++
+		return []byte(c.region), nil
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = Stdout(ctx).Write(data)
+	return err
+}
+
+func (c *runMemoRegion) String() string {
+	return "query a region"
+}
+
+func TestMemoizeFlags(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cli-cache")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	t.Setenv("XDG_CACHE_HOME", dir)
+
+	app := New("myapp", "0.0.1")
+	cmd := &runMemoRegion{}
+	app.Rule(cmd, "query", "", EnvVar("region", "MYAPP_REGION"))
+
+	var stdout bytes.Buffer
+	app.stdout = &stdout
+	for _, region := range []string{"us", "ap", "us"} {
+		t.Setenv("MYAPP_REGION", region)
+		app.Dispatch([]string{"query"})
+	}
+
+	if have, want := stdout.String(), "usapus"; have != want || cmd.calls != 2 {
+		t.Errorf("output\nhave %q %d\nwant %q %d", have, cmd.calls, want, 2)
+	}
+}
+
+func (c *runMemo) Run(ctx context.Context, q string) int {
+	data, err := Memoize(ctx, time.Hour, func() ([]byte, error) {
+		c.calls++
+		return []byte(q + string(rune('0'+c.calls))), nil
+	})
+	if err != nil {
+		return 1
+	}
+
+	Stdout(ctx).Write(data)
+	return 0
+}
+
+func (c *runMemo) String() string {
+	return "query a slow backend"
+}
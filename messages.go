@@ -0,0 +1,281 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"reflect"
+)
+
+// Messages are the strings the framework prints, for translation or
+// branding, see WithMessages. Strings containing verbs are formatted with
+// the arguments described beside them.
+type Messages struct {
+	// Error and Warning label messages printed to stderr.
+	Error   string
+	Warning string
+
+	// Usage labels the usage line of the Application and its commands.
+	Usage string
+	// GlobalOptions, Commands, Arguments, Options, Examples and ExitCodes
+	// head the sections of the usage and help.
+	GlobalOptions string
+	Commands      string
+	Arguments     string
+	Options       string
+	Examples      string
+	ExitCodes     string
+	// BuiltinCommands is the category of the built-in commands.
+	BuiltinCommands string
+	// MatchingCommands follows a compact usage, with the Application name.
+	MatchingCommands string
+
+	// InvalidCommand is printed with the name of an unknown command.
+	InvalidCommand string
+	// UnknownCommand is printed with the name of an unknown command and the
+	// most similar command.
+	UnknownCommand string
+	// RunningSuggestion is printed with the name of an unknown command and
+	// the similar command run instead, see SuggestAndRun.
+	RunningSuggestion string
+
+	// Deprecated notes a deprecated command in the usage, and DeprecatedUse
+	// its replacement.
+	Deprecated    string
+	DeprecatedUse string
+	// DeprecatedWarning is printed with the name of a deprecated command,
+	// followed by DeprecatedSince and DeprecatedRemoval with the versions,
+	// and DeprecatedInstead with the replacement, if any.
+	DeprecatedWarning string
+	DeprecatedSince   string
+	DeprecatedRemoval string
+	DeprecatedInstead string
+	// Removed is printed with the name of a removed command and the version
+	// it was removed in, followed by DeprecatedInstead.
+	Removed string
+	// Gated and Experimental are printed with the name of a command that is
+	// not enabled and the environment variable enabling it, see RequireEnv
+	// and Experimental.
+	Gated        string
+	Experimental string
+	// SetupRequired is printed with the name of the Application, twice, and
+	// the setup command when it has not run, and SetupRunning with the name
+	// of the Application and the setup command when it runs first, see
+	// Setup.
+	SetupRequired string
+	SetupRunning  string
+	// PermissionDenied is printed with the error of a command the invocation
+	// may not run, see Guard.
+	PermissionDenied string
+	// RunsItself is printed for a command dispatching to itself.
+	RunsItself string
+	// TimedOut is printed with the duration after which a command was
+	// stopped, see Timeout.
+	TimedOut string
+	// RequiresAdministrator is printed for a command requiring privileges on
+	// Windows, and RequiresRoot with the name of the Application and its
+	// arguments elsewhere, see RequirePrivilege.
+	RequiresAdministrator string
+	RequiresRoot          string
+	// UnsupportedShell is printed with the name of an unknown shell and the
+	// supported shells, see Completion.
+	UnsupportedShell string
+	// KilledChild is printed with the name and process ID of a child process
+	// killed after the grace period, and the grace period, see Command.
+	KilledChild string
+	// MissingCommand is printed when the command to run is not given, see
+	// Watch.
+	MissingCommand string
+	// ShuttingDown is printed when the process receives a signal to shut
+	// down, and ShutdownTimeout with the grace period when it has not shut
+	// down within it, see ShutdownGrace.
+	ShuttingDown    string
+	ShutdownTimeout string
+
+	// AreYouSure asks to confirm an action, followed by YesNo, see
+	// Confirmer, and WouldDo describes the action during a dry run.
+	AreYouSure string
+	WouldDo    string
+	// YesNo follows a question answered with yes or no, and Choose asks for
+	// the number of a choice, with the number of choices, see Confirm and
+	// Select.
+	YesNo  string
+	Choose string
+	// Retrying is printed with the name of a command, the delay, the attempt
+	// and the number of retries before it is run again, see Retry.
+	Retrying string
+	// CopiedToClipboard is printed once the output of a command is copied,
+	// see CopyVar.
+	CopiedToClipboard string
+	// OpenInBrowser precedes a URL the browser could not be started for, see
+	// OpenURL.
+	OpenInBrowser string
+	// LoggedIn is printed with the name of the user after logging in, see
+	// Login.
+	LoggedIn string
+	// UpToDate is printed with the name and version of the Application,
+	// UpdateAvailable with the name, the new version and the name again,
+	// and WouldUpdate and Updated with the name and both versions, see
+	// WithSelfUpdate. InvalidSignature follows the name and new version
+	// when the release cannot be verified.
+	UpToDate         string
+	UpdateAvailable  string
+	WouldUpdate      string
+	Updated          string
+	InvalidSignature string
+	// FlagsExclusive and FlagsTogether note flag groups in the usage, with
+	// the flags, and FlagRequiredIf with the flag and the flag requiring it,
+	// see Exclusive, Together and RequiredIf.
+	FlagsExclusive string
+	FlagsTogether  string
+	FlagRequiredIf string
+	// ExitSuccess, ExitFailure and ExitInvalid describe the exit codes shared
+	// by all applications, see MapError.
+	ExitSuccess string
+	ExitFailure string
+	ExitInvalid string
+	// WouldWrite and WouldReplace are printed during a dry run with the path,
+	// size and permissions of a file, and WouldBackUp with the path of the
+	// backup first, see WriteFileAtomic.
+	WouldWrite   string
+	WouldReplace string
+	WouldBackUp  string
+	// ScheduleSkipped is printed after the arguments of a job still running
+	// from its previous run, and ScheduleExited with the exit code and
+	// duration of a run, see Scheduler.
+	ScheduleSkipped string
+	ScheduleExited  string
+	// ReloadOK and ReloadFailed, with the error, follow the name of a step of
+	// the reload pipeline, see Reload.
+	ReloadOK     string
+	ReloadFailed string
+	// StatsTime is printed with the wall, user and system time of a command,
+	// and StatsMemory with its peak resident set size, or StatsUnknown, the
+	// heap size, allocated bytes, and number and duration of garbage
+	// collections, see WithStatsFlag.
+	StatsTime    string
+	StatsMemory  string
+	StatsUnknown string
+}
+
+// DefaultMessages are the English messages of the framework.
+var DefaultMessages = Messages{
+	Error:   "Error",
+	Warning: "Warning",
+
+	Usage:            "Usage",
+	GlobalOptions:    "Global options",
+	Commands:         "Commands",
+	Arguments:        "Arguments",
+	Options:          "Options",
+	Examples:         "Examples",
+	ExitCodes:        "Exit codes",
+	BuiltinCommands:  "Built-in commands",
+	MatchingCommands: "Run '%s help <prefix>' for the options of matching commands.",
+
+	InvalidCommand:    "invalid command %s",
+	UnknownCommand:    "unknown command '%s', did you mean '%s'?",
+	RunningSuggestion: "Unknown command '%s', running '%s'.",
+
+	Deprecated:        "deprecated",
+	DeprecatedUse:     "use %s",
+	DeprecatedWarning: "%s is deprecated",
+	DeprecatedSince:   " since v%s",
+	DeprecatedRemoval: " and will be removed in v%s",
+	DeprecatedInstead: ", use %s instead",
+	Removed:           "%s was removed in v%s",
+
+	Gated:        "%s is not enabled, set %s=1 to use it",
+	Experimental: "%s is experimental and may change, set %s=1 to use it",
+
+	SetupRequired: "%s is not set up, run '%s %s' first",
+	SetupRunning:  "Setting up %s with '%s' first.",
+
+	PermissionDenied: "permission denied: %v",
+	RunsItself:       "cannot run within itself",
+	TimedOut:         "timed out after %v",
+
+	RequiresAdministrator: "must be run as Administrator",
+	RequiresRoot:          "must be run as root, try:\n\n  sudo %s %s",
+
+	UnsupportedShell: "unsupported shell %q, expected one of %s",
+	KilledChild:      "killed child process %s (pid %d) after %v grace period",
+	MissingCommand:   "missing command to run",
+
+	ShuttingDown:    "shutting down, press Ctrl-C again to force",
+	ShutdownTimeout: "did not shut down within %v",
+
+	AreYouSure: "%s. Are you sure?",
+	WouldDo:    "Would %s.",
+	YesNo:      "[y/N]",
+	Choose:     "Choose 1-%d:",
+	Retrying:   "Retrying %s in %v (%d/%d)",
+
+	CopiedToClipboard: "Copied to clipboard.",
+	OpenInBrowser:     "Open this URL in your browser:",
+	LoggedIn:          "Logged in as %s.",
+
+	UpToDate:         "%s is up to date (%s).",
+	UpdateAvailable:  "%s %s is available, run '%s update' to install it.",
+	WouldUpdate:      "Would update %s from %s to %s.",
+	Updated:          "Updated %s from %s to %s.",
+	InvalidSignature: "invalid signature",
+
+	FlagsExclusive: "At most one of %s may be set.",
+	FlagsTogether:  "Flags %s must be set together.",
+	FlagRequiredIf: "Flag %s is required when -%s is set.",
+
+	ExitSuccess: "Success.",
+	ExitFailure: "Failure.",
+	ExitInvalid: "Invalid flags or arguments.",
+
+	WouldWrite:   "dry run: would write %s (%d bytes, %v)",
+	WouldReplace: "dry run: would replace %s (%d bytes, %v)",
+	WouldBackUp:  "dry run: would back up to %s and replace %s (%d bytes, %v)",
+
+	ScheduleSkipped: "skipped, previous run still in progress",
+	ScheduleExited:  "exit %d in %v",
+	ReloadOK:        "ok",
+	ReloadFailed:    "%v",
+
+	StatsTime:    "wall %v, user %v, system %v",
+	StatsMemory:  "peak rss %s, heap %s, allocated %s, gc %d cycles, pause %v",
+	StatsUnknown: "unknown",
+}
+
+// WithMessages is an Option replacing the messages of the framework. Empty
+// messages are left as in DefaultMessages.
+func WithMessages(m Messages) Option {
+	return func(a *Application) {
+		a.messages = m.merge()
+	}
+}
+
+// merge returns m with its empty messages replaced by the defaults.
+func (m Messages) merge() Messages {
+	v := reflect.ValueOf(&m).Elem()
+	defaults := reflect.ValueOf(DefaultMessages)
+	for i := 0; i < v.NumField(); i++ {
+		if v.Field(i).String() == "" {
+			v.Field(i).Set(defaults.Field(i))
+		}
+	}
+
+	return m
+}
+
+// ctxMessages returns the messages of the Application whose command received
+// ctx, or the defaults outside of a command.
+func ctxMessages(ctx context.Context) *Messages {
+	inv, ok := ctx.Value(invocationContextKey{}).(*invocation)
+	if !ok {
+		return &DefaultMessages
+	}
+
+	return &inv.app.messages
+}
+
+// errorf prints an error message to w, labelled as an error.
+func (a *Application) errorf(w io.Writer, format string, args ...interface{}) {
+	fmt.Fprintf(w, "%s: %s\n", a.messages.Error, fmt.Sprintf(format, args...))
+}
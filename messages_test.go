@@ -0,0 +1,66 @@
+package cli
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMessages(t *testing.T) {
+	app := New("myapp", "1.0.0", WithMessages(Messages{
+		Error:             "Fehler",
+		Usage:             "Aufruf",
+		InvalidCommand:    "unbekannter Befehl %s",
+		DeprecatedWarning: "%s ist veraltet",
+		UnsupportedShell:  "Shell %q wird nicht unterstützt, erwartet %s",
+		FlagsExclusive:    "Höchstens eine von %s.",
+		Retrying:          "Wiederhole %s in %v (%d/%d)",
+	}))
+	app.RuleFunc("old", "Old.", "", func() {}, Deprecated("", "", ""))
+	app.RuleFunc("fail", "Fail.", "", func() int { return 75 }, Retry(1, time.Nanosecond))
+	app.Rule(&runConnect{}, "connect", "", Exclusive("json", "yaml"))
+	app.Completion("completion")
+
+	var stdout, stderr bytes.Buffer
+	app.RunWithArgs([]string{"xyzzy"}, &stdout, &stderr)
+	want := "Fehler: unbekannter Befehl xyzzy\nAufruf: myapp <cmd> [options] [<args>]\n"
+	if !strings.HasPrefix(stderr.String(), want) {
+		t.Errorf("invalid command\nhave %q\nwant prefix %q", stderr.String(), want)
+	}
+
+	stderr.Reset()
+	app.RunWithArgs([]string{"old"}, &stdout, &stderr)
+	want = "Warning: old ist veraltet\n"
+	if stderr.String() != want {
+		t.Errorf("deprecated\nhave %q\nwant %q", stderr.String(), want)
+	}
+
+	stderr.Reset()
+	app.RunWithArgs([]string{"completion", "tcsh"}, &stdout, &stderr)
+	want = "Fehler: completion: Shell \"tcsh\" wird nicht unterstützt, erwartet "
+	if !strings.HasPrefix(stderr.String(), want) {
+		t.Errorf("unsupported shell\nhave %q\nwant prefix %q", stderr.String(), want)
+	}
+
+	stderr.Reset()
+	app.RunWithArgs([]string{"fail"}, &stdout, &stderr)
+	want = "Wiederhole fail in "
+	if !strings.HasPrefix(stderr.String(), want) {
+		t.Errorf("retrying\nhave %q\nwant prefix %q", stderr.String(), want)
+	}
+
+	var buf bytes.Buffer
+	app.RunWithArgs([]string{"help", "connect"}, &buf, &buf)
+	want = "\nHöchstens eine von -json and -yaml.\n"
+	if !strings.Contains(buf.String(), want) {
+		t.Errorf("flag group\nhave %q\nwant %q", buf.String(), want)
+	}
+}
+
+func TestMessagesMerge(t *testing.T) {
+	m := Messages{Error: "Erreur"}.merge()
+	if m.Error != "Erreur" || m.Warning != DefaultMessages.Warning || m.Removed != DefaultMessages.Removed {
+		t.Errorf("merge\nhave %+v", m)
+	}
+}
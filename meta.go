@@ -0,0 +1,135 @@
+package cli
+
+import (
+	"encoding/json"
+	"flag"
+	"io"
+)
+
+// metaCommand is the hidden command writing a description of the
+// Application as JSON for external tools, such as documentation generators,
+// graphical interfaces and completion engines.
+const metaCommand = "__meta"
+
+// metaApp is the description of an Application written by the meta command.
+type metaApp struct {
+	Name        string       `json:"name"`
+	Version     string       `json:"version"`
+	Description string       `json:"description,omitempty"`
+	About       string       `json:"about,omitempty"`
+	Global      []metaOption `json:"global"`
+	Commands    []metaRule   `json:"commands"`
+	ExitCodes   []metaExit   `json:"exit_codes,omitempty"`
+}
+
+// metaRule describes a command, including those that are hidden.
+type metaRule struct {
+	Name        string         `json:"name"`
+	Synopsis    string         `json:"synopsis"`
+	Description string         `json:"description"`
+	Help        string         `json:"help,omitempty"`
+	Category    string         `json:"category,omitempty"`
+	Hidden      bool           `json:"hidden,omitempty"`
+	Deprecated  bool           `json:"deprecated,omitempty"`
+	Arguments   []metaArgument `json:"arguments"`
+	Options     []metaOption   `json:"options"`
+	Notes       []string       `json:"notes,omitempty"`
+	Examples    []string       `json:"examples,omitempty"`
+}
+
+type metaArgument struct {
+	Name     string `json:"name"`
+	Usage    string `json:"usage,omitempty"`
+	Optional bool   `json:"optional"`
+	Repeated bool   `json:"repeated"`
+}
+
+type metaOption struct {
+	Name     string   `json:"name"`
+	Short    string   `json:"short,omitempty"`
+	Value    string   `json:"value,omitempty"`
+	Default  string   `json:"default,omitempty"`
+	Usage    string   `json:"usage"`
+	Env      string   `json:"env,omitempty"`
+	Repeated bool     `json:"repeated"`
+	Choices  []string `json:"choices,omitempty"`
+}
+
+type metaExit struct {
+	Code        int    `json:"code"`
+	Description string `json:"description"`
+}
+
+// meta writes the description of the Application and each of its commands
+// to w, from the same metadata as the usage. Commands that fail to load are
+// omitted.
+func (a *Application) meta(w io.Writer) error {
+	global := flag.NewFlagSet(a.name, flag.ContinueOnError)
+	a.defineGlobal(global, &globals{})
+	m := metaApp{
+		Name:        a.name,
+		Version:     a.version,
+		Description: a.description,
+		About:       a.about,
+		Global:      metaOptions(global, a.optionUsages(global, a.env)),
+		Commands:    []metaRule{},
+	}
+
+	for _, e := range a.exitCodeUsages() {
+		m.ExitCodes = append(m.ExitCodes, metaExit{e.Code, e.Description})
+	}
+
+	for _, name := range a.ordered(a.match("")) {
+		r, _ := a.lookup(name)
+		if r.load() != nil {
+			continue
+		}
+
+		c := a.commandUsage(r)
+		mr := metaRule{
+			Name:        c.Name,
+			Synopsis:    c.Synopsis,
+			Description: r.command.String(),
+			Help:        c.Help,
+			Category:    c.Category,
+			Hidden:      a.hidden(r.name),
+			Deprecated:  r.deprecated != nil,
+			Arguments:   []metaArgument{},
+			Options:     metaOptions(r.options, c.Options),
+			Notes:       c.Notes,
+			Examples:    c.Examples,
+		}
+		for _, arg := range c.Arguments {
+			mr.Arguments = append(mr.Arguments, metaArgument(arg))
+		}
+
+		m.Commands = append(m.Commands, mr)
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(m)
+}
+
+// metaOptions describes the options of the flags, with their choices.
+func metaOptions(flags *flag.FlagSet, usages []OptionUsage) []metaOption {
+	options := []metaOption{}
+	for _, o := range usages {
+		mo := metaOption{
+			Name:     o.Name,
+			Short:    o.Short,
+			Value:    o.Value,
+			Default:  o.Default,
+			Usage:    o.Usage,
+			Env:      o.Env,
+			Repeated: o.Repeated,
+		}
+		if f := flags.Lookup(o.Name); f != nil {
+			mo.Choices = choices(f)
+		}
+
+		options = append(options, mo)
+	}
+
+	return options
+}
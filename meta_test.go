@@ -0,0 +1,71 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"testing"
+)
+
+func TestMeta(t *testing.T) {
+	app := New("myapp", "0.0.1")
+	app.RuleFunc("build", "Build it.", "", func() {}, Category("Build commands"), Examples("myapp build"))
+	app.RuleFunc("old", "Old build.", "", func() {}, Hidden())
+	app.Rule(&runDeclared{}, "pkg", "<pkg> [<tags>...]")
+	app.Flags(func(flags *flag.FlagSet) {
+		ChoiceVar(flags, "color", "auto", []string{"auto", "never"}, "Colorize output.")
+	})
+
+	var stdout, stderr bytes.Buffer
+	code := app.RunWithArgs([]string{metaCommand}, &stdout, &stderr)
+	if code != 0 {
+		t.Fatalf("exit code\nhave %d %q\nwant %d", code, stderr.String(), 0)
+	}
+
+	var m metaApp
+	err := json.Unmarshal(stdout.Bytes(), &m)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if m.Name != "myapp" || m.Version != "0.0.1" {
+		t.Errorf("app\nhave %q %q\nwant %q %q", m.Name, m.Version, "myapp", "0.0.1")
+	}
+
+	if len(m.Global) != 1 || fmt.Sprint(m.Global[0].Choices) != "[auto never]" {
+		t.Errorf("global\nhave %v\nwant color with choices [auto never]", m.Global)
+	}
+
+	var names []string
+	for _, c := range m.Commands {
+		names = append(names, c.Name)
+	}
+
+	want := "[help version build old pkg]"
+	if fmt.Sprint(names) != want {
+		t.Fatalf("names\nhave %v\nwant %v", names, want)
+	}
+
+	if c := m.Commands[2]; c.Category != "Build commands" || fmt.Sprint(c.Examples) != "[myapp build]" {
+		t.Errorf("build\nhave %q %v\nwant %q %v", c.Category, c.Examples, "Build commands", "[myapp build]")
+	}
+
+	if c := m.Commands[3]; !c.Hidden {
+		t.Errorf("hidden\nhave %v\nwant %v", c.Hidden, true)
+	}
+
+	c := m.Commands[4]
+	if have := fmt.Sprint(c.Arguments); have != "[{<pkg>  false false} {<tags>  true true}]" {
+		t.Errorf("arguments\nhave %v\nwant %v", have, "[{<pkg>  false false} {<tags>  true true}]")
+	}
+
+	options := make(map[string]string)
+	for _, o := range c.Options {
+		options[o.Name] = o.Default
+	}
+
+	if options["o"] != "a.out" || options["timeout"] != "1m0s" {
+		t.Errorf("options\nhave %v\nwant o=a.out timeout=1m0s", options)
+	}
+}
@@ -0,0 +1,105 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+)
+
+// A RunFunc runs a command with its positional arguments, returning its exit
+// code and any error to report.
+type RunFunc func(ctx context.Context, args []string) (int, error)
+
+// A Middleware wraps the execution of commands to implement cross-cutting
+// concerns such as logging, timing or authorization. It may short-circuit by
+// returning without calling next.
+type Middleware func(next RunFunc) RunFunc
+
+// Use adds middleware wrapping the execution of every command of the
+// Application. Middleware added first runs outermost, and application
+// middleware runs outside that of the rule, see Wrap. A non-nil error
+// returned through the chain is printed to stderr as "app: command: message"
+// and results in an exit code of 1 if the exit code would otherwise be 0.
+func (a *Application) Use(middleware ...Middleware) {
+	a.middleware = append(a.middleware, middleware...)
+}
+
+// Wrap is a RuleOption adding middleware wrapping the execution of the
+// command, see Application.Use.
+func Wrap(middleware ...Middleware) RuleOption {
+	return func(r *rule) {
+		r.middleware = append(r.middleware, middleware...)
+	}
+}
+
+// Before returns Middleware calling fn before the command runs. If fn returns
+// an error, the command does not run.
+func Before(fn func(ctx context.Context) error) Middleware {
+	return func(next RunFunc) RunFunc {
+		return func(ctx context.Context, args []string) (int, error) {
+			err := fn(ctx)
+			if err != nil {
+				return 1, err
+			}
+
+			return next(ctx, args)
+		}
+	}
+}
+
+// After returns Middleware calling fn with the exit code of the command after
+// it runs.
+func After(fn func(ctx context.Context, code int)) Middleware {
+	return func(next RunFunc) RunFunc {
+		return func(ctx context.Context, args []string) (int, error) {
+			code, err := next(ctx, args)
+			fn(ctx, code)
+			return code, err
+		}
+	}
+}
+
+// CommandName returns the name of the command that received ctx.
+func CommandName(ctx context.Context) string {
+	return ctxInvocation(ctx).rule.name
+}
+
+// chain wraps run in the middleware of the Application and of the rule.
+func (a *Application) chain(r *rule, run RunFunc) RunFunc {
+	for i := len(r.middleware) - 1; i >= 0; i-- {
+		run = r.middleware[i](run)
+	}
+
+	for i := len(a.middleware) - 1; i >= 0; i-- {
+		run = a.middleware[i](run)
+	}
+
+	return run
+}
+
+// execute runs the command of the rule through the middleware chain,
+// reporting any error, and returns the exit code.
+func (a *Application) execute(ctx context.Context, r *rule, args []string) (code int) {
+	defer a.recoverPanic(ctx, r, &code)
+
+	run := a.chain(r, func(ctx context.Context, args []string) (int, error) {
+		// Call the command, retrying failures if the rule allows it.
+		inv := ctxInvocation(ctx)
+		code := r.call(ctx, args)
+		for attempt := 1; r.retry.again(ctx, Stderr(ctx), &inv.app.messages, r.name, attempt, code, inv.lastError()); attempt++ {
+			code = r.call(ctx, args)
+		}
+
+		return code, nil
+	})
+
+	code, err := run(ctx, args)
+	if err != nil {
+		fmt.Fprintf(Stderr(ctx), "%s: %s: %v\n", a.name, r.name, err)
+		ctxInvocation(ctx).fail(err)
+		if code == 0 {
+			code = 1
+		}
+	}
+
+	return code
+}
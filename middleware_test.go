@@ -0,0 +1,51 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestMiddleware(t *testing.T) {
+	var calls []string
+	record := func(label string) Middleware {
+		return func(next RunFunc) RunFunc {
+			return func(ctx context.Context, args []string) (int, error) {
+				calls = append(calls, label+" "+CommandName(ctx))
+				return next(ctx, args)
+			}
+		}
+	}
+
+	app := New("myapp", "0.0.1")
+	app.Use(record("app"))
+	app.Use(After(func(ctx context.Context, code int) {
+		calls = append(calls, "after")
+	}))
+	app.Rule(&runRecord{}, "record", "<a> <b>", Wrap(record("rule")))
+	app.Rule(&runRecord{}, "denied", "", Wrap(Before(func(ctx context.Context) error {
+		return errors.New("not allowed")
+	})))
+
+	code := app.Dispatch([]string{"record", "-number", "4", "x", "y"})
+	if code != 4 {
+		t.Errorf("exit code\nhave %d\nwant %d", code, 4)
+	}
+
+	want := []string{"app record", "rule record", "after"}
+	if !reflect.DeepEqual(calls, want) {
+		t.Errorf("calls\nhave %q\nwant %q", calls, want)
+	}
+
+	var stdout, stderr bytes.Buffer
+	code = app.RunWithArgs([]string{"denied"}, &stdout, &stderr)
+	if code != 1 {
+		t.Errorf("short-circuit exit code\nhave %d\nwant %d", code, 1)
+	}
+
+	if want := "myapp: denied: not allowed\n"; stderr.String() != want {
+		t.Errorf("short-circuit stderr\nhave %q\nwant %q", stderr.String(), want)
+	}
+}
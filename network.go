@@ -0,0 +1,76 @@
+package cli
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"flag"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// NetworkFlags is an opt-in bundle of the flags conventionally offered by
+// commands that talk to remote services. Define the flags from the Flags
+// method of the command and build a client for them with Client.
+type NetworkFlags struct {
+	Proxy          string
+	CACert         string
+	Insecure       bool
+	ConnectTimeout time.Duration
+}
+
+// Define defines the -proxy, -cacert, -insecure-skip-verify and
+// -connect-timeout flags on flags.
+func (n *NetworkFlags) Define(flags *flag.FlagSet) {
+	flags.StringVar(&n.Proxy, "proxy", "", "Proxy URL. Defaults to $HTTPS_PROXY and $HTTP_PROXY.")
+	flags.StringVar(&n.CACert, "cacert", "", "PEM file of additional certificate authorities to trust.")
+	flags.BoolVar(&n.Insecure, "insecure-skip-verify", false, "Do not verify TLS certificates.")
+	flags.DurationVar(&n.ConnectTimeout, "connect-timeout", 30*time.Second, "Timeout for establishing connections.")
+}
+
+// Client returns an *http.Client configured by the flags.
+func (n *NetworkFlags) Client() (*http.Client, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DialContext = (&net.Dialer{
+		Timeout:   n.ConnectTimeout,
+		KeepAlive: 30 * time.Second,
+	}).DialContext
+	transport.TLSHandshakeTimeout = n.ConnectTimeout
+
+	if n.Proxy != "" {
+		proxy, err := url.Parse(n.Proxy)
+		if err != nil {
+			return nil, err
+		}
+
+		transport.Proxy = http.ProxyURL(proxy)
+	}
+
+	if n.CACert != "" || n.Insecure {
+		config := &tls.Config{InsecureSkipVerify: n.Insecure}
+		if n.CACert != "" {
+			pem, err := ioutil.ReadFile(n.CACert)
+			if err != nil {
+				return nil, err
+			}
+
+			pool, err := x509.SystemCertPool()
+			if err != nil {
+				pool = x509.NewCertPool()
+			}
+
+			if !pool.AppendCertsFromPEM(pem) {
+				return nil, errors.New("cacert: no certificates found in " + n.CACert)
+			}
+
+			config.RootCAs = pool
+		}
+
+		transport.TLSClientConfig = config
+	}
+
+	return &http.Client{Transport: transport}, nil
+}
@@ -0,0 +1,47 @@
+package cli
+
+import (
+	"flag"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestNetworkFlagsClient(t *testing.T) {
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer ts.Close()
+
+	tests := []struct {
+		args []string
+		ok   bool
+	}{
+		{[]string{}, false},
+		{[]string{"-insecure-skip-verify"}, true},
+	}
+
+	for _, tt := range tests {
+		var n NetworkFlags
+		flags := flag.NewFlagSet("get", flag.ContinueOnError)
+		n.Define(flags)
+		flags.Parse(tt.args)
+
+		client, err := n.Client()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		_, err = client.Get(ts.URL)
+		if (err == nil) != tt.ok {
+			t.Errorf("%v\nhave %v", tt.args, err)
+		}
+	}
+
+	var n NetworkFlags
+	flags := flag.NewFlagSet("get", flag.ContinueOnError)
+	n.Define(flags)
+	flags.Parse([]string{"-proxy", "http://proxy:3128", "-connect-timeout", "2s"})
+	if n.Proxy != "http://proxy:3128" || n.ConnectTimeout != 2*time.Second {
+		t.Errorf("flags\nhave %+v", n)
+	}
+}
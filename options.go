@@ -0,0 +1,98 @@
+package cli
+
+import (
+	"context"
+	"flag"
+	"io"
+)
+
+// WithWriter is an Option setting the standard output of the Application,
+// os.Stdout by default, for commands run with Run, Main and Dispatch.
+func WithWriter(w io.Writer) Option {
+	return func(a *Application) {
+		a.stdout = w
+	}
+}
+
+// WithErrWriter is an Option setting the standard error of the Application,
+// os.Stderr by default, to which usage and errors are printed.
+func WithErrWriter(w io.Writer) Option {
+	return func(a *Application) {
+		a.stderr = w
+	}
+}
+
+// WithExitFunc is an Option setting the function Run calls to exit the
+// process, os.Exit by default, see SetExit.
+func WithExitFunc(fn func(code int)) Option {
+	return func(a *Application) {
+		a.exit = fn
+	}
+}
+
+// WithoutDefaultCommands is an Option skipping the registration of the help
+// and version commands, along with the -version flag, such as for
+// applications providing their own. The usage is still printed for -h.
+func WithoutDefaultCommands() Option {
+	return func(a *Application) {
+		a.noDefaults = true
+	}
+}
+
+// WithFlagErrorHandling is an Option setting how invalid flags are handled,
+// flag.ContinueOnError by default, which prints the error and usage and
+// results in an exit code of ExitUsage. With flag.ExitOnError the process is
+// exited through the function set with SetExit instead, and with
+// flag.PanicOnError the error is raised as a panic.
+func WithFlagErrorHandling(h flag.ErrorHandling) Option {
+	return func(a *Application) {
+		a.flagErrors = h
+	}
+}
+
+// OnFlagSet registers fn to be called with each FlagSet before it parses the
+// arguments of a dispatch, with the name of its command, or an empty name
+// for the global flags. The flags are defined and the output and usage of
+// the FlagSet are set, so that fn may replace them, or change its error
+// handling with the Init method of the FlagSet.
+func (a *Application) OnFlagSet(fn func(cmd string, flags *flag.FlagSet)) {
+	a.onFlagSet = append(a.onFlagSet, fn)
+}
+
+// OnFlagError registers fn to be called with the name of the command, or an
+// empty name for the global flags, and the error when parsing flags fails,
+// such as to record usage mistakes, before the error is handled according to
+// WithFlagErrorHandling. Requests for help with -h are not errors.
+func (a *Application) OnFlagError(fn func(cmd string, err error)) {
+	a.onFlagErr = append(a.onFlagErr, fn)
+}
+
+// prepareFlags calls the functions registered with OnFlagSet.
+func (a *Application) prepareFlags(cmd string, flags *flag.FlagSet) {
+	for _, fn := range a.onFlagSet {
+		fn(cmd, flags)
+	}
+}
+
+// flagError handles an error parsing the flags of the named command, which
+// received ctx, returning the exit code. Commands dispatched by Execute never
+// exit.
+func (a *Application) flagError(ctx context.Context, cmd string, err error) int {
+	if err != flag.ErrHelp {
+		for _, fn := range a.onFlagErr {
+			fn(cmd, err)
+		}
+	}
+
+	code := parseCode(err)
+	switch a.flagErrors {
+	case flag.ExitOnError:
+		if !embedded(ctx) {
+			a.exit(code)
+		}
+	case flag.PanicOnError:
+		panic(err)
+	}
+
+	return code
+}
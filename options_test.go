@@ -0,0 +1,97 @@
+package cli
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"testing"
+)
+
+func TestOptions(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	exited := -1
+	app := New("myapp", "0.0.1",
+		WithWriter(&stdout),
+		WithErrWriter(&stderr),
+		WithExitFunc(func(code int) { exited = code }),
+		WithoutDefaultCommands(),
+		WithFlagErrorHandling(flag.ExitOnError),
+	)
+	app.RuleFunc("hello", "Say hello.", "", func() { fmt.Fprint(app.stdout, "hello") })
+
+	var names []string
+	for _, c := range app.Commands() {
+		names = append(names, c.Name)
+	}
+
+	if fmt.Sprint(names) != "[hello]" {
+		t.Errorf("commands\nhave %v\nwant %v", names, "[hello]")
+	}
+
+	code := app.Dispatch([]string{"hello"})
+	if code != 0 || stdout.String() != "hello" {
+		t.Errorf("hello\nhave %d %q\nwant %d %q", code, stdout.String(), 0, "hello")
+	}
+
+	code = app.Dispatch([]string{"hello", "-bogus"})
+	if code != ExitUsage || exited != ExitUsage || stderr.Len() == 0 {
+		t.Errorf("invalid flag\nhave %d %d %q\nwant %d %d", code, exited, stderr.String(), ExitUsage, ExitUsage)
+	}
+
+	exited = -1
+	code = app.Dispatch([]string{"-version"})
+	if code != ExitUsage || exited != ExitUsage {
+		t.Errorf("-version\nhave %d %d\nwant %d %d", code, exited, ExitUsage, ExitUsage)
+	}
+}
+
+func TestFlagErrorPanic(t *testing.T) {
+	app := New("myapp", "0.0.1", WithErrWriter(&bytes.Buffer{}), WithFlagErrorHandling(flag.PanicOnError))
+	defer func() {
+		if recover() == nil {
+			t.Errorf("panic\nhave nil\nwant flag error")
+		}
+	}()
+
+	app.Dispatch([]string{"-bogus"})
+}
+
+func TestFlagHooks(t *testing.T) {
+	app := New("myapp", "0.0.1")
+	app.Rule(&runRecord{}, "record", "[<a>] [<b>]")
+
+	var prepared, failed []string
+	var usage bytes.Buffer
+	app.OnFlagSet(func(cmd string, flags *flag.FlagSet) {
+		prepared = append(prepared, cmd)
+		flags.SetOutput(&usage)
+		flags.Usage = func() { fmt.Fprintf(&usage, "usage of %q\n", cmd) }
+	})
+	app.OnFlagError(func(cmd string, err error) {
+		failed = append(failed, fmt.Sprintf("%s: %v", cmd, err))
+	})
+
+	var stderr bytes.Buffer
+	code := app.RunWithArgs([]string{"record", "-bogus"}, &stderr, &stderr)
+	if code != ExitUsage || stderr.Len() != 0 {
+		t.Errorf("exit code\nhave %d %q\nwant %d %q", code, stderr.String(), ExitUsage, "")
+	}
+
+	if have, want := fmt.Sprint(prepared), `[ record]`; have != want {
+		t.Errorf("prepared\nhave %s\nwant %s", have, want)
+	}
+
+	if have, want := fmt.Sprint(failed), "[record: flag provided but not defined: -bogus]"; have != want {
+		t.Errorf("failed\nhave %s\nwant %s", have, want)
+	}
+
+	if have, want := usage.String(), "flag provided but not defined: -bogus\nusage of \"record\"\n"; have != want {
+		t.Errorf("usage\nhave %q\nwant %q", have, want)
+	}
+
+	failed = nil
+	app.RunWithArgs([]string{"record", "-h"}, &stderr, &stderr)
+	if len(failed) != 0 {
+		t.Errorf("help reported as error\nhave %q", failed)
+	}
+}
@@ -0,0 +1,141 @@
+package cli
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strconv"
+	"strings"
+)
+
+// An outSetter is implemented by commands receiving the leveled output of
+// their invocation before they are run, see Out.
+type outSetter interface {
+	SetOut(out *LevelWriters)
+}
+
+// LevelWriters are the writers of the messages of a command by level, see
+// Out. The writers of the levels silenced by -quiet or not enabled by
+// -verbose discard their messages.
+type LevelWriters struct {
+	// Error is always written.
+	Error io.Writer
+	// Warn is silenced by -quiet given twice, such as -qq.
+	Warn io.Writer
+	// Info is silenced by -quiet.
+	Info io.Writer
+	// Verbose is enabled by -verbose.
+	Verbose io.Writer
+	// Debug is enabled by -verbose given twice, such as -vv.
+	Debug io.Writer
+}
+
+// A counter is a boolean flag.Value counting the times it is given, such
+// that -v -v is 2, which may also be given as -vv or -v=2.
+type counter int
+
+// Out returns the writers of the messages of the invocation of the command
+// that received ctx by level, all writing to its standard error. The levels
+// written depend on the Verbosity, such that the commands of an Application
+// with StandardFlags respect -quiet and -verbose without checking them. The
+// command may also have a method SetOut, called with the writers before it
+// is run.
+func Out(ctx context.Context) *LevelWriters {
+	w := Stderr(ctx)
+	level := Verbosity(ctx)
+	enabled := func(min int) io.Writer {
+		if level < min {
+			return ioutil.Discard
+		}
+
+		return w
+	}
+
+	return &LevelWriters{
+		Error:   w,
+		Warn:    enabled(-1),
+		Info:    enabled(0),
+		Verbose: enabled(1),
+		Debug:   enabled(2),
+	}
+}
+
+// Verbosity returns the number of times the -verbose flag was given less the
+// number of times the -quiet flag was given, see StandardFlags.
+func Verbosity(ctx context.Context) int {
+	return ctxOutput(ctx).verbosity()
+}
+
+// verbosity returns the verbosity set by the -verbose and -quiet flags.
+func (o output) verbosity() int {
+	return int(o.verbose) - int(o.quiet)
+}
+
+// String implements the flag.Value interface.
+func (c *counter) String() string {
+	if c == nil {
+		return "0"
+	}
+
+	return strconv.Itoa(int(*c))
+}
+
+// Set implements the flag.Value interface. The flag counts once more when
+// given without a value, or is set to the given count.
+func (c *counter) Set(value string) error {
+	switch value {
+	case "true":
+		*c++
+		return nil
+	case "false":
+		*c = 0
+		return nil
+	}
+
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 0 {
+		return fmt.Errorf("invalid count %q", value)
+	}
+
+	*c = counter(n)
+	return nil
+}
+
+// IsBoolFlag reports whether the flag is a boolean flag.
+func (c *counter) IsBoolFlag() bool {
+	return true
+}
+
+// repeatCounter returns the arguments giving the counting flag named by the
+// repeated letter of name once each, such that -vv is -v -v, or nil if name
+// does not repeat the name of a counting flag.
+func repeatCounter(flags *flag.FlagSet, name string) []string {
+	if len(name) < 2 || strings.Trim(name, name[:1]) != "" {
+		return nil
+	}
+
+	if f := flags.Lookup(name[:1]); f == nil || !isCounter(f) {
+		return nil
+	}
+
+	args := make([]string, len(name))
+	for i := range args {
+		args[i] = "-" + name[:1]
+	}
+
+	return args
+}
+
+// isCounter reports whether the flag, or the flag it is the short spelling
+// of, counts the times it is given.
+func isCounter(f *flag.Flag) bool {
+	v := f.Value
+	if s, ok := v.(*shorthand); ok {
+		v = s.Value
+	}
+
+	_, ok := v.(*counter)
+	return ok
+}
@@ -0,0 +1,94 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+type runOut struct {
+	*NullFlags
+	out *LevelWriters
+}
+
+func (r *runOut) String() string {
+	return "Write messages."
+}
+
+func (r *runOut) SetOut(out *LevelWriters) {
+	r.out = out
+}
+
+func (r *runOut) Run(ctx context.Context) {
+	fmt.Fprintln(r.out.Error, "error")
+	fmt.Fprintln(r.out.Warn, "warn")
+	fmt.Fprintln(r.out.Info, "info")
+	fmt.Fprintln(r.out.Verbose, "verbose")
+	fmt.Fprintln(Out(ctx).Debug, "debug")
+}
+
+func TestOut(t *testing.T) {
+	tests := []struct {
+		args   []string
+		stderr string
+	}{
+		{[]string{"out"}, "error\nwarn\ninfo\n"},
+		{[]string{"-q", "out"}, "error\nwarn\n"},
+		{[]string{"-qq", "out"}, "error\n"},
+		{[]string{"-quiet", "-q", "out"}, "error\n"},
+		{[]string{"-v", "out"}, "error\nwarn\ninfo\nverbose\n"},
+		{[]string{"-vv", "out"}, "error\nwarn\ninfo\nverbose\ndebug\n"},
+		{[]string{"-verbose=2", "out"}, "error\nwarn\ninfo\nverbose\ndebug\n"},
+		{[]string{"-vv", "-q", "out"}, "error\nwarn\ninfo\nverbose\n"},
+	}
+
+	for _, tt := range tests {
+		var stdout, stderr bytes.Buffer
+		app := New("myapp", "0.0.1")
+		app.StandardFlags()
+		app.Rule(&runOut{}, "out", "")
+
+		code := app.RunWithArgs(tt.args, &stdout, &stderr)
+		if code != 0 || stderr.String() != tt.stderr {
+			t.Errorf("%q\nhave %d %q\nwant %d %q", tt.args, code, stderr.String(), 0, tt.stderr)
+		}
+	}
+}
+
+func TestOutLogger(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	app := New("myapp", "0.0.1")
+	app.StandardFlags()
+	app.Rule(&runLog{}, "log", "")
+
+	app.RunWithArgs([]string{"-qq", "log"}, &stdout, &stderr)
+	if stderr.String() != "" {
+		t.Errorf("-qq log\nhave %q\nwant %q", stderr.String(), "")
+	}
+}
+
+func TestOutUsage(t *testing.T) {
+	var buf bytes.Buffer
+	app := New("myapp", "0.0.1")
+	app.StandardFlags()
+	app.PrintUsage(&buf)
+
+	want := "-q, -quiet  "
+	if !strings.Contains(buf.String(), want) {
+		t.Errorf("usage\nhave %s\nwant %s", buf.String(), want)
+	}
+}
+
+func TestOutInvalidCount(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	app := New("myapp", "0.0.1")
+	app.StandardFlags()
+	app.Rule(&runOut{}, "out", "")
+
+	code := app.RunWithArgs([]string{"-verbose=lots", "out"}, &stdout, &stderr)
+	if want := `invalid count "lots"`; code != ExitUsage || !strings.Contains(stderr.String(), want) {
+		t.Errorf("-verbose=lots\nhave %d %q\nwant %d %q", code, stderr.String(), ExitUsage, want)
+	}
+}
@@ -0,0 +1,111 @@
+package cli
+
+import (
+	"context"
+	"flag"
+	"fmt"
+)
+
+// output holds the output preferences of an invocation set by the standard
+// flags, see StandardFlags.
+type output struct {
+	quiet   counter
+	verbose counter
+	json    bool
+	noColor bool
+	format  *Choice
+
+	logLevel  *Choice
+	logFormat *Choice
+}
+
+// StandardFlags enables the conventional -quiet, -verbose, -output, -json,
+// -no-color, -log-level and -log-format global flags, see Flags. Commands
+// observe them through Logf, Debugf, Logger, Out, Print, Printer and Color,
+// or directly with Quiet, Verbose, Verbosity and JSON. The -output flag
+// selects text, json or csv output, where table is the same as text, and
+// -json is short for -output json. The -q and -v flags are short for -quiet
+// and -verbose, which count the times they are given, such that -qq or -q -q
+// silences all but errors and -vv enables debugging messages, see Out.
+func (a *Application) StandardFlags() {
+	a.standard = true
+}
+
+// define defines the standard flags on flags.
+func (o *output) define(flags *flag.FlagSet) {
+	flags.Var(&o.quiet, "quiet", "Suppress informational messages, and warnings if repeated.")
+	flags.Var(&o.verbose, "verbose", "Print verbose messages, and debugging messages if repeated.")
+	o.format = ChoiceVar(flags, "output", "text", []string{"text", "table", "json", "csv"}, "Format of results.")
+	flags.BoolVar(&o.json, "json", false, "Print results as JSON.")
+	flags.BoolVar(&o.noColor, "no-color", false, "Disable colored output.")
+	o.logLevel = ChoiceVar(flags, "log-level", "", []string{"debug", "info", "warn", "error"}, "Minimum level of log messages.")
+	o.logFormat = ChoiceVar(flags, "log-format", "text", []string{"text", "json"}, "Format of log messages.")
+	Shorthand(flags, "quiet", "q")
+	Shorthand(flags, "verbose", "v")
+}
+
+// ctxOutput returns the output preferences of the invocation of the command
+// that received ctx.
+func ctxOutput(ctx context.Context) output {
+	inv, ok := ctx.Value(invocationContextKey{}).(*invocation)
+	if !ok {
+		return output{}
+	}
+
+	return inv.output
+}
+
+// Quiet reports whether the -quiet flag was given.
+func Quiet(ctx context.Context) bool {
+	return ctxOutput(ctx).quiet > 0
+}
+
+// Verbose reports whether the -verbose flag was given.
+func Verbose(ctx context.Context) bool {
+	return ctxOutput(ctx).verbose > 0
+}
+
+// JSON reports whether the -json or -output json flag was given.
+func JSON(ctx context.Context) bool {
+	o := ctxOutput(ctx)
+	return o.json || o.format != nil && o.format.Value() == "json"
+}
+
+// Color reports whether the standard output of the invocation may be
+// colored. It may not if the -no-color flag was given and, unless the
+// Application is set to ColorAlways, if the NO_COLOR environment variable is
+// set, TERM is dumb or the output is not a terminal, see SetColorMode.
+func Color(ctx context.Context) bool {
+	mode := ColorAuto
+	if inv, ok := ctx.Value(invocationContextKey{}).(*invocation); ok {
+		mode = inv.app.color
+	}
+
+	return colorize(Stdout(ctx), mode, ctxOutput(ctx).noColor)
+}
+
+// Logf prints an informational message to the standard error of the
+// invocation unless the -quiet flag was given.
+func Logf(ctx context.Context, format string, args ...interface{}) {
+	if Quiet(ctx) {
+		return
+	}
+
+	fmt.Fprintf(Stderr(ctx), format+"\n", args...)
+}
+
+// Debugf prints a debugging message to the standard error of the invocation
+// if the -verbose flag was given.
+func Debugf(ctx context.Context, format string, args ...interface{}) {
+	if !Verbose(ctx) {
+		return
+	}
+
+	fmt.Fprintf(Stderr(ctx), format+"\n", args...)
+}
+
+// Print prints the result v to the standard output of the invocation with a
+// Printer, as indented JSON if the -json flag was given.
+func Print(ctx context.Context, v interface{}) error {
+	return NewPrinter(ctx).Print(v)
+}
@@ -0,0 +1,75 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+)
+
+type runOutput struct {
+	*NullFlags
+}
+
+func TestStandardFlags(t *testing.T) {
+	tests := []struct {
+		args   []string
+		stdout string
+		stderr string
+	}{
+		{[]string{"output"}, "map[n:1]\n", "working\n"},
+		{[]string{"-quiet", "output"}, "map[n:1]\n", ""},
+		{[]string{"-verbose", "output"}, "map[n:1]\n", "working\ndetails\n"},
+		{[]string{"-json", "-no-color", "output"}, "{\n  \"n\": 1\n}\n", "working\n"},
+	}
+
+	for _, tt := range tests {
+		var stdout, stderr bytes.Buffer
+		app := New("myapp", "0.0.1")
+		app.stdout = &stdout
+		app.stderr = &stderr
+		app.StandardFlags()
+		app.Rule(&runOutput{}, "output", "")
+
+		code := app.Dispatch(tt.args)
+		if code != 0 {
+			t.Errorf("%q exit code\nhave %d\nwant %d", tt.args, code, 0)
+		}
+
+		if stdout.String() != tt.stdout {
+			t.Errorf("%q stdout\nhave %q\nwant %q", tt.args, stdout.String(), tt.stdout)
+		}
+
+		if stderr.String() != tt.stderr {
+			t.Errorf("%q stderr\nhave %q\nwant %q", tt.args, stderr.String(), tt.stderr)
+		}
+	}
+}
+
+func TestStandardFlagsUsage(t *testing.T) {
+	var buf bytes.Buffer
+	app := New("myapp", "0.0.1")
+	app.StandardFlags()
+	app.PrintUsage(&buf)
+
+	for _, want := range []string{"-quiet", "-verbose", "-json", "-no-color"} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("usage does not document %s\n%s", want, buf.String())
+		}
+	}
+}
+
+func (c *runOutput) Run(ctx context.Context) int {
+	Logf(ctx, "working")
+	Debugf(ctx, "details")
+	if Color(ctx) {
+		return 1
+	}
+
+	Print(ctx, map[string]int{"n": 1})
+	return 0
+}
+
+func (c *runOutput) String() string {
+	return "print output"
+}
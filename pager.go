@@ -0,0 +1,88 @@
+package cli
+
+import (
+	"context"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// A pager pipes the output written to it to a pager program displaying it
+// on a terminal, see Pager.
+type pager struct {
+	io.WriteCloser
+	term *os.File
+	cmd  *exec.Cmd
+}
+
+// A nopCloser is a writer with a Close method doing nothing.
+type nopCloser struct {
+	io.Writer
+}
+
+// UsePager sets whether the output of the help command, and of commands
+// writing to a Pager, is paged on a terminal. It is by default, unless the
+// -no-pager flag is given before the command name, see WithNoPagerFlag.
+func (a *Application) UsePager(enabled bool) {
+	a.noPager = !enabled
+}
+
+// Pager returns a writer for the standard output of the invocation of the
+// command that received ctx, paging the output through the $PAGER program,
+// or less, if the standard output is a terminal, in the manner of git.
+// Otherwise, and if $PAGER is empty or cat, output is written to the
+// standard output directly. Close must be called once the output is
+// written to wait for the user to quit the pager.
+func Pager(ctx context.Context) io.WriteCloser {
+	inv := ctxInvocation(ctx)
+	return inv.app.pager(Stdout(ctx), inv.noPager)
+}
+
+// pager returns a writer paging the output written to it on w if w is a
+// terminal and paging is not disabled.
+func (a *Application) pager(w io.Writer, disabled bool) io.WriteCloser {
+	f, ok := w.(*os.File)
+	if disabled || a.noPager || !ok || !terminal(f.Fd()) {
+		return nopCloser{w}
+	}
+
+	program, ok := os.LookupEnv("PAGER")
+	if !ok {
+		program = "less"
+	}
+
+	args := strings.Fields(program)
+	if len(args) == 0 || args[0] == "cat" {
+		return nopCloser{w}
+	}
+
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Stdout = f
+	cmd.Stderr = os.Stderr
+	cmd.Env = os.Environ()
+	if _, ok := os.LookupEnv("LESS"); !ok {
+		cmd.Env = append(cmd.Env, "LESS=FRX")
+	}
+
+	in, err := cmd.StdinPipe()
+	if err == nil {
+		err = cmd.Start()
+	}
+	if err != nil {
+		return nopCloser{w}
+	}
+
+	return &pager{WriteCloser: in, term: f, cmd: cmd}
+}
+
+// Close closes the input of the pager and waits for it to exit.
+func (p *pager) Close() error {
+	p.WriteCloser.Close()
+	return p.cmd.Wait()
+}
+
+// Close implements the io.Closer interface.
+func (nopCloser) Close() error {
+	return nil
+}
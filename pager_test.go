@@ -0,0 +1,30 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"testing"
+)
+
+func TestPager(t *testing.T) {
+	app := New("myapp", "0.0.1", WithNoPagerFlag())
+	app.RuleFunc("log", "Show the log.", "", func(ctx context.Context) error {
+		w := Pager(ctx)
+		fmt.Fprintln(w, "commit 1")
+		return w.Close()
+	})
+
+	for _, args := range [][]string{{"log"}, {"-no-pager", "log"}} {
+		var stdout, stderr bytes.Buffer
+		code := app.RunWithArgs(args, &stdout, &stderr)
+		if want := "commit 1\n"; code != 0 || stdout.String() != want {
+			t.Errorf("%q\nhave %d %q\nwant %d %q", args, code, stdout.String(), 0, want)
+		}
+	}
+
+	var buf bytes.Buffer
+	if _, ok := app.pager(&buf, false).(nopCloser); !ok {
+		t.Errorf("paged output that is not a terminal")
+	}
+}
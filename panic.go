@@ -0,0 +1,123 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"runtime/debug"
+	"strings"
+)
+
+// ExitPanic is the exit code of invocations whose command panicked, matching
+// EX_SOFTWARE of sysexits.h.
+const ExitPanic = 70
+
+// A PanicReport describes a panic in a command, see OnPanic.
+type PanicReport struct {
+	// Command is the name of the command.
+	Command string
+	// Args are the arguments of the command with the values of secret flags
+	// redacted, see SecretVar.
+	Args []string
+	// Value is the value passed to panic.
+	Value interface{}
+	// Stack is the stack trace of the goroutine that panicked, without the
+	// frames of the reflection package.
+	Stack []byte
+}
+
+// OnPanic registers fn to be called with the report of a panic in a command,
+// such as to file a bug report. Panics are recovered, reported to the
+// standard error of the invocation and result in exit code 70.
+func (a *Application) OnPanic(fn func(ctx context.Context, report *PanicReport)) {
+	a.onPanic = append(a.onPanic, fn)
+}
+
+// recoverPanic reports a panic in the command of the rule, if there was one,
+// and sets code to ExitPanic. It must be deferred.
+func (a *Application) recoverPanic(ctx context.Context, r *rule, code *int) {
+	v := recover()
+	if v == nil {
+		return
+	}
+
+	report := &PanicReport{
+		Command: r.name,
+		Args:    redactArgs(r, ctxInvocation(ctx).args),
+		Value:   v,
+		Stack:   cleanStack(debug.Stack()),
+	}
+
+	fmt.Fprintf(Stderr(ctx), "panic: %v\n\ncommand: %s %s\nargs: %s\nversion: %s\n\n%s\n",
+		v, a.name, r.name, strings.Join(report.Args, " "), a.version, report.Stack)
+
+	for _, fn := range a.onPanic {
+		fn(ctx, report)
+	}
+
+	ctxInvocation(ctx).fail(fmt.Errorf("panic: %v", v))
+	*code = ExitPanic
+}
+
+// redactArgs returns a copy of args with the values of the secret flags of
+// the rule replaced.
+func redactArgs(r *rule, args []string) []string {
+	out := append([]string{}, args...)
+	for i := 0; i < len(out); i++ {
+		if out[i] == "--" {
+			break
+		}
+
+		name := strings.TrimLeft(out[i], "-")
+		if name == out[i] {
+			continue
+		}
+
+		value := ""
+		if j := strings.Index(name, "="); j >= 0 {
+			name, value = name[:j], name[j+1:]
+		}
+
+		f := r.options.Lookup(name)
+		if f == nil || !isSecret(f) {
+			continue
+		}
+
+		if value != "" {
+			out[i] = strings.TrimSuffix(out[i], value) + redacted
+		} else if i+1 < len(out) {
+			i++
+			out[i] = redacted
+		}
+	}
+
+	return out
+}
+
+// cleanStack removes the frames of the recovery and of the reflection
+// package from a stack trace.
+func cleanStack(stack []byte) []byte {
+	lines := bytes.Split(bytes.TrimSpace(stack), []byte("\n"))
+	if len(lines) == 0 {
+		return stack
+	}
+
+	// Frames are a function line followed by a file line.
+	out := [][]byte{lines[0]}
+	var frames [][]byte
+	for i := 1; i+1 < len(lines); i += 2 {
+		fn := lines[i]
+		if bytes.HasPrefix(fn, []byte("panic(")) {
+			frames = nil
+			continue
+		}
+
+		if bytes.HasPrefix(fn, []byte("reflect.")) {
+			continue
+		}
+
+		frames = append(frames, fn, lines[i+1])
+	}
+
+	return bytes.Join(append(out, frames...), []byte("\n"))
+}
@@ -0,0 +1,70 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestPanic(t *testing.T) {
+	app := New("myapp", "0.0.1")
+	err := app.RuleFunc("crash", "Crash.", "<name>", func(name string) int {
+		panic("boom " + name)
+	}, DefineFlags(func(flags *flag.FlagSet) {
+		SecretVar(flags, "token", "", "API token.")
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var report *PanicReport
+	app.OnPanic(func(ctx context.Context, r *PanicReport) {
+		report = r
+	})
+
+	var stdout, stderr bytes.Buffer
+	code := app.RunWithArgs([]string{"crash", "-token", "hunter2", "a"}, &stdout, &stderr)
+	if code != ExitPanic {
+		t.Errorf("exit code\nhave %d\nwant %d", code, ExitPanic)
+	}
+
+	if report == nil {
+		t.Fatal("OnPanic not called")
+	}
+
+	if want := []string{"-token", "********", "a"}; !reflect.DeepEqual(report.Args, want) {
+		t.Errorf("args\nhave %q\nwant %q", report.Args, want)
+	}
+
+	if report.Command != "crash" || report.Value != "boom a" {
+		t.Errorf("report\nhave %q %v", report.Command, report.Value)
+	}
+
+	out := stderr.String()
+	if !strings.HasPrefix(out, "panic: boom a\n\ncommand: myapp crash\nargs: -token ******** a\n") {
+		t.Errorf("report\n%s", out)
+	}
+
+	if strings.Contains(out, "hunter2") || strings.Contains(out, "reflect.") || strings.Contains(out, "runtime/debug") {
+		t.Errorf("stack not cleaned\n%s", out)
+	}
+
+	if !strings.Contains(out, "TestPanic") {
+		t.Errorf("stack missing command frame\n%s", out)
+	}
+}
+
+func TestRedactArgs(t *testing.T) {
+	r := &rule{options: flag.NewFlagSet("test", flag.ContinueOnError)}
+	SecretVar(r.options, "token", "", "")
+	r.options.Bool("v", false, "")
+
+	have := redactArgs(r, []string{"-v", "--token=abc", "x", "--", "-token", "y"})
+	want := []string{"-v", "--token=********", "x", "--", "-token", "y"}
+	if !reflect.DeepEqual(have, want) {
+		t.Errorf("redact\nhave %q\nwant %q", have, want)
+	}
+}
@@ -0,0 +1,79 @@
+package cli
+
+import "flag"
+
+// A ParseMode controls where the flags of a command may appear, see
+// SetParseMode.
+type ParseMode int
+
+// Parse modes.
+const (
+	// ParseDefault uses the ParseMode of the Application, or ParsePOSIX.
+	ParseDefault ParseMode = iota
+	// ParsePOSIX ends flags at the first positional argument, such that the
+	// arguments following it are passed verbatim even if they begin with a
+	// dash, as for "app exec ls -l".
+	ParsePOSIX
+	// ParseInterspersed accepts flags among and after positional arguments,
+	// as for "app copy src dst -force", in the manner of GNU getopt. Flags end
+	// at a -- argument.
+	ParseInterspersed
+)
+
+// SetParseMode sets the ParseMode of the commands of the Application. The
+// default is ParsePOSIX. Commands may have their own, see ParseFlags.
+func (a *Application) SetParseMode(mode ParseMode) {
+	a.parseMode = mode
+}
+
+// ParseFlags is a RuleOption setting the ParseMode of the command,
+// overriding that of the Application. Commands passing their arguments to
+// another program should use ParsePOSIX.
+func ParseFlags(mode ParseMode) RuleOption {
+	return func(r *rule) {
+		r.parseMode = mode
+	}
+}
+
+// parseFlags parses the flags of the rule from args in its ParseMode, or the
+// default mode, and returns the positional arguments.
+func (r *rule) parseFlags(args []string, mode ParseMode) ([]string, error) {
+	if r.parseMode != ParseDefault {
+		mode = r.parseMode
+	}
+
+	if mode != ParseInterspersed {
+		err := r.options.Parse(args)
+		return r.options.Args(), err
+	}
+
+	return parseInterspersed(r.options, args)
+}
+
+// parseInterspersed parses flags from args, continuing past each positional
+// argument until the end of the arguments or a -- argument, and returns the
+// positional arguments.
+func parseInterspersed(flags *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for len(args) > 0 {
+		err := flags.Parse(args)
+		if err != nil {
+			return nil, err
+		}
+
+		rest := flags.Args()
+		consumed := len(args) - len(rest)
+		if consumed > 0 && args[consumed-1] == "--" {
+			return append(positional, rest...), nil
+		}
+
+		if len(rest) == 0 {
+			break
+		}
+
+		positional = append(positional, rest[0])
+		args = rest[1:]
+	}
+
+	return positional, nil
+}
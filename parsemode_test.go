@@ -0,0 +1,45 @@
+package cli
+
+import (
+	"flag"
+	"reflect"
+	"testing"
+)
+
+func TestParseMode(t *testing.T) {
+	var (
+		force bool
+		have  []string
+	)
+	define := DefineFlags(func(flags *flag.FlagSet) {
+		flags.BoolVar(&force, "force", false, "Overwrite files.")
+	})
+	run := func(args []string) {
+		have = args
+	}
+
+	app := New("myapp", "0.0.1")
+	app.SetParseMode(ParseInterspersed)
+	app.RuleFunc("copy", "Copy files.", "<src>...", run, define)
+	app.RuleFunc("exec", "Run a program.", "<cmd> [<args>...]", run, define, ParseFlags(ParsePOSIX))
+
+	tests := []struct {
+		args  []string
+		force bool
+		want  []string
+	}{
+		{[]string{"copy", "src", "dst", "-force"}, true, []string{"src", "dst"}},
+		{[]string{"copy", "src", "-force", "dst"}, true, []string{"src", "dst"}},
+		{[]string{"copy", "src", "--", "-force", "dst"}, false, []string{"src", "-force", "dst"}},
+		{[]string{"exec", "ls", "-force"}, false, []string{"ls", "-force"}},
+		{[]string{"exec", "-force", "ls", "-l"}, true, []string{"ls", "-l"}},
+	}
+
+	for _, tt := range tests {
+		force, have = false, nil
+		code := app.Dispatch(tt.args)
+		if code != 0 || force != tt.force || !reflect.DeepEqual(have, tt.want) {
+			t.Errorf("%q\nhave %d %t %q\nwant %d %t %q", tt.args, code, force, have, 0, tt.force, tt.want)
+		}
+	}
+}
@@ -0,0 +1,244 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"plugin"
+	"reflect"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+)
+
+// A PluginManifest describes the command provided by a plugin found by
+// PluginDir.
+type PluginManifest struct {
+	// Name is the name of the command.
+	Name string `json:"name"`
+	// Synopsis is the short description of the command.
+	Synopsis string `json:"synopsis"`
+	// Args is the arguments string of the command, see Rule.
+	Args string `json:"args"`
+}
+
+// A pluginDir is a directory of plugins, see PluginDir.
+type pluginDir struct {
+	path   string
+	mu     sync.Mutex
+	loaded map[string]time.Time
+	failed map[string]time.Time
+}
+
+// PluginDir registers the plugins in dir as commands, for extending the
+// Application without recompiling it. A plugin is either an executable, run
+// like an External command, or a Go plugin with the extension .so exporting
+// a variable named Command holding a command, such as a Runner. Either may
+// be described by a manifest with the same name and the extension .json,
+// such as deploy.json for deploy or deploy.so, holding a PluginManifest.
+// Without one, the command is named after the file without its extension,
+// and takes any arguments.
+//
+// The directory is scanned again as each command is dispatched, such that
+// plugins that are added, changed or removed while the Application runs,
+// such as within a Shell, are reflected in the usage, completion and
+// dispatch. A Go plugin cannot be unloaded, so changes to it take effect
+// once the Application restarts. Plugins do not replace the commands
+// registered by the Application. If Plugins was enabled with verify set,
+// plugins must be trusted by the TrustStore. Plugins that fail to load are
+// skipped with a warning, once until they change.
+func (a *Application) PluginDir(dir string) {
+	a.pluginDir = &pluginDir{
+		path:   dir,
+		loaded: make(map[string]time.Time),
+		failed: make(map[string]time.Time),
+	}
+}
+
+// scanPlugins registers the plugins in the plugin directory that were added
+// or changed since it was last scanned and removes those that are gone,
+// warning of plugins that fail to load to w once until they change.
+func (a *Application) scanPlugins(w io.Writer) {
+	d := a.pluginDir
+	if d == nil {
+		return
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	files, err := ioutil.ReadDir(d.path)
+	if err != nil && !os.IsNotExist(err) {
+		fmt.Fprintf(w, "%s: plugins: %v\n", a.messages.Warning, err)
+		return
+	}
+
+	found := make(map[string]bool)
+	for _, fi := range files {
+		ext := filepath.Ext(fi.Name())
+		if fi.IsDir() || ext == ".json" || ext != ".so" && !isExecutable(fi) {
+			continue
+		}
+
+		path := filepath.Join(d.path, fi.Name())
+		m, modTime, err := readManifest(path)
+		if _, ok := d.loaded[m.Name]; err == nil && !ok {
+			err = a.checkName(m.Name)
+		}
+		if err == nil && found[m.Name] {
+			err = fmt.Errorf("duplicate command %q", m.Name)
+		}
+
+		if modTime.Before(fi.ModTime()) {
+			modTime = fi.ModTime()
+		}
+
+		if loaded, ok := d.loaded[m.Name]; err == nil && ok && !modTime.After(loaded) {
+			found[m.Name] = true
+			continue
+		}
+
+		if err == nil {
+			err = a.loadPlugin(path, m)
+		}
+		if err != nil {
+			if failed, ok := d.failed[path]; !ok || !failed.Equal(modTime) {
+				fmt.Fprintf(w, "%s: plugin %s: %v\n", a.messages.Warning, fi.Name(), err)
+				d.failed[path] = modTime
+			}
+
+			continue
+		}
+
+		found[m.Name] = true
+		d.loaded[m.Name] = modTime
+		delete(d.failed, path)
+	}
+
+	for name := range d.loaded {
+		if !found[name] {
+			a.remove(name)
+			delete(d.loaded, name)
+		}
+	}
+}
+
+// loadPlugin registers the command of the plugin at path.
+func (a *Application) loadPlugin(path string, m PluginManifest) error {
+	if a.verify {
+		store, err := a.TrustStore()
+		if err != nil {
+			return err
+		}
+
+		err = store.Verify(path)
+		if err != nil {
+			return err
+		}
+	}
+
+	var c command = &External{Path: path, Description: m.Synopsis}
+	if filepath.Ext(path) == ".so" {
+		var err error
+		c, err = openPlugin(path)
+		if err != nil {
+			return err
+		}
+	}
+
+	r := &rule{name: m.Name, arguments: m.Args}
+	err := r.bind(c)
+	if err != nil {
+		return err
+	}
+
+	return a.add(r, true)
+}
+
+// readManifest returns the manifest of the plugin at path and the time it
+// was modified, or the defaults if it has none.
+func readManifest(path string) (PluginManifest, time.Time, error) {
+	name := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	m := PluginManifest{Name: name, Synopsis: "Plugin " + path + ".", Args: "[<args>...]"}
+	manifest := strings.TrimSuffix(path, filepath.Ext(path)) + ".json"
+	fi, err := os.Stat(manifest)
+	if os.IsNotExist(err) {
+		return m, time.Time{}, nil
+	}
+	if err != nil {
+		return m, time.Time{}, err
+	}
+
+	data, err := ioutil.ReadFile(manifest)
+	if err != nil {
+		return m, time.Time{}, err
+	}
+
+	err = json.Unmarshal(data, &m)
+	if err != nil {
+		return m, time.Time{}, fmt.Errorf("%s: %v", manifest, err)
+	}
+
+	return m, fi.ModTime(), nil
+}
+
+// openPlugin returns the command held by the variable named Command of the
+// Go plugin at path.
+func openPlugin(path string) (command, error) {
+	p, err := plugin.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	sym, err := p.Lookup("Command")
+	if err != nil {
+		return nil, err
+	}
+
+	// Lookup returns a pointer to the variable.
+	v := reflect.ValueOf(sym)
+	if v.Kind() == reflect.Ptr && !v.IsNil() {
+		v = v.Elem()
+	}
+
+	c, ok := v.Interface().(command)
+	if !ok {
+		return nil, fmt.Errorf("variable Command of type %v is not a command", v.Type())
+	}
+
+	return c, nil
+}
+
+// isExecutable reports whether the file may be executed.
+func isExecutable(fi os.FileInfo) bool {
+	if runtime.GOOS == "windows" {
+		return strings.EqualFold(filepath.Ext(fi.Name()), ".exe")
+	}
+
+	return fi.Mode().IsRegular() && fi.Mode()&0111 != 0
+}
+
+// remove removes the named rule from the Application.
+func (a *Application) remove(name string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	delete(a.rules, name)
+	for i, n := range a.names {
+		if n == name {
+			a.names = append(a.names[:i], a.names[i+1:]...)
+			break
+		}
+	}
+
+	for i, n := range a.order {
+		if n == name {
+			a.order = append(a.order[:i], a.order[i+1:]...)
+			break
+		}
+	}
+}
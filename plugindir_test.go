@@ -0,0 +1,63 @@
+//go:build !windows
+
+package cli
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPluginDir(t *testing.T) {
+	dir := t.TempDir()
+	script := []byte("#!/bin/sh\necho \"$0 $*\"\n")
+	ioutil.WriteFile(filepath.Join(dir, "hello"), script, 0755)
+	ioutil.WriteFile(filepath.Join(dir, "hello.json"), []byte(`{"name": "greet", "synopsis": "Greet someone.", "args": "<name>"}`), 0644)
+	ioutil.WriteFile(filepath.Join(dir, "plain"), script, 0755)
+	ioutil.WriteFile(filepath.Join(dir, "record"), script, 0755)
+	ioutil.WriteFile(filepath.Join(dir, "notes.txt"), script, 0644)
+
+	app := New("myapp", "0.0.1")
+	app.Rule(&runRecord{}, "record", "[<a>] [<b>]")
+	app.PluginDir(dir)
+
+	run := func(args ...string) (string, string) {
+		var stdout, stderr bytes.Buffer
+		app.RunWithArgs(args, &stdout, &stderr)
+		return stdout.String(), stderr.String()
+	}
+
+	stdout, stderr := run("greet", "bob")
+	if want := filepath.Join(dir, "hello") + " bob\n"; stdout != want {
+		t.Errorf("greet\nhave %q\nwant %q", stdout, want)
+	}
+
+	if want := "Warning: plugin record: rule: duplicate command \"record\"\n"; stderr != want {
+		t.Errorf("warnings\nhave %q\nwant %q", stderr, want)
+	}
+
+	_, stderr = run("help")
+	for _, want := range []string{"greet <name>", "Greet someone.", "plain [<args>...]", "Plugin " + filepath.Join(dir, "plain") + "."} {
+		if !strings.Contains(stderr, want) {
+			t.Errorf("help\nhave %s\nwant %s", stderr, want)
+		}
+	}
+
+	later := time.Now().Add(time.Minute)
+	ioutil.WriteFile(filepath.Join(dir, "hello.json"), []byte(`{"name": "greet", "synopsis": "Say hello.", "args": "<name>"}`), 0644)
+	os.Chtimes(filepath.Join(dir, "hello.json"), later, later)
+	os.Remove(filepath.Join(dir, "plain"))
+	_, stderr = run("help")
+	if !strings.Contains(stderr, "Say hello.") || strings.Contains(stderr, "plain") {
+		t.Errorf("reload\nhave %s", stderr)
+	}
+
+	_, stderr = run("plain")
+	if !strings.HasPrefix(stderr, "Error: invalid command plain\n") {
+		t.Errorf("removed\nhave %q", stderr)
+	}
+}
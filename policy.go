@@ -0,0 +1,154 @@
+package cli
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// ExitPolicy is the exit code of invocations denied by the policy file, or by
+// a Guard. It matches EX_NOPERM of sysexits.h.
+const ExitPolicy = 77
+
+// A Policy restricts the commands and flags that may be used, as configured
+// by an administrator in a JSON file such as:
+//
+//	{
+//	  "allow": ["status", "deploy"],
+//	  "deny": ["destroy"],
+//	  "deny_flags": {"deploy": ["force"], "*": ["insecure-skip-verify"]}
+//	}
+//
+// If Allow is not empty, only the listed commands may run. Commands listed in
+// Deny may never run. DenyFlags lists the flags that may not be set for each
+// command, with the key * applying to all commands, and global flags denied
+// like those of the command. Listing a group of commands, such as remote,
+// applies to each command in it, such as remote add. The help command is
+// always allowed.
+type Policy struct {
+	Allow     []string            `json:"allow"`
+	Deny      []string            `json:"deny"`
+	DenyFlags map[string][]string `json:"deny_flags"`
+}
+
+// PolicyFile sets the path of the policy file of the Application, replacing
+// the default of policy.json in the system configuration directory: /etc/name
+// on Unix and %ProgramData%\name on Windows. An empty path disables policies.
+func (a *Application) PolicyFile(path string) {
+	a.policy = &path
+}
+
+// policyPath returns the path of the policy file.
+func (a *Application) policyPath() string {
+	if a.policy != nil {
+		return *a.policy
+	}
+
+	if runtime.GOOS == "windows" {
+		dir := os.Getenv("ProgramData")
+		if dir == "" {
+			return ""
+		}
+
+		return filepath.Join(dir, a.name, "policy.json")
+	}
+
+	return filepath.Join("/etc", a.name, "policy.json")
+}
+
+// loadPolicy returns the policy of the Application, or nil if there is none.
+func (a *Application) loadPolicy() (*Policy, error) {
+	path := a.policyPath()
+	if path == "" {
+		return nil, nil
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var p Policy
+	err = json.Unmarshal(data, &p)
+	if err != nil {
+		return nil, fmt.Errorf("policy: %s: %v", path, err)
+	}
+
+	return &p, nil
+}
+
+// check returns an error if the policy denies running the named command with
+// the flags that were set in any of the sets.
+func (p *Policy) check(name string, sets ...*flag.FlagSet) error {
+	if name == "help" {
+		return nil
+	}
+
+	groups := commandGroups(name)
+	if len(p.Allow) > 0 && !containsAny(p.Allow, groups) {
+		return fmt.Errorf("command %s is not allowed by policy", name)
+	}
+
+	if containsAny(p.Deny, groups) {
+		return fmt.Errorf("command %s is denied by policy", name)
+	}
+
+	denied := p.DenyFlags["*"]
+	for _, group := range groups {
+		denied = append(denied, p.DenyFlags[group]...)
+	}
+
+	var err error
+	for _, flags := range sets {
+		flags.Visit(func(f *flag.Flag) {
+			flag := logicalName(f)
+			if err == nil && contains(denied, flag) {
+				err = fmt.Errorf("flag -%s is denied by policy", flag)
+			}
+		})
+	}
+
+	return err
+}
+
+// commandGroups returns the command name followed by the names of the groups
+// it belongs to, such as remote add and remote.
+func commandGroups(name string) []string {
+	groups := []string{name}
+	for i := strings.LastIndex(name, " "); i > 0; i = strings.LastIndex(name, " ") {
+		name = name[:i]
+		groups = append(groups, name)
+	}
+
+	return groups
+}
+
+// containsAny reports whether list contains any of values.
+func containsAny(list, values []string) bool {
+	for _, s := range values {
+		if contains(list, s) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// contains reports whether list contains s.
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+
+	return false
+}
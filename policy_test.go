@@ -0,0 +1,50 @@
+package cli
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+func TestPolicy(t *testing.T) {
+	f, err := ioutil.TempFile("", "cli-policy")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer os.Remove(f.Name())
+	f.WriteString(`{"allow": ["full", "record", "remote"], "deny": ["record", "remote rm"], "deny_flags": {"full": ["number"], "remote": ["number"], "*": ["yes"]}}`)
+	f.Close()
+
+	app := New("myapp", "0.0.1", WithYesFlag())
+	app.PolicyFile(f.Name())
+	app.Rule(&runFull{}, "full", "<arg1> <arg2> [<extra>]")
+	app.Rule(&runRecord{}, "record", "<a> <b>")
+	remote := app.Group("remote", "Manage remotes.")
+	remote.Rule(&runFull{}, "add", "<arg1> <arg2> [<extra>]")
+	remote.Rule(&runFull{}, "rm", "<arg1> <arg2> [<extra>]")
+	app.stdout = &bytes.Buffer{}
+	app.stderr = &bytes.Buffer{}
+
+	tests := []struct {
+		args []string
+		want int
+	}{
+		{[]string{"full"}, 2},
+		{[]string{"full", "-number", "1"}, ExitPolicy},
+		{[]string{"record"}, ExitPolicy},
+		{[]string{"version"}, ExitPolicy},
+		{[]string{"-yes", "full"}, ExitPolicy},
+		{[]string{"remote", "add"}, 2},
+		{[]string{"remote", "add", "-number", "1"}, ExitPolicy},
+		{[]string{"remote", "rm"}, ExitPolicy},
+		{[]string{"help"}, 0},
+	}
+
+	for _, tt := range tests {
+		have := app.Dispatch(tt.args)
+		if have != tt.want {
+			t.Errorf("%v\nhave %d\nwant %d", tt.args, have, tt.want)
+		}
+	}
+}
@@ -0,0 +1,135 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"text/tabwriter"
+)
+
+// A Printer renders the results of a command to its standard output, as
+// indented JSON if -output json or -json was given and for people
+// otherwise, see StandardFlags. Commands with a RunStructured method rather
+// than a Run method have their result printed with a Printer.
+type Printer struct {
+	w    io.Writer
+	json bool
+	csv  bool
+}
+
+// NewPrinter returns a Printer for the invocation of the command that
+// received ctx.
+func NewPrinter(ctx context.Context) *Printer {
+	return &Printer{w: Stdout(ctx), json: JSON(ctx), csv: outputFormat(ctx) == "csv"}
+}
+
+// Print prints v. In text, slices of structs are printed as a table of their
+// exported fields, structs as a list of their exported fields and other
+// values with fmt.Println.
+func (p *Printer) Print(v interface{}) error {
+	if p.json {
+		enc := json.NewEncoder(p.w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(v)
+	}
+
+	if _, ok := v.(fmt.Stringer); ok {
+		_, err := fmt.Fprintln(p.w, v)
+		return err
+	}
+
+	rv := reflect.Indirect(reflect.ValueOf(v))
+	switch {
+	case rv.Kind() == reflect.Struct:
+		tw := tabwriter.NewWriter(p.w, 0, 0, 2, ' ', 0)
+		for i, name := range fieldNames(rv.Type()) {
+			fmt.Fprintf(tw, "%s:\t%v\n", name, exported(rv)[i])
+		}
+
+		return tw.Flush()
+	case (rv.Kind() == reflect.Slice || rv.Kind() == reflect.Array) && structElem(rv.Type()):
+		header := fieldNames(indirectType(rv.Type().Elem()))
+		rows := make([][]string, rv.Len())
+		for i := range rows {
+			for _, field := range exported(reflect.Indirect(rv.Index(i))) {
+				rows[i] = append(rows[i], fmt.Sprint(field))
+			}
+		}
+
+		return p.Table(header, rows)
+	}
+
+	_, err := fmt.Fprintln(p.w, v)
+	return err
+}
+
+// Table prints rows of cells under the header, as an aligned table in text,
+// as an array of objects keyed by the header in JSON and as comma-separated
+// values in CSV, see Table.
+func (p *Printer) Table(header []string, rows [][]string) error {
+	t := &Table{Header: header, w: p.w, format: "text", rows: rows}
+	if p.json {
+		t.format = "json"
+	} else if p.csv {
+		t.format = "csv"
+	}
+
+	return t.Flush()
+}
+
+// fieldNames returns the names of the exported fields of a struct type.
+func fieldNames(t reflect.Type) []string {
+	var names []string
+	for i := 0; i < t.NumField(); i++ {
+		if t.Field(i).PkgPath == "" {
+			names = append(names, t.Field(i).Name)
+		}
+	}
+
+	return names
+}
+
+// exported returns the values of the exported fields of a struct, or none if
+// v is the zero Value.
+func exported(v reflect.Value) []interface{} {
+	if !v.IsValid() {
+		return nil
+	}
+
+	var values []interface{}
+	for i := 0; i < v.NumField(); i++ {
+		if v.Type().Field(i).PkgPath == "" {
+			values = append(values, v.Field(i).Interface())
+		}
+	}
+
+	return values
+}
+
+// structElem reports whether the elements of a slice or array type are
+// structs or pointers to structs.
+func structElem(t reflect.Type) bool {
+	return indirectType(t.Elem()).Kind() == reflect.Struct
+}
+
+// indirectType returns the type pointed to by t, if t is a pointer.
+func indirectType(t reflect.Type) reflect.Type {
+	if t.Kind() == reflect.Ptr {
+		return t.Elem()
+	}
+
+	return t
+}
+
+// isNil reports whether v is a nil interface, pointer, map, slice or
+// function.
+func isNil(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Interface, reflect.Ptr, reflect.Map, reflect.Slice, reflect.Func, reflect.Chan:
+		return v.IsNil()
+	}
+
+	return !v.IsValid()
+}
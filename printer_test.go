@@ -0,0 +1,80 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"testing"
+)
+
+type runList struct {
+	*NullFlags
+}
+
+type listItem struct {
+	Name string `json:"name"`
+	Size int    `json:"size"`
+	note string
+}
+
+func TestRunStructured(t *testing.T) {
+	app := New("myapp", "0.0.1")
+	app.StandardFlags()
+	app.Rule(&runList{}, "list", "[<fail>]")
+
+	tests := []struct {
+		args   []string
+		code   int
+		stdout string
+	}{
+		{[]string{"list"}, 0, "Name   Size\nalpha  1\nbeta   22\n"},
+		{[]string{"-output", "json", "list"}, 0, "[\n  {\n    \"name\": \"alpha\",\n    \"size\": 1\n  },\n  {\n    \"name\": \"beta\",\n    \"size\": 22\n  }\n]\n"},
+		{[]string{"list", "fail"}, 3, ""},
+		{[]string{"-output", "xml", "list"}, ExitUsage, ""},
+	}
+
+	for _, tt := range tests {
+		var stdout, stderr bytes.Buffer
+		code := app.RunWithArgs(tt.args, &stdout, &stderr)
+		if code != tt.code || stdout.String() != tt.stdout {
+			t.Errorf("%q\nhave %d %q\nwant %d %q", tt.args, code, stdout.String(), tt.code, tt.stdout)
+		}
+	}
+}
+
+func TestPrinterTable(t *testing.T) {
+	var buf bytes.Buffer
+	header := []string{"ID", "State"}
+	rows := [][]string{{"1", "running"}, {"22", "stopped"}}
+
+	p := &Printer{w: &buf}
+	p.Table(header, rows)
+	if want := "ID  State\n1   running\n22  stopped\n"; buf.String() != want {
+		t.Errorf("text\nhave %q\nwant %q", buf.String(), want)
+	}
+
+	buf.Reset()
+	p.json = true
+	p.Table(header, rows[:1])
+	if want := "[\n  {\n    \"ID\": \"1\",\n    \"State\": \"running\"\n  }\n]\n"; buf.String() != want {
+		t.Errorf("json\nhave %q\nwant %q", buf.String(), want)
+	}
+
+	buf.Reset()
+	p.json = false
+	p.Print(&listItem{Name: "alpha", Size: 1})
+	if want := "Name:  alpha\nSize:  1\n"; buf.String() != want {
+		t.Errorf("struct\nhave %q\nwant %q", buf.String(), want)
+	}
+}
+
+func (c *runList) RunStructured(ctx context.Context, fail string) (interface{}, int) {
+	if fail != "" {
+		return nil, 3
+	}
+
+	return []listItem{{"alpha", 1, "x"}, {"beta", 22, "y"}}, 0
+}
+
+func (c *runList) String() string {
+	return "list items"
+}
@@ -0,0 +1,76 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// isPrivileged is Privileged, replaced in tests.
+var isPrivileged = Privileged
+
+// RequirePrivilege is a RuleOption marking the command as requiring root, or
+// Administrator on Windows. Invocations by other users fail with instructions
+// to run the command again with elevated privileges and exit with ExitPolicy,
+// unless the Application has been told to Elevate.
+func RequirePrivilege() RuleOption {
+	return func(r *rule) {
+		r.privilege = true
+	}
+}
+
+// Elevate sets whether unprivileged invocations of commands that
+// RequirePrivilege are run again under sudo, which may prompt the user for
+// their password. Elevation is not supported on Windows.
+func (a *Application) Elevate(enabled bool) {
+	a.elevate = enabled
+}
+
+// Privileged reports whether the process is running as root, or as an
+// Administrator on Windows.
+func Privileged() bool {
+	return privileged()
+}
+
+// elevated runs the invocation with the arguments args, as they were given
+// to the dispatch, again under sudo and returns its exit code, or explains
+// how to do so if it may not.
+func (a *Application) elevated(ctx context.Context, name string, args []string) int {
+	w := Stderr(ctx)
+	if runtime.GOOS == "windows" {
+		a.errorf(w, "%s: "+a.messages.RequiresAdministrator, name)
+		return ExitPolicy
+	}
+
+	sudo, err := exec.LookPath("sudo")
+	if !a.elevate || err != nil {
+		a.errorf(w, "%s: "+a.messages.RequiresRoot, name, a.name, strings.Join(args, " "))
+		return ExitPolicy
+	}
+
+	exe, err := os.Executable()
+	if err != nil {
+		a.errorf(w, "%s: %v", name, err)
+		return 1
+	}
+
+	cmd := exec.CommandContext(ctx, sudo, append([]string{"--", exe}, args...)...)
+	cmd.Stdin = Stdin(ctx)
+	cmd.Stdout = Stdout(ctx)
+	cmd.Stderr = w
+	err = cmd.Run()
+
+	var exit *exec.ExitError
+	if errors.As(err, &exit) {
+		return exit.ExitCode()
+	}
+	if err != nil {
+		a.errorf(w, "%s: %v", name, err)
+		return 1
+	}
+
+	return 0
+}
@@ -0,0 +1,10 @@
+//go:build !windows
+
+package cli
+
+import "os"
+
+// privileged reports whether the effective user is root.
+func privileged() bool {
+	return os.Geteuid() == 0
+}
@@ -0,0 +1,34 @@
+package cli
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestRequirePrivilege(t *testing.T) {
+	defer func() { isPrivileged = Privileged }()
+
+	var stderr bytes.Buffer
+	app := New("myapp", "0.0.1", WithNoCacheFlag())
+	app.stderr = &stderr
+	cmd := &runRecord{}
+	app.Rule(cmd, "install", "<a> <b>", RequirePrivilege())
+
+	isPrivileged = func() bool { return false }
+	code := app.Dispatch([]string{"-no-cache", "-number", "3", "install", "pkg", "v1"})
+	if code != ExitPolicy {
+		t.Errorf("unprivileged exit code\nhave %d\nwant %d", code, ExitPolicy)
+	}
+
+	want := "sudo myapp -no-cache -number 3 install pkg v1"
+	if !strings.Contains(stderr.String(), want) {
+		t.Errorf("instructions\nhave %q\nwant %q", stderr.String(), want)
+	}
+
+	isPrivileged = func() bool { return true }
+	code = app.Dispatch([]string{"install", "pkg", "v1"})
+	if code != 0 {
+		t.Errorf("privileged exit code\nhave %d\nwant %d", code, 0)
+	}
+}
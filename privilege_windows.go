@@ -0,0 +1,15 @@
+package cli
+
+import "os"
+
+// privileged reports whether the process is elevated. Only Administrators may
+// open the physical drive.
+func privileged() bool {
+	f, err := os.Open(`\\.\PHYSICALDRIVE0`)
+	if err != nil {
+		return false
+	}
+
+	f.Close()
+	return true
+}
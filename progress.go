@@ -0,0 +1,266 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+)
+
+// progressInterval is the least time between lines of progress printed when
+// the standard error is not a terminal.
+const progressInterval = 5 * time.Second
+
+// clearLine returns the cursor to the start of the line and erases it.
+const clearLine = "\r\033[K"
+
+// A ProgressBar reports the progress of an operation of known size to the
+// standard error of an invocation. On a terminal, a bar is redrawn in place
+// as progress is made. Otherwise a line is printed at most every few
+// seconds. Nothing is printed if the -quiet flag was given.
+//
+// A ProgressBar is an io.Writer counting the bytes written to it, so that it
+// may be used with io.TeeReader or io.MultiWriter to report a copy.
+type ProgressBar struct {
+	mu      sync.Mutex
+	w       io.Writer
+	tty     bool
+	quiet   bool
+	label   string
+	total   int64
+	current int64
+	last    time.Time
+	now     func() time.Time
+}
+
+// A Spinner reports that an operation of unknown length is still running to
+// the standard error of an invocation. On a terminal, a spinner is animated
+// beside the latest status. Otherwise a line is printed when it starts, at
+// most every few seconds while it runs and when it stops. Nothing is printed
+// if the -quiet flag was given.
+//
+// A Spinner is an io.Writer whose status is the last line written to it.
+type Spinner struct {
+	mu       sync.Mutex
+	w        io.Writer
+	tty      bool
+	quiet    bool
+	label    string
+	status   string
+	interval time.Duration
+	start    time.Time
+	frame    int
+	stop     chan struct{}
+	done     chan struct{}
+}
+
+// spinnerFrames are drawn in turn by a Spinner on a terminal.
+var spinnerFrames = []string{"|", "/", "-", `\`}
+
+// NewProgressBar returns a ProgressBar for the invocation of the command that
+// received ctx, reporting progress towards total.
+func NewProgressBar(ctx context.Context, label string, total int64) *ProgressBar {
+	return &ProgressBar{
+		w:     Stderr(ctx),
+		tty:   isTerminal(Stderr(ctx)),
+		quiet: Quiet(ctx),
+		label: label,
+		total: total,
+		now:   time.Now,
+	}
+}
+
+// Add adds n to the progress.
+func (p *ProgressBar) Add(n int64) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.current += n
+	p.draw(false)
+}
+
+// Set sets the progress to n.
+func (p *ProgressBar) Set(n int64) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.current = n
+	p.draw(false)
+}
+
+// Write implements the io.Writer interface by adding the length of b to the
+// progress.
+func (p *ProgressBar) Write(b []byte) (int, error) {
+	p.Add(int64(len(b)))
+	return len(b), nil
+}
+
+// Done reports the final progress and ends the line.
+func (p *ProgressBar) Done() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.draw(true)
+}
+
+// draw reports the progress, on a new line unless on a terminal or within
+// the interval since the last line, unless final.
+func (p *ProgressBar) draw(final bool) {
+	if p.quiet {
+		return
+	}
+
+	if p.tty {
+		fmt.Fprintf(p.w, "%s%s %s", clearLine, p.label, p.bar(30))
+		if final {
+			fmt.Fprintln(p.w)
+		}
+		return
+	}
+
+	now := p.now()
+	if !final && !p.last.IsZero() && now.Sub(p.last) < progressInterval {
+		return
+	}
+
+	p.last = now
+	fmt.Fprintf(p.w, "%s: %s\n", p.label, p.percent())
+}
+
+// bar returns a bar of the given width followed by the percentage.
+func (p *ProgressBar) bar(width int) string {
+	if p.total <= 0 {
+		return p.percent()
+	}
+
+	filled := int(int64(width) * p.done() / p.total)
+	bar := strings.Repeat("=", filled) + strings.Repeat(" ", width-filled)
+	return fmt.Sprintf("[%s] %s", bar, p.percent())
+}
+
+// percent describes the progress as a percentage of the total, if it is
+// known, and a count.
+func (p *ProgressBar) percent() string {
+	if p.total <= 0 {
+		return fmt.Sprintf("%d", p.current)
+	}
+
+	return fmt.Sprintf("%d%% (%d/%d)", 100*p.done()/p.total, p.current, p.total)
+}
+
+// done returns the progress, at most the total.
+func (p *ProgressBar) done() int64 {
+	if p.current > p.total {
+		return p.total
+	}
+
+	return p.current
+}
+
+// NewSpinner returns a Spinner for the invocation of the command that
+// received ctx. The spinner does not report anything until it is started.
+func NewSpinner(ctx context.Context, label string) *Spinner {
+	s := &Spinner{
+		w:        Stderr(ctx),
+		tty:      isTerminal(Stderr(ctx)),
+		quiet:    Quiet(ctx),
+		label:    label,
+		interval: progressInterval,
+	}
+	if s.tty {
+		s.interval = 100 * time.Millisecond
+	}
+
+	return s
+}
+
+// Start starts reporting that the operation is running.
+func (s *Spinner) Start() {
+	if s.quiet {
+		return
+	}
+
+	s.mu.Lock()
+	s.start = time.Now()
+	s.stop = make(chan struct{})
+	s.done = make(chan struct{})
+	s.draw()
+	s.mu.Unlock()
+
+	go s.spin(s.stop, s.done)
+}
+
+// Write implements the io.Writer interface by setting the status to the
+// last line of b.
+func (s *Spinner) Write(b []byte) (int, error) {
+	lines := bytes.Split(bytes.TrimRight(b, "\r\n"), []byte("\n"))
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.status = string(lines[len(lines)-1])
+	return len(b), nil
+}
+
+// Stop stops reporting and prints the result of the operation, such as
+// "done" or "failed".
+func (s *Spinner) Stop(result string) {
+	if s.quiet || s.stop == nil {
+		return
+	}
+
+	close(s.stop)
+	<-s.done
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.stop = nil
+	if s.tty {
+		fmt.Fprint(s.w, clearLine)
+	}
+
+	fmt.Fprintf(s.w, "%s... %s\n", s.label, result)
+}
+
+// spin redraws the spinner every interval until stop is closed.
+func (s *Spinner) spin(stop <-chan struct{}, done chan<- struct{}) {
+	defer close(done)
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			s.mu.Lock()
+			s.frame++
+			s.draw()
+			s.mu.Unlock()
+		}
+	}
+}
+
+// draw reports that the operation is running.
+func (s *Spinner) draw() {
+	message := s.label + "..."
+	if s.status != "" {
+		message += " " + s.status
+	}
+
+	if s.tty {
+		frame := spinnerFrames[s.frame%len(spinnerFrames)]
+		fmt.Fprintf(s.w, "%s%s %s", clearLine, frame, message)
+		return
+	}
+
+	if s.frame > 0 {
+		message += fmt.Sprintf(" (%s)", time.Since(s.start).Round(time.Second))
+	}
+
+	fmt.Fprintln(s.w, message)
+}
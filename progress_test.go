@@ -0,0 +1,76 @@
+package cli
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestProgressBar(t *testing.T) {
+	var buf bytes.Buffer
+	p := &ProgressBar{w: &buf, tty: true, label: "copy", total: 4}
+	io.Copy(p, strings.NewReader("ab"))
+	p.Add(2)
+	p.Done()
+	want := clearLine + "copy [===============               ] 50% (2/4)" +
+		clearLine + "copy [==============================] 100% (4/4)" +
+		clearLine + "copy [==============================] 100% (4/4)\n"
+	if buf.String() != want {
+		t.Errorf("tty\nhave %q\nwant %q", buf.String(), want)
+	}
+}
+
+func TestProgressBarPlain(t *testing.T) {
+	var buf bytes.Buffer
+	now := time.Unix(0, 0)
+	p := &ProgressBar{w: &buf, label: "copy", total: 10, now: func() time.Time { return now }}
+	p.Set(1)
+	p.Set(2)
+	now = now.Add(progressInterval)
+	p.Set(3)
+	p.Set(12)
+	p.Done()
+	want := "copy: 10% (1/10)\ncopy: 30% (3/10)\ncopy: 100% (12/10)\n"
+	if buf.String() != want {
+		t.Errorf("plain\nhave %q\nwant %q", buf.String(), want)
+	}
+
+	buf.Reset()
+	p = &ProgressBar{w: &buf, label: "copy", quiet: true, now: time.Now}
+	p.Add(1)
+	p.Done()
+	if buf.Len() != 0 {
+		t.Errorf("quiet\nhave %q\nwant %q", buf.String(), "")
+	}
+}
+
+func TestSpinner(t *testing.T) {
+	var buf bytes.Buffer
+	s := &Spinner{w: &buf, label: "Deploying", interval: time.Hour}
+	s.Start()
+	io.WriteString(s, "uploading\nwaiting\n")
+	s.Stop("done")
+	want := "Deploying...\nDeploying... done\n"
+	if buf.String() != want {
+		t.Errorf("plain\nhave %q\nwant %q", buf.String(), want)
+	}
+
+	buf.Reset()
+	s = &Spinner{w: &buf, tty: true, label: "Deploying", status: "waiting", interval: time.Hour}
+	s.Start()
+	s.Stop("failed")
+	want = clearLine + "| Deploying... waiting" + clearLine + "Deploying... failed\n"
+	if buf.String() != want {
+		t.Errorf("tty\nhave %q\nwant %q", buf.String(), want)
+	}
+
+	buf.Reset()
+	s = &Spinner{w: &buf, quiet: true, label: "Deploying"}
+	s.Start()
+	s.Stop("done")
+	if buf.Len() != 0 {
+		t.Errorf("quiet\nhave %q\nwant %q", buf.String(), "")
+	}
+}
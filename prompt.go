@@ -0,0 +1,170 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// A prompter asks the user questions on the terminal of an invocation.
+type prompter struct {
+	r           io.Reader
+	w           io.Writer
+	interactive bool
+	noInput     bool
+	yes         bool
+	messages    *Messages
+}
+
+// newPrompter returns a prompter for the invocation of the command that
+// received ctx.
+func newPrompter(ctx context.Context) *prompter {
+	inv := ctxInvocation(ctx)
+	return &prompter{
+		r:           Stdin(ctx),
+		w:           Stderr(ctx),
+		interactive: isTerminal(Stdin(ctx)),
+		noInput:     inv.noInput,
+		yes:         inv.yes,
+		messages:    &inv.app.messages,
+	}
+}
+
+// NoInput reports whether -no-input was given before the command name, see
+// WithNoInputFlag, or the command was run by the Handler, in which case the
+// command must not prompt the user for input.
+func NoInput(ctx context.Context) bool {
+	return ctxInvocation(ctx).noInput
+}
+
+// Input prints label and reads a line from the terminal, with surrounding
+// white space removed. Input fails without asking if there is no terminal or
+// -no-input was given.
+func Input(ctx context.Context, label string) (string, error) {
+	return newPrompter(ctx).input(label)
+}
+
+// Password prints label and reads a line from the terminal without echoing
+// it. Password fails without asking if there is no terminal or -no-input was
+// given.
+func Password(ctx context.Context, label string) (string, error) {
+	p := newPrompter(ctx)
+	err := p.check(label)
+	if err != nil {
+		return "", err
+	}
+
+	return readPassword(p.r, p.w, label+": ")
+}
+
+// Select prints label with a numbered list of choices and reads the chosen
+// one from the terminal, by number or by value, asking again until a choice
+// is made. Select fails without asking if there is no terminal or -no-input
+// was given.
+func Select(ctx context.Context, label string, choices []string) (string, error) {
+	return newPrompter(ctx).choose(label, choices)
+}
+
+// Confirm asks a yes or no question on the terminal, defaulting to no. The
+// answer is yes without asking if -yes was given before the command name.
+// Otherwise Confirm fails without asking if there is no terminal or -no-input
+// was given.
+func Confirm(ctx context.Context, question string) (bool, error) {
+	return newPrompter(ctx).confirm(question)
+}
+
+// check returns an error if the user cannot be asked for label.
+func (p *prompter) check(label string) error {
+	if p.noInput {
+		return fmt.Errorf("cannot prompt for %s with -no-input", label)
+	}
+
+	if !p.interactive {
+		return fmt.Errorf("cannot prompt for %s without a terminal", label)
+	}
+
+	return nil
+}
+
+// readLine prints prompt and reads a line, with surrounding white space
+// removed.
+func (p *prompter) readLine(prompt string) (string, error) {
+	fmt.Fprint(p.w, prompt)
+	line, err := bufio.NewReader(p.r).ReadString('\n')
+	if err != nil && line == "" {
+		return "", err
+	}
+
+	return strings.TrimSpace(line), nil
+}
+
+func (p *prompter) input(label string) (string, error) {
+	err := p.check(label)
+	if err != nil {
+		return "", err
+	}
+
+	return p.readLine(label + ": ")
+}
+
+func (p *prompter) choose(label string, choices []string) (string, error) {
+	err := p.check(label)
+	if err != nil {
+		return "", err
+	}
+
+	if len(choices) == 0 {
+		return "", fmt.Errorf("%s: no choices", label)
+	}
+
+	fmt.Fprintf(p.w, "%s:\n", label)
+	for i, choice := range choices {
+		fmt.Fprintf(p.w, "  %d) %s\n", i+1, choice)
+	}
+
+	r := bufio.NewReader(p.r)
+	for {
+		fmt.Fprintf(p.w, p.messages.Choose+" ", len(choices))
+		line, err := r.ReadString('\n')
+		if err != nil && line == "" {
+			return "", err
+		}
+
+		answer := strings.TrimSpace(line)
+		if n, err := strconv.Atoi(answer); err == nil && n >= 1 && n <= len(choices) {
+			return choices[n-1], nil
+		}
+
+		for _, choice := range choices {
+			if answer == choice {
+				return choice, nil
+			}
+		}
+	}
+}
+
+func (p *prompter) confirm(question string) (bool, error) {
+	if p.yes {
+		return true, nil
+	}
+
+	err := p.check(fmt.Sprintf("%q", question))
+	if err != nil {
+		return false, err
+	}
+
+	answer, err := p.readLine(question + " " + p.messages.YesNo + " ")
+	if err != nil {
+		return false, err
+	}
+
+	switch strings.ToLower(answer) {
+	case "y", "yes":
+		return true, nil
+	}
+
+	return false, nil
+}
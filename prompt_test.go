@@ -0,0 +1,81 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestPrompter(t *testing.T) {
+	var out bytes.Buffer
+	p := &prompter{r: strings.NewReader("  alice \n"), w: &out, interactive: true, messages: &DefaultMessages}
+	have, err := p.input("username")
+	if err != nil || have != "alice" || out.String() != "username: " {
+		t.Errorf("input\nhave %q %v %q\nwant %q %v %q", have, err, out.String(), "alice", nil, "username: ")
+	}
+
+	out.Reset()
+	p.r = strings.NewReader("4\nyaml\n")
+	have, err = p.choose("Format", []string{"json", "yaml", "table"})
+	want := "Format:\n  1) json\n  2) yaml\n  3) table\nChoose 1-3: Choose 1-3: "
+	if err != nil || have != "yaml" || out.String() != want {
+		t.Errorf("select\nhave %q %v %q\nwant %q %v %q", have, err, out.String(), "yaml", nil, want)
+	}
+
+	p.r = strings.NewReader("2\n")
+	have, _ = p.choose("Format", []string{"json", "yaml"})
+	if have != "yaml" {
+		t.Errorf("select by number\nhave %q\nwant %q", have, "yaml")
+	}
+
+	for input, want := range map[string]bool{"y\n": true, "Yes\n": true, "\n": false, "no\n": false} {
+		p.r = strings.NewReader(input)
+		have, err := p.confirm("Continue?")
+		if err != nil || have != want {
+			t.Errorf("confirm %q\nhave %t %v\nwant %t", input, have, err, want)
+		}
+	}
+
+	ok, err := (&prompter{yes: true}).confirm("Continue?")
+	if !ok || err != nil {
+		t.Errorf("confirm -yes\nhave %t %v\nwant true <nil>", ok, err)
+	}
+}
+
+func TestPrompterNoInput(t *testing.T) {
+	tests := []struct {
+		p    prompter
+		want string
+	}{
+		{prompter{}, "cannot prompt for username without a terminal"},
+		{prompter{interactive: true, noInput: true}, "cannot prompt for username with -no-input"},
+	}
+
+	for i, tt := range tests {
+		tt.p.r = strings.NewReader("alice\n")
+		_, err := tt.p.input("username")
+		if err == nil || err.Error() != tt.want {
+			t.Errorf("%d\nhave %v\nwant %s", i, err, tt.want)
+		}
+	}
+}
+
+func TestNoInputFlag(t *testing.T) {
+	app := New("myapp", "0.0.1", WithNoInputFlag())
+	app.RuleFunc("ask", "Ask a question.", "", func(ctx context.Context) error {
+		if !NoInput(ctx) {
+			t.Errorf("NoInput\nhave false\nwant true")
+		}
+
+		_, err := Input(ctx, "name")
+		return err
+	})
+
+	var stdout, stderr bytes.Buffer
+	code := app.RunWithArgs([]string{"-no-input", "ask"}, &stdout, &stderr)
+	want := "myapp: ask: cannot prompt for name with -no-input\n"
+	if code != 1 || stderr.String() != want {
+		t.Errorf("ask\nhave %d %q\nwant %d %q", code, stderr.String(), 1, want)
+	}
+}
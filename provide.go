@@ -0,0 +1,169 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// A container holds the values provided to an Application by type, see
+// Provide.
+type container struct {
+	mu        sync.Mutex
+	providers map[reflect.Type]*provider
+}
+
+// A provider is a value provided to an Application, or the constructor
+// building it once it is first needed.
+type provider struct {
+	fn    reflect.Value
+	value reflect.Value
+}
+
+// Provide registers a value shared by the commands of the Application, such
+// as an HTTP client or a database handle, which is injected by its type into
+// the commands that need it, see Inject.
+//
+// The value may instead be a constructor, a function returning the value,
+// optionally followed by an error, which is called once, when the value is
+// first needed. Its parameters may be a context.Context, that of the
+// invocation of the first command needing the value, from which it may read
+// the global flags with GlobalFlag, and other provided values, which it must
+// not obtain with Provided instead. A constructor that fails is called again
+// when the value is next needed.
+//
+// Each type may be provided once. Values are provided by their exact type,
+// such that a *sql.DB is not injected as an interface it implements.
+func (a *Application) Provide(value interface{}) error {
+	if value == nil {
+		return fmt.Errorf("provide: nil value")
+	}
+
+	p := &provider{value: reflect.ValueOf(value)}
+	t := p.value.Type()
+	if t.Kind() == reflect.Func {
+		if t.NumOut() < 1 || t.NumOut() > 2 || t.NumOut() == 2 && t.Out(1) != errorType {
+			return fmt.Errorf("provide: constructor %v must return a value, optionally followed by an error", t)
+		}
+
+		p.fn, p.value = p.value, reflect.Value{}
+		t = t.Out(0)
+	}
+
+	c := &a.container
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, ok := c.providers[t]; ok {
+		return fmt.Errorf("provide: duplicate %v", t)
+	}
+
+	if c.providers == nil {
+		c.providers = make(map[reflect.Type]*provider)
+	}
+
+	c.providers[t] = p
+	return nil
+}
+
+// Provided returns the value of type T provided to the Application of the
+// invocation of the command that received ctx, building it if needed, see
+// Provide.
+func Provided[T any](ctx context.Context) (T, error) {
+	var zero T
+	c := &ctxInvocation(ctx).app.container
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	v, err := c.resolve(ctx, reflect.TypeOf((*T)(nil)).Elem(), nil)
+	if err != nil {
+		return zero, err
+	}
+
+	return v.Interface().(T), nil
+}
+
+// inject calls the Inject method of the command, if it has one, with the
+// values of its parameters provided to the Application. Its first parameter
+// may be a context.Context, and it may return an error.
+func (c *container) inject(ctx context.Context, command command) error {
+	m := reflect.ValueOf(command).MethodByName("Inject")
+	if !m.IsValid() {
+		return nil
+	}
+
+	t := m.Type()
+	if t.NumOut() > 1 || t.NumOut() == 1 && t.Out(0) != errorType {
+		return fmt.Errorf("provide: Inject may only return an error")
+	}
+
+	c.mu.Lock()
+	args, err := c.args(ctx, t, nil)
+	c.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	rv := m.Call(args)
+	if len(rv) > 0 && !rv[0].IsNil() {
+		return rv[0].Interface().(error)
+	}
+
+	return nil
+}
+
+// args resolves the parameters of the function type t, passing ctx for a
+// context.Context. The constructors of the types in building are in
+// progress. The caller must hold the lock of the container.
+func (c *container) args(ctx context.Context, t reflect.Type, building []reflect.Type) ([]reflect.Value, error) {
+	args := make([]reflect.Value, t.NumIn())
+	for i := range args {
+		in := t.In(i)
+		if in == contextType {
+			args[i] = reflect.ValueOf(ctx)
+			continue
+		}
+
+		v, err := c.resolve(ctx, in, building)
+		if err != nil {
+			return nil, err
+		}
+
+		args[i] = v
+	}
+
+	return args, nil
+}
+
+// resolve returns the value provided for t, building it if needed. The
+// caller must hold the lock of the container.
+func (c *container) resolve(ctx context.Context, t reflect.Type, building []reflect.Type) (reflect.Value, error) {
+	p, ok := c.providers[t]
+	if !ok {
+		return reflect.Value{}, fmt.Errorf("provide: no %v provided", t)
+	}
+
+	if p.value.IsValid() {
+		return p.value, nil
+	}
+
+	for _, b := range building {
+		if b == t {
+			return reflect.Value{}, fmt.Errorf("provide: constructor of %v depends on itself", t)
+		}
+	}
+
+	args, err := c.args(ctx, p.fn.Type(), append(building, t))
+	if err != nil {
+		return reflect.Value{}, err
+	}
+
+	rv := p.fn.Call(args)
+	if len(rv) > 1 && !rv[1].IsNil() {
+		return reflect.Value{}, fmt.Errorf("provide: %v: %v", t, rv[1].Interface())
+	}
+
+	p.value = rv[0]
+	return p.value, nil
+}
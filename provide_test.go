@@ -0,0 +1,130 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+type apiClient struct {
+	endpoint string
+}
+
+type apiStore struct {
+	client *apiClient
+}
+
+type runProvide struct {
+	*NullFlags
+	client *apiClient
+	store  *apiStore
+}
+
+func (r *runProvide) Inject(client *apiClient, store *apiStore) {
+	r.client = client
+	r.store = store
+}
+
+func (r *runProvide) Run(ctx context.Context) error {
+	if r.store.client != r.client {
+		return fmt.Errorf("different clients")
+	}
+
+	fmt.Fprintln(Stdout(ctx), r.client.endpoint)
+	return nil
+}
+
+func (r *runProvide) String() string {
+	return "Print the endpoint."
+}
+
+func TestProvide(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	built := 0
+	app := New("myapp", "0.0.1")
+	app.stdout = &stdout
+	app.stderr = &stderr
+	app.Flags(func(flags *flag.FlagSet) {
+		flags.String("endpoint", "https://api.example.com", "API endpoint.")
+	})
+	app.Provide(func(ctx context.Context) *apiClient {
+		built++
+		return &apiClient{endpoint: GlobalFlag(ctx, "endpoint").Value.String()}
+	})
+	app.Provide(func(client *apiClient) (*apiStore, error) {
+		return &apiStore{client: client}, nil
+	})
+	app.Rule(&runProvide{}, "endpoint", "")
+	app.RuleFunc("other", "Use the client.", "", func(ctx context.Context) error {
+		client, err := Provided[*apiClient](ctx)
+		if err != nil {
+			return err
+		}
+
+		fmt.Fprintln(Stdout(ctx), client.endpoint)
+		return nil
+	})
+
+	code := app.Dispatch([]string{"-endpoint", "http://localhost", "endpoint"})
+	code += app.Dispatch([]string{"other"})
+	if want := "http://localhost\nhttp://localhost\n"; code != 0 || built != 1 || stdout.String() != want {
+		t.Errorf("endpoint\nhave %d %d %q %q\nwant %d %d %q", code, built, stdout.String(), stderr.String(), 0, 1, want)
+	}
+}
+
+func TestProvideErrors(t *testing.T) {
+	app := New("myapp", "0.0.1")
+	app.Provide(&apiClient{})
+
+	tests := []struct {
+		value interface{}
+		want  string
+	}{
+		{nil, "provide: nil value"},
+		{&apiClient{}, "provide: duplicate *cli.apiClient"},
+		{func() {}, "provide: constructor func() must return a value, optionally followed by an error"},
+		{func() (*apiStore, int) { return nil, 0 }, "provide: constructor func() (*cli.apiStore, int) must return a value, optionally followed by an error"},
+	}
+	for _, tt := range tests {
+		err := app.Provide(tt.value)
+		if err == nil || err.Error() != tt.want {
+			t.Errorf("Provide %T\nhave %v\nwant %s", tt.value, err, tt.want)
+		}
+	}
+}
+
+func TestProvideMissing(t *testing.T) {
+	tests := []struct {
+		provide []interface{}
+		want    string
+	}{
+		{nil, "myapp: endpoint: provide: no *cli.apiClient provided\n"},
+		{
+			[]interface{}{&apiClient{}, func(s *apiStore) (*apiStore, error) { return s, nil }},
+			"myapp: endpoint: provide: constructor of *cli.apiStore depends on itself\n",
+		},
+		{
+			[]interface{}{&apiClient{}, func() (*apiStore, error) { return nil, fmt.Errorf("offline") }},
+			"myapp: endpoint: provide: *cli.apiStore: offline\n",
+		},
+	}
+
+	for _, tt := range tests {
+		var stdout, stderr bytes.Buffer
+		app := New("myapp", "0.0.1")
+		app.stdout = &stdout
+		app.stderr = &stderr
+		for _, v := range tt.provide {
+			app.Provide(v)
+		}
+		app.Rule(&runProvide{}, "endpoint", "")
+
+		code := app.Dispatch([]string{"endpoint"})
+		if code != 1 || !strings.HasSuffix(stderr.String(), tt.want) {
+			t.Errorf("endpoint\nhave %d %q\nwant %d %q", code, stderr.String(), 1, tt.want)
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// rateLimit restricts how often a rule may be run.
+type rateLimit struct {
+	n      int
+	period time.Duration
+}
+
+// RateLimit is a RuleOption that refuses to run the command more than n times
+// within period. Previous runs are recorded under the CacheDir of the
+// Application, so the limit applies across processes for the current user.
+// Runs are recorded once the invocation is authorized and confirmed, so that
+// denied and declined runs do not count towards the limit. RateLimit panics
+// if n is not positive.
+func RateLimit(n int, period time.Duration) RuleOption {
+	if n <= 0 {
+		panic(fmt.Sprintf("cli: rate limit of %d runs", n))
+	}
+
+	return func(r *rule) {
+		r.limit = &rateLimit{n: n, period: period}
+	}
+}
+
+// CacheDir returns the directory in which the Application may cache data for
+// the current user. The directory is not created.
+func (a *Application) CacheDir() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(dir, a.name), nil
+}
+
+// allow returns the time to wait before another run of the named rule is
+// allowed at now, if the limit has been reached. Otherwise the run is
+// recorded if record is true. Processes recording runs of the same rule wait
+// for each other.
+func (l *rateLimit) allow(dir, name string, now time.Time, record bool) (time.Duration, error) {
+	path := filepath.Join(dir, "ratelimit", name+".json")
+	if record {
+		unlock, err := lockFile(path + ".lock")
+		if err != nil {
+			return 0, err
+		}
+		defer unlock()
+	}
+
+	var runs []time.Time
+	data, err := ioutil.ReadFile(path)
+	if err == nil {
+		err = json.Unmarshal(data, &runs)
+	}
+	if err != nil && !os.IsNotExist(err) {
+		return 0, err
+	}
+
+	// Forget the runs that are outside of the period.
+	recent := runs[:0]
+	for _, t := range runs {
+		if now.Sub(t) < l.period {
+			recent = append(recent, t)
+		}
+	}
+
+	if len(recent) >= l.n {
+		return recent[len(recent)-l.n].Add(l.period).Sub(now), nil
+	}
+	if !record {
+		return 0, nil
+	}
+
+	data, err = json.Marshal(append(recent, now))
+	if err != nil {
+		return 0, err
+	}
+
+	return 0, writeFile(path, data)
+}
+
+// rateLimited reports whether the rule has reached its rate limit, printing
+// a message to stderr if so, and otherwise records the run if record is
+// true.
+func (a *Application) rateLimited(r *rule, s streams, record bool) bool {
+	if r.limit == nil {
+		return false
+	}
+
+	dir, err := a.CacheDir()
+	if err == nil {
+		var wait time.Duration
+		wait, err = r.limit.allow(dir, r.name, time.Now(), record)
+		if err == nil && wait > 0 {
+			err = fmt.Errorf("limited to %d runs every %v, try again in %v", r.limit.n, r.limit.period, wait.Round(time.Second))
+		}
+	}
+
+	if err != nil {
+		a.errorf(s.stderr, "%s: %v", r.name, err)
+		return true
+	}
+
+	return false
+}
@@ -0,0 +1,107 @@
+package cli
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRateLimit(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cli-cache")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	t.Setenv("XDG_CACHE_HOME", dir)
+
+	app := New("myapp", "0.0.1")
+	app.Rule(&runFlaky{}, "limited", "", RateLimit(2, time.Hour))
+
+	var stderr bytes.Buffer
+	app.stderr = &stderr
+	codes := []int{75, 75, 1}
+	for i, want := range codes {
+		have := app.Dispatch([]string{"limited"})
+		if have != want {
+			t.Errorf("run %d\nhave %d\nwant %d", i+1, have, want)
+		}
+	}
+
+	if !strings.Contains(stderr.String(), "limited to 2 runs every 1h0m0s") {
+		t.Errorf("stderr\nhave %q", stderr.String())
+	}
+}
+
+func TestRateLimitInvalid(t *testing.T) {
+	defer func() {
+		want := "cli: rate limit of 0 runs"
+		if have := recover(); have != want {
+			t.Errorf("panic\nhave %v\nwant %v", have, want)
+		}
+	}()
+
+	RateLimit(0, time.Hour)
+}
+
+func TestRateLimitConfirm(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+
+	app := New("myapp", "0.0.1", WithYesFlag())
+	cmd := &runConfirm{}
+	app.Rule(cmd, "purge", "", RateLimit(1, time.Hour))
+
+	var stderr bytes.Buffer
+	app.stderr = &stderr
+	tests := []struct {
+		args []string
+		code int
+		ran  bool
+	}{
+		{[]string{"purge"}, 1, false},
+		{[]string{"-yes", "purge"}, 0, true},
+		{[]string{"-yes", "purge"}, 1, false},
+	}
+
+	for i, tt := range tests {
+		cmd.ran = false
+		code := app.Dispatch(tt.args)
+		if code != tt.code || cmd.ran != tt.ran {
+			t.Errorf("run %d\nhave %d %t\nwant %d %t", i+1, code, cmd.ran, tt.code, tt.ran)
+		}
+	}
+}
+
+func TestRateLimitConcurrent(t *testing.T) {
+	dir := t.TempDir()
+	l := &rateLimit{n: 5, period: time.Hour}
+
+	var wg sync.WaitGroup
+	allowed := make(chan bool, 20)
+	for i := 0; i < cap(allowed); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			wait, err := l.allow(dir, "limited", time.Now(), true)
+			if err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+			allowed <- wait == 0
+		}()
+	}
+	wg.Wait()
+	close(allowed)
+
+	n := 0
+	for ok := range allowed {
+		if ok {
+			n++
+		}
+	}
+	if n != l.n {
+		t.Errorf("allowed runs\nhave %d\nwant %d", n, l.n)
+	}
+}
@@ -0,0 +1,110 @@
+package cli
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"unicode"
+)
+
+// cmdPrefix is the prefix of the methods registered as commands by Register.
+const cmdPrefix = "Cmd"
+
+// Register registers each exported method of v named with the prefix Cmd as
+// a command, for applications with many commands implemented by a single
+// type. The name of the command is the rest of the method name in kebab
+// case, such that CmdRemoteAdd is registered as remote-add. The method is
+// called as the Run method of a command would be and must satisfy the same
+// requirements, see Rule. The options are applied to each command.
+//
+// The description of the command is returned by a method named after the
+// command with the suffix Help, such as RemoteAddHelp, and its arguments
+// string by one with the suffix Args, such as RemoteAddArgs, if v has them.
+// Otherwise each parameter of the method is an optional argument.
+func (a *Application) Register(v interface{}, options ...RuleOption) error {
+	rv := reflect.ValueOf(v)
+	t := rv.Type()
+	for i := 0; i < t.NumMethod(); i++ {
+		m := t.Method(i)
+		base := strings.TrimPrefix(m.Name, cmdPrefix)
+		if base == m.Name || base == "" {
+			continue
+		}
+
+		fn := rv.Method(i)
+		description := callString(rv, base+"Help")
+		arguments, ok := callStringOK(rv, base+"Args")
+		if !ok {
+			arguments = inferArguments(fn.Type())
+		}
+
+		err := a.Rule(&funcCommand{description: description, fn: fn}, kebab(base), arguments, options...)
+		if err != nil {
+			return fmt.Errorf("%v: method %s", err, m.Name)
+		}
+	}
+
+	return nil
+}
+
+// callString returns the result of the named method of v, if it has one
+// returning a string, or the empty string.
+func callString(v reflect.Value, name string) string {
+	s, _ := callStringOK(v, name)
+	return s
+}
+
+// callStringOK returns the result of the named method of v and true, if it
+// has one returning a string.
+func callStringOK(v reflect.Value, name string) (string, bool) {
+	m := v.MethodByName(name)
+	if !m.IsValid() {
+		return "", false
+	}
+
+	fn, ok := m.Interface().(func() string)
+	if !ok {
+		return "", false
+	}
+
+	return fn(), true
+}
+
+// inferArguments returns an arguments string with an optional argument for
+// each parameter of a Run method of type t.
+func inferArguments(t reflect.Type) string {
+	var args []string
+	for i := 0; i < t.NumIn(); i++ {
+		in := t.In(i)
+		switch {
+		case i == 0 && in == contextType:
+			continue
+		case i == t.NumIn()-1 && in.Kind() == reflect.Slice && !convertible(in):
+			args = append(args, "[<args>...]")
+		default:
+			args = append(args, fmt.Sprintf("[<arg%d>]", len(args)+1))
+		}
+	}
+
+	return strings.Join(args, " ")
+}
+
+// kebab converts a Go identifier to kebab case, such that RemoteAdd is
+// remote-add and ServeHTTP is serve-http.
+func kebab(s string) string {
+	runes := []rune(s)
+	var b strings.Builder
+	for i, r := range runes {
+		if i > 0 && unicode.IsUpper(r) {
+			prev := runes[i-1]
+			next := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && next) {
+				b.WriteByte('-')
+			}
+		}
+
+		b.WriteRune(unicode.ToLower(r))
+	}
+
+	return b.String()
+}
@@ -0,0 +1,81 @@
+package cli
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+type service struct {
+	calls []string
+}
+
+func (s *service) CmdRemoteAdd(name, url string) {
+	s.calls = append(s.calls, "add "+name+" "+url)
+}
+
+func (s *service) RemoteAddHelp() string {
+	return "Add a remote."
+}
+
+func (s *service) RemoteAddArgs() string {
+	return "<name> <url>"
+}
+
+func (s *service) CmdServeHTTP(ctx context.Context, ports []int) int {
+	s.calls = append(s.calls, "serve")
+	return len(ports)
+}
+
+func (s *service) Helper() {}
+
+func TestRegister(t *testing.T) {
+	app := New("myapp", "0.0.1")
+	svc := &service{}
+	err := app.Register(svc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := app.rules["helper"]; ok {
+		t.Errorf("registered a method without the Cmd prefix")
+	}
+
+	add := app.rules["remote-add"]
+	if add == nil || add.command.String() != "Add a remote." || add.arguments != "<name> <url>" {
+		t.Fatalf("remote-add\nhave %+v", add)
+	}
+
+	serve := app.rules["serve-http"]
+	if serve == nil || serve.arguments != "[<args>...]" {
+		t.Fatalf("serve-http\nhave %+v", serve)
+	}
+
+	if code := app.Dispatch([]string{"remote-add", "origin", "git@x"}); code != 0 {
+		t.Errorf("remote-add exit code\nhave %d\nwant %d", code, 0)
+	}
+
+	if code := app.Dispatch([]string{"serve-http", "80", "443"}); code != 2 {
+		t.Errorf("serve-http exit code\nhave %d\nwant %d", code, 2)
+	}
+
+	if want := []string{"add origin git@x", "serve"}; !reflect.DeepEqual(svc.calls, want) {
+		t.Errorf("calls\nhave %q\nwant %q", svc.calls, want)
+	}
+}
+
+func TestKebab(t *testing.T) {
+	tests := map[string]string{
+		"RemoteAdd": "remote-add",
+		"ServeHTTP": "serve-http",
+		"HTTPServe": "http-serve",
+		"V2Sync":    "v2-sync",
+		"List":      "list",
+	}
+
+	for in, want := range tests {
+		if have := kebab(in); have != want {
+			t.Errorf("kebab(%q)\nhave %q\nwant %q", in, have, want)
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+)
+
+// reloadHook is a named step of the reload pipeline of an invocation,
+// registered with ctx.
+type reloadHook struct {
+	name string
+	fn   func(ctx context.Context) error
+	ctx  context.Context
+}
+
+// OnReload registers fn as a step, identified by name, of the reload pipeline
+// of the invocation of the command that received ctx, until ctx is done. The
+// pipeline runs when the process receives SIGHUP, where supported, while any
+// step is registered, or when Reload is called. Steps run in the order they
+// were registered, such as re-reading configuration before reopening log
+// files and rebinding listeners, each with the context it was registered
+// with.
+func OnReload(ctx context.Context, name string, fn func(ctx context.Context) error) {
+	inv := ctxInvocation(ctx)
+	h := &reloadHook{name: name, fn: fn, ctx: ctx}
+	inv.mu.Lock()
+	defer inv.mu.Unlock()
+
+	inv.reload = append(inv.reload, h)
+	go inv.unregister(h)
+	if inv.stopHUP != nil || len(reloadSignals) == 0 {
+		return
+	}
+
+	ch := make(chan os.Signal, 1)
+	stop := make(chan struct{})
+	inv.stopHUP = stop
+	signal.Notify(ch, reloadSignals...)
+	go func() {
+		defer signal.Stop(ch)
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ch:
+				Reload(ctx)
+			}
+		}
+	}()
+}
+
+// unregister removes the reload step once the context it was registered with
+// is done, stopping the signal listener after the last step is removed.
+func (inv *invocation) unregister(h *reloadHook) {
+	<-h.ctx.Done()
+	inv.mu.Lock()
+	defer inv.mu.Unlock()
+
+	for i, hook := range inv.reload {
+		if hook == h {
+			inv.reload = append(inv.reload[:i], inv.reload[i+1:]...)
+			break
+		}
+	}
+
+	if len(inv.reload) == 0 && inv.stopHUP != nil {
+		close(inv.stopHUP)
+		inv.stopHUP = nil
+	}
+}
+
+// Reload runs the reload pipeline of the invocation of the command that
+// received ctx, logging the outcome of each step to its standard error. The
+// pipeline stops at the first step to fail and its error is returned.
+func Reload(ctx context.Context) error {
+	inv := ctxInvocation(ctx)
+	inv.mu.Lock()
+	hooks := append([]*reloadHook{}, inv.reload...)
+	inv.mu.Unlock()
+
+	w := Stderr(ctx)
+	for _, hook := range hooks {
+		err := hook.fn(hook.ctx)
+		if err != nil {
+			fmt.Fprintf(w, "reload: %s: "+inv.app.messages.ReloadFailed+"\n", hook.name, err)
+			return err
+		}
+
+		fmt.Fprintf(w, "reload: %s: %s\n", hook.name, inv.app.messages.ReloadOK)
+	}
+
+	return nil
+}
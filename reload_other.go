@@ -0,0 +1,9 @@
+//go:build windows || plan9 || js || wasip1
+
+package cli
+
+import "os"
+
+// reloadSignals request the reload pipeline of an invocation. There are none
+// on this platform, where the pipeline only runs through Reload.
+var reloadSignals []os.Signal
@@ -0,0 +1,118 @@
+//go:build !windows && !plan9 && !js && !wasip1
+
+package cli
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"syscall"
+	"testing"
+	"time"
+)
+
+type runReload struct {
+	*NullFlags
+	steps chan string
+}
+
+func TestReload(t *testing.T) {
+	app := New("myapp", "0.0.1")
+	cmd := &runReload{steps: make(chan string, 4)}
+	app.Rule(cmd, "serve", "")
+
+	app.stderr = &bytes.Buffer{}
+	code := app.Dispatch([]string{"serve"})
+	if code != 0 {
+		t.Errorf("exit code\nhave %d\nwant %d", code, 0)
+	}
+}
+
+func TestReloadRestart(t *testing.T) {
+	steps := make(chan string, 4)
+	app := New("myapp", "0.0.1")
+	app.RuleFunc("serve", "Serve.", "", func(ctx context.Context) int {
+		first, cancel := context.WithCancel(ctx)
+		OnReload(first, "first", func(ctx context.Context) error {
+			steps <- "first"
+			return nil
+		})
+		cancel()
+
+		// Wait for the listener of the first step to stop.
+		for i := 0; i < 100 && ctxInvocation(ctx).hooks() > 0; i++ {
+			time.Sleep(time.Millisecond)
+		}
+
+		OnReload(ctx, "second", func(ctx context.Context) error {
+			steps <- "second"
+			return nil
+		})
+
+		syscall.Kill(syscall.Getpid(), syscall.SIGHUP)
+		select {
+		case have := <-steps:
+			if have != "second" {
+				return 1
+			}
+		case <-time.After(time.Second):
+			return 2
+		}
+
+		return 0
+	})
+
+	app.stderr = &bytes.Buffer{}
+	code := app.Dispatch([]string{"serve"})
+	if code != 0 {
+		t.Errorf("exit code\nhave %d\nwant %d", code, 0)
+	}
+}
+
+func (c *runReload) Run(ctx context.Context) int {
+	OnReload(ctx, "config", func(ctx context.Context) error {
+		c.steps <- "config"
+		return nil
+	})
+	OnReload(ctx, "logs", func(ctx context.Context) error {
+		c.steps <- "logs"
+		return errors.New("reopen failed")
+	})
+	OnReload(ctx, "listeners", func(ctx context.Context) error {
+		c.steps <- "listeners"
+		return nil
+	})
+
+	syscall.Kill(syscall.Getpid(), syscall.SIGHUP)
+	for _, want := range []string{"config", "logs"} {
+		select {
+		case have := <-c.steps:
+			if have != want {
+				return 1
+			}
+		case <-time.After(time.Second):
+			return 2
+		}
+	}
+
+	// The pipeline stops at the first failure.
+	select {
+	case <-c.steps:
+		return 3
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	return 0
+}
+
+func (c *runReload) String() string {
+	return "serve with reload"
+}
+
+// hooks returns the number of registered reload steps.
+func (inv *invocation) hooks() int {
+	inv.mu.Lock()
+	defer inv.mu.Unlock()
+
+	return len(inv.reload)
+}
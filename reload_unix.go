@@ -0,0 +1,11 @@
+//go:build !windows && !plan9 && !js && !wasip1
+
+package cli
+
+import (
+	"os"
+	"syscall"
+)
+
+// reloadSignals request the reload pipeline of an invocation, see OnReload.
+var reloadSignals = []os.Signal{syscall.SIGHUP}
@@ -0,0 +1,636 @@
+package cli
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// A HelpRenderer formats the usage of an Application and the help of its
+// commands, see SetHelpRenderer. TextRenderer is the default.
+type HelpRenderer interface {
+	// Usage renders the usage of the Application listing its commands.
+	Usage(w io.Writer, u *Usage)
+	// Help renders the detailed help of the command c.
+	Help(w io.Writer, u *Usage, c *CommandUsage)
+}
+
+// Usage describes an Application for a HelpRenderer.
+type Usage struct {
+	Name    string
+	Version string
+	// Description is the one-line description of the Application, if any.
+	Description string
+	// About is the extended description of the Application, if any.
+	About string
+	// Footer is the text to show after the commands, if any.
+	Footer string
+	// Messages are the strings to render, such as section headers.
+	Messages Messages
+	// Global are the documented global options.
+	Global []OptionUsage
+	// Commands are the commands to render, in order.
+	Commands []CommandUsage
+	// Compact is true if the Application has too many commands to list with
+	// their options and commands sharing a name prefix are collapsed.
+	Compact bool
+	// Categorized is true if the commands are ordered by their category and
+	// should be listed under a header for each, see Category.
+	Categorized bool
+	// ExitCodes are the documented exit codes, if any, see MapError.
+	ExitCodes []ExitCodeUsage
+}
+
+// CommandUsage describes a command for a HelpRenderer.
+type CommandUsage struct {
+	// Name is the full name of the command, such as "remote add". The name of
+	// a collapsed entry is the shared prefix followed by an asterisk.
+	Name string
+	// Synopsis is the full name of the command and its arguments.
+	Synopsis string
+	// Description is the short description, noting any deprecation.
+	Description string
+	// Help is the long description, if the command has a Help method.
+	Help string
+	// Custom is the help written by the Usage method of the command, if it
+	// has one, to render in place of the description, arguments, options,
+	// notes and examples.
+	Custom string
+	// Category is the category of the command, if any.
+	Category string
+	// Depth is the number of groups the command is nested in.
+	Depth int
+	// Count is the number of commands of a collapsed entry.
+	Count     int
+	Arguments []ArgumentUsage
+	Options   []OptionUsage
+	// Notes describe relationships between the options, such as flags that
+	// are mutually exclusive.
+	Notes []string
+	// Examples are command lines illustrating the use of the command.
+	Examples []string
+}
+
+// ArgumentUsage describes a positional argument for a HelpRenderer.
+type ArgumentUsage struct {
+	Name string
+	// Usage describes the argument, if it was declared with Arguments.
+	Usage    string
+	Optional bool
+	Repeated bool
+}
+
+// OptionUsage describes a flag for a HelpRenderer.
+type OptionUsage struct {
+	Name string
+	// Short is the short spelling of the flag, if any, see Shorthand.
+	Short string
+	// Value hints at the value of the flag, such as <n>, or is empty for
+	// boolean flags.
+	Value   string
+	Default string
+	Usage   string
+	// Env is the environment variable populating the flag, if any.
+	Env string
+	// Repeated is true if the flag may be repeated, see StringSliceVar.
+	Repeated bool
+}
+
+// TextRenderer is the default HelpRenderer, aligning names and descriptions
+// in columns. The zero value renders plain text for pipes and files.
+type TextRenderer struct {
+	// Padding is the number of spaces separating the columns, or 3 if zero.
+	Padding int
+	// Color renders names in bold and flags dimmed with ANSI escape
+	// sequences.
+	Color bool
+	// Width is the number of columns to wrap descriptions to, or zero to
+	// leave them unwrapped.
+	Width int
+}
+
+// SetHelpRenderer sets the HelpRenderer of the usage and the help of
+// commands.
+func (a *Application) SetHelpRenderer(r HelpRenderer) {
+	a.renderer = r
+}
+
+// A FlagValueFormatter formats the values of flags in the usage and help,
+// see SetFlagValueFormatter. StandardFormatter is the default.
+type FlagValueFormatter interface {
+	// Placeholder returns the hint of the value of the flag, such as <n>, or
+	// an empty string for boolean flags.
+	Placeholder(f *flag.Flag) string
+	// Default returns the default value of the flag.
+	Default(f *flag.Flag) string
+}
+
+// StandardFormatter is the default FlagValueFormatter. Flags whose usage
+// names their value in back quotes, such as "Read from `path`.", have it as
+// their placeholder, such as <path>. Otherwise numeric defaults are hinted as
+// <n>, other defaults are quoted and flags without a default are hinted by
+// their type, such as <duration>. It may be embedded to override either
+// method.
+type StandardFormatter struct{}
+
+// SetFlagValueFormatter sets the FlagValueFormatter of the placeholders and
+// defaults of flags in the usage and help of commands.
+func (a *Application) SetFlagValueFormatter(f FlagValueFormatter) {
+	a.formatter = f
+}
+
+// render returns the HelpRenderer of the Application for output to w. The
+// default TextRenderer colors and wraps output to terminals, see
+// SetColorMode.
+func (a *Application) render(w io.Writer, noColor bool) HelpRenderer {
+	if a.renderer == nil {
+		return TextRenderer{Color: colorize(w, a.color, noColor), Width: width(w)}
+	}
+
+	return a.renderer
+}
+
+// String formats the option, preceded by its short spelling, and its value
+// hint.
+func (o *OptionUsage) String() string {
+	option := "-" + o.Name
+	if o.Short != "" {
+		option = "-" + o.Short + ", " + option
+	}
+
+	if o.Value == "" {
+		return option
+	}
+
+	return option + "=" + o.Value
+}
+
+// Display formats the command for listing beneath its group, indented by its
+// depth and without the names of its groups.
+func (c *CommandUsage) Display() string {
+	i := strings.LastIndex(c.Name, " ")
+	return strings.Repeat("  ", c.Depth) + c.Synopsis[i+1:]
+}
+
+// Usage implements the HelpRenderer interface.
+func (t TextRenderer) Usage(w io.Writer, u *Usage) {
+	m := u.Messages.merge()
+	fmt.Fprintf(w, "%s: %s <cmd> [options] [<args>]\n", m.Usage, u.Name)
+	if u.Description != "" || u.About != "" {
+		for _, s := range []string{u.Description, u.About} {
+			if s != "" {
+				fmt.Fprintf(w, "\n%s\n", t.wrap(strings.TrimRight(s, "\n"), 0))
+			}
+		}
+
+		fmt.Fprintf(w, "\n")
+	}
+
+	if len(u.Global) > 0 {
+		fmt.Fprintf(w, "%s:\n", m.GlobalOptions)
+		t.options(w, u.Global, 0)
+		fmt.Fprintf(w, "\n")
+	}
+
+	if u.Compact {
+		length := 0
+		for _, c := range u.Commands {
+			if len(c.Name) > length {
+				length = len(c.Name)
+			}
+		}
+
+		for i, c := range u.Commands {
+			t.header(w, u, i)
+			spaces := strings.Repeat(" ", length+t.padding()-len(c.Name))
+			fmt.Fprintf(w, "  %s%s%s\n", t.bold(c.Name), spaces, t.wrap(c.Description, 2+length+t.padding()))
+		}
+
+		fmt.Fprintf(w, "\n"+m.MatchingCommands+"\n\n", u.Name)
+		t.footer(w, u)
+		return
+	}
+
+	// Options are indented by two more spaces than their command.
+	length := 0
+	for _, c := range u.Commands {
+		if n := len(c.Display()); n > length {
+			length = n
+		}
+
+		for _, o := range c.Options {
+			if n := 2*c.Depth + len(o.String()) + 2; n > length {
+				length = n
+			}
+		}
+	}
+	length += t.padding()
+
+	for i, c := range u.Commands {
+		t.header(w, u, i)
+		display := c.Display()
+		spaces := strings.Repeat(" ", length-len(display))
+		fmt.Fprintf(w, "  %s%s%s\n", t.bold(display), spaces, t.wrap(c.Description, 2+length))
+
+		indent := strings.Repeat("  ", c.Depth)
+		for _, o := range c.Options {
+			option := o.String()
+			spaces := strings.Repeat(" ", length-len(indent)-len(option)-2)
+			fmt.Fprintf(w, "    %s%s%s%s\n", indent, t.dim(option), spaces, t.wrap(o.usage(), 2+length))
+		}
+
+		for _, note := range c.Notes {
+			fmt.Fprintf(w, "    %s%s\n", indent, t.wrap(note, 4+len(indent)))
+		}
+	}
+
+	fmt.Fprintf(w, "\n")
+	t.footer(w, u)
+}
+
+// footer prints the footer of the usage, if any.
+func (t TextRenderer) footer(w io.Writer, u *Usage) {
+	if u.Footer != "" {
+		fmt.Fprintf(w, "%s\n\n", t.wrap(strings.TrimRight(u.Footer, "\n"), 0))
+	}
+}
+
+// Help implements the HelpRenderer interface.
+func (t TextRenderer) Help(w io.Writer, u *Usage, c *CommandUsage) {
+	m := u.Messages.merge()
+	fmt.Fprintf(w, "%s: %s %s\n\n", m.Usage, u.Name, t.bold(c.Synopsis))
+	if c.Custom != "" {
+		fmt.Fprintf(w, "%s\n", strings.TrimRight(c.Custom, "\n"))
+		t.exitCodes(w, u)
+		return
+	}
+
+	fmt.Fprintf(w, "%s\n", t.wrap(c.Description, 0))
+	if c.Help != "" {
+		fmt.Fprintf(w, "\n%s\n", t.wrap(strings.TrimRight(c.Help, "\n"), 0))
+	}
+
+	if described(c.Arguments) {
+		fmt.Fprintf(w, "\n%s\n", t.bold(m.Arguments+":"))
+		t.arguments(w, c.Arguments)
+	}
+
+	if len(c.Options) > 0 {
+		fmt.Fprintf(w, "\n%s\n", t.bold(m.Options+":"))
+		t.options(w, c.Options, 0)
+	}
+
+	if len(c.Notes) > 0 {
+		fmt.Fprintf(w, "\n%s\n", t.wrap(strings.Join(c.Notes, "\n"), 0))
+	}
+
+	if len(c.Examples) > 0 {
+		fmt.Fprintf(w, "\n%s\n", t.bold(m.Examples+":"))
+		for _, example := range c.Examples {
+			fmt.Fprintf(w, "  %s\n", strings.Replace(example, "\n", "\n  ", -1))
+		}
+	}
+
+	t.exitCodes(w, u)
+}
+
+// exitCodes prints the documented exit codes, ending the help of a command.
+func (t TextRenderer) exitCodes(w io.Writer, u *Usage) {
+	if len(u.ExitCodes) > 0 {
+		fmt.Fprintf(w, "\n%s\n", t.bold(u.Messages.merge().ExitCodes+":"))
+		for _, e := range u.ExitCodes {
+			code := strconv.Itoa(e.Code)
+			spaces := strings.Repeat(" ", 3+t.padding()-len(code))
+			fmt.Fprintf(w, "  %s%s%s\n", code, spaces, t.wrap(e.Description, 5+t.padding()))
+		}
+	}
+
+	fmt.Fprintf(w, "\n")
+}
+
+// header prints the header of the category of the command at index i if the
+// commands are categorized and it is the first of its category.
+func (t TextRenderer) header(w io.Writer, u *Usage, i int) {
+	if !u.Categorized || (i > 0 && u.Commands[i-1].Category == u.Commands[i].Category) {
+		return
+	}
+
+	category := u.Commands[i].Category
+	if category == "" {
+		category = u.Messages.merge().Commands
+	}
+
+	if i > 0 {
+		fmt.Fprintf(w, "\n")
+	}
+
+	fmt.Fprintf(w, "%s\n", t.bold(category+":"))
+}
+
+// options prints options aligned in columns.
+func (t TextRenderer) options(w io.Writer, options []OptionUsage, length int) {
+	for _, o := range options {
+		if n := len(o.String()); n > length {
+			length = n
+		}
+	}
+
+	for _, o := range options {
+		option := o.String()
+		spaces := strings.Repeat(" ", length+t.padding()-len(option))
+		fmt.Fprintf(w, "  %s%s%s\n", t.dim(option), spaces, t.wrap(o.usage(), 2+length+t.padding()))
+	}
+}
+
+// arguments prints arguments aligned in columns.
+func (t TextRenderer) arguments(w io.Writer, arguments []ArgumentUsage) {
+	length := 0
+	for _, arg := range arguments {
+		if len(arg.Name) > length {
+			length = len(arg.Name)
+		}
+	}
+
+	for _, arg := range arguments {
+		spaces := strings.Repeat(" ", length+t.padding()-len(arg.Name))
+		fmt.Fprintf(w, "  %s%s%s\n", t.bold(arg.Name), spaces, t.wrap(arg.Usage, 2+length+t.padding()))
+	}
+}
+
+// described reports whether any of the arguments has a description.
+func described(arguments []ArgumentUsage) bool {
+	for _, arg := range arguments {
+		if arg.Usage != "" {
+			return true
+		}
+	}
+
+	return false
+}
+
+// bold returns s in bold if the renderer colors its output.
+func (t TextRenderer) bold(s string) string {
+	if !t.Color {
+		return s
+	}
+
+	return ansiBold + s + ansiReset
+}
+
+// dim returns s dimmed if the renderer colors its output.
+func (t TextRenderer) dim(s string) string {
+	if !t.Color {
+		return s
+	}
+
+	return ansiDim + s + ansiReset
+}
+
+// wrap wraps the lines of s to the width of the renderer for printing from
+// the given column, indenting the continuation lines to the column. Text is
+// not wrapped to fewer than 20 columns.
+func (t TextRenderer) wrap(s string, column int) string {
+	limit := t.Width - column
+	if t.Width <= 0 || limit < 20 {
+		return s
+	}
+
+	var lines []string
+	for _, line := range strings.Split(s, "\n") {
+		words := strings.Fields(line)
+		if len(words) == 0 {
+			lines = append(lines, "")
+			continue
+		}
+
+		current := words[0]
+		for _, word := range words[1:] {
+			if len(current)+1+len(word) > limit {
+				lines = append(lines, current)
+				current = word
+				continue
+			}
+
+			current += " " + word
+		}
+
+		lines = append(lines, current)
+	}
+
+	return strings.Join(lines, "\n"+strings.Repeat(" ", column))
+}
+
+// padding returns the number of spaces separating the columns.
+func (t TextRenderer) padding() int {
+	if t.Padding <= 0 {
+		return 3
+	}
+
+	return t.Padding
+}
+
+// usage returns the usage of the option, noting its environment variable.
+func (o *OptionUsage) usage() string {
+	if o.Env == "" {
+		return o.Usage
+	}
+
+	return fmt.Sprintf("%s [$%s]", o.Usage, o.Env)
+}
+
+// usage describes the Application with the commands beginning with prefix.
+// Large applications are described compactly unless filtered.
+func (a *Application) usage(prefix string) *Usage {
+	names := a.ordered(a.visible(a.match(prefix), prefix))
+	u := &Usage{
+		Name:        a.name,
+		Version:     a.version,
+		Description: a.description,
+		About:       a.about,
+		Footer:      a.footer,
+		Messages:    a.messages,
+	}
+	names, u.Categorized = a.categorize(names)
+
+	global := flag.NewFlagSet(a.name, flag.ContinueOnError)
+	a.defineGlobal(global, &globals{})
+	u.Global = a.optionUsages(global, a.env)
+
+	if prefix == "" && len(names) > compactThreshold {
+		u.Compact = true
+		u.Commands = a.compact(topLevel(names))
+		return u
+	}
+
+	for _, name := range names {
+		r, _ := a.lookup(name)
+		if r.listed() {
+			u.Commands = append(u.Commands, *a.summaryUsage(r))
+			continue
+		}
+
+		if r.load() != nil {
+			continue
+		}
+
+		u.Commands = append(u.Commands, *a.commandUsage(r))
+	}
+
+	return u
+}
+
+// compact describes one entry per command, collapsing commands that share a
+// name prefix into a single entry. Collapsed commands are not instantiated.
+func (a *Application) compact(names []string) []CommandUsage {
+	// Collect the commands sharing each key, in order of first appearance.
+	var keys []string
+	groups := make(map[string][]string)
+	for _, name := range names {
+		key := groupKey(name)
+		if _, ok := groups[key]; !ok {
+			keys = append(keys, key)
+		}
+
+		groups[key] = append(groups[key], name)
+	}
+
+	var commands []CommandUsage
+	for _, key := range keys {
+		group := groups[key]
+		if len(group) > 1 {
+			commands = append(commands, CommandUsage{
+				Name:        key + "*",
+				Synopsis:    key + "*",
+				Category:    a.category(group[0]),
+				Description: fmt.Sprintf("%d commands, see '%s help %s'.", len(group), a.name, key),
+				Count:       len(group),
+			})
+			continue
+		}
+
+		r, _ := a.lookup(group[0])
+		if r.listed() {
+			commands = append(commands, *a.summaryUsage(r))
+			continue
+		}
+
+		if r.load() != nil {
+			continue
+		}
+
+		commands = append(commands, *a.commandUsage(r))
+	}
+
+	return commands
+}
+
+// commandUsage describes the loaded rule.
+func (a *Application) commandUsage(r *rule) *CommandUsage {
+	c := &CommandUsage{
+		Name:        r.name,
+		Synopsis:    r.String(),
+		Description: r.usage(&a.messages),
+		Category:    a.category(r.name),
+		Depth:       strings.Count(r.name, " "),
+		Arguments:   argumentUsages(r.positional()),
+		Options:     a.optionUsages(r.options, r.env),
+		Notes:       r.notes(&a.messages),
+		Examples:    r.allExamples(),
+	}
+
+	if note := a.versionNote(r); note != "" {
+		c.Notes = append(c.Notes, note)
+	}
+
+	if h, ok := r.command.(helper); ok {
+		c.Help = h.Help()
+	}
+
+	if u, ok := r.command.(usager); ok {
+		var buf bytes.Buffer
+		u.Usage(&buf)
+		c.Custom = buf.String()
+	}
+
+	return c
+}
+
+// optionUsages describes the flags with their environment variables.
+func (a *Application) optionUsages(flags *flag.FlagSet, env map[string]string) []OptionUsage {
+	var options []OptionUsage
+	short := shorthands(flags)
+	formatter := a.formatter
+	if formatter == nil {
+		formatter = StandardFormatter{}
+	}
+
+	flags.VisitAll(func(f *flag.Flag) {
+		if isShorthand(f) {
+			return
+		}
+
+		o := OptionUsage{
+			Name:     f.Name,
+			Short:    short[f.Name],
+			Value:    formatter.Placeholder(f),
+			Usage:    f.Usage,
+			Env:      a.envName(f.Name, env),
+			Repeated: repeated(f),
+		}
+		if named, usage := flag.UnquoteUsage(f); strings.Contains(f.Usage, "`"+named+"`") {
+			o.Usage = usage
+		}
+		if !isSecret(f) {
+			o.Default = formatter.Default(f)
+		}
+
+		options = append(options, o)
+	})
+
+	return options
+}
+
+// Placeholder implements the FlagValueFormatter interface.
+func (StandardFormatter) Placeholder(f *flag.Flag) string {
+	if named, _ := flag.UnquoteUsage(f); strings.Contains(f.Usage, "`"+named+"`") {
+		return "<" + named + ">"
+	}
+
+	value := f.DefValue
+	if value == "" || isSecret(f) {
+		return typeHint(f)
+	} else if value == "false" || isCounter(f) {
+		return ""
+	} else if _, err := strconv.Atoi(value); err == nil {
+		return "<n>"
+	}
+
+	return "\"" + value + "\""
+}
+
+// Default implements the FlagValueFormatter interface.
+func (StandardFormatter) Default(f *flag.Flag) string {
+	return f.DefValue
+}
+
+// typeHint returns a hint of the type of value of a flag without a default.
+func typeHint(f *flag.Flag) string {
+	v := f.Value
+	if s, ok := v.(*shorthand); ok {
+		v = s.Value
+	}
+
+	switch v.(type) {
+	case *Timestamp:
+		return "<time>"
+	case *Duration:
+		return "<duration>"
+	case *Size:
+		return "<size>"
+	}
+
+	return "<value>"
+}
@@ -0,0 +1,118 @@
+package cli
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+)
+
+type markdownRenderer struct {
+	TextRenderer
+}
+
+type countFormatter struct {
+	StandardFormatter
+}
+
+type runPlaceholder struct {
+	NullFlags
+}
+
+func TestHelpRenderer(t *testing.T) {
+	app := New("myapp", "0.0.1")
+	app.EnvPrefix("MYAPP")
+	app.Rule(&runRecord{}, "record", "[<a>] [<b>]")
+	app.SetHelpRenderer(markdownRenderer{})
+
+	var buf bytes.Buffer
+	app.PrintUsage(&buf)
+	want := "# myapp\n- help\n- version: Print the version and build information as JSON. (-json, $MYAPP_JSON)\n- record: exit code (-number=<n>, $MYAPP_NUMBER)\n"
+	if buf.String() != want {
+		t.Errorf("usage\nhave %q\nwant %q", buf.String(), want)
+	}
+
+	buf.Reset()
+	app.RunWithArgs([]string{"help", "record"}, &buf, &buf)
+	want = "Usage: myapp record [options] [<a>] [<b>]\n\nrecord arguments\n\nOptions:\n" +
+		"  -number=<n>   exit code [$MYAPP_NUMBER]\n\n## Environment\nMYAPP_NUMBER\n"
+	if buf.String() != want {
+		t.Errorf("help\nhave %q\nwant %q", buf.String(), want)
+	}
+}
+
+func TestTextRendererPadding(t *testing.T) {
+	app := New("myapp", "0.0.1")
+	app.Rule(&runRecord{}, "record", "[<a>] [<b>]")
+	app.SetHelpRenderer(TextRenderer{Padding: 1})
+
+	var buf bytes.Buffer
+	app.printHelp(&buf, app.rules["record"], false)
+	if !strings.Contains(buf.String(), "  -number=<n> exit code\n") {
+		t.Errorf("padding\n%s", buf.String())
+	}
+}
+
+func TestFlagValueFormatter(t *testing.T) {
+	app := New("myapp", "0.0.1")
+	app.Rule(&runPlaceholder{}, "format", "")
+
+	var buf bytes.Buffer
+	app.printHelp(&buf, app.rules["format"], false)
+	for _, want := range []string{"  -jobs=<n>   ", "  -out=<path>   Write to path.\n"} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("help missing %q\n%s", want, buf.String())
+		}
+	}
+
+	app.SetFlagValueFormatter(countFormatter{})
+	buf.Reset()
+	app.printHelp(&buf, app.rules["format"], false)
+	for _, want := range []string{"  -jobs=<count>   ", "  -out=<path>   "} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("help missing %q\n%s", want, buf.String())
+		}
+	}
+}
+
+func (f countFormatter) Placeholder(fl *flag.Flag) string {
+	if fl.Name == "jobs" {
+		return "<count>"
+	}
+
+	return f.StandardFormatter.Placeholder(fl)
+}
+
+func (c *runPlaceholder) Flags(flags *flag.FlagSet) {
+	flags.Int("jobs", 4, "Number of jobs.")
+	flags.String("out", "", "Write to `path`.")
+}
+
+func (c *runPlaceholder) Run() {}
+
+func (c *runPlaceholder) String() string {
+	return "format arguments"
+}
+
+func (r markdownRenderer) Usage(w io.Writer, u *Usage) {
+	fmt.Fprintf(w, "# %s\n", u.Name)
+	for _, c := range u.Commands {
+		if len(c.Options) == 0 {
+			fmt.Fprintf(w, "- %s\n", c.Name)
+			continue
+		}
+
+		o := c.Options[0]
+		fmt.Fprintf(w, "- %s: %s (%s, $%s)\n", c.Name, o.Usage, o.String(), o.Env)
+	}
+}
+
+func (r markdownRenderer) Help(w io.Writer, u *Usage, c *CommandUsage) {
+	r.TextRenderer.Help(w, u, c)
+	fmt.Fprintf(w, "## Environment\n")
+	for _, o := range c.Options {
+		fmt.Fprintf(w, "%s\n", o.Env)
+	}
+}
@@ -0,0 +1,130 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// StringSlice is a flag.Value accumulating the values of a repeated flag,
+// such as -tag a -tag b, see StringSliceVar.
+type StringSlice struct {
+	values []string
+	set    bool
+}
+
+// StringMap is a flag.Value accumulating key=value pairs of a repeated flag,
+// such as -label env=prod -label team=web, see StringMapVar.
+type StringMap struct {
+	values map[string]string
+	set    bool
+}
+
+// A repeatable is a flag.Value accumulating the values of a repeated flag.
+type repeatable interface {
+	Repeatable() bool
+}
+
+// StringSliceVar defines a flag with the specified name, default values and
+// usage string that may be repeated. Each value is appended, with values
+// separated by commas split, such that -tag a,b is -tag a -tag b. Setting
+// the flag replaces the defaults.
+func StringSliceVar(flags *flag.FlagSet, name string, value []string, usage string) *StringSlice {
+	s := &StringSlice{values: value}
+	flags.Var(s, name, usage+" May be repeated.")
+	return s
+}
+
+// StringMapVar defines a flag with the specified name, default values and
+// usage string accepting key=value pairs that may be repeated. Pairs
+// separated by commas are split and later values of a key replace earlier
+// ones. Setting the flag replaces the defaults.
+func StringMapVar(flags *flag.FlagSet, name string, value map[string]string, usage string) *StringMap {
+	m := &StringMap{values: value}
+	flags.Var(m, name, usage+" May be repeated.")
+	return m
+}
+
+// Values returns the values of the flag.
+func (s *StringSlice) Values() []string {
+	return s.values
+}
+
+// String implements the flag.Value interface.
+func (s *StringSlice) String() string {
+	if s == nil {
+		return ""
+	}
+
+	return strings.Join(s.values, ",")
+}
+
+// Set implements the flag.Value interface.
+func (s *StringSlice) Set(value string) error {
+	if !s.set {
+		s.values, s.set = nil, true
+	}
+
+	s.values = append(s.values, strings.Split(value, ",")...)
+	return nil
+}
+
+// Repeatable reports that the flag may be repeated.
+func (s *StringSlice) Repeatable() bool {
+	return true
+}
+
+// Values returns the pairs of the flag.
+func (m *StringMap) Values() map[string]string {
+	return m.values
+}
+
+// String implements the flag.Value interface.
+func (m *StringMap) String() string {
+	if m == nil {
+		return ""
+	}
+
+	pairs := make([]string, 0, len(m.values))
+	for k, v := range m.values {
+		pairs = append(pairs, k+"="+v)
+	}
+
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
+
+// Set implements the flag.Value interface.
+func (m *StringMap) Set(value string) error {
+	if !m.set {
+		m.values, m.set = make(map[string]string), true
+	}
+
+	for _, pair := range strings.Split(value, ",") {
+		i := strings.Index(pair, "=")
+		if i < 1 {
+			return fmt.Errorf("expected key=value, got %q", pair)
+		}
+
+		m.values[pair[:i]] = pair[i+1:]
+	}
+
+	return nil
+}
+
+// Repeatable reports that the flag may be repeated.
+func (m *StringMap) Repeatable() bool {
+	return true
+}
+
+// repeated reports whether the flag may be repeated.
+func repeated(f *flag.Flag) bool {
+	v := f.Value
+	if s, ok := v.(*shorthand); ok {
+		v = s.Value
+	}
+
+	r, ok := v.(repeatable)
+	return ok && r.Repeatable()
+}
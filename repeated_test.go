@@ -0,0 +1,53 @@
+package cli
+
+import (
+	"bytes"
+	"flag"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestStringSlice(t *testing.T) {
+	var (
+		tags   *StringSlice
+		labels *StringMap
+	)
+	app := New("myapp", "0.0.1")
+	app.RuleFunc("tag", "Tag things.", "", func() {}, DefineFlags(func(flags *flag.FlagSet) {
+		tags = StringSliceVar(flags, "tag", []string{"latest"}, "Tag to apply.")
+		labels = StringMapVar(flags, "label", nil, "Label to apply.")
+	}))
+
+	tests := []struct {
+		args   []string
+		tags   []string
+		labels map[string]string
+	}{
+		{[]string{"tag"}, []string{"latest"}, nil},
+		{[]string{"tag", "-tag", "a", "-tag", "b,c"}, []string{"a", "b", "c"}, nil},
+		{[]string{"tag", "-label", "env=prod", "-label", "team=web,env=dev"}, []string{"latest"}, map[string]string{"env": "dev", "team": "web"}},
+	}
+
+	for _, tt := range tests {
+		code := app.Dispatch(tt.args)
+		if code != 0 || !reflect.DeepEqual(tags.Values(), tt.tags) || !reflect.DeepEqual(labels.Values(), tt.labels) {
+			t.Errorf("%q\nhave %d %q %q\nwant %d %q %q", tt.args, code, tags.Values(), labels.Values(), 0, tt.tags, tt.labels)
+		}
+	}
+
+	var stdout, stderr bytes.Buffer
+	code := app.RunWithArgs([]string{"tag", "-label", "env"}, &stdout, &stderr)
+	if code != ExitUsage || !strings.Contains(stderr.String(), `expected key=value, got "env"`) {
+		t.Errorf("invalid pair\nhave %d %q", code, stderr.String())
+	}
+
+	c := app.commandUsage(app.rules["tag"])
+	if len(c.Options) != 2 || !c.Options[0].Repeated || !strings.HasSuffix(c.Options[0].Usage, "May be repeated.") {
+		t.Errorf("usage\nhave %+v", c.Options)
+	}
+
+	if have := (&StringMap{values: map[string]string{"b": "2", "a": "1"}}).String(); have != "a=1,b=2" {
+		t.Errorf("String\nhave %q\nwant %q", have, "a=1,b=2")
+	}
+}
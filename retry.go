@@ -0,0 +1,139 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"math/rand"
+	"time"
+)
+
+// retryPolicy describes how failed invocations of a rule are retried.
+type retryPolicy struct {
+	retries     int
+	delay       time.Duration
+	codes       []int
+	retryIf     func(err error) bool
+	parsedTries *int
+	parsedDelay *time.Duration
+}
+
+// A RetryableError is an error reporting whether the operation that failed
+// may succeed if retried, see Retry.
+type RetryableError interface {
+	error
+	Retryable() bool
+}
+
+// Retry is a RuleOption that runs the command again when it exits with one of
+// the given exit codes, or any non-zero exit code if none are given, up to
+// retries more times. A command returning an error is instead run again if
+// the error is retryable, as reported by the predicate of RetryIf, or by an
+// error in its chain implementing RetryableError or a Temporary method like
+// that of some network errors. The delay before each retry doubles, starting
+// from delay, with random jitter, and retries stop once the invocation is
+// cancelled. The command gains -retries and -retry-delay flags so that users
+// may override the defaults.
+func Retry(retries int, delay time.Duration, codes ...int) RuleOption {
+	return func(r *rule) {
+		if r.retry == nil {
+			r.retry = &retryPolicy{}
+		}
+
+		r.retry.retries = retries
+		r.retry.delay = delay
+		r.retry.codes = codes
+	}
+}
+
+// RetryIf is a RuleOption setting the predicate deciding whether an error
+// returned by the command is retried, see Retry.
+func RetryIf(fn func(err error) bool) RuleOption {
+	return func(r *rule) {
+		if r.retry == nil {
+			r.retry = &retryPolicy{}
+		}
+
+		r.retry.retryIf = fn
+	}
+}
+
+// define defines the retry flags on flags.
+func (p *retryPolicy) define(flags *flag.FlagSet) {
+	if p == nil {
+		return
+	}
+
+	p.parsedTries = flags.Int("retries", p.retries, "Number of times to retry on failure.")
+	p.parsedDelay = flags.Duration("retry-delay", p.delay, "Initial delay between retries.")
+}
+
+// again reports whether to retry after the given attempt exited with code,
+// having reported err, sleeping for the backoff period first. It returns
+// false if ctx is done before the period elapses.
+func (p *retryPolicy) again(ctx context.Context, w io.Writer, m *Messages, name string, attempt, code int, err error) bool {
+	if p == nil || attempt > *p.parsedTries || !p.retryable(code, err) || ctx.Err() != nil {
+		return false
+	}
+
+	delay := p.backoff(attempt)
+	fmt.Fprintf(w, m.Retrying+"\n", name, delay, attempt, *p.parsedTries)
+
+	t := time.NewTimer(delay)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-t.C:
+		return true
+	}
+}
+
+// retryable reports whether an invocation exiting with code, having reported
+// err, may be retried.
+func (p *retryPolicy) retryable(code int, err error) bool {
+	if err != nil && p.retryIf != nil {
+		return p.retryIf(err)
+	}
+
+	var retryable RetryableError
+	if errors.As(err, &retryable) {
+		return retryable.Retryable()
+	}
+
+	var temporary interface{ Temporary() bool }
+	if errors.As(err, &temporary) {
+		return temporary.Temporary()
+	}
+
+	// Errors are only retried if classified as retryable.
+	if err != nil {
+		return false
+	}
+
+	if len(p.codes) == 0 {
+		return code != 0
+	}
+
+	for _, c := range p.codes {
+		if c == code {
+			return true
+		}
+	}
+
+	return false
+}
+
+// backoff returns the delay before the given retry attempt. The delay doubles
+// with each attempt and is randomly reduced by up to half to avoid retrying in
+// lockstep with other clients.
+func (p *retryPolicy) backoff(attempt int) time.Duration {
+	d := *p.parsedDelay << uint(attempt-1)
+	if d <= 0 {
+		return 0
+	}
+
+	return d/2 + time.Duration(rand.Int63n(int64(d/2)+1))
+}
@@ -0,0 +1,100 @@
+package cli
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"testing"
+	"time"
+)
+
+type runFlaky struct {
+	*NullFlags
+	calls int
+}
+
+type flakyError bool
+
+func TestRetry(t *testing.T) {
+	tests := []struct {
+		args  []string
+		code  int
+		calls int
+	}{
+		{[]string{"flaky"}, 0, 3},
+		{[]string{"flaky", "-retries", "1"}, 75, 2},
+		{[]string{"flaky", "-retries", "0"}, 75, 1},
+	}
+
+	for _, tt := range tests {
+		app := New("myapp", "0.0.1")
+		cmd := &runFlaky{}
+		err := app.Rule(cmd, "flaky", "", Retry(5, time.Millisecond, 75))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		app.stderr = &bytes.Buffer{}
+		code := app.Dispatch(tt.args)
+		if code != tt.code || cmd.calls != tt.calls {
+			t.Errorf("%v\nhave %d after %d calls\nwant %d after %d calls", tt.args, code, cmd.calls, tt.code, tt.calls)
+		}
+	}
+}
+
+func TestRetryErrors(t *testing.T) {
+	tests := []struct {
+		errs   []error
+		option RuleOption
+		code   int
+		calls  int
+	}{
+		{[]error{flakyError(true), flakyError(true), nil}, nil, 0, 3},
+		{[]error{flakyError(false), nil}, nil, 1, 1},
+		{[]error{fmt.Errorf("dial: %w", flakyError(true)), nil}, nil, 0, 2},
+		{[]error{io.ErrUnexpectedEOF, nil}, RetryIf(func(err error) bool { return err == io.ErrUnexpectedEOF }), 0, 2},
+		{[]error{flakyError(true), nil}, RetryIf(func(err error) bool { return false }), 1, 1},
+		{[]error{io.ErrUnexpectedEOF, nil}, Retry(5, time.Millisecond), 1, 1},
+	}
+
+	for i, tt := range tests {
+		app := New("myapp", "0.0.1")
+		calls := 0
+		options := []RuleOption{Retry(5, time.Millisecond, 75)}
+		if tt.option != nil {
+			options = append(options, tt.option)
+		}
+
+		app.RuleFunc("fetch", "Fetch it.", "", func() error {
+			calls++
+			return tt.errs[calls-1]
+		}, options...)
+
+		app.stderr = &bytes.Buffer{}
+		code := app.Dispatch([]string{"fetch"})
+		if code != tt.code || calls != tt.calls {
+			t.Errorf("%d\nhave %d after %d calls\nwant %d after %d calls", i, code, calls, tt.code, tt.calls)
+		}
+	}
+}
+
+func (e flakyError) Error() string {
+	return "flaky"
+}
+
+func (e flakyError) Retryable() bool {
+	return bool(e)
+}
+
+func (c *runFlaky) Run() int {
+	c.calls++
+	if c.calls < 3 {
+		return 75
+	}
+
+	return 0
+}
+
+func (c *runFlaky) String() string {
+	return "fails twice"
+}
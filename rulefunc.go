@@ -0,0 +1,42 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"reflect"
+)
+
+// funcCommand adapts a function to a command, see RuleFunc.
+type funcCommand struct {
+	description string
+	fn          reflect.Value
+}
+
+var errRuleFunc = fmt.Errorf("rule: RuleFunc requires a function")
+
+// RuleFunc registers the function fn as a command with the given name,
+// description and arguments, for commands too small to justify a type of
+// their own. The function is called as the Run method of a command would be
+// and must satisfy the same requirements, see Rule. Its flags may be defined
+// with the DefineFlags option.
+func (a *Application) RuleFunc(name, description, arguments string, fn interface{}, options ...RuleOption) error {
+	v := reflect.ValueOf(fn)
+	if v.Kind() != reflect.Func || v.IsNil() {
+		return errRuleFunc
+	}
+
+	return a.Rule(&funcCommand{description: description, fn: v}, name, arguments, options...)
+}
+
+// DefineFlags is a RuleOption calling fn to define flags of the command
+// alongside those of its Flags method, if any. Like the Flags method, fn is
+// called with a new FlagSet for each invocation.
+func DefineFlags(fn func(flags *flag.FlagSet)) RuleOption {
+	return func(r *rule) {
+		r.define = append(r.define, fn)
+	}
+}
+
+func (c *funcCommand) String() string {
+	return c.description
+}
@@ -0,0 +1,59 @@
+package cli
+
+import (
+	"context"
+	"flag"
+	"reflect"
+	"testing"
+)
+
+func TestRuleFunc(t *testing.T) {
+	app := New("myapp", "0.0.1")
+
+	var (
+		times *int
+		have  []string
+	)
+	err := app.RuleFunc("greet", "Greet people.", "<name>...", func(ctx context.Context, names []string) int {
+		for i := 0; i < *times; i++ {
+			have = append(have, names...)
+		}
+
+		return len(names)
+	}, DefineFlags(func(flags *flag.FlagSet) {
+		times = flags.Int("times", 1, "Number of greetings.")
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	code := app.Dispatch([]string{"greet", "-times", "2", "a", "b"})
+	if code != 2 {
+		t.Errorf("exit code\nhave %d\nwant %d", code, 2)
+	}
+
+	if want := []string{"a", "b", "a", "b"}; !reflect.DeepEqual(have, want) {
+		t.Errorf("arguments\nhave %q\nwant %q", have, want)
+	}
+
+	if app.rules["greet"].command.String() != "Greet people." {
+		t.Errorf("description\nhave %q", app.rules["greet"].command.String())
+	}
+
+	tests := []struct {
+		fn   interface{}
+		want error
+	}{
+		{"not a function", errRuleFunc},
+		{(func())(nil), errRuleFunc},
+		{func(c chan int) {}, errRunString},
+		{func() string { return "" }, errRunReturnValue},
+	}
+
+	for _, tt := range tests {
+		err := app.RuleFunc("bad", "", "", tt.fn)
+		if err != tt.want {
+			t.Errorf("%T\nhave %v\nwant %v", tt.fn, err, tt.want)
+		}
+	}
+}
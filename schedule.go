@@ -0,0 +1,263 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// A Scheduler dispatches to commands of an Application on cron schedules
+// within a long running command, such as a server.
+type Scheduler struct {
+	app  *Application
+	jobs []*job
+}
+
+type job struct {
+	spec    string
+	sched   *cronSchedule
+	args    []string
+	running sync.Mutex
+}
+
+// cronSchedule is a parsed cron expression. Each field is a bit set of the
+// values it matches.
+type cronSchedule struct {
+	minute, hour, dom, month, dow uint64
+	anyDom, anyDow                bool
+}
+
+var cronAliases = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// NewScheduler returns a Scheduler for the Application of the invocation of
+// the command that received ctx.
+func NewScheduler(ctx context.Context) *Scheduler {
+	return &Scheduler{app: ctxInvocation(ctx).app}
+}
+
+// Add schedules the command invocation described by args, as they would be
+// given on the command line, to run according to the cron expression spec.
+//
+// The expression has five fields: minute, hour, day of month, month and day
+// of week. Each field may be *, a value, a range such as 1-5, a step such as
+// */15 or 0-30/10, or a comma separated list of these. Months and days of the
+// week must be numeric, with Sunday as 0 or 7. The aliases @yearly, @monthly,
+// @weekly, @daily and @hourly are also accepted.
+func (s *Scheduler) Add(spec string, args ...string) error {
+	sched, err := parseCron(spec)
+	if err != nil {
+		return err
+	}
+
+	if len(args) == 0 {
+		return fmt.Errorf("schedule: missing command for %q", spec)
+	}
+
+	if _, ok := s.app.lookup(args[0]); !ok {
+		return fmt.Errorf("schedule: invalid command %s", args[0])
+	}
+
+	s.jobs = append(s.jobs, &job{spec: spec, sched: sched, args: args})
+
+	return nil
+}
+
+// Run dispatches to the scheduled commands as they become due until ctx is
+// done, then waits for any running commands to complete. The outcome of each
+// run is logged to the standard error of the invocation. A run is skipped if
+// the previous run of the same job is still in progress.
+func (s *Scheduler) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+	defer wg.Wait()
+
+	for {
+		now := time.Now()
+		next := time.Time{}
+		for _, j := range s.jobs {
+			t := j.sched.next(now)
+			if next.IsZero() || t.Before(next) {
+				next = t
+			}
+		}
+
+		if next.IsZero() {
+			return
+		}
+
+		timer := time.NewTimer(next.Sub(now))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		for _, j := range s.jobs {
+			if !j.sched.next(next.Add(-time.Minute)).Equal(next) {
+				continue
+			}
+
+			wg.Add(1)
+			go func(j *job) {
+				defer wg.Done()
+				s.run(ctx, j)
+			}(j)
+		}
+	}
+}
+
+// run dispatches to the job unless its previous run is still in progress.
+func (s *Scheduler) run(ctx context.Context, j *job) {
+	w := Stderr(ctx)
+	name := strings.Join(j.args, " ")
+	if !j.running.TryLock() {
+		fmt.Fprintf(w, "schedule: %s: %s\n", name, s.app.messages.ScheduleSkipped)
+		return
+	}
+	defer j.running.Unlock()
+
+	start := time.Now()
+	code := s.app.dispatch(ctx, j.args)
+	fmt.Fprintf(w, "schedule: %s: "+s.app.messages.ScheduleExited+"\n", name, code, time.Since(start).Round(time.Millisecond))
+}
+
+// parseCron parses a five field cron expression.
+func parseCron(spec string) (*cronSchedule, error) {
+	expr := spec
+	if alias, ok := cronAliases[spec]; ok {
+		expr = alias
+	}
+
+	fields := strings.Fields(expr)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("schedule: %q: expected 5 fields", spec)
+	}
+
+	bounds := [5][2]int{{0, 59}, {0, 23}, {1, 31}, {1, 12}, {0, 7}}
+	var sets [5]uint64
+	for i, field := range fields {
+		set, err := parseCronField(field, bounds[i][0], bounds[i][1])
+		if err != nil {
+			return nil, fmt.Errorf("schedule: %q: %v", spec, err)
+		}
+
+		sets[i] = set
+	}
+
+	// Sunday may be given as either 0 or 7.
+	if sets[4]&(1<<7) != 0 {
+		sets[4] |= 1
+	}
+
+	return &cronSchedule{
+		minute: sets[0],
+		hour:   sets[1],
+		dom:    sets[2],
+		month:  sets[3],
+		dow:    sets[4],
+		anyDom: strings.HasPrefix(fields[2], "*"),
+		anyDow: strings.HasPrefix(fields[4], "*"),
+	}, nil
+}
+
+// parseCronField parses a single field of a cron expression.
+func parseCronField(field string, min, max int) (uint64, error) {
+	var set uint64
+	for _, part := range strings.Split(field, ",") {
+		step := 1
+		if i := strings.Index(part, "/"); i >= 0 {
+			n, err := strconv.Atoi(part[i+1:])
+			if err != nil || n < 1 {
+				return 0, fmt.Errorf("invalid step %q", part)
+			}
+
+			step = n
+			part = part[:i]
+		}
+
+		lo, hi := min, max
+		if part != "*" {
+			bounds := strings.SplitN(part, "-", 2)
+			n, err := strconv.Atoi(bounds[0])
+			if err != nil {
+				return 0, fmt.Errorf("invalid value %q", part)
+			}
+
+			lo, hi = n, n
+			if len(bounds) == 2 {
+				hi, err = strconv.Atoi(bounds[1])
+				if err != nil {
+					return 0, fmt.Errorf("invalid range %q", part)
+				}
+			} else if step > 1 {
+				hi = max
+			}
+		}
+
+		if lo < min || hi > max || lo > hi {
+			return 0, fmt.Errorf("%q out of range %d-%d", part, min, max)
+		}
+
+		for v := lo; v <= hi; v += step {
+			set |= 1 << uint(v)
+		}
+	}
+
+	return set, nil
+}
+
+// next returns the first time after t matching the schedule, or the zero
+// time if there is none within five years.
+func (c *cronSchedule) next(t time.Time) time.Time {
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	limit := t.AddDate(5, 0, 0)
+	for t.Before(limit) {
+		if c.month&(1<<uint(t.Month())) == 0 {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+
+		if !c.matchDay(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+
+		if c.hour&(1<<uint(t.Hour())) == 0 {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+			continue
+		}
+
+		if c.minute&(1<<uint(t.Minute())) == 0 {
+			t = t.Add(time.Minute)
+			continue
+		}
+
+		return t
+	}
+
+	return time.Time{}
+}
+
+// matchDay reports whether the day of t matches the schedule. As with cron,
+// if both the day of month and day of week are restricted, either may match.
+// A field beginning with *, such as */2, is not restricted.
+func (c *cronSchedule) matchDay(t time.Time) bool {
+	dom := c.dom&(1<<uint(t.Day())) != 0
+	dow := c.dow&(1<<uint(t.Weekday())) != 0
+	if c.anyDom || c.anyDow {
+		return dom && dow
+	}
+
+	return dom || dow
+}
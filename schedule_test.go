@@ -0,0 +1,46 @@
+package cli
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCronNext(t *testing.T) {
+	from := time.Date(2024, time.February, 28, 23, 59, 30, 0, time.UTC)
+	tests := []struct {
+		spec string
+		want time.Time
+	}{
+		{"* * * * *", time.Date(2024, time.February, 29, 0, 0, 0, 0, time.UTC)},
+		{"*/15 9-17 * * 1-5", time.Date(2024, time.February, 29, 9, 0, 0, 0, time.UTC)},
+		{"30 4 1,15 * *", time.Date(2024, time.March, 1, 4, 30, 0, 0, time.UTC)},
+		{"0 0 * * 7", time.Date(2024, time.March, 3, 0, 0, 0, 0, time.UTC)},
+		{"0 12 13 * 5", time.Date(2024, time.March, 1, 12, 0, 0, 0, time.UTC)},
+		{"0 0 */2 * 1", time.Date(2024, time.March, 11, 0, 0, 0, 0, time.UTC)},
+		{"@yearly", time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 0 30 2 *", time.Time{}},
+	}
+
+	for _, tt := range tests {
+		sched, err := parseCron(tt.spec)
+		if err != nil {
+			t.Errorf("%q: unexpected error: %v", tt.spec, err)
+			continue
+		}
+
+		have := sched.next(from)
+		if !have.Equal(tt.want) {
+			t.Errorf("%q\nhave %v\nwant %v", tt.spec, have, tt.want)
+		}
+	}
+}
+
+func TestCronInvalid(t *testing.T) {
+	specs := []string{"* * * *", "60 * * * *", "*/0 * * * *", "5-1 * * * *", "a * * * *"}
+	for _, spec := range specs {
+		_, err := parseCron(spec)
+		if err == nil {
+			t.Errorf("%q: expected error", spec)
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package cli
+
+import (
+	"context"
+	"os"
+	"sync"
+)
+
+// scopeMu serializes commands changing the working directory or environment
+// of the process, which are shared by all goroutines.
+var scopeMu sync.Mutex
+
+type scopeContextKey struct{}
+
+// InDir is a RuleOption changing the working directory to path while the
+// command runs and restoring it afterward, such as for tools whose commands
+// operate in a subdirectory of a repository. A relative path is relative to
+// the working directory when the command is dispatched.
+//
+// The working directory is shared by the whole process, so commands scoped
+// with InDir or WithEnv do not run concurrently with one another.
+func InDir(path string) RuleOption {
+	return Wrap(scoped(func() (func(), error) {
+		wd, err := os.Getwd()
+		if err != nil {
+			return nil, err
+		}
+
+		err = os.Chdir(path)
+		if err != nil {
+			return nil, err
+		}
+
+		return func() { os.Chdir(wd) }, nil
+	}))
+}
+
+// WithEnv is a RuleOption setting the environment variables of env while the
+// command runs and restoring their previous values afterward. Flags are
+// parsed before the variables are set, see EnvPrefix. Like InDir, commands
+// scoped with WithEnv do not run concurrently with one another.
+func WithEnv(env map[string]string) RuleOption {
+	return Wrap(scoped(func() (func(), error) {
+		var restore []func()
+		undo := func() {
+			for i := len(restore) - 1; i >= 0; i-- {
+				restore[i]()
+			}
+		}
+
+		for key, value := range env {
+			key := key
+			if prev, ok := os.LookupEnv(key); ok {
+				restore = append(restore, func() { os.Setenv(key, prev) })
+			} else {
+				restore = append(restore, func() { os.Unsetenv(key) })
+			}
+
+			err := os.Setenv(key, value)
+			if err != nil {
+				undo()
+				return nil, err
+			}
+		}
+
+		return undo, nil
+	}))
+}
+
+// scoped returns Middleware calling enter before the command runs and the
+// function it returns after, holding scopeMu unless the command was
+// dispatched from a command that already holds it.
+func scoped(enter func() (func(), error)) Middleware {
+	return func(next RunFunc) RunFunc {
+		return func(ctx context.Context, args []string) (int, error) {
+			if ctx.Value(scopeContextKey{}) == nil {
+				scopeMu.Lock()
+				defer scopeMu.Unlock()
+				ctx = context.WithValue(ctx, scopeContextKey{}, true)
+			}
+
+			exit, err := enter()
+			if err != nil {
+				return 1, err
+			}
+			defer exit()
+
+			return next(ctx, args)
+		}
+	}
+}
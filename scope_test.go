@@ -0,0 +1,61 @@
+package cli
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestScope(t *testing.T) {
+	dir := t.TempDir()
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	os.Unsetenv("CLI_SCOPE_NEW")
+	t.Setenv("CLI_SCOPE_OLD", "old")
+
+	var have []string
+	app := New("myapp", "0.0.1")
+	app.RuleFunc("where", "Print the directory.", "", func(ctx context.Context) {
+		d, _ := os.Getwd()
+		have = []string{d, os.Getenv("CLI_SCOPE_NEW"), os.Getenv("CLI_SCOPE_OLD")}
+		app.dispatch(ctx, []string{"nested"})
+	}, InDir(dir), WithEnv(map[string]string{"CLI_SCOPE_NEW": "new", "CLI_SCOPE_OLD": "changed"}))
+	app.RuleFunc("nested", "Run nested.", "", func() {}, InDir("."))
+	app.RuleFunc("missing", "Run nowhere.", "", func() {}, InDir(filepath.Join(dir, "missing")))
+
+	code := app.Dispatch([]string{"where"})
+	want := []string{dir, "new", "changed"}
+	if code != 0 || have == nil || !sameDir(have[0], want[0]) || have[1] != want[1] || have[2] != want[2] {
+		t.Errorf("scoped\nhave %d %q\nwant %d %q", code, have, 0, want)
+	}
+
+	d, _ := os.Getwd()
+	if d != wd || os.Getenv("CLI_SCOPE_OLD") != "old" {
+		t.Errorf("restored\nhave %q %q\nwant %q %q", d, os.Getenv("CLI_SCOPE_OLD"), wd, "old")
+	}
+
+	if _, ok := os.LookupEnv("CLI_SCOPE_NEW"); ok {
+		t.Errorf("unset\nhave CLI_SCOPE_NEW set\nwant unset")
+	}
+
+	code = app.RunWithArgs([]string{"missing"}, io.Discard, io.Discard)
+	if code != 1 {
+		t.Errorf("missing directory exit code\nhave %d\nwant %d", code, 1)
+	}
+}
+
+// sameDir reports whether a and b name the same directory.
+func sameDir(a, b string) bool {
+	ai, err := os.Stat(a)
+	if err != nil {
+		return false
+	}
+
+	bi, err := os.Stat(b)
+	return err == nil && os.SameFile(ai, bi)
+}
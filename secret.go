@@ -0,0 +1,190 @@
+package cli
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+)
+
+// redacted replaces the values of secrets wherever the framework prints them.
+const redacted = "********"
+
+// A Secret is a flag.Value holding sensitive data such as an access token.
+// Its value is never printed by the framework. Define one with SecretVar, or
+// mark an existing flag with MarkSecret.
+type Secret struct {
+	name   string
+	env    string
+	file   string
+	value  string
+	given  bool
+	target flag.Value
+}
+
+// SecretVar defines a flag with the specified name and usage string whose
+// value is sensitive, along with a name-file flag naming a file to read the
+// value from. Values given on the command line are visible to other users of
+// the system, so the file or the environment variable env, if not empty,
+// should be preferred. If none are given and stdin is a terminal, the user is
+// prompted for the value without echoing it.
+func SecretVar(flags *flag.FlagSet, name, env, usage string) *Secret {
+	s := &Secret{name: name, env: env}
+	if env != "" {
+		usage += fmt.Sprintf(" Defaults to $%s.", env)
+	}
+
+	flags.Var(s, name, usage)
+	s.defineFile(flags)
+	return s
+}
+
+// MarkSecret marks the flag with the given name, which must already be
+// defined, as secret like one defined with SecretVar. Its value is redacted
+// wherever the framework prints it and may be read from a file named by a
+// name-file flag, the environment variable env, if not empty, or a prompt.
+// The value is set on the flag as given, so that it may be of any type.
+func MarkSecret(flags *flag.FlagSet, name, env string) {
+	f := flags.Lookup(name)
+	if f == nil {
+		panic("cli: secret of undefined flag -" + name)
+	}
+
+	s := &Secret{name: name, env: env, target: f.Value}
+	if env != "" {
+		f.Usage += fmt.Sprintf(" Defaults to $%s.", env)
+	}
+
+	f.Value = s
+	s.defineFile(flags)
+}
+
+// defineFile defines the flag naming a file to read the secret from.
+func (s *Secret) defineFile(flags *flag.FlagSet) {
+	flags.Func(s.name+"-file", "Read the value of -"+s.name+" from a file.", func(path string) error {
+		s.file = path
+		return nil
+	})
+}
+
+// Value returns the secret.
+func (s *Secret) Value() string {
+	return s.value
+}
+
+// String implements the flag.Value interface. The value is redacted.
+func (s *Secret) String() string {
+	if s == nil || !s.set() {
+		return ""
+	}
+
+	return redacted
+}
+
+// Set implements the flag.Value interface.
+func (s *Secret) Set(value string) error {
+	if s.target != nil {
+		err := s.target.Set(value)
+		if err != nil {
+			return err
+		}
+	}
+
+	s.value = value
+	s.given = true
+	return nil
+}
+
+// raw returns the value of the secret, unlike String, or the value of the
+// flag it marks.
+func (s *Secret) raw() string {
+	if s.target != nil {
+		return s.target.String()
+	}
+
+	return s.value
+}
+
+// rawValue returns the value of f, unredacted if it is a secret.
+func rawValue(f *flag.Flag) string {
+	if s, ok := f.Value.(*Secret); ok {
+		return s.raw()
+	}
+
+	return f.Value.String()
+}
+
+// set reports whether the secret was given a value, rather than holding the
+// default of the flag it marks.
+func (s *Secret) set() bool {
+	return s.given
+}
+
+// resolve sources the value of the secret from its file, environment
+// variable or a prompt if it was not given on the command line.
+func (s *Secret) resolve(ctx context.Context) error {
+	if s.set() {
+		return nil
+	}
+
+	if s.file != "" {
+		data, err := ioutil.ReadFile(s.file)
+		if err != nil {
+			return err
+		}
+
+		return s.Set(strings.TrimRight(string(data), "\r\n"))
+	}
+
+	if s.env != "" {
+		if value := os.Getenv(s.env); value != "" {
+			return s.Set(value)
+		}
+	}
+
+	if NoInput(ctx) || !isTerminal(Stdin(ctx)) {
+		return nil
+	}
+
+	value, err := Password(ctx, s.name)
+	if err != nil {
+		return err
+	}
+
+	return s.Set(value)
+}
+
+// resolveSecrets resolves the values of the secrets defined on flags.
+func resolveSecrets(ctx context.Context, flags *flag.FlagSet) error {
+	var err error
+	flags.VisitAll(func(f *flag.Flag) {
+		s, ok := baseValue(f.Value).(*Secret)
+		if ok && err == nil {
+			err = s.resolve(ctx)
+		}
+	})
+
+	return err
+}
+
+// isSecret reports whether the value of f must not be printed.
+func isSecret(f *flag.Flag) bool {
+	_, ok := baseValue(f.Value).(*Secret)
+	return ok
+}
+
+// baseValue returns the flag.Value wrapped by Shorthand and Validate, if any.
+func baseValue(v flag.Value) flag.Value {
+	for {
+		switch w := v.(type) {
+		case *shorthand:
+			v = w.Value
+		case *validated:
+			v = w.Value
+		default:
+			return v
+		}
+	}
+}
@@ -0,0 +1,147 @@
+package cli
+
+import (
+	"bytes"
+	"flag"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+)
+
+type runSecret struct {
+	token *Secret
+	have  string
+}
+
+func TestSecret(t *testing.T) {
+	f, err := ioutil.TempFile("", "cli-secret")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer os.Remove(f.Name())
+	f.WriteString("from-file\n")
+	f.Close()
+
+	t.Setenv("MYAPP_TOKEN", "from-env")
+
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{[]string{"login", "-token", "from-flag"}, "from-flag"},
+		{[]string{"login", "-token-file", f.Name()}, "from-file"},
+		{[]string{"login"}, "from-env"},
+	}
+
+	for _, tt := range tests {
+		app := New("myapp", "0.0.1")
+		cmd := &runSecret{}
+		app.Rule(cmd, "login", "")
+		app.stdin = strings.NewReader("")
+
+		app.Dispatch(tt.args)
+		if cmd.have != tt.want {
+			t.Errorf("%v\nhave %q\nwant %q", tt.args, cmd.have, tt.want)
+		}
+	}
+}
+
+func TestSecretRedacted(t *testing.T) {
+	app := New("myapp", "0.0.1")
+	cmd := &runSecret{}
+	app.Rule(cmd, "login", "")
+	cmd.token.Set("hunter2")
+
+	var buf bytes.Buffer
+	app.PrintUsage(&buf)
+	if strings.Contains(buf.String(), "hunter2") {
+		t.Errorf("secret printed in usage\n%s", buf.String())
+	}
+
+	if have := cmd.token.String(); have != redacted {
+		t.Errorf("String\nhave %q\nwant %q", have, redacted)
+	}
+}
+
+func TestMarkSecret(t *testing.T) {
+	f, err := ioutil.TempFile("", "cli-secret")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer os.Remove(f.Name())
+	f.WriteString("from-file\n")
+	f.Close()
+
+	var (
+		have  string
+		token *string
+	)
+	app := New("myapp", "0.0.1")
+	app.RuleFunc("push", "Push it.", "", func() { have = *token }, DefineFlags(func(flags *flag.FlagSet) {
+		token = flags.String("token", "", "API token.")
+		MarkSecret(flags, "token", "")
+		Shorthand(flags, "token", "t")
+	}))
+	app.stdin = strings.NewReader("")
+
+	for _, args := range [][]string{{"push", "-t", "hunter2"}, {"push", "-token-file", f.Name()}} {
+		app.Dispatch(args)
+		want := "hunter2"
+		if args[1] != "-t" {
+			want = "from-file"
+		}
+
+		if have != want {
+			t.Errorf("%v\nhave %q\nwant %q", args, have, want)
+		}
+	}
+
+	r := app.rules["push"]
+	r.options.Set("t", "hunter2")
+	if have := redactArgs(r, []string{"-t", "hunter2"}); have[1] != "********" {
+		t.Errorf("redacted\nhave %q\nwant %q", have, []string{"-t", "********"})
+	}
+
+	var buf bytes.Buffer
+	app.printHelp(&buf, r, false)
+	if strings.Contains(buf.String(), "hunter2") || !strings.Contains(buf.String(), "-token-file") {
+		t.Errorf("help\n%s", buf.String())
+	}
+}
+
+func TestMarkSecretDefault(t *testing.T) {
+	var (
+		token *string
+		pin   *int
+	)
+	app := New("myapp", "0.0.1")
+	app.RuleFunc("push", "Push it.", "", func() {}, DefineFlags(func(flags *flag.FlagSet) {
+		token = flags.String("token", "default", "API token.")
+		pin = flags.Int("pin", 0, "PIN.")
+		MarkSecret(flags, "token", "MYAPP_TOKEN")
+		MarkSecret(flags, "pin", "MYAPP_PIN")
+	}))
+	app.stdin = strings.NewReader("")
+	os.Setenv("MYAPP_TOKEN", "hunter2")
+	defer os.Unsetenv("MYAPP_TOKEN")
+	os.Setenv("MYAPP_PIN", "1234")
+	defer os.Unsetenv("MYAPP_PIN")
+
+	app.Dispatch([]string{"push"})
+	if *token != "hunter2" || *pin != 1234 {
+		t.Errorf("push\nhave %q %d\nwant %q %d", *token, *pin, "hunter2", 1234)
+	}
+}
+
+func (c *runSecret) Flags(flags *flag.FlagSet) {
+	c.token = SecretVar(flags, "token", "MYAPP_TOKEN", "API token.")
+}
+
+func (c *runSecret) Run() {
+	c.have = c.token.Value()
+}
+
+func (c *runSecret) String() string {
+	return "log in"
+}
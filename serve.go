@@ -0,0 +1,166 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// A remoteRequest is the body of a request to run a command with Handler.
+type remoteRequest struct {
+	Args  []string               `json:"args"`
+	Flags map[string]interface{} `json:"flags"`
+}
+
+// flushWriter flushes each write to the response so that output is streamed
+// to the client as it is written.
+type flushWriter struct {
+	mu sync.Mutex
+	w  http.ResponseWriter
+}
+
+// Local is a RuleOption keeping the command from being run through Handler,
+// such as for destructive commands or those that only make sense on the
+// machine running the Application. Local commands are not found by requests
+// to the Handler, nor by the commands they run. The built-in commands that
+// interact with the user or run other commands, such as those registered
+// with Login, Batch, Watch, Shell and Docs, are Local.
+func Local() RuleOption {
+	return func(r *rule) {
+		r.local = true
+	}
+}
+
+// Handler returns an http.Handler running the commands of the Application
+// in response to requests of the form POST /commands/<name>, where the words
+// of the names of subcommands are separated by slashes, such as
+// /commands/remote/add. The body is a JSON object with the arguments of the
+// command in "args" and its flags in "flags", such as
+//
+//	{"args": ["origin"], "flags": {"verbose": true, "tag": ["a", "b"]}}
+//
+// where an array repeats the flag. Standard output is streamed back as the
+// response body, while standard error and the exit code are sent in the
+// Stderr and Exit-Code trailers. Commands run as if -no-input was given,
+// with an empty standard input, and are cancelled if the client goes away.
+// Commands marked Local are not found.
+//
+// The Handler does not authenticate requests. Wrap it to do so before
+// serving it beyond the local machine.
+func (a *Application) Handler() http.Handler {
+	return http.HandlerFunc(a.serveHTTP)
+}
+
+// serveHTTP implements Handler.
+func (a *Application) serveHTTP(w http.ResponseWriter, req *http.Request) {
+	path := strings.Trim(strings.TrimPrefix(req.URL.Path, "/commands/"), "/")
+	if path == req.URL.Path || path == "" {
+		http.NotFound(w, req)
+		return
+	}
+
+	name := strings.Replace(path, "/", " ", -1)
+	r, ok := a.lookup(name)
+	if !ok || r.local {
+		http.NotFound(w, req)
+		return
+	}
+
+	if req.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var body remoteRequest
+	err := json.NewDecoder(req.Body).Decode(&body)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("invalid request: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	flags, err := remoteFlags(body.Flags)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("invalid request: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	args := strings.Fields(name)
+	args = append(args, flags...)
+	args = append(args, "--")
+	args = append(args, body.Args...)
+
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Header().Set("Trailer", "Exit-Code, Stderr")
+	w.WriteHeader(http.StatusOK)
+
+	var stderr bytes.Buffer
+	ctx := context.WithValue(req.Context(), remoteContextKey{}, true)
+	ctx = withStreams(ctx, streams{strings.NewReader(""), &flushWriter{w: w}, &stderr})
+	code := a.dispatch(ctx, args)
+	w.Header().Set("Exit-Code", strconv.Itoa(code))
+	w.Header().Set("Stderr", strings.TrimSpace(stderr.String()))
+}
+
+type remoteContextKey struct{}
+
+// remote reports whether ctx is of a request to the Handler.
+func remote(ctx context.Context) bool {
+	ok, _ := ctx.Value(remoteContextKey{}).(bool)
+	return ok
+}
+
+// remoteFlags returns the command line arguments setting the flags of a
+// request, in order of their names.
+func remoteFlags(flags map[string]interface{}) ([]string, error) {
+	names := make([]string, 0, len(flags))
+	for name := range flags {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var args []string
+	for _, name := range names {
+		values, ok := flags[name].([]interface{})
+		if !ok {
+			values = []interface{}{flags[name]}
+		}
+
+		for _, v := range values {
+			var s string
+			switch v := v.(type) {
+			case string:
+				s = v
+			case bool:
+				s = strconv.FormatBool(v)
+			case float64:
+				s = strconv.FormatFloat(v, 'f', -1, 64)
+			default:
+				return nil, fmt.Errorf("flag %s: unsupported value %v", name, v)
+			}
+
+			args = append(args, "-"+name+"="+s)
+		}
+	}
+
+	return args, nil
+}
+
+// Write implements the io.Writer interface.
+func (w *flushWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	n, err := w.w.Write(p)
+	if f, ok := w.w.(http.Flusher); ok {
+		f.Flush()
+	}
+
+	return n, err
+}
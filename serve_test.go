@@ -0,0 +1,115 @@
+package cli
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type runRemote struct {
+	verbose *bool
+	tags    *StringSlice
+}
+
+func TestHandler(t *testing.T) {
+	app := New("myapp", "0.0.1")
+	app.Rule(&runRemote{}, "echo", "[<words>...]")
+	app.RuleFunc("wipe", "Wipe everything.", "", func() {}, Local())
+	srv := httptest.NewServer(app.Handler())
+	defer srv.Close()
+
+	tests := []struct {
+		method, path, body string
+		status             int
+		want, code, stderr string
+	}{
+		{"POST", "/commands/echo", `{"args": ["a", "-b"], "flags": {"v": true, "tag": ["x", "y"]}}`, 200, "true [x y] [a -b]\n", "0", ""},
+		{"POST", "/commands/echo", `{"args": ["fail"]}`, 200, "false [] [fail]\n", "1", "myapp: echo: failed"},
+		{"POST", "/commands/echo", `{"flags": {"v": {}}}`, 400, "invalid request: flag v: unsupported value map[]\n", "", ""},
+		{"POST", "/commands/echo", `{`, 400, "invalid request: unexpected EOF\n", "", ""},
+		{"GET", "/commands/echo", "", 405, "method not allowed\n", "", ""},
+		{"POST", "/commands/wipe", `{}`, 404, "404 page not found\n", "", ""},
+		{"POST", "/commands/nope", `{}`, 404, "404 page not found\n", "", ""},
+	}
+
+	for _, tt := range tests {
+		req, _ := http.NewRequest(tt.method, srv.URL+tt.path, strings.NewReader(tt.body))
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if resp.StatusCode != tt.status || string(body) != tt.want {
+			t.Errorf("%s %s %s\nhave %d %q\nwant %d %q", tt.method, tt.path, tt.body, resp.StatusCode, body, tt.status, tt.want)
+		}
+
+		if code, stderr := resp.Trailer.Get("Exit-Code"), resp.Trailer.Get("Stderr"); code != tt.code || stderr != tt.stderr {
+			t.Errorf("%s trailers\nhave %q %q\nwant %q %q", tt.body, code, stderr, tt.code, tt.stderr)
+		}
+	}
+}
+
+func TestHandlerLocal(t *testing.T) {
+	app := New("myapp", "0.0.1")
+	app.Chain(",")
+	app.Interpolate(true)
+	app.Rule(&runRemote{}, "echo", "[<words>...]")
+	wiped := false
+	app.RuleFunc("wipe", "Wipe everything.", "", func() { wiped = true }, Local())
+	app.RuleFunc("nested", "Run wipe.", "", func(ctx context.Context) int {
+		return app.dispatch(ctx, []string{"wipe"})
+	})
+	srv := httptest.NewServer(app.Handler())
+	defer srv.Close()
+
+	tests := []struct {
+		path, body string
+		want, code string
+	}{
+		{"/commands/echo", `{"args": ["a", ",", "wipe"]}`, "false [] [a , wipe]\n", "0"},
+		{"/commands/echo", `{"args": ["${HOME}", "$(wipe)"]}`, "false [] [${HOME} $(wipe)]\n", "0"},
+		{"/commands/nested", `{}`, "", "1"},
+	}
+
+	for _, tt := range tests {
+		resp, err := http.Post(srv.URL+tt.path, "application/json", strings.NewReader(tt.body))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if code := resp.Trailer.Get("Exit-Code"); string(body) != tt.want || code != tt.code {
+			t.Errorf("%s %s\nhave %q %s\nwant %q %s", tt.path, tt.body, body, code, tt.want, tt.code)
+		}
+	}
+
+	if wiped {
+		t.Errorf("ran a local command")
+	}
+}
+
+func (c *runRemote) Flags(flags *flag.FlagSet) {
+	c.verbose = flags.Bool("v", false, "verbose")
+	c.tags = StringSliceVar(flags, "tag", nil, "tags")
+}
+
+func (c *runRemote) Run(ctx context.Context, words []string) error {
+	fmt.Fprintln(Stdout(ctx), *c.verbose, c.tags.Values(), words)
+	if len(words) > 0 && words[0] == "fail" {
+		return fmt.Errorf("failed")
+	}
+
+	return nil
+}
+
+func (c *runRemote) String() string {
+	return "echo words"
+}
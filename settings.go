@@ -0,0 +1,102 @@
+package cli
+
+import (
+	"context"
+	"flag"
+	"os"
+)
+
+type commandEnv struct {
+	app  *Application
+	json *bool
+}
+
+// A setting is a flag listed by the env command with its current value and
+// where the value came from.
+type setting struct {
+	Setting string `json:"setting"`
+	Value   string `json:"value"`
+	Source  string `json:"source"`
+}
+
+// EnvCommand registers a built-in command with the given name, such as env
+// or "config list", listing every setting of the Application: the global
+// flags and the flags of each command by their configuration keys, with
+// their current values and whether they came from the command line, an
+// environment variable, the configuration or the default. The values of
+// secrets are redacted.
+func (a *Application) EnvCommand(name string) {
+	a.settings = name
+	a.lazy(name, "", func() command {
+		return &commandEnv{app: a}
+	}, Local())
+}
+
+func (c *commandEnv) Flags(flags *flag.FlagSet) {
+	c.json = flags.Bool("json", false, "Print the settings as JSON.")
+}
+
+func (c *commandEnv) Run(ctx context.Context) error {
+	inv := ctxInvocation(ctx)
+	var settings []setting
+	inv.global.VisitAll(func(f *flag.Flag) {
+		if isShorthand(f) || isCmdline(f) {
+			return
+		}
+
+		source, ok := inv.sources[f.Name]
+		if !ok {
+			source = "default"
+		}
+
+		settings = append(settings, setting{f.Name, settingValue(f, f.Value.String()), source})
+	})
+
+	for _, name := range c.app.ordered(c.app.match("")) {
+		r, _ := c.app.lookup(name)
+		if r.load() != nil {
+			continue
+		}
+
+		r.options.VisitAll(func(f *flag.Flag) {
+			if !isShorthand(f) && !isCmdline(f) {
+				settings = append(settings, c.app.setting(r, f, inv.config))
+			}
+		})
+	}
+
+	p := &Printer{w: Stdout(ctx), json: *c.json || JSON(ctx)}
+	return p.Print(settings)
+}
+
+func (c *commandEnv) String() string {
+	return "List the settings and where their values came from."
+}
+
+// setting describes the flag of the rule with the value it would be set to
+// from the environment or the configuration c, in that order, or its default.
+func (a *Application) setting(r *rule, f *flag.Flag, c Config) setting {
+	key := configPrefix(r.name) + "." + f.Name
+	if env := a.envName(f.Name, r.env); env != "" {
+		if value, ok := os.LookupEnv(env); ok {
+			return setting{key, settingValue(f, value), "$" + env}
+		}
+	}
+
+	if v, ok := c.Get(key); ok {
+		if value, ok := configString(v); ok {
+			return setting{key, settingValue(f, value), "config " + key}
+		}
+	}
+
+	return setting{key, settingValue(f, f.DefValue), "default"}
+}
+
+// settingValue returns value, or the redacted value if the flag is a secret.
+func settingValue(f *flag.Flag, value string) string {
+	if isSecret(f) && value != "" {
+		return redacted
+	}
+
+	return value
+}
@@ -0,0 +1,63 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestEnvCommand(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	os.MkdirAll(filepath.Join(dir, "myapp"), 0700)
+	ioutil.WriteFile(filepath.Join(dir, "myapp", "config.json"), []byte(`{"copy": {"level": 3}}`), 0600)
+	t.Setenv("MYAPP_NAME", "env")
+	t.Setenv("MYAPP_TOKEN", "hunter2")
+
+	app := New("myapp", "0.0.1", WithBuiltinFlags())
+	app.EnvPrefix("MYAPP")
+	app.Flags(func(flags *flag.FlagSet) {
+		flags.String("region", "us", "Region.")
+	})
+	app.Rule(&runTrace{}, "copy", "<src> <dst>...")
+	app.EnvCommand("env")
+
+	var stdout, stderr bytes.Buffer
+	code := app.RunWithArgs([]string{"-region", "eu", "env", "-json"}, &stdout, &stderr)
+	if code != 0 {
+		t.Fatalf("exit code\nhave %d\nwant %d\n%s", code, 0, stderr.String())
+	}
+
+	var settings []setting
+	err := json.Unmarshal(stdout.Bytes(), &settings)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]setting{
+		"region":     {"region", "eu", "command line"},
+		"timeout":    {"timeout", "0s", "default"},
+		"copy.level": {"copy.level", "3", "config copy.level"},
+		"copy.name":  {"copy.name", "env", "$MYAPP_NAME"},
+		"copy.token": {"copy.token", redacted, "$MYAPP_TOKEN"},
+		"env.json":   {"env.json", "false", "default"},
+	}
+	for _, s := range settings {
+		if w, ok := want[s.Setting]; ok {
+			if !reflect.DeepEqual(s, w) {
+				t.Errorf("setting\nhave %+v\nwant %+v", s, w)
+			}
+
+			delete(want, s.Setting)
+		}
+	}
+
+	for _, w := range want {
+		t.Errorf("missing setting %+v", w)
+	}
+}
@@ -0,0 +1,77 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// A setup is the command that must complete before the others run, see
+// Setup.
+type setup struct {
+	name string
+	auto bool
+}
+
+// Setup makes the command with the given name, such as init, the first-run
+// setup of the Application, such as to prompt for credentials and default
+// settings. Until the command has succeeded once, which is recorded by a
+// setup file in the ConfigDir, other commands fail asking the user to run
+// it. If auto is set, the setup command instead runs first when there is a
+// terminal and -no-input was not given, followed by the requested command if
+// it succeeds. The built-in commands do not require the setup.
+func (a *Application) Setup(name string, auto bool) {
+	a.setup = &setup{name: name, auto: auto}
+}
+
+// needsSetup reports whether the setup must run before the named command.
+func (a *Application) needsSetup(name string) bool {
+	if a.setup == nil || name == a.setup.name || a.builtin(name) {
+		return false
+	}
+
+	path, err := a.setupPath()
+	if err != nil {
+		return false
+	}
+
+	_, err = os.Stat(path)
+	return os.IsNotExist(err)
+}
+
+// runSetup runs the setup command before the requested command if it can,
+// and otherwise asks the user to run it, returning the exit code and false
+// if the requested command must not run.
+func (a *Application) runSetup(ctx context.Context, s streams, noInput bool) (int, bool) {
+	if !a.setup.auto || noInput || !isTerminal(s.stdin) {
+		a.errorf(s.stderr, a.messages.SetupRequired, a.name, a.name, a.setup.name)
+		return 1, false
+	}
+
+	fmt.Fprintf(s.stderr, a.messages.SetupRunning+"\n", a.name, a.setup.name)
+	code := a.dispatch(ctx, []string{a.setup.name})
+	return code, code == 0
+}
+
+// completeSetup records that the setup command succeeded.
+func (a *Application) completeSetup() error {
+	path, err := a.setupPath()
+	if err != nil {
+		return err
+	}
+
+	return writeFile(path, []byte(time.Now().UTC().Format(time.RFC3339)+"\n"))
+}
+
+// setupPath returns the path of the file recording that the setup command
+// succeeded.
+func (a *Application) setupPath() (string, error) {
+	dir, err := a.ConfigDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(dir, "setup"), nil
+}
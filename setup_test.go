@@ -0,0 +1,41 @@
+package cli
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSetup(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+
+	ran := ""
+	app := New("myapp", "0.0.1")
+	app.RuleFunc("init", "Set up myapp.", "", func() { ran += "init " })
+	app.RuleFunc("deploy", "Deploy it.", "", func() { ran += "deploy " })
+	app.Setup("init", true)
+
+	var stdout, stderr bytes.Buffer
+	code := app.RunWithArgs([]string{"deploy"}, &stdout, &stderr)
+	if want := "Error: myapp is not set up, run 'myapp init' first\n"; code != 1 || stderr.String() != want || ran != "" {
+		t.Errorf("deploy before setup\nhave %d %q %q\nwant %d %q %q", code, stderr.String(), ran, 1, want, "")
+	}
+
+	code = app.RunWithArgs([]string{"version", "-short"}, &stdout, &stderr)
+	if code != 0 {
+		t.Errorf("version before setup\nhave %d\nwant %d", code, 0)
+	}
+
+	code = app.Dispatch([]string{"init"})
+	if _, err := os.Stat(filepath.Join(dir, "myapp", "setup")); code != 0 || err != nil {
+		t.Errorf("init\nhave %d %v\nwant %d <nil>", code, err, 0)
+	}
+
+	ran = ""
+	code = app.Dispatch([]string{"deploy"})
+	if code != 0 || ran != "deploy " {
+		t.Errorf("deploy after setup\nhave %d %q\nwant %d %q", code, ran, 0, "deploy ")
+	}
+}
@@ -0,0 +1,78 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+)
+
+type commandShell struct {
+	*NullFlags
+	app  *Application
+	name string
+}
+
+// Shell registers a command with the given name that reads commands from the
+// standard input, one per line, and dispatches them in turn until exit, quit
+// or the end of the input. Lines are split into arguments like a shell
+// would, see SplitArgs, with any interpolation, see Interpolate, and may
+// begin with global flags. Each command is
+// parsed with fresh flags, as if it were given on the command line. Blank
+// lines and lines beginning with # are skipped. The command exits with the
+// exit code of the last command.
+func (a *Application) Shell(name string) error {
+	return a.Rule(&commandShell{app: a, name: name}, name, "", Local())
+}
+
+func (c *commandShell) Run(ctx context.Context) int {
+	in := bufio.NewReader(Stdin(ctx))
+	interactive := isTerminal(Stdin(ctx))
+	ctx = withStreams(ctx, streams{in, Stdout(ctx), Stderr(ctx)})
+
+	code := 0
+	for ctx.Err() == nil {
+		if interactive {
+			fmt.Fprintf(Stderr(ctx), "%s> ", c.app.name)
+		}
+
+		line, err := in.ReadString('\n')
+		if err != nil && line == "" {
+			if err != io.EOF {
+				c.app.errorf(Stderr(ctx), "%s: %v", c.name, err)
+				return 1
+			}
+
+			return code
+		}
+
+		var expand func(token string) (string, error)
+		if c.app.interpolate {
+			expand = c.app.expander(ctx, true)
+		}
+
+		args, err := splitArgs(line, expand)
+		if err != nil {
+			c.app.errorf(Stderr(ctx), "%s: %v", c.name, err)
+			code = ExitUsage
+			continue
+		}
+
+		if len(args) == 0 || strings.HasPrefix(args[0], "#") {
+			continue
+		}
+
+		if args[0] == "exit" || args[0] == "quit" {
+			return code
+		}
+
+		code = c.app.dispatch(ctx, args)
+	}
+
+	return code
+}
+
+func (c *commandShell) String() string {
+	return "Read and run commands interactively."
+}
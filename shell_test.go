@@ -0,0 +1,31 @@
+package cli
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestShell(t *testing.T) {
+	app := New("myapp", "0.0.1")
+	cmd := &runRecord{}
+	app.Rule(cmd, "record", "[<a>] [<b>]")
+	app.Shell("shell")
+
+	app.stdin = strings.NewReader("# comment\n\nrecord -number 2 'one two'\nshell\nrecord 'x\nrecord -number 4\nquit\nrecord -number 9\n")
+	var stdout, stderr bytes.Buffer
+	code := app.RunWithArgs([]string{"shell"}, &stdout, &stderr)
+	if code != 4 {
+		t.Errorf("exit code\nhave %d\nwant %d", code, 4)
+	}
+
+	if want := []string{"", ""}; !reflect.DeepEqual(cmd.have, want) {
+		t.Errorf("arguments\nhave %q\nwant %q", cmd.have, want)
+	}
+
+	want := "Error: shell: cannot run within itself\nError: shell: split: unterminated quoted string at offset 7\n"
+	if stderr.String() != want {
+		t.Errorf("stderr\nhave %q\nwant %q", stderr.String(), want)
+	}
+}
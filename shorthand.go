@@ -0,0 +1,103 @@
+package cli
+
+import (
+	"flag"
+	"time"
+)
+
+// shorthand is a flag.Value shared by a short spelling of a flag, such as -o
+// for -output, see Shorthand.
+type shorthand struct {
+	flag.Value
+	name string
+}
+
+// Shorthand defines short as another spelling of the flag with the given
+// name, which must already be defined. Both spellings set the same value and
+// are documented and completed as a single flag.
+func Shorthand(flags *flag.FlagSet, name, short string) {
+	f := flags.Lookup(name)
+	if f == nil {
+		panic("cli: shorthand of undefined flag -" + name)
+	}
+
+	flags.Var(&shorthand{Value: f.Value, name: name}, short, f.Usage)
+}
+
+// StringP defines a string flag with the specified name, short spelling,
+// default value and usage string, see Shorthand.
+func StringP(flags *flag.FlagSet, name, short, value, usage string) *string {
+	p := flags.String(name, value, usage)
+	Shorthand(flags, name, short)
+	return p
+}
+
+// BoolP defines a bool flag with the specified name, short spelling, default
+// value and usage string, see Shorthand.
+func BoolP(flags *flag.FlagSet, name, short string, value bool, usage string) *bool {
+	p := flags.Bool(name, value, usage)
+	Shorthand(flags, name, short)
+	return p
+}
+
+// IntP defines an int flag with the specified name, short spelling, default
+// value and usage string, see Shorthand.
+func IntP(flags *flag.FlagSet, name, short string, value int, usage string) *int {
+	p := flags.Int(name, value, usage)
+	Shorthand(flags, name, short)
+	return p
+}
+
+// DurationP defines a time.Duration flag with the specified name, short
+// spelling, default value and usage string, see Shorthand.
+func DurationP(flags *flag.FlagSet, name, short string, value time.Duration, usage string) *time.Duration {
+	p := flags.Duration(name, value, usage)
+	Shorthand(flags, name, short)
+	return p
+}
+
+// IsBoolFlag reports whether the flag is a boolean flag.
+func (s *shorthand) IsBoolFlag() bool {
+	b, ok := s.Value.(interface{ IsBoolFlag() bool })
+	return ok && b.IsBoolFlag()
+}
+
+// isShorthand reports whether the flag is the short spelling of another.
+func isShorthand(f *flag.Flag) bool {
+	_, ok := f.Value.(*shorthand)
+	return ok
+}
+
+// logicalName returns the name of the flag, or of the flag it is the short
+// spelling of.
+func logicalName(f *flag.Flag) string {
+	if s, ok := f.Value.(*shorthand); ok {
+		return s.name
+	}
+
+	return f.Name
+}
+
+// shorthands returns the short spellings of the flags by their name.
+func shorthands(flags *flag.FlagSet) map[string]string {
+	short := make(map[string]string)
+	flags.VisitAll(func(f *flag.Flag) {
+		if s, ok := f.Value.(*shorthand); ok {
+			short[s.name] = f.Name
+		}
+	})
+
+	return short
+}
+
+// visited returns the names of the flags that were set, under either
+// spelling.
+func visited(flags *flag.FlagSet) map[string]bool {
+	set := make(map[string]bool)
+	flags.Visit(func(f *flag.Flag) {
+		set[f.Name] = true
+		set[logicalName(f)] = true
+	})
+
+	return set
+}
@@ -0,0 +1,66 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"os"
+	"strings"
+	"testing"
+)
+
+type runShorthand struct {
+	output  *string
+	verbose *bool
+}
+
+func TestShorthand(t *testing.T) {
+	app := New("myapp", "0.0.1")
+	app.EnvPrefix("MYAPP")
+	cmd := &runShorthand{}
+	app.Rule(cmd, "build", "")
+
+	os.Setenv("MYAPP_OUTPUT", "env")
+	defer os.Unsetenv("MYAPP_OUTPUT")
+
+	tests := []struct {
+		args    []string
+		output  string
+		verbose bool
+	}{
+		{[]string{"build", "-o", "short", "-v"}, "short", true},
+		{[]string{"build", "--output", "long", "--verbose"}, "long", true},
+		{[]string{"build"}, "env", false},
+	}
+
+	for _, tt := range tests {
+		code := app.Dispatch(tt.args)
+		if code != 0 || *cmd.output != tt.output || *cmd.verbose != tt.verbose {
+			t.Errorf("%q\nhave %d %q %v\nwant %d %q %v", tt.args, code, *cmd.output, *cmd.verbose, 0, tt.output, tt.verbose)
+		}
+	}
+
+	var buf bytes.Buffer
+	app.PrintUsage(&buf)
+	usage := buf.String()
+	if !strings.Contains(usage, "-o, -output=<value>") || strings.Count(usage, "Output path.") != 1 {
+		t.Errorf("usage does not combine the spellings\n%s", usage)
+	}
+
+	buf.Reset()
+	app.complete(&buf, []string{"build", "-"})
+	if want := "-output\tOutput path.\n-verbose\tVerbose output.\n"; buf.String() != want {
+		t.Errorf("completion\nhave %q\nwant %q", buf.String(), want)
+	}
+}
+
+func (c *runShorthand) Flags(flags *flag.FlagSet) {
+	c.output = StringP(flags, "output", "o", "", "Output path.")
+	c.verbose = BoolP(flags, "verbose", "v", false, "Verbose output.")
+}
+
+func (c *runShorthand) Run(ctx context.Context) {}
+
+func (c *runShorthand) String() string {
+	return "build a thing"
+}
@@ -0,0 +1,149 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+// shutdownSignals cancel the invocation started by Run by default.
+var shutdownSignals = []os.Signal{os.Interrupt, syscall.SIGTERM}
+
+// exitHookTimeout bounds the OnExit hooks run before a forced exit when no
+// ShutdownGrace is set.
+const exitHookTimeout = 5 * time.Second
+
+// A shutdown configures how an invocation responds to signals, see
+// notifyContext.
+type shutdown struct {
+	name     string
+	signals  []os.Signal
+	grace    time.Duration
+	w        io.Writer
+	exit     func(code int)
+	onExit   func(code int)
+	messages *Messages
+}
+
+// ShutdownSignals is an Option setting the signals that cancel the
+// invocation started by Run, SIGINT and SIGTERM by default.
+func ShutdownSignals(sigs ...os.Signal) Option {
+	return func(a *Application) {
+		a.signals = sigs
+	}
+}
+
+// ShutdownGrace is an Option setting how long a command has to return once
+// its invocation is cancelled by a signal before the process exits anyway.
+// By default, the command is waited for until a second signal is received.
+// Before the process exits anyway, the functions registered with OnExit are
+// called, for no longer than the grace period, or five seconds if there is
+// none. Run and Main do not return in that case.
+func ShutdownGrace(d time.Duration) Option {
+	return func(a *Application) {
+		a.grace = d
+	}
+}
+
+// shutdown returns the shutdown of the invocation started by Run.
+func (a *Application) shutdown() shutdown {
+	s := shutdown{
+		name:     a.name,
+		signals:  a.signals,
+		grace:    a.grace,
+		w:        a.stderr,
+		exit:     a.exit,
+		messages: &a.messages,
+	}
+	if len(s.signals) == 0 {
+		s.signals = shutdownSignals
+	}
+
+	return s
+}
+
+// notifyContext returns a copy of parent that is cancelled with a SignalError
+// as the cause when one of the signals of s is received. If s has an exit
+// function, it is called with the exit code for the signal when a second
+// signal is received or the grace period of s elapses, so that a command
+// slow to shut down can be stopped, after calling the onExit function of s,
+// see force. The stop function stops listening for
+// the signals and releases the context.
+func notifyContext(parent context.Context, s shutdown) (ctx context.Context, stop func()) {
+	ctx, cancel := context.WithCancelCause(parent)
+	ch := make(chan os.Signal, 2)
+	done := make(chan struct{})
+	signal.Notify(ch, s.signals...)
+	go func() {
+		var sig os.Signal
+		select {
+		case sig = <-ch:
+			cancel(&SignalError{Signal: sig})
+		case <-ctx.Done():
+			return
+		}
+
+		if s.exit == nil {
+			return
+		}
+
+		code := ExitSignal
+		if n, ok := signalNumber(sig); ok {
+			code += n
+		}
+
+		fmt.Fprintf(s.w, "\n%s: "+s.messages.ShuttingDown+"\n", s.name)
+		var timeout <-chan time.Time
+		if s.grace > 0 {
+			timer := time.NewTimer(s.grace)
+			defer timer.Stop()
+			timeout = timer.C
+		}
+
+		select {
+		case <-ch:
+			s.force(code)
+		case <-timeout:
+			fmt.Fprintf(s.w, "%s: "+s.messages.ShutdownTimeout+"\n", s.name, s.grace)
+			s.force(code)
+		case <-done:
+		}
+	}()
+
+	return ctx, func() {
+		signal.Stop(ch)
+		close(done)
+		cancel(nil)
+	}
+}
+
+// force calls the onExit function of s with code, waiting no longer than the
+// grace period, and then exits with code. It does not return unless the exit
+// function of s does.
+func (s shutdown) force(code int) {
+	if s.onExit != nil {
+		limit := s.grace
+		if limit <= 0 {
+			limit = exitHookTimeout
+		}
+
+		done := make(chan struct{})
+		go func() {
+			s.onExit(code)
+			close(done)
+		}()
+
+		timer := time.NewTimer(limit)
+		defer timer.Stop()
+		select {
+		case <-done:
+		case <-timer.C:
+		}
+	}
+
+	s.exit(code)
+}
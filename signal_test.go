@@ -0,0 +1,95 @@
+//go:build !windows && !plan9 && !js && !wasip1
+
+package cli
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+)
+
+type runInterrupt struct {
+	*NullFlags
+}
+
+func TestNotifyContext(t *testing.T) {
+	ctx, stop := notifyContext(context.Background(), shutdown{signals: []os.Signal{syscall.SIGUSR1}})
+	defer stop()
+
+	app := New("myapp", "0.0.1")
+	app.Rule(&runInterrupt{}, "interrupt", "")
+
+	code := app.dispatch(ctx, []string{"interrupt"})
+	if want := ExitSignal + int(syscall.SIGUSR1); code != want {
+		t.Errorf("exit code\nhave %d\nwant %d", code, want)
+	}
+}
+
+func TestNotifyContextForce(t *testing.T) {
+	tests := []struct {
+		grace time.Duration
+		twice bool
+		want  string
+	}{
+		{0, true, "\nmyapp: shutting down, press Ctrl-C again to force\n"},
+		{10 * time.Millisecond, false, "\nmyapp: shutting down, press Ctrl-C again to force\nmyapp: did not shut down within 10ms\n"},
+	}
+
+	p, _ := os.FindProcess(os.Getpid())
+	for _, tt := range tests {
+		var buf bytes.Buffer
+		exit := make(chan int, 1)
+		hooked := make(chan int, 1)
+		ctx, stop := notifyContext(context.Background(), shutdown{
+			name:     "myapp",
+			signals:  []os.Signal{syscall.SIGUSR1},
+			grace:    tt.grace,
+			w:        &buf,
+			exit:     func(code int) { exit <- code },
+			onExit:   func(code int) { hooked <- code },
+			messages: &DefaultMessages,
+		})
+
+		p.Signal(syscall.SIGUSR1)
+		<-ctx.Done()
+		if tt.twice {
+			p.Signal(syscall.SIGUSR1)
+		}
+
+		select {
+		case code := <-exit:
+			if want := ExitSignal + int(syscall.SIGUSR1); code != want || buf.String() != tt.want {
+				t.Errorf("%v\nhave %d %q\nwant %d %q", tt.grace, code, buf.String(), want, tt.want)
+			}
+
+			select {
+			case <-hooked:
+			default:
+				t.Errorf("%v\nhave no exit hooks", tt.grace)
+			}
+		case <-time.After(5 * time.Second):
+			t.Errorf("%v\nhave no exit", tt.grace)
+		}
+
+		stop()
+	}
+}
+
+func (c *runInterrupt) Run(ctx context.Context) int {
+	p, _ := os.FindProcess(os.Getpid())
+	p.Signal(syscall.SIGUSR1)
+
+	select {
+	case <-ctx.Done():
+		return 0
+	case <-time.After(5 * time.Second):
+		return 1
+	}
+}
+
+func (c *runInterrupt) String() string {
+	return "wait for a signal"
+}
@@ -0,0 +1,14 @@
+//go:build !plan9
+
+package cli
+
+import (
+	"os"
+	"syscall"
+)
+
+// signalNumber returns the number of the signal, if it has one.
+func signalNumber(sig os.Signal) (int, bool) {
+	n, ok := sig.(syscall.Signal)
+	return int(n), ok
+}
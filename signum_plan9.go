@@ -0,0 +1,8 @@
+package cli
+
+import "os"
+
+// signalNumber returns the number of the signal. Plan 9 notes have none.
+func signalNumber(sig os.Signal) (int, bool) {
+	return 0, false
+}
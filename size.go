@@ -0,0 +1,107 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// sizeUnits are the units accepted by ParseSize, ending with bytes.
+var sizeUnits = []struct {
+	suffix string
+	size   int64
+}{
+	{"PiB", 1 << 50},
+	{"TiB", 1 << 40},
+	{"GiB", 1 << 30},
+	{"MiB", 1 << 20},
+	{"KiB", 1 << 10},
+	{"PB", 1e15},
+	{"TB", 1e12},
+	{"GB", 1e9},
+	{"MB", 1e6},
+	{"kB", 1e3},
+	{"B", 1},
+}
+
+// A Size is a flag.Value holding a number of bytes. Define one with SizeVar.
+type Size struct {
+	n int64
+}
+
+// SizeVar defines a flag with the specified name, default value in bytes and
+// usage string holding a number of bytes parsed with ParseSize, such as
+// 512KiB or 10MB.
+func SizeVar(flags *flag.FlagSet, name string, value int64, usage string) *Size {
+	s := &Size{n: value}
+	flags.Var(s, name, usage)
+	return s
+}
+
+// Bytes returns the number of bytes.
+func (s *Size) Bytes() int64 {
+	return s.n
+}
+
+// String implements the flag.Value interface.
+func (s *Size) String() string {
+	if s == nil || s.n == 0 {
+		return ""
+	}
+
+	// Sizes are formatted like FormatBytes, unless that would round them.
+	text := formatBytes(s.n)
+	if n, err := ParseSize(text); err != nil || n != s.n {
+		text = fmt.Sprintf("%d B", s.n)
+	}
+
+	return text
+}
+
+// Set implements the flag.Value interface.
+func (s *Size) Set(value string) error {
+	n, err := ParseSize(value)
+	if err != nil {
+		return err
+	}
+
+	s.n = n
+	return nil
+}
+
+// ParseSize parses a number of bytes with an optional unit, either binary,
+// such as KiB, MiB and GiB, or decimal, such as kB, MB and GB. Units are not
+// case sensitive, the B may be omitted and the number may have a fraction,
+// such as 1.5G.
+func ParseSize(s string) (int64, error) {
+	s = strings.TrimSpace(s)
+	i := strings.IndexFunc(s, func(r rune) bool { return (r < '0' || r > '9') && r != '.' })
+	if i < 0 {
+		i = len(s)
+	}
+
+	// A number without a unit is a number of bytes, and units may omit the
+	// B, such as K or Ki.
+	size := int64(1)
+	if unit := strings.ToLower(strings.TrimSpace(s[i:])); unit != "" {
+		if !strings.HasSuffix(unit, "b") {
+			unit += "b"
+		}
+
+		size = 0
+		for _, u := range sizeUnits {
+			if strings.EqualFold(u.suffix, unit) {
+				size = u.size
+			}
+		}
+	}
+
+	n, err := strconv.ParseFloat(s[:i], 64)
+	if err != nil || size == 0 || n*float64(size) >= math.MaxInt64 {
+		return 0, fmt.Errorf("invalid size %q", s)
+	}
+
+	return int64(n * float64(size)), nil
+}
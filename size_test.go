@@ -0,0 +1,87 @@
+package cli
+
+import (
+	"bytes"
+	"flag"
+	"strings"
+	"testing"
+)
+
+func TestParseSize(t *testing.T) {
+	tests := []struct {
+		s    string
+		want int64
+	}{
+		{"0", 0},
+		{"512", 512},
+		{"10MiB", 10 << 20},
+		{"10 mib", 10 << 20},
+		{"10Mi", 10 << 20},
+		{"10MB", 10e6},
+		{"10m", 10e6},
+		{"1.5G", 1.5e9},
+		{"2KiB", 2048},
+		{"3B", 3},
+	}
+
+	for _, tt := range tests {
+		have, err := ParseSize(tt.s)
+		if err != nil || have != tt.want {
+			t.Errorf("ParseSize(%q)\nhave %d %v\nwant %d", tt.s, have, err, tt.want)
+		}
+	}
+
+	for _, s := range []string{"", "MiB", "-1KiB", "10XB", "1.2.3", "9999PiB"} {
+		_, err := ParseSize(s)
+		if err == nil {
+			t.Errorf("ParseSize(%q)\nhave nil\nwant error", s)
+		}
+	}
+}
+
+func TestSizeString(t *testing.T) {
+	tests := []struct {
+		n    int64
+		want string
+	}{
+		{1500, "1500 B"},
+		{1536, "1.5 KiB"},
+		{2000, "2000 B"},
+		{1 << 20, "1 MiB"},
+		{3 << 30, "3 GiB"},
+		{5e12, "5000000000000 B"},
+	}
+
+	for _, tt := range tests {
+		have := (&Size{n: tt.n}).String()
+		if have != tt.want {
+			t.Errorf("Size(%d)\nhave %q\nwant %q", tt.n, have, tt.want)
+		}
+
+		if n, err := ParseSize(have); err != nil || n != tt.n {
+			t.Errorf("ParseSize(%q)\nhave %d %v\nwant %d", have, n, err, tt.n)
+		}
+	}
+}
+
+func TestSizeVar(t *testing.T) {
+	app := New("myapp", "0.0.1")
+	var limit, chunk *Size
+	app.RuleFunc("upload", "Upload a file.", "", func() {}, DefineFlags(func(flags *flag.FlagSet) {
+		limit = SizeVar(flags, "limit", 0, "Maximum size.")
+		chunk = SizeVar(flags, "chunk", 8<<20, "Chunk size.")
+	}))
+
+	code := app.Dispatch([]string{"upload", "-limit", "1GB"})
+	if code != 0 || limit.Bytes() != 1e9 || chunk.Bytes() != 8<<20 {
+		t.Errorf("sizes\nhave %d %d %d\nwant %d %d %d", code, limit.Bytes(), chunk.Bytes(), 0, int64(1e9), 8<<20)
+	}
+
+	var buf bytes.Buffer
+	app.printHelp(&buf, app.rules["upload"], false)
+	for _, want := range []string{`-chunk="8 MiB"`, "-limit=<size>"} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("help\nhave %q\nwant %q", buf.String(), want)
+		}
+	}
+}
@@ -0,0 +1,154 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+)
+
+var (
+	errSplitQuote  = fmt.Errorf("split: unterminated quoted string")
+	errSplitEscape = fmt.Errorf("split: unterminated escape sequence")
+)
+
+// SplitArgs splits line into arguments using rules similar to sh.
+//
+// Arguments are separated by unquoted whitespace. Characters within single
+// quotes are preserved literally. Within double quotes, a backslash only
+// escapes a following backslash, double quote, dollar sign, backtick or
+// newline. Outside of quotes, a backslash preserves the literal value of the
+// following character and an escaped newline is removed entirely. Empty
+// quoted strings produce empty arguments. No variable, command or glob
+// expansion is performed, so SplitArgs is safe for command lines stored in
+// configuration files or received over the network. Errors report the offset,
+// in characters, of the unterminated quote or escape.
+func SplitArgs(line string) ([]string, error) {
+	return splitArgs(line, nil)
+}
+
+// splitArgs implements SplitArgs, replacing the interpolations outside of
+// single quotes with their expansion if expand is not nil, see Interpolate.
+func splitArgs(line string, expand func(token string) (string, error)) ([]string, error) {
+	var args []string
+	var arg strings.Builder
+	inArg := false
+
+	runes := []rune(line)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case r == '\\':
+			i++
+			if i >= len(runes) {
+				return nil, fmt.Errorf("%w at offset %d", errSplitEscape, i-1)
+			}
+
+			// A backslash-newline is a line continuation.
+			if runes[i] == '\n' {
+				continue
+			}
+
+			arg.WriteRune(runes[i])
+			inArg = true
+		case r == '\'':
+			start := i
+			closed := false
+			for i++; i < len(runes); i++ {
+				if runes[i] == '\'' {
+					closed = true
+					break
+				}
+
+				arg.WriteRune(runes[i])
+			}
+
+			if !closed {
+				return nil, fmt.Errorf("%w at offset %d", errSplitQuote, start)
+			}
+
+			inArg = true
+		case r == '"':
+			start := i
+			closed := false
+			for i++; i < len(runes); i++ {
+				r = runes[i]
+				if r == '"' {
+					closed = true
+					break
+				}
+
+				n, err := expansion(runes, i, expand, &arg)
+				if err != nil {
+					return nil, err
+				}
+
+				if n > 0 {
+					i += n - 1
+					continue
+				}
+
+				if r == '\\' && i+1 < len(runes) && strings.ContainsRune("\\\"$`\n", runes[i+1]) {
+					i++
+					if runes[i] == '\n' {
+						continue
+					}
+
+					r = runes[i]
+				}
+
+				arg.WriteRune(r)
+			}
+
+			if !closed {
+				return nil, fmt.Errorf("%w at offset %d", errSplitQuote, start)
+			}
+
+			inArg = true
+		case r == ' ' || r == '\t' || r == '\n' || r == '\r':
+			if inArg {
+				args = append(args, arg.String())
+				arg.Reset()
+				inArg = false
+			}
+		default:
+			n, err := expansion(runes, i, expand, &arg)
+			if err != nil {
+				return nil, err
+			}
+
+			if n > 0 {
+				i += n - 1
+			} else {
+				arg.WriteRune(r)
+			}
+
+			inArg = true
+		}
+	}
+
+	if inArg {
+		args = append(args, arg.String())
+	}
+
+	return args, nil
+}
+
+// expansion writes the expansion of the interpolation at runes[i] to b, if
+// expand is not nil and there is one, returning its length.
+func expansion(runes []rune, i int, expand func(token string) (string, error), b *strings.Builder) (int, error) {
+	if expand == nil {
+		return 0, nil
+	}
+
+	n, err := dollar(runes, i)
+	if err != nil || n == 0 {
+		return 0, err
+	}
+
+	v, err := expand(string(runes[i : i+n]))
+	if err != nil {
+		return 0, err
+	}
+
+	b.WriteString(v)
+	return n, nil
+}
@@ -0,0 +1,53 @@
+package cli
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestSplitArgs(t *testing.T) {
+	tests := []struct {
+		line string
+		want []string
+	}{
+		{"", nil},
+		{"  add  key\tuser ", []string{"add", "key", "user"}},
+		{`add 'a b' "c d"`, []string{"add", "a b", "c d"}},
+		{`'it''s' "say \"hi\"" \$x`, []string{"its", `say "hi"`, "$x"}},
+		{`'\n' "\n" \n`, []string{`\n`, `\n`, "n"}},
+		{`a"b"'c' "" ''`, []string{"abc", "", ""}},
+		{"one \\\ntwo", []string{"one", "two"}},
+	}
+
+	for _, tt := range tests {
+		have, err := SplitArgs(tt.line)
+		if err != nil {
+			t.Errorf("%q: unexpected error: %v", tt.line, err)
+			continue
+		}
+
+		if !reflect.DeepEqual(have, tt.want) {
+			t.Errorf("%q\nhave %q\nwant %q", tt.line, have, tt.want)
+		}
+	}
+}
+
+func TestSplitArgsErrors(t *testing.T) {
+	tests := []struct {
+		line string
+		want error
+		msg  string
+	}{
+		{`'open`, errSplitQuote, "split: unterminated quoted string at offset 0"},
+		{`a "open`, errSplitQuote, "split: unterminated quoted string at offset 2"},
+		{`trailing\`, errSplitEscape, "split: unterminated escape sequence at offset 8"},
+	}
+
+	for _, tt := range tests {
+		_, err := SplitArgs(tt.line)
+		if !errors.Is(err, tt.want) || err.Error() != tt.msg {
+			t.Errorf("%q\nhave %v\nwant %v", tt.line, err, tt.msg)
+		}
+	}
+}
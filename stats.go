@@ -0,0 +1,51 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"runtime"
+	"time"
+)
+
+// usage is a snapshot of the resources used by the process.
+type usage struct {
+	wall   time.Time
+	user   time.Duration
+	system time.Duration
+	maxRSS int64
+	mem    runtime.MemStats
+}
+
+// sampleUsage returns the resources used by the process so far.
+func sampleUsage() *usage {
+	u := &usage{wall: time.Now()}
+	u.user, u.system, u.maxRSS = processTimes()
+	runtime.ReadMemStats(&u.mem)
+	return u
+}
+
+// report writes the resources used since u was sampled to w.
+func (u *usage) report(w io.Writer, m *Messages) {
+	end := sampleUsage()
+	fmt.Fprintf(w, "stats: "+m.StatsTime+"\n",
+		end.wall.Sub(u.wall).Round(time.Millisecond),
+		(end.user - u.user).Round(time.Millisecond),
+		(end.system - u.system).Round(time.Millisecond))
+
+	peak := m.StatsUnknown
+	if end.maxRSS > 0 {
+		peak = formatBytes(end.maxRSS)
+	}
+
+	fmt.Fprintf(w, "stats: "+m.StatsMemory+"\n",
+		peak,
+		formatBytes(int64(end.mem.HeapAlloc)),
+		formatBytes(int64(end.mem.TotalAlloc-u.mem.TotalAlloc)),
+		end.mem.NumGC-u.mem.NumGC,
+		time.Duration(end.mem.PauseTotalNs-u.mem.PauseTotalNs).Round(time.Microsecond))
+}
+
+// formatBytes formats n using binary units.
+func formatBytes(n int64) string {
+	return defaultLocale.bytes(n)
+}
@@ -0,0 +1,10 @@
+//go:build windows || plan9 || js || wasip1
+
+package cli
+
+import "time"
+
+// processTimes is not supported on this platform.
+func processTimes() (user, system time.Duration, maxRSS int64) {
+	return 0, 0, 0
+}
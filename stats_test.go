@@ -0,0 +1,40 @@
+package cli
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestStats(t *testing.T) {
+	app := New("myapp", "0.0.1", WithStatsFlag())
+	var stderr bytes.Buffer
+	app.stdout = &bytes.Buffer{}
+	app.stderr = &stderr
+	app.Dispatch([]string{"-stats", "version"})
+
+	have := stderr.String()
+	if !strings.HasPrefix(have, "stats: wall ") || !strings.Contains(have, "stats: peak rss ") {
+		t.Errorf("stats\nhave %q", have)
+	}
+}
+
+func TestFormatBytes(t *testing.T) {
+	tests := []struct {
+		n    int64
+		want string
+	}{
+		{0, "0 B"},
+		{1023, "1023 B"},
+		{1536, "1.5 KiB"},
+		{25 << 20, "25 MiB"},
+		{25<<20 + 1<<19, "25.5 MiB"},
+	}
+
+	for _, tt := range tests {
+		have := formatBytes(tt.n)
+		if have != tt.want {
+			t.Errorf("%d\nhave %q\nwant %q", tt.n, have, tt.want)
+		}
+	}
+}
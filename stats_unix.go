@@ -0,0 +1,26 @@
+//go:build !windows && !plan9 && !js && !wasip1
+
+package cli
+
+import (
+	"runtime"
+	"syscall"
+	"time"
+)
+
+// processTimes returns the user and system CPU time used by the process and
+// its peak resident set size in bytes.
+func processTimes() (user, system time.Duration, maxRSS int64) {
+	var ru syscall.Rusage
+	if syscall.Getrusage(syscall.RUSAGE_SELF, &ru) != nil {
+		return 0, 0, 0
+	}
+
+	// Linux and the BSDs report the peak resident set size in kilobytes.
+	maxRSS = int64(ru.Maxrss)
+	if runtime.GOOS != "darwin" && runtime.GOOS != "ios" {
+		maxRSS *= 1024
+	}
+
+	return time.Duration(ru.Utime.Nano()), time.Duration(ru.Stime.Nano()), maxRSS
+}
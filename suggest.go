@@ -0,0 +1,74 @@
+package cli
+
+// maxSuggestDistance is the largest edit distance of a suggested command.
+const maxSuggestDistance = 2
+
+// SuggestAndRun runs the command suggested for an unknown command when it is
+// the only one within a single edit, such as build for biuld, rather than
+// failing with the suggestion.
+func (a *Application) SuggestAndRun(enabled bool) {
+	a.suggestRun = enabled
+}
+
+// suggest returns the registered commands closest to the unknown name, in
+// sorted order, and their edit distance. No commands are returned if none
+// are within maxSuggestDistance edits or half the length of name.
+func (a *Application) suggest(name string) ([]string, int) {
+	limit := maxSuggestDistance
+	if n := len([]rune(name)) / 2; n < limit {
+		limit = n
+	}
+
+	var best []string
+	min := limit + 1
+	for _, candidate := range a.visible(a.match(""), "") {
+		d := levenshtein(name, candidate)
+		if d < min {
+			best, min = nil, d
+		}
+
+		if d == min {
+			best = append(best, candidate)
+		}
+	}
+
+	return best, min
+}
+
+// levenshtein returns the number of single character insertions, deletions
+// and substitutions required to change a into b.
+func levenshtein(a, b string) int {
+	s, t := []rune(a), []rune(b)
+	prev := make([]int, len(t)+1)
+	curr := make([]int, len(t)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(s); i++ {
+		curr[0] = i
+		for j := 1; j <= len(t); j++ {
+			cost := 1
+			if s[i-1] == t[j-1] {
+				cost = 0
+			}
+
+			curr[j] = minInt(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+
+		prev, curr = curr, prev
+	}
+
+	return prev[len(t)]
+}
+
+// minInt returns the smallest of its arguments.
+func minInt(n int, rest ...int) int {
+	for _, m := range rest {
+		if m < n {
+			n = m
+		}
+	}
+
+	return n
+}
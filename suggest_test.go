@@ -0,0 +1,59 @@
+package cli
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestLevenshtein(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"", "", 0},
+		{"build", "build", 0},
+		{"biuld", "build", 2},
+		{"buid", "build", 1},
+		{"", "abc", 3},
+		{"kitten", "sitting", 3},
+		{"héllo", "hello", 1},
+	}
+
+	for _, tt := range tests {
+		have := levenshtein(tt.a, tt.b)
+		if have != tt.want {
+			t.Errorf("levenshtein(%q, %q)\nhave %d\nwant %d", tt.a, tt.b, have, tt.want)
+		}
+	}
+}
+
+func TestSuggest(t *testing.T) {
+	app := New("myapp", "0.0.1")
+	app.Rule(&runRecord{}, "build", "[<a>] [<b>]")
+
+	var stdout, stderr bytes.Buffer
+	code := app.RunWithArgs([]string{"biuld"}, &stdout, &stderr)
+	want := "Error: unknown command 'biuld', did you mean 'build'?\n"
+	if code != 1 || !strings.HasPrefix(stderr.String(), want) {
+		t.Errorf("suggestion\nhave %d %q\nwant %d %q", code, stderr.String(), 1, want)
+	}
+
+	stderr.Reset()
+	app.RunWithArgs([]string{"deploy"}, &stdout, &stderr)
+	if !strings.HasPrefix(stderr.String(), "Error: invalid command deploy\n") {
+		t.Errorf("no suggestion\nhave %q", stderr.String())
+	}
+
+	stderr.Reset()
+	app.SuggestAndRun(true)
+	code = app.RunWithArgs([]string{"buid", "-number", "4"}, &stdout, &stderr)
+	if code != 4 {
+		t.Errorf("suggest and run exit code\nhave %d\nwant %d", code, 4)
+	}
+
+	code = app.RunWithArgs([]string{"biuld"}, &stdout, &stderr)
+	if code != 1 {
+		t.Errorf("suggest and run distance 2 exit code\nhave %d\nwant %d", code, 1)
+	}
+}
@@ -0,0 +1,194 @@
+package cli
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+)
+
+// An Alignment aligns the cells of a column of a Table.
+type Alignment int
+
+// Alignments.
+const (
+	AlignLeft Alignment = iota
+	AlignRight
+)
+
+// A Table renders rows of cells to the standard output of an invocation:
+// as aligned columns in text, as an array of objects keyed by the header in
+// JSON and as comma-separated values in CSV, according to the -output and
+// -json flags, see StandardFlags. Rows are added with Append and rendered
+// with Flush.
+type Table struct {
+	// Header names the columns.
+	Header []string
+	// NoHeader omits the header from text and CSV.
+	NoHeader bool
+	// Align aligns the columns in text, left by default.
+	Align []Alignment
+	// Width is the number of columns beyond which text rows are truncated
+	// with an ellipsis, or if zero, the width of the terminal, if any.
+	Width int
+	// Padding is the number of spaces separating the columns in text, or 2
+	// if zero.
+	Padding int
+
+	w      io.Writer
+	format string
+	rows   [][]string
+}
+
+// NewTable returns a Table with the header for the invocation of the command
+// that received ctx.
+func NewTable(ctx context.Context, header ...string) *Table {
+	return &Table{Header: header, w: Stdout(ctx), format: outputFormat(ctx)}
+}
+
+// Append adds a row of cells, formatted with fmt.Sprint.
+func (t *Table) Append(cells ...interface{}) {
+	row := make([]string, len(cells))
+	for i, cell := range cells {
+		row[i] = fmt.Sprint(cell)
+	}
+
+	t.rows = append(t.rows, row)
+}
+
+// Flush renders the rows added since the last call.
+func (t *Table) Flush() error {
+	defer func() { t.rows = nil }()
+	switch t.format {
+	case "json":
+		return t.json()
+	case "csv":
+		return t.csv()
+	}
+
+	return t.text()
+}
+
+// json writes the rows as an array of objects keyed by the header, or of
+// arrays if there is none.
+func (t *Table) json() error {
+	var v interface{} = t.rows
+	if t.Header != nil {
+		objects := make([]map[string]string, len(t.rows))
+		for i, row := range t.rows {
+			objects[i] = make(map[string]string, len(t.Header))
+			for j, cell := range row {
+				if j < len(t.Header) {
+					objects[i][t.Header[j]] = cell
+				}
+			}
+		}
+
+		v = objects
+	}
+
+	enc := json.NewEncoder(t.w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// csv writes the rows as comma-separated values.
+func (t *Table) csv() error {
+	w := csv.NewWriter(t.w)
+	if t.Header != nil && !t.NoHeader {
+		w.Write(t.Header)
+	}
+
+	w.WriteAll(t.rows)
+	return w.Error()
+}
+
+// text writes the rows as aligned columns.
+func (t *Table) text() error {
+	rows := t.rows
+	if t.Header != nil && !t.NoHeader {
+		rows = append([][]string{t.Header}, rows...)
+	}
+
+	var widths []int
+	for _, row := range rows {
+		for i, cell := range row {
+			if i == len(widths) {
+				widths = append(widths, 0)
+			}
+
+			if n := utf8.RuneCountInString(cell); n > widths[i] {
+				widths[i] = n
+			}
+		}
+	}
+
+	padding := t.Padding
+	if padding <= 0 {
+		padding = 2
+	}
+
+	limit := t.Width
+	if limit == 0 {
+		limit = width(t.w)
+	}
+
+	var b strings.Builder
+	for _, row := range rows {
+		b.Reset()
+		for i, cell := range row {
+			if i > 0 {
+				b.WriteString(strings.Repeat(" ", padding))
+			}
+
+			pad := strings.Repeat(" ", widths[i]-utf8.RuneCountInString(cell))
+			switch {
+			case i < len(t.Align) && t.Align[i] == AlignRight:
+				b.WriteString(pad + cell)
+			case i < len(row)-1:
+				b.WriteString(cell + pad)
+			default:
+				b.WriteString(cell)
+			}
+		}
+
+		_, err := fmt.Fprintln(t.w, truncate(b.String(), limit))
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// truncate shortens s to n runes with a trailing ellipsis if it is longer
+// and n is positive.
+func truncate(s string, n int) string {
+	if n <= 0 || utf8.RuneCountInString(s) <= n {
+		return s
+	}
+
+	r := []rune(s)
+	if n == 1 {
+		return "…"
+	}
+
+	return string(r[:n-1]) + "…"
+}
+
+// outputFormat returns the format of results for the invocation of the
+// command that received ctx: text, json or csv.
+func outputFormat(ctx context.Context) string {
+	o := ctxOutput(ctx)
+	switch {
+	case o.json:
+		return "json"
+	case o.format != nil && o.format.Value() != "table":
+		return o.format.Value()
+	}
+
+	return "text"
+}
@@ -0,0 +1,65 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"testing"
+)
+
+func TestTable(t *testing.T) {
+	var buf bytes.Buffer
+	table := &Table{Header: []string{"NAME", "SIZE", "PATH"}, Align: []Alignment{AlignLeft, AlignRight}, w: &buf}
+	table.Append("alpha", 1, "/srv/alpha")
+	table.Append("β", 2048, "/srv/beta/with/a/long/path")
+	table.Flush()
+	want := "NAME   SIZE  PATH\n" +
+		"alpha     1  /srv/alpha\n" +
+		"β      2048  /srv/beta/with/a/long/path\n"
+	if buf.String() != want {
+		t.Errorf("text\nhave %q\nwant %q", buf.String(), want)
+	}
+
+	buf.Reset()
+	table.Width, table.NoHeader = 20, true
+	table.Append("β", 2048, "/srv/beta/with/a/long/path")
+	table.Flush()
+	if want := "β  2048  /srv/beta/…\n"; buf.String() != want {
+		t.Errorf("truncated\nhave %q\nwant %q", buf.String(), want)
+	}
+
+	buf.Reset()
+	table.format, table.NoHeader = "csv", false
+	table.Append("a,b", 1, "/")
+	table.Flush()
+	if want := "NAME,SIZE,PATH\n\"a,b\",1,/\n"; buf.String() != want {
+		t.Errorf("csv\nhave %q\nwant %q", buf.String(), want)
+	}
+}
+
+func TestTableOutput(t *testing.T) {
+	app := New("myapp", "0.0.1")
+	app.StandardFlags()
+	app.RuleFunc("list", "List them.", "", func(ctx context.Context) error {
+		table := NewTable(ctx, "ID", "State")
+		table.Append(1, "running")
+		return table.Flush()
+	})
+
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{[]string{"list"}, "ID  State\n1   running\n"},
+		{[]string{"-output", "table", "list"}, "ID  State\n1   running\n"},
+		{[]string{"-output", "csv", "list"}, "ID,State\n1,running\n"},
+		{[]string{"-json", "list"}, "[\n  {\n    \"ID\": \"1\",\n    \"State\": \"running\"\n  }\n]\n"},
+	}
+
+	for _, tt := range tests {
+		var stdout, stderr bytes.Buffer
+		app.RunWithArgs(tt.args, &stdout, &stderr)
+		if stdout.String() != tt.want {
+			t.Errorf("%q\nhave %q\nwant %q\n%s", tt.args, stdout.String(), tt.want, stderr.String())
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package cli
+
+import (
+	"context"
+	"sync"
+)
+
+// A TaskGroup is a collection of goroutines working on subtasks of a
+// command. The first goroutine to return a non-nil error cancels the context
+// of the group. The zero value is not usable; use NewTaskGroup.
+type TaskGroup struct {
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+	once   sync.Once
+	err    error
+}
+
+type taskGroupContextKey struct{}
+
+// NewTaskGroup returns a new TaskGroup and an associated context derived
+// from ctx.
+func NewTaskGroup(ctx context.Context) (*TaskGroup, context.Context) {
+	ctx, cancel := context.WithCancel(ctx)
+	g := &TaskGroup{cancel: cancel}
+	return g, context.WithValue(ctx, taskGroupContextKey{}, g)
+}
+
+// Go calls fn in a new goroutine.
+func (g *TaskGroup) Go(fn func() error) {
+	g.wg.Add(1)
+	go func() {
+		defer g.wg.Done()
+		err := fn()
+		if err != nil {
+			g.once.Do(func() {
+				g.err = err
+				g.cancel()
+			})
+		}
+	}()
+}
+
+// Wait blocks until all goroutines in the group have returned, cancels the
+// context of the group and returns the first non-nil error, if any.
+func (g *TaskGroup) Wait() error {
+	g.wg.Wait()
+	g.cancel()
+	return g.err
+}
+
+// Go calls fn in a new goroutine belonging to the invocation of the command
+// that received ctx, which must be derived from the context passed to Run.
+// The invocation does not complete until fn returns. If fn returns an error,
+// the context is cancelled and the error is reported as the command error.
+func Go(ctx context.Context, fn func() error) {
+	ctxTaskGroup(ctx).Go(fn)
+}
+
+// Wait blocks until all goroutines started with Go for the invocation of the
+// command that received ctx have returned and returns the first error.
+func Wait(ctx context.Context) error {
+	g := ctxTaskGroup(ctx)
+	g.wg.Wait()
+	return g.err
+}
+
+// ctxTaskGroup returns the TaskGroup of ctx or panics if there is none.
+func ctxTaskGroup(ctx context.Context) *TaskGroup {
+	g, ok := ctx.Value(taskGroupContextKey{}).(*TaskGroup)
+	if !ok {
+		panic("cli: context was not passed to Run")
+	}
+
+	return g
+}
@@ -0,0 +1,61 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+type runTaskGroup struct {
+	*NullFlags
+	cancelled bool
+}
+
+func TestTaskGroupWait(t *testing.T) {
+	errFirst := errors.New("first")
+	g, ctx := NewTaskGroup(context.Background())
+	g.Go(func() error { return errFirst })
+	g.Go(func() error {
+		<-ctx.Done()
+		return errors.New("second")
+	})
+
+	err := g.Wait()
+	if err != errFirst {
+		t.Errorf("error\nhave %v\nwant %v", err, errFirst)
+	}
+}
+
+func TestDispatchTaskGroup(t *testing.T) {
+	app := New("myapp", "0.0.1")
+	cmd := &runTaskGroup{}
+	err := app.Rule(cmd, "group", "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	code := app.Dispatch([]string{"group"})
+	if code != 1 {
+		t.Errorf("exit code\nhave %d\nwant %d", code, 1)
+	}
+
+	if !cmd.cancelled {
+		t.Errorf("context not cancelled")
+	}
+}
+
+func (c *runTaskGroup) Run(ctx context.Context) {
+	Go(ctx, func() error {
+		return errors.New("failed")
+	})
+
+	Go(ctx, func() error {
+		<-ctx.Done()
+		c.cancelled = true
+		return nil
+	})
+}
+
+func (c *runTaskGroup) String() string {
+	return "run goroutines"
+}
@@ -0,0 +1,31 @@
+package cli
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+)
+
+// TempDir returns a temporary directory for the invocation of the command that
+// received ctx, creating it on first use. The directory and its contents are
+// removed when the invocation completes, including when it was interrupted by
+// a signal or timed out.
+func TempDir(ctx context.Context) (string, error) {
+	inv := ctxInvocation(ctx)
+	inv.mu.Lock()
+	defer inv.mu.Unlock()
+
+	if inv.tempDir != "" {
+		return inv.tempDir, nil
+	}
+
+	dir, err := ioutil.TempDir("", inv.app.name+"-"+inv.rule.name+"-")
+	if err != nil {
+		return "", err
+	}
+
+	inv.tempDir = dir
+	inv.cleanup = append(inv.cleanup, func() { os.RemoveAll(dir) })
+
+	return dir, nil
+}
@@ -0,0 +1,54 @@
+package cli
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+type runTemp struct {
+	*NullFlags
+	dir string
+}
+
+func TestTempDir(t *testing.T) {
+	app := New("myapp", "0.0.1")
+	cmd := &runTemp{}
+	app.Rule(cmd, "temp", "")
+
+	code := app.Dispatch([]string{"temp"})
+	if code != 0 {
+		t.Fatalf("exit code\nhave %d\nwant %d", code, 0)
+	}
+
+	_, err := os.Stat(cmd.dir)
+	if !os.IsNotExist(err) {
+		t.Errorf("temp dir %s not removed: %v", cmd.dir, err)
+	}
+}
+
+func (c *runTemp) Run(ctx context.Context) int {
+	dir, err := TempDir(ctx)
+	if err != nil {
+		return 1
+	}
+
+	again, _ := TempDir(ctx)
+	if again != dir {
+		return 2
+	}
+
+	c.dir = dir
+	err = ioutil.WriteFile(filepath.Join(dir, "scratch"), []byte("x"), 0644)
+	if err != nil {
+		return 3
+	}
+
+	return 0
+}
+
+func (c *runTemp) String() string {
+	return "use a scratch directory"
+}
@@ -0,0 +1,44 @@
+package cli
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// isTerminal reports whether r or w is an *os.File attached to a terminal,
+// or a Pager displaying on one.
+func isTerminal(v interface{}) bool {
+	if p, ok := v.(*pager); ok {
+		v = p.term
+	}
+
+	f, ok := v.(*os.File)
+	return ok && terminal(f.Fd())
+}
+
+// readPassword prints prompt to w and reads a line from r without echoing
+// it, provided r is a terminal.
+func readPassword(r io.Reader, w io.Writer, prompt string) (string, error) {
+	f, ok := r.(*os.File)
+	if !ok || !terminal(f.Fd()) {
+		return "", fmt.Errorf("cannot prompt for %s without a terminal", strings.TrimSuffix(prompt, ": "))
+	}
+
+	fmt.Fprint(w, prompt)
+	restore, err := disableEcho(f.Fd())
+	if err != nil {
+		return "", err
+	}
+
+	line, err := bufio.NewReader(f).ReadString('\n')
+	restore()
+	fmt.Fprintln(w)
+	if err != nil && !(err == io.EOF && line != "") {
+		return "", err
+	}
+
+	return strings.TrimRight(line, "\r\n"), nil
+}
@@ -0,0 +1,20 @@
+//go:build !linux && !darwin && !dragonfly && !freebsd && !netbsd && !openbsd
+
+package cli
+
+import "errors"
+
+// terminal is not supported on this platform.
+func terminal(fd uintptr) bool {
+	return false
+}
+
+// disableEcho is not supported on this platform.
+func disableEcho(fd uintptr) (func(), error) {
+	return nil, errors.New("terminal echo cannot be disabled on this platform")
+}
+
+// terminalWidth is not supported on this platform.
+func terminalWidth(fd uintptr) int {
+	return 0
+}
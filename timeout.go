@@ -0,0 +1,37 @@
+package cli
+
+import "time"
+
+// A timeouter is implemented by commands declaring how long they may run,
+// see Timeout.
+type timeouter interface {
+	Timeout() time.Duration
+}
+
+// Timeout is a RuleOption setting how long the command may run. When the
+// duration elapses, the context of the command is cancelled and the
+// invocation exits with code 124. Commands may instead declare the duration
+// with a method Timeout. Either is overridden with the -timeout flag before
+// the command name, such as "app -timeout 10m build", see WithTimeoutFlag.
+// Commands must return promptly when their context is cancelled.
+func Timeout(d time.Duration) RuleOption {
+	return func(r *rule) {
+		r.timeout = d
+	}
+}
+
+// timeLimit returns how long the command of the rule may run, or zero if it
+// may run indefinitely, given the -timeout flag.
+func (r *rule) timeLimit(flag time.Duration) time.Duration {
+	if flag > 0 {
+		return flag
+	}
+
+	if t, ok := r.command.(timeouter); ok {
+		if d := t.Timeout(); d > 0 {
+			return d
+		}
+	}
+
+	return r.timeout
+}
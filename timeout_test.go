@@ -0,0 +1,55 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+)
+
+type runSlow struct {
+	*NullFlags
+	timeout time.Duration
+}
+
+func (r *runSlow) String() string {
+	return "Wait for the context."
+}
+
+func (r *runSlow) Timeout() time.Duration {
+	return r.timeout
+}
+
+func (r *runSlow) Run(ctx context.Context) {
+	select {
+	case <-ctx.Done():
+	case <-time.After(time.Second):
+	}
+}
+
+func TestTimeout(t *testing.T) {
+	tests := []struct {
+		method time.Duration
+		option time.Duration
+		args   []string
+		code   int
+		stderr string
+	}{
+		{0, 0, []string{"slow"}, 0, ""},
+		{0, 10 * time.Millisecond, []string{"slow"}, ExitTimeout, "Error: slow: timed out after 10ms\n"},
+		{20 * time.Millisecond, 0, []string{"slow"}, ExitTimeout, "Error: slow: timed out after 20ms\n"},
+		{time.Minute, time.Minute, []string{"-timeout", "30ms", "slow"}, ExitTimeout, "Error: slow: timed out after 30ms\n"},
+		{0, 0, []string{"-timeout", "1m", "slow"}, 0, ""},
+	}
+
+	for i, tt := range tests {
+		app := New("myapp", "0.0.1", WithTimeoutFlag())
+		app.Rule(&runSlow{timeout: tt.method}, "slow", "", Timeout(tt.option))
+
+		var stdout, stderr bytes.Buffer
+		code := app.RunWithArgs(tt.args, &stdout, &stderr)
+		if code != tt.code || stderr.String() != tt.stderr {
+			t.Errorf("%d\nhave %d %q\nwant %d %q", i, code, stderr.String(), tt.code, tt.stderr)
+		}
+	}
+}
@@ -0,0 +1,142 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// timestampFormats describes the values accepted by ParseTimestamp.
+const timestampFormats = "RFC 3339, YYYY-MM-DD[ HH:MM[:SS]], now, today, yesterday, tomorrow or an age such as 2h ago or 3 days ago"
+
+// timestampLayouts are the absolute layouts accepted by ParseTimestamp.
+// Layouts without a zone are interpreted in the local time zone.
+var timestampLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02T15:04",
+	"2006-01-02 15:04",
+	"2006-01-02",
+}
+
+// A Timestamp is a flag.Value holding a point in time. Define one with
+// TimestampVar.
+type Timestamp struct {
+	time time.Time
+}
+
+// TimestampVar defines a flag with the specified name, default value and
+// usage string holding a point in time parsed with ParseTimestamp. The
+// accepted formats are appended to the usage string.
+func TimestampVar(flags *flag.FlagSet, name string, value time.Time, usage string) *Timestamp {
+	t := &Timestamp{time: value}
+	flags.Var(t, name, usage+" Accepts "+timestampFormats+".")
+	return t
+}
+
+// Time returns the time.
+func (t *Timestamp) Time() time.Time {
+	return t.time
+}
+
+// String implements the flag.Value interface.
+func (t *Timestamp) String() string {
+	if t == nil || t.time.IsZero() {
+		return ""
+	}
+
+	return t.time.Format(time.RFC3339)
+}
+
+// Set implements the flag.Value interface.
+func (t *Timestamp) Set(value string) error {
+	v, err := ParseTimestamp(value)
+	if err != nil {
+		return err
+	}
+
+	t.time = v
+	return nil
+}
+
+// ParseTimestamp parses a point in time given as an RFC 3339 timestamp, a
+// date with an optional time of day in the local time zone, one of the words
+// now, today, yesterday and tomorrow, or an age relative to now such as 90m
+// ago, 2h ago or 3 days ago. It may be used for positional arguments, which
+// are always strings.
+func ParseTimestamp(s string) (time.Time, error) {
+	return parseTimestamp(s, time.Now())
+}
+
+// parseTimestamp is ParseTimestamp relative to now.
+func parseTimestamp(s string, now time.Time) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	midnight := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	switch strings.ToLower(s) {
+	case "now":
+		return now, nil
+	case "today":
+		return midnight, nil
+	case "yesterday":
+		return midnight.AddDate(0, 0, -1), nil
+	case "tomorrow":
+		return midnight.AddDate(0, 0, 1), nil
+	}
+
+	for _, layout := range timestampLayouts {
+		t, err := time.ParseInLocation(layout, s, now.Location())
+		if err == nil {
+			return t, nil
+		}
+	}
+
+	if age := strings.TrimSuffix(s, " ago"); age != s {
+		t, ok := ago(strings.TrimSpace(age), now)
+		if ok {
+			return t, nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("invalid timestamp %q", s)
+}
+
+// ago returns the time the given age before now. The age is either a
+// time.Duration or a count of calendar units such as 3 days or 1w.
+func ago(age string, now time.Time) (time.Time, bool) {
+	d, err := time.ParseDuration(strings.Replace(age, " ", "", -1))
+	if err == nil {
+		return now.Add(-d), true
+	}
+
+	i := strings.IndexFunc(age, func(r rune) bool { return r < '0' || r > '9' })
+	if i <= 0 {
+		return time.Time{}, false
+	}
+
+	n, err := strconv.Atoi(age[:i])
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	switch strings.TrimSuffix(strings.TrimSpace(age[i:]), "s") {
+	case "second", "sec":
+		return now.Add(-time.Duration(n) * time.Second), true
+	case "minute", "min":
+		return now.Add(-time.Duration(n) * time.Minute), true
+	case "hour":
+		return now.Add(-time.Duration(n) * time.Hour), true
+	case "d", "day":
+		return now.AddDate(0, 0, -n), true
+	case "w", "week":
+		return now.AddDate(0, 0, -7*n), true
+	case "month":
+		return now.AddDate(0, -n, 0), true
+	case "y", "year":
+		return now.AddDate(-n, 0, 0), true
+	}
+
+	return time.Time{}, false
+}
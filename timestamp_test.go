@@ -0,0 +1,65 @@
+package cli
+
+import (
+	"flag"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseTimestamp(t *testing.T) {
+	loc := time.FixedZone("EST", -5*60*60)
+	now := time.Date(2020, 3, 15, 12, 30, 0, 0, loc)
+	tests := map[string]time.Time{
+		"now":                       now,
+		"today":                     time.Date(2020, 3, 15, 0, 0, 0, 0, loc),
+		"yesterday":                 time.Date(2020, 3, 14, 0, 0, 0, 0, loc),
+		"2020-01-02":                time.Date(2020, 1, 2, 0, 0, 0, 0, loc),
+		"2020-01-02 03:04":          time.Date(2020, 1, 2, 3, 4, 0, 0, loc),
+		"2020-01-02T03:04:05Z":      time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC),
+		"2020-01-02T03:04:05+01:00": time.Date(2020, 1, 2, 2, 4, 5, 0, time.UTC),
+		"2h ago":                    now.Add(-2 * time.Hour),
+		"1h30m ago":                 now.Add(-90 * time.Minute),
+		"3 days ago":                now.AddDate(0, 0, -3),
+		"1w ago":                    now.AddDate(0, 0, -7),
+	}
+
+	for s, want := range tests {
+		have, err := parseTimestamp(s, now)
+		if err != nil {
+			t.Errorf("parseTimestamp(%q)\nhave %v\nwant %v", s, err, want)
+			continue
+		}
+
+		if !have.Equal(want) {
+			t.Errorf("parseTimestamp(%q)\nhave %v\nwant %v", s, have, want)
+		}
+	}
+
+	for _, s := range []string{"", "soon", "2 fortnights ago", "ago", "2020-13-01"} {
+		_, err := parseTimestamp(s, now)
+		if err == nil {
+			t.Errorf("parseTimestamp(%q)\nhave nil\nwant error", s)
+		}
+	}
+}
+
+func TestTimestampVar(t *testing.T) {
+	flags := flag.NewFlagSet("test", flag.ContinueOnError)
+	since := TimestampVar(flags, "since", time.Time{}, "Show entries since the time.")
+
+	err := flags.Parse([]string{"-since", "2020-01-02T03:04:05Z"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	if !since.Time().Equal(want) {
+		t.Errorf("since\nhave %v\nwant %v", since.Time(), want)
+	}
+
+	usage := flags.Lookup("since").Usage
+	if !strings.Contains(usage, "2h ago") {
+		t.Errorf("usage %q does not document the accepted formats", usage)
+	}
+}
@@ -0,0 +1,92 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// flagSources records where the values of flags were set from by their
+// names, such as "command line", "$APP_VERBOSE" or "config remote.add.url".
+type flagSources map[string]string
+
+// record notes source as the source of the flags set since the last call.
+func (s flagSources) record(flags *flag.FlagSet, source func(name string) string) {
+	for name := range visited(flags) {
+		if _, ok := s[name]; !ok {
+			s[name] = source(name)
+		}
+	}
+}
+
+// parsed records the flags set so far as set on the command line.
+func (s flagSources) parsed(flags *flag.FlagSet) {
+	s.record(flags, func(string) string { return "command line" })
+}
+
+// env records the flags set since the last call as set by the environment.
+func (s flagSources) env(a *Application, flags *flag.FlagSet, overrides map[string]string) {
+	s.record(flags, func(name string) string { return "$" + a.envName(name, overrides) })
+}
+
+// config records the flags set since the last call as set by the
+// configuration, under the keys beginning with prefix.
+func (s flagSources) config(flags *flag.FlagSet, prefix string) {
+	s.record(flags, func(name string) string {
+		if prefix != "" {
+			name = prefix + "." + name
+		}
+
+		return "config " + name
+	})
+}
+
+// trace writes the resolved invocation of the rule with the positional
+// arguments args to w for the -trace flag: the command, the value and source
+// of each flag, set globally or for the command, and the arguments bound to
+// each of its parameters. The values of secrets are redacted.
+func (a *Application) trace(w io.Writer, r *rule, global *flag.FlagSet, globalSources, sources flagSources, args []string) {
+	fmt.Fprintf(w, "+ %s %s\n", a.name, r.name)
+	global.VisitAll(func(f *flag.Flag) {
+		if source, ok := globalSources[f.Name]; ok && !isShorthand(f) {
+			fmt.Fprintf(w, "  global -%s=%s (%s)\n", f.Name, traceValue(f), source)
+		}
+	})
+
+	r.options.VisitAll(func(f *flag.Flag) {
+		if isShorthand(f) {
+			return
+		}
+
+		source, ok := sources[f.Name]
+		if !ok {
+			source = "default"
+		}
+
+		fmt.Fprintf(w, "  flag -%s=%s (%s)\n", f.Name, traceValue(f), source)
+	})
+
+	params := a.commandUsage(r).Arguments
+	for i, arg := range args {
+		name := "#" + strconv.Itoa(i+1)
+		switch {
+		case i < len(params):
+			name = params[i].Name
+		case len(params) > 0 && params[len(params)-1].Repeated:
+			name = params[len(params)-1].Name
+		}
+
+		fmt.Fprintf(w, "  arg %s=%q\n", name, arg)
+	}
+}
+
+// traceValue returns the quoted value of the flag, or the redacted value of
+// a secret.
+func traceValue(f *flag.Flag) string {
+	if isSecret(f) {
+		return redacted
+	}
+
+	return strconv.Quote(f.Value.String())
+}
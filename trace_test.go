@@ -0,0 +1,64 @@
+package cli
+
+import (
+	"bytes"
+	"flag"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+type runTrace struct {
+	level *int
+	name  *string
+	token *Secret
+}
+
+func (c *runTrace) Flags(flags *flag.FlagSet) {
+	c.level = flags.Int("level", 1, "level")
+	c.name = flags.String("name", "", "name")
+	c.token = SecretVar(flags, "token", "", "token")
+	Shorthand(flags, "name", "n")
+}
+
+func (c *runTrace) Run(src string, dst []string) {}
+
+func (c *runTrace) String() string {
+	return "trace arguments"
+}
+
+func TestTrace(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	os.MkdirAll(filepath.Join(dir, "myapp"), 0700)
+	ioutil.WriteFile(filepath.Join(dir, "myapp", "config.yaml"), []byte("copy:\n  level: 3\n"), 0600)
+	t.Setenv("MYAPP_NAME", "env")
+
+	app := New("myapp", "0.0.1", WithTraceFlag(), WithYesFlag())
+	app.EnvPrefix("MYAPP")
+	app.Rule(&runTrace{}, "copy", "<src> <dst>...")
+
+	var stdout, stderr bytes.Buffer
+	code := app.RunWithArgs([]string{"-x", "-yes", "copy", "-token", "hunter2", "a", "b", "c"}, &stdout, &stderr)
+	want := `+ myapp copy
+  global -trace="true" (command line)
+  global -yes="true" (command line)
+  flag -level="3" (config copy.level)
+  flag -name="env" ($MYAPP_NAME)
+  flag -token=******** (command line)
+  flag -token-file="" (default)
+  arg <src>="a"
+  arg <dst>="b"
+  arg <dst>="c"
+`
+	if code != 0 || stderr.String() != want {
+		t.Errorf("trace\nhave %d %q\nwant %d %q", code, stderr.String(), 0, want)
+	}
+
+	stderr.Reset()
+	app.RunWithArgs([]string{"copy", "a", "b"}, &stdout, &stderr)
+	if stderr.Len() != 0 {
+		t.Errorf("trace without -trace\nhave %q\nwant %q", stderr.String(), "")
+	}
+}
@@ -0,0 +1,185 @@
+package cli
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// A TrustStore records the executables, such as plugins, that the user
+// trusts the Application to run. An executable is trusted if its SHA-256
+// checksum has been recorded with TrustChecksum, or if a detached signature in
+// a file of the same name with a .sig suffix verifies with one of the keys
+// recorded with TrustKey. Signatures are base64 encoded Ed25519 signatures of
+// the contents of the executable.
+type TrustStore struct {
+	dir string
+}
+
+// ErrUntrusted is returned when an executable is not trusted.
+var ErrUntrusted = errors.New("executable is not trusted")
+
+// TrustStore returns the TrustStore of the Application kept in the trust
+// directory under the ConfigDir.
+func (a *Application) TrustStore() (*TrustStore, error) {
+	dir, err := a.ConfigDir()
+	if err != nil {
+		return nil, err
+	}
+
+	return &TrustStore{dir: filepath.Join(dir, "trust")}, nil
+}
+
+// Verify returns nil if the executable at path is trusted, ErrUntrusted if it
+// is not, or any error encountered while checking.
+func (t *TrustStore) Verify(path string) error {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	sum := sha256.Sum256(data)
+	ok, err := t.hasChecksum(hex.EncodeToString(sum[:]))
+	if ok || err != nil {
+		return err
+	}
+
+	sig, err := ioutil.ReadFile(path + ".sig")
+	if os.IsNotExist(err) {
+		return fmt.Errorf("%s: %w", filepath.Base(path), ErrUntrusted)
+	}
+	if err != nil {
+		return err
+	}
+
+	sig, err = base64.StdEncoding.DecodeString(strings.TrimSpace(string(sig)))
+	if err != nil {
+		return fmt.Errorf("%s.sig: %v", filepath.Base(path), err)
+	}
+
+	keys, err := t.keys()
+	if err != nil {
+		return err
+	}
+
+	for _, key := range keys {
+		if ed25519.Verify(key, data, sig) {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("%s: %w", filepath.Base(path), ErrUntrusted)
+}
+
+// TrustChecksum records the checksum of the executable at path as trusted.
+func (t *TrustStore) TrustChecksum(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	_, err = io.Copy(h, f)
+	if err != nil {
+		return err
+	}
+
+	line := fmt.Sprintf("%x  %s\n", h.Sum(nil), filepath.Base(path))
+	return t.append("checksums", line)
+}
+
+// TrustKey records key, identified by name, as trusted to sign executables.
+func (t *TrustStore) TrustKey(name string, key ed25519.PublicKey) error {
+	if len(key) != ed25519.PublicKeySize {
+		return errors.New("trust: invalid public key")
+	}
+
+	line := fmt.Sprintf("%s  %s\n", base64.StdEncoding.EncodeToString(key), name)
+	return t.append("keys", line)
+}
+
+// hasChecksum reports whether sum has been recorded as trusted.
+func (t *TrustStore) hasChecksum(sum string) (bool, error) {
+	found := false
+	err := t.scan("checksums", func(field string) error {
+		if field == sum {
+			found = true
+		}
+		return nil
+	})
+
+	return found, err
+}
+
+// keys returns the keys recorded as trusted.
+func (t *TrustStore) keys() ([]ed25519.PublicKey, error) {
+	var keys []ed25519.PublicKey
+	err := t.scan("keys", func(field string) error {
+		key, err := base64.StdEncoding.DecodeString(field)
+		if err != nil || len(key) != ed25519.PublicKeySize {
+			return errors.New("trust: invalid public key " + field)
+		}
+
+		keys = append(keys, ed25519.PublicKey(key))
+		return nil
+	})
+
+	return keys, err
+}
+
+// scan calls fn with the first field of each line of the named file.
+func (t *TrustStore) scan(name string, fn func(field string) error) error {
+	data, err := ioutil.ReadFile(filepath.Join(t.dir, name))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+
+		err = fn(fields[0])
+		if err != nil {
+			return err
+		}
+	}
+
+	return scanner.Err()
+}
+
+// append appends line to the named file.
+func (t *TrustStore) append(name, line string) error {
+	err := os.MkdirAll(t.dir, 0700)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(filepath.Join(t.dir, name), os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0600)
+	if err != nil {
+		return err
+	}
+
+	_, err = f.WriteString(line)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+
+	return err
+}
@@ -0,0 +1,50 @@
+package cli
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestTrustStore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cli-trust")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	store := &TrustStore{dir: filepath.Join(dir, "trust")}
+	plugin := filepath.Join(dir, "myapp-deploy")
+	ioutil.WriteFile(plugin, []byte("#!/bin/sh\necho deploy\n"), 0755)
+
+	err = store.Verify(plugin)
+	if !errors.Is(err, ErrUntrusted) {
+		t.Errorf("unknown\nhave %v\nwant %v", err, ErrUntrusted)
+	}
+
+	pub, priv, _ := ed25519.GenerateKey(nil)
+	sig := ed25519.Sign(priv, []byte("#!/bin/sh\necho deploy\n"))
+	ioutil.WriteFile(plugin+".sig", []byte(base64.StdEncoding.EncodeToString(sig)), 0644)
+	store.TrustKey("release", pub)
+
+	err = store.Verify(plugin)
+	if err != nil {
+		t.Errorf("signed\nhave %v\nwant %v", err, nil)
+	}
+
+	ioutil.WriteFile(plugin, []byte("#!/bin/sh\nrm -rf /\n"), 0755)
+	err = store.Verify(plugin)
+	if !errors.Is(err, ErrUntrusted) {
+		t.Errorf("tampered\nhave %v\nwant %v", err, ErrUntrusted)
+	}
+
+	store.TrustChecksum(plugin)
+	err = store.Verify(plugin)
+	if err != nil {
+		t.Errorf("checksum\nhave %v\nwant %v", err, nil)
+	}
+}
@@ -0,0 +1,243 @@
+package cli
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// maxUpdateSize is the largest binary downloaded by the update command.
+const maxUpdateSize = 512 << 20
+
+type selfUpdate struct {
+	url string
+	key ed25519.PublicKey
+}
+
+type commandUpdate struct {
+	app     *Application
+	network NetworkFlags
+	check   *bool
+	force   *bool
+
+	// executable returns the path of the binary to replace.
+	executable func() (string, error)
+}
+
+// A Release describes the latest release of an Application, served as JSON
+// by the release endpoint of WithSelfUpdate.
+type Release struct {
+	Version string `json:"version"`
+	// Binaries are keyed by the operating system and architecture, such as
+	// "linux/amd64" or "windows/amd64".
+	Binaries map[string]ReleaseBinary `json:"binaries"`
+}
+
+// A ReleaseBinary is the binary of a Release for one platform.
+type ReleaseBinary struct {
+	// URL is the location of the binary, relative to the release endpoint.
+	URL string `json:"url"`
+	// Signature is the base64 encoded Ed25519 signature of the
+	// ReleaseManifest of the binary.
+	Signature string `json:"signature"`
+}
+
+// ReleaseManifest returns the message signed for the binary of a release,
+// binding its version and platform, such as "linux/amd64", to the SHA-256
+// hash of the binary, so that a signed binary cannot be served as that of
+// another release or platform.
+func ReleaseManifest(version, platform string, binary []byte) []byte {
+	return []byte(fmt.Sprintf("%s\n%s\n%x\n", strings.TrimPrefix(version, "v"), platform, sha256.Sum256(binary)))
+}
+
+// WithSelfUpdate is an Option adding a built-in update command replacing the
+// running binary with the latest release. The release is described by a
+// Release served as JSON from endpoint, and the ReleaseManifest of its binary
+// must be signed by the private key of publicKey. Only releases with a newer
+// version are installed, unless the -force flag is given. The binary is replaced atomically, so a failed
+// update leaves the previous binary in place. On Windows, where a running
+// binary cannot be replaced, the previous binary is kept beside the new one
+// with a .old suffix until the next update.
+func WithSelfUpdate(endpoint string, publicKey ed25519.PublicKey) Option {
+	return func(a *Application) {
+		a.update = &selfUpdate{url: endpoint, key: publicKey}
+	}
+}
+
+func (c *commandUpdate) Flags(flags *flag.FlagSet) {
+	c.network.Define(flags)
+	c.check = flags.Bool("check", false, "Report whether an update is available without installing it.")
+	c.force = flags.Bool("force", false, "Install the latest release even if it is not newer.")
+}
+
+func (c *commandUpdate) Run(ctx context.Context) error {
+	client, err := c.network.Client()
+	if err != nil {
+		return err
+	}
+
+	release, err := c.release(ctx, client)
+	if err != nil {
+		return err
+	}
+
+	name := c.app.name
+	if compareVersions(release.Version, c.app.version) <= 0 && !*c.force {
+		fmt.Fprintf(Stdout(ctx), c.app.messages.UpToDate+"\n", name, c.app.version)
+		return nil
+	}
+
+	if *c.check {
+		fmt.Fprintf(Stdout(ctx), c.app.messages.UpdateAvailable+"\n", name, release.Version, name)
+		return nil
+	}
+
+	platform := runtime.GOOS + "/" + runtime.GOARCH
+	binary, ok := release.Binaries[platform]
+	if !ok {
+		return fmt.Errorf("no release of %s %s for %s", name, release.Version, platform)
+	}
+
+	if DryRun(ctx) {
+		fmt.Fprintf(Stdout(ctx), c.app.messages.WouldUpdate+"\n", name, c.app.version, release.Version)
+		return nil
+	}
+
+	data, err := c.download(ctx, client, binary.URL)
+	if err != nil {
+		return err
+	}
+
+	sig, err := base64.StdEncoding.DecodeString(strings.TrimSpace(binary.Signature))
+	if err != nil || !ed25519.Verify(c.app.update.key, ReleaseManifest(release.Version, platform, data), sig) {
+		return fmt.Errorf("%s %s: "+c.app.messages.InvalidSignature, name, release.Version)
+	}
+
+	path, err := c.executable()
+	if err != nil {
+		return err
+	}
+
+	err = replaceExecutable(path, data)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(Stdout(ctx), c.app.messages.Updated+"\n", name, c.app.version, release.Version)
+	return nil
+}
+
+func (c *commandUpdate) String() string {
+	return "Update to the latest release."
+}
+
+// release fetches the description of the latest release.
+func (c *commandUpdate) release(ctx context.Context, client *http.Client) (*Release, error) {
+	data, err := c.download(ctx, client, "")
+	if err != nil {
+		return nil, err
+	}
+
+	var release Release
+	err = json.Unmarshal(data, &release)
+	if err != nil {
+		return nil, fmt.Errorf("release: %v", err)
+	}
+
+	if release.Version == "" {
+		return nil, fmt.Errorf("release: missing version")
+	}
+
+	return &release, nil
+}
+
+// download fetches ref, resolved against the release endpoint.
+func (c *commandUpdate) download(ctx context.Context, client *http.Client, ref string) ([]byte, error) {
+	base, err := url.Parse(c.app.update.url)
+	if err != nil {
+		return nil, err
+	}
+
+	u, err := base.Parse(ref)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s: %s", u, resp.Status)
+	}
+
+	data, err := ioutil.ReadAll(io.LimitReader(resp.Body, maxUpdateSize+1))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(data) > maxUpdateSize {
+		return nil, fmt.Errorf("%s: too large", u)
+	}
+
+	return data, nil
+}
+
+// executable returns the path of the running binary, with symbolic links
+// resolved.
+func executable() (string, error) {
+	path, err := os.Executable()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.EvalSymlinks(path)
+}
+
+// replaceExecutable atomically replaces the binary at path with data,
+// keeping its permissions.
+func replaceExecutable(path string, data []byte) error {
+	perm := os.FileMode(0755)
+	if fi, err := os.Stat(path); err == nil {
+		perm = fi.Mode().Perm()
+	}
+
+	if runtime.GOOS != "windows" {
+		return writeFileAtomic(path, data, perm, writeOptions{})
+	}
+
+	// The running binary cannot be replaced on Windows, but it can be moved
+	// aside.
+	old := path + ".old"
+	os.Remove(old)
+	err := os.Rename(path, old)
+	if err != nil {
+		return err
+	}
+
+	err = writeFileAtomic(path, data, perm, writeOptions{})
+	if err != nil {
+		os.Rename(old, path)
+	}
+
+	return err
+}
@@ -0,0 +1,108 @@
+package cli
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/base64"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestSelfUpdate(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	binary := []byte("new binary")
+	platform := runtime.GOOS + "/" + runtime.GOARCH
+	signature := base64.StdEncoding.EncodeToString(ed25519.Sign(priv, ReleaseManifest("1.1.0", platform, binary)))
+	mux := http.NewServeMux()
+	mux.HandleFunc("/latest.json", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"version": "1.1.0", "binaries": {%q: {"url": "myapp-1.1.0", "signature": %q}}}`,
+			platform, signature)
+	})
+	mux.HandleFunc("/myapp-1.1.0", func(w http.ResponseWriter, r *http.Request) {
+		w.Write(binary)
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	path := filepath.Join(t.TempDir(), "myapp")
+	err = ioutil.WriteFile(path, []byte("old binary"), 0755)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		version string
+		args    []string
+		want    string
+		data    string
+	}{
+		{"1.1.0", []string{"update"}, "myapp is up to date (1.1.0).\n", "old binary"},
+		{"1.2.0", []string{"update"}, "myapp is up to date (1.2.0).\n", "old binary"},
+		{"1.2.0", []string{"-dry-run", "update", "-force"}, "Would update myapp from 1.2.0 to 1.1.0.\n", "old binary"},
+		{"1.0.0", []string{"update", "-check"}, "myapp 1.1.0 is available, run 'myapp update' to install it.\n", "old binary"},
+		{"1.0.0", []string{"-dry-run", "update"}, "Would update myapp from 1.0.0 to 1.1.0.\n", "old binary"},
+		{"1.0.0", []string{"update"}, "Updated myapp from 1.0.0 to 1.1.0.\n", "new binary"},
+	}
+
+	for _, tt := range tests {
+		app := New("myapp", tt.version, WithSelfUpdate(srv.URL+"/latest.json", pub), WithDryRunFlag())
+		r, _ := app.lookup("update")
+		r.load()
+		r.command.(*commandUpdate).executable = func() (string, error) { return path, nil }
+
+		var stdout, stderr bytes.Buffer
+		code := app.RunWithArgs(tt.args, &stdout, &stderr)
+		data, _ := ioutil.ReadFile(path)
+		if code != 0 || stdout.String() != tt.want || string(data) != tt.data {
+			t.Errorf("%s %q\nhave %d %q %q %q\nwant %d %q %q", tt.version, tt.args, code, stdout.String(), stderr.String(), data, 0, tt.want, tt.data)
+		}
+	}
+
+	// A binary signed by another key is rejected.
+	other, _, _ := ed25519.GenerateKey(nil)
+	app := New("myapp", "1.0.0", WithSelfUpdate(srv.URL+"/latest.json", other))
+	var stdout, stderr bytes.Buffer
+	code := app.RunWithArgs([]string{"update"}, &stdout, &stderr)
+	want := "myapp: update: myapp 1.1.0: invalid signature\n"
+	if code != 1 || stderr.String() != want {
+		t.Errorf("untrusted\nhave %d %q\nwant %d %q", code, stderr.String(), 1, want)
+	}
+}
+
+func TestSelfUpdateRollback(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The signed binary of 1.0.0 is served as 1.2.0.
+	binary := []byte("old release")
+	platform := runtime.GOOS + "/" + runtime.GOARCH
+	signature := base64.StdEncoding.EncodeToString(ed25519.Sign(priv, ReleaseManifest("1.0.0", platform, binary)))
+	mux := http.NewServeMux()
+	mux.HandleFunc("/latest.json", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"version": "1.2.0", "binaries": {%q: {"url": "myapp", "signature": %q}}}`, platform, signature)
+	})
+	mux.HandleFunc("/myapp", func(w http.ResponseWriter, r *http.Request) {
+		w.Write(binary)
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	app := New("myapp", "1.1.0", WithSelfUpdate(srv.URL+"/latest.json", pub))
+	var stdout, stderr bytes.Buffer
+	code := app.RunWithArgs([]string{"update"}, &stdout, &stderr)
+	want := "myapp: update: myapp 1.2.0: invalid signature\n"
+	if code != 1 || stderr.String() != want {
+		t.Errorf("rollback\nhave %d %q\nwant %d %q", code, stderr.String(), 1, want)
+	}
+}
@@ -0,0 +1,105 @@
+package cli
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// A Validator checks the value of a positional argument, see Arg, or of a
+// flag, see Validate, returning an error describing why it is invalid.
+type Validator func(value string) error
+
+// IsFile is a Validator accepting the path of an existing file that is not a
+// directory.
+func IsFile(value string) error {
+	fi, err := os.Stat(value)
+	if os.IsNotExist(err) {
+		return fmt.Errorf("no such file")
+	} else if err != nil {
+		return err
+	} else if fi.IsDir() {
+		return fmt.Errorf("is a directory")
+	}
+
+	return nil
+}
+
+// IsDir is a Validator accepting the path of an existing directory.
+func IsDir(value string) error {
+	fi, err := os.Stat(value)
+	if os.IsNotExist(err) {
+		return fmt.Errorf("no such directory")
+	} else if err != nil {
+		return err
+	} else if !fi.IsDir() {
+		return fmt.Errorf("not a directory")
+	}
+
+	return nil
+}
+
+// IsURL is a Validator accepting an absolute URL with a host, such as
+// https://example.com/path.
+func IsURL(value string) error {
+	u, err := url.Parse(value)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return fmt.Errorf("not a URL")
+	}
+
+	return nil
+}
+
+// Matches returns a Validator accepting values matching re.
+func Matches(re *regexp.Regexp) Validator {
+	return func(value string) error {
+		if !re.MatchString(value) {
+			return fmt.Errorf("must match %s", re)
+		}
+
+		return nil
+	}
+}
+
+// OneOf returns a Validator accepting only one of choices.
+func OneOf(choices ...string) Validator {
+	return func(value string) error {
+		for _, choice := range choices {
+			if value == choice {
+				return nil
+			}
+		}
+
+		return fmt.Errorf("must be one of %s", strings.Join(choices, ", "))
+	}
+}
+
+// validateArgs checks args with the validators of the positional arguments
+// of the rule declared with Arguments. The validators of a variadic argument
+// check each of the values it is given.
+func (r *rule) validateArgs(args []string) error {
+	positional := r.positional()
+	for i, value := range args {
+		if len(positional) == 0 {
+			break
+		}
+
+		arg := positional[len(positional)-1]
+		if i < len(positional) {
+			arg = positional[i]
+		} else if !arg.Variadic {
+			break
+		}
+
+		for _, fn := range arg.Validate {
+			err := fn(value)
+			if err != nil {
+				return fmt.Errorf("invalid argument %q for <%s>: %v", value, arg.Name, err)
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,75 @@
+package cli
+
+import (
+	"bytes"
+	"io/ioutil"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestValidators(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "a.txt")
+	ioutil.WriteFile(file, nil, 0600)
+
+	tests := []struct {
+		fn    Validator
+		value string
+		want  string
+	}{
+		{IsFile, file, ""},
+		{IsFile, dir, "is a directory"},
+		{IsFile, filepath.Join(dir, "missing"), "no such file"},
+		{IsDir, dir, ""},
+		{IsDir, file, "not a directory"},
+		{IsURL, "https://example.com/x", ""},
+		{IsURL, "example.com", "not a URL"},
+		{Matches(regexp.MustCompile(`^v\d+$`)), "v2", ""},
+		{Matches(regexp.MustCompile(`^v\d+$`)), "2", `must match ^v\d+$`},
+		{OneOf("a", "b"), "b", ""},
+		{OneOf("a", "b"), "c", "must be one of a, b"},
+	}
+
+	for _, tt := range tests {
+		have := ""
+		if err := tt.fn(tt.value); err != nil {
+			have = err.Error()
+		}
+
+		if have != tt.want {
+			t.Errorf("%q\nhave %q\nwant %q", tt.value, have, tt.want)
+		}
+	}
+}
+
+func TestValidateArguments(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "a.txt")
+	ioutil.WriteFile(file, nil, 0600)
+
+	app := New("myapp", "0.0.1")
+	app.RuleFunc("upload", "Upload files.", "", func(target string, files []string) {}, Arguments(
+		Arg{Name: "target", Required: true, Validate: []Validator{OneOf("staging", "production")}},
+		Arg{Name: "files", Required: true, Variadic: true, Validate: []Validator{IsFile}},
+	))
+
+	tests := []struct {
+		args []string
+		code int
+		want string
+	}{
+		{[]string{"upload", "staging", file, file}, 0, ""},
+		{[]string{"upload", "dev", file}, ExitUsage, `Error: upload: invalid argument "dev" for <target>: must be one of staging, production`},
+		{[]string{"upload", "staging", file, dir}, ExitUsage, `Error: upload: invalid argument "` + dir + `" for <files>: is a directory`},
+	}
+
+	for _, tt := range tests {
+		var stdout, stderr bytes.Buffer
+		code := app.RunWithArgs(tt.args, &stdout, &stderr)
+		if code != tt.code || !strings.HasPrefix(stderr.String(), tt.want) {
+			t.Errorf("%q\nhave %d %q\nwant %d %q", tt.args, code, stderr.String(), tt.code, tt.want)
+		}
+	}
+}
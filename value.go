@@ -0,0 +1,28 @@
+package cli
+
+import (
+	"context"
+	"io/ioutil"
+	"strings"
+)
+
+// ReadValue interprets arg, such as a positional argument or flag value, by
+// the convention of curl and the AWS CLI for large payloads: an argument
+// beginning with @ is the name of a file whose contents are the value, with
+// @- naming the standard input of the invocation of the command that received
+// ctx. Any other argument is the value itself. A leading @@ escapes a literal
+// value beginning with @.
+func ReadValue(ctx context.Context, arg string) (string, error) {
+	switch {
+	case strings.HasPrefix(arg, "@@"):
+		return arg[1:], nil
+	case arg == "@-":
+		data, err := ioutil.ReadAll(Stdin(ctx))
+		return string(data), err
+	case strings.HasPrefix(arg, "@"):
+		data, err := ioutil.ReadFile(arg[1:])
+		return string(data), err
+	}
+
+	return arg, nil
+}
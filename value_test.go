@@ -0,0 +1,41 @@
+package cli
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestReadValue(t *testing.T) {
+	f, err := ioutil.TempFile("", "value")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(f.Name())
+	f.WriteString("from file")
+	f.Close()
+
+	s := streams{strings.NewReader("from stdin"), ioutil.Discard, ioutil.Discard}
+	ctx := withStreams(context.Background(), s)
+	tests := map[string]string{
+		"literal":      "literal",
+		"@" + f.Name(): "from file",
+		"@-":           "from stdin",
+		"@@handle":     "@handle",
+		"":             "",
+	}
+
+	for arg, want := range tests {
+		have, err := ReadValue(ctx, arg)
+		if err != nil || have != want {
+			t.Errorf("ReadValue(%q)\nhave %q %v\nwant %q", arg, have, err, want)
+		}
+	}
+
+	_, err = ReadValue(ctx, "@/does/not/exist")
+	if err == nil {
+		t.Errorf("ReadValue of a missing file\nhave nil\nwant error")
+	}
+}
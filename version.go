@@ -1,17 +1,134 @@
 package cli
 
-import "fmt"
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"runtime/debug"
+	"sort"
+	"strconv"
+)
 
 type commandVersion struct {
-	*NullFlags
-	name    string
-	version string
+	app   *Application
+	short *bool
+	json  *bool
 }
 
-func (c *commandVersion) Run() {
-	fmt.Printf("%s v%s\n", c.name, c.version)
+// cmdlineBool is a bool flag.Value that is only set on the command line and
+// never from environment variables or configuration.
+type cmdlineBool bool
+
+// BuildInfo adds build information with the given key, such as commit or
+// date, to the output of the version command. Otherwise, the commit, date
+// and go version are taken from the build information of the executable,
+// where available.
+func (a *Application) BuildInfo(key, value string) {
+	if a.build == nil {
+		a.build = make(map[string]string)
+	}
+
+	a.build[key] = value
+}
+
+// buildInfo returns the build information of the Application.
+func (a *Application) buildInfo() map[string]string {
+	info := make(map[string]string)
+	if bi, ok := debug.ReadBuildInfo(); ok {
+		info["go"] = bi.GoVersion
+		for _, s := range bi.Settings {
+			switch s.Key {
+			case "vcs.revision":
+				info["commit"] = s.Value
+			case "vcs.time":
+				info["date"] = s.Value
+			}
+		}
+	}
+
+	for k, v := range a.build {
+		info[k] = v
+	}
+
+	return info
+}
+
+func (c *commandVersion) Flags(flags *flag.FlagSet) {
+	c.short = flags.Bool("short", false, "Print only the version number.")
+	c.json = flags.Bool("json", false, "Print the version and build information as JSON.")
+}
+
+func (c *commandVersion) Run(ctx context.Context) error {
+	w := Stdout(ctx)
+	if *c.short {
+		_, err := fmt.Fprintln(w, c.app.version)
+		return err
+	}
+
+	info := c.app.buildInfo()
+	if *c.json {
+		v := map[string]string{"name": c.app.name, "version": c.app.version}
+		for k, value := range info {
+			v[k] = value
+		}
+
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(v)
+	}
+
+	fmt.Fprintf(w, "%s v%s\n", c.app.name, c.app.version)
+
+	// The go version alone is not worth reporting.
+	if len(info) == 1 && info["go"] != "" {
+		return nil
+	}
+
+	keys := make([]string, 0, len(info))
+	for k := range info {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		fmt.Fprintf(w, "%s: %s\n", k, info[k])
+	}
+
+	return nil
 }
 
 func (c *commandVersion) String() string {
 	return "Output the application version."
 }
+
+// String implements the flag.Value interface.
+func (b *cmdlineBool) String() string {
+	if b == nil {
+		return "false"
+	}
+
+	return strconv.FormatBool(bool(*b))
+}
+
+// Set implements the flag.Value interface.
+func (b *cmdlineBool) Set(value string) error {
+	v, err := strconv.ParseBool(value)
+	if err != nil {
+		return err
+	}
+
+	*b = cmdlineBool(v)
+	return nil
+}
+
+// IsBoolFlag implements the boolean flag interface of the flag package.
+func (b *cmdlineBool) IsBoolFlag() bool {
+	return true
+}
+
+// isCmdline reports whether the flag may only be set on the command line.
+func isCmdline(f *flag.Flag) bool {
+	_, ok := f.Value.(*cmdlineBool)
+	return ok
+}
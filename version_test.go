@@ -0,0 +1,51 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestVersion(t *testing.T) {
+	app := New("myapp", "1.2.3")
+	app.EnvPrefix("MYAPP")
+
+	tests := []struct {
+		args   []string
+		stdout string
+	}{
+		{[]string{"version"}, "myapp v1.2.3\n"},
+		{[]string{"version", "-short"}, "1.2.3\n"},
+		{[]string{"-version"}, "myapp v1.2.3\n"},
+		{[]string{"--version", "ignored"}, "myapp v1.2.3\n"},
+	}
+
+	// Variables named like the flag do not request the version.
+	os.Setenv("MYAPP_VERSION", "2.0.0")
+	defer os.Unsetenv("MYAPP_VERSION")
+
+	for _, tt := range tests {
+		var stdout, stderr bytes.Buffer
+		code := app.RunWithArgs(tt.args, &stdout, &stderr)
+		if code != 0 || stdout.String() != tt.stdout {
+			t.Errorf("%q\nhave %d %q\nwant %d %q", tt.args, code, stdout.String(), 0, tt.stdout)
+		}
+	}
+
+	app.BuildInfo("commit", "abc123")
+	var stdout bytes.Buffer
+	app.RunWithArgs([]string{"version"}, &stdout, &stdout)
+	if !strings.HasPrefix(stdout.String(), "myapp v1.2.3\ncommit: abc123\n") {
+		t.Errorf("build info\nhave %q", stdout.String())
+	}
+
+	stdout.Reset()
+	app.RunWithArgs([]string{"version", "-json"}, &stdout, &stdout)
+	var v map[string]string
+	err := json.Unmarshal(stdout.Bytes(), &v)
+	if err != nil || v["name"] != "myapp" || v["version"] != "1.2.3" || v["commit"] != "abc123" {
+		t.Errorf("json\nhave %v %q", err, stdout.String())
+	}
+}
@@ -0,0 +1,145 @@
+package cli
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+type commandWatch struct {
+	app      *Application
+	name     string
+	files    *string
+	debounce *time.Duration
+	interval *time.Duration
+	clear    *bool
+}
+
+// snapshot maps file names to their modification time and size.
+type snapshot map[string]string
+
+var errWatchFiles = fmt.Errorf("watch: no file patterns given")
+
+// Watch registers a command with the given name that dispatches to another
+// command and dispatches to it again whenever files matching the -files glob
+// patterns change, until interrupted. For example:
+//
+//	app watch -files '*.go,templates/*' build -race ./...
+//
+// Files are polled at the -interval, which must be positive, and the command
+// is run again once no further changes have been seen for the -debounce
+// period. The -clear flag clears the terminal before each run.
+func (a *Application) Watch(name string) error {
+	return a.Rule(&commandWatch{app: a, name: name}, name, "<cmd> [<args>...]", ParseFlags(ParsePOSIX), Local())
+}
+
+func (c *commandWatch) Flags(flags *flag.FlagSet) {
+	c.files = flags.String("files", "", "Comma separated glob patterns of files to watch.")
+	c.debounce = flags.Duration("debounce", 200*time.Millisecond, "Quiet period before running again.")
+	c.interval = flags.Duration("interval", 500*time.Millisecond, "Interval between checks for changes.")
+	c.clear = flags.Bool("clear", false, "Clear the screen between runs.")
+}
+
+func (c *commandWatch) Run(ctx context.Context, args []string) (int, error) {
+	if *c.files == "" {
+		c.app.errorf(Stderr(ctx), "%s: %v", c.name, errWatchFiles)
+		return 1, nil
+	}
+
+	if len(args) == 0 || args[0] == c.name {
+		c.app.errorf(Stderr(ctx), "%s: "+c.app.messages.MissingCommand, c.name)
+		return 1, nil
+	}
+
+	if *c.interval <= 0 {
+		return 0, UsageErrorf("invalid interval %v, must be positive", *c.interval)
+	}
+
+	patterns := strings.Split(*c.files, ",")
+	last, err := watchSnapshot(patterns)
+	if err != nil {
+		c.app.errorf(Stderr(ctx), "%s: %v", c.name, err)
+		return 1, nil
+	}
+
+	code := c.app.dispatch(ctx, args)
+
+	ticker := time.NewTicker(*c.interval)
+	defer ticker.Stop()
+
+	var changed time.Time
+	for {
+		select {
+		case <-ctx.Done():
+			return code, nil
+		case now := <-ticker.C:
+			current, err := watchSnapshot(patterns)
+			if err != nil {
+				c.app.errorf(Stderr(ctx), "%s: %v", c.name, err)
+				return 1, nil
+			}
+
+			if !current.equal(last) {
+				last = current
+				changed = now
+				continue
+			}
+
+			if changed.IsZero() || now.Sub(changed) < *c.debounce {
+				continue
+			}
+
+			changed = time.Time{}
+			if *c.clear {
+				fmt.Fprint(Stdout(ctx), "\033[H\033[2J")
+			}
+
+			code = c.app.dispatch(ctx, args)
+		}
+	}
+}
+
+func (c *commandWatch) String() string {
+	return "Run a command again whenever files change."
+}
+
+// watchSnapshot returns the state of the files matching patterns.
+func watchSnapshot(patterns []string) (snapshot, error) {
+	s := make(snapshot)
+	for _, pattern := range patterns {
+		matches, err := filepath.Glob(strings.TrimSpace(pattern))
+		if err != nil {
+			return nil, err
+		}
+
+		for _, name := range matches {
+			fi, err := os.Stat(name)
+			if err != nil {
+				continue
+			}
+
+			s[name] = fmt.Sprintf("%d:%d", fi.ModTime().UnixNano(), fi.Size())
+		}
+	}
+
+	return s, nil
+}
+
+// equal reports whether the snapshots describe the same files.
+func (s snapshot) equal(other snapshot) bool {
+	if len(s) != len(other) {
+		return false
+	}
+
+	for name, state := range s {
+		if other[name] != state {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,60 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWatch(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cli-watch")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	name := filepath.Join(dir, "a.txt")
+	ioutil.WriteFile(name, []byte("a"), 0644)
+
+	app := New("myapp", "0.0.1")
+	app.Rule(&runEcho{}, "echo", "<word>")
+	app.Watch("watch")
+
+	var stdout bytes.Buffer
+	app.stdout = &stdout
+
+	ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+	defer cancel()
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		ioutil.WriteFile(name, []byte("changed"), 0644)
+	}()
+
+	args := []string{"watch", "-files", filepath.Join(dir, "*.txt"), "-interval", "10ms", "-debounce", "20ms", "echo", "hi"}
+	code := app.dispatch(ctx, args)
+	if code != ExitTimeout {
+		t.Errorf("exit code\nhave %d\nwant %d", code, ExitTimeout)
+	}
+
+	if have, want := stdout.String(), "hi\nhi\n"; have != want {
+		t.Errorf("output\nhave %q\nwant %q", have, want)
+	}
+}
+
+func TestWatchInterval(t *testing.T) {
+	app := New("myapp", "0.0.1")
+	app.Rule(&runEcho{}, "echo", "<word>")
+	app.Watch("watch")
+
+	var stdout, stderr bytes.Buffer
+	code := app.RunWithArgs([]string{"watch", "-files", "*.txt", "-interval", "0s", "echo", "hi"}, &stdout, &stderr)
+	want := "myapp: watch: invalid interval 0s, must be positive\n"
+	if code != ExitUsage || stdout.String() != "" || !strings.HasPrefix(stderr.String(), want) {
+		t.Errorf("interval\nhave %d %q %q\nwant %d %q", code, stdout.String(), stderr.String(), ExitUsage, want)
+	}
+}
@@ -0,0 +1,159 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// A WriteOption configures WriteFileAtomic.
+type WriteOption func(w *writeOptions)
+
+type writeOptions struct {
+	noClobber bool
+	backup    string
+}
+
+// NoClobber is a WriteOption that fails with an error satisfying
+// errors.Is(err, os.ErrExist) rather than replace an existing file.
+func NoClobber() WriteOption {
+	return func(w *writeOptions) {
+		w.noClobber = true
+	}
+}
+
+// Backup is a WriteOption that preserves the existing file, if any, by
+// giving it the name of the file followed by suffix, such as "~" or ".bak".
+func Backup(suffix string) WriteOption {
+	return func(w *writeOptions) {
+		w.backup = suffix
+	}
+}
+
+// WriteFileAtomic writes data to the file named by path with the permissions
+// perm on behalf of the command that received ctx. The data is written to a
+// temporary file in the same directory, which then replaces path, so readers
+// never observe a partially written file. Missing parent directories are
+// created. When the invocation is a dry run, see DryRun, a description of the
+// write is printed to the standard error of the invocation instead.
+func WriteFileAtomic(ctx context.Context, path string, data []byte, perm os.FileMode, options ...WriteOption) error {
+	var w writeOptions
+	for _, option := range options {
+		option(&w)
+	}
+
+	if DryRun(ctx) {
+		m := ctxMessages(ctx)
+		description := fmt.Sprintf(m.WouldWrite, path, len(data), perm)
+		if _, err := os.Lstat(path); err == nil {
+			if w.noClobber {
+				return fmt.Errorf("%s: %w", path, os.ErrExist)
+			}
+
+			description = fmt.Sprintf(m.WouldReplace, path, len(data), perm)
+			if w.backup != "" {
+				description = fmt.Sprintf(m.WouldBackUp, path+w.backup, path, len(data), perm)
+			}
+		}
+
+		fmt.Fprintln(Stderr(ctx), description)
+		return nil
+	}
+
+	return writeFileAtomic(path, data, perm, w)
+}
+
+// writeFile replaces the contents of path with data readable only by the
+// current user, creating the parent directories if needed. Readers never
+// observe a partially written file.
+func writeFile(path string, data []byte) error {
+	return writeFileAtomic(path, data, 0600, writeOptions{})
+}
+
+// writeFileAtomic implements WriteFileAtomic.
+func writeFileAtomic(path string, data []byte, perm os.FileMode, w writeOptions) error {
+	dir := filepath.Dir(path)
+	err := os.MkdirAll(dir, 0755)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := ioutil.TempFile(dir, "."+filepath.Base(path))
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	_, err = tmp.Write(data)
+	if err == nil {
+		err = tmp.Chmod(perm)
+	}
+	if err == nil {
+		err = tmp.Sync()
+	}
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return err
+	}
+
+	// Linking fails if path exists, unlike renaming.
+	if w.noClobber {
+		err = os.Link(tmp.Name(), path)
+		if os.IsExist(err) {
+			return fmt.Errorf("%s: %w", path, os.ErrExist)
+		}
+
+		return err
+	}
+
+	if w.backup != "" {
+		err = backup(path, path+w.backup)
+		if err != nil {
+			return err
+		}
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
+
+// backup preserves the file at path, if any, as name. The file is hard linked
+// where possible so that path exists throughout.
+func backup(path, name string) error {
+	_, err := os.Lstat(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+
+	os.Remove(name)
+	if os.Link(path, name) == nil {
+		return nil
+	}
+
+	src, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	fi, err := src.Stat()
+	if err != nil {
+		return err
+	}
+
+	dst, err := os.OpenFile(name, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, fi.Mode().Perm())
+	if err != nil {
+		return err
+	}
+
+	_, err = io.Copy(dst, src)
+	if cerr := dst.Close(); err == nil {
+		err = cerr
+	}
+
+	return err
+}
@@ -0,0 +1,99 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestWriteFileAtomic(t *testing.T) {
+	dir, err := ioutil.TempDir("", "writefile")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	ctx := context.Background()
+	path := filepath.Join(dir, "out", "file")
+	err = WriteFileAtomic(ctx, path, []byte("one"), 0640)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fi, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fi.Mode().Perm() != 0640 {
+		t.Errorf("mode\nhave %v\nwant %v", fi.Mode().Perm(), os.FileMode(0640))
+	}
+
+	err = WriteFileAtomic(ctx, path, []byte("two"), 0640, NoClobber())
+	if !errors.Is(err, os.ErrExist) {
+		t.Errorf("no clobber\nhave %v\nwant %v", err, os.ErrExist)
+	}
+
+	err = WriteFileAtomic(ctx, path, []byte("two"), 0640, Backup(".bak"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := map[string]string{path: "two", path + ".bak": "one"}
+	for name, want := range tests {
+		have, _ := ioutil.ReadFile(name)
+		if string(have) != want {
+			t.Errorf("%s\nhave %q\nwant %q", name, have, want)
+		}
+	}
+}
+
+func TestWriteFileAtomicDryRun(t *testing.T) {
+	dir, err := ioutil.TempDir("", "writefile")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	var stderr bytes.Buffer
+	app := New("myapp", "0.0.1", WithDryRunFlag())
+	app.stderr = &stderr
+	path := filepath.Join(dir, "file")
+	app.Rule(&runWrite{path: path}, "write", "")
+
+	code := app.Dispatch([]string{"-dry-run", "write"})
+	if code != 0 {
+		t.Fatalf("exit code\nhave %d\nwant %d", code, 0)
+	}
+
+	_, err = os.Stat(path)
+	if !os.IsNotExist(err) {
+		t.Errorf("file written during dry run: %v", err)
+	}
+
+	if !strings.Contains(stderr.String(), "would write "+path) {
+		t.Errorf("dry run output\nhave %q\nwant %q", stderr.String(), "would write "+path)
+	}
+}
+
+type runWrite struct {
+	*NullFlags
+	path string
+}
+
+func (c *runWrite) Run(ctx context.Context) int {
+	err := WriteFileAtomic(ctx, c.path, []byte("data"), 0644)
+	if err != nil {
+		return 1
+	}
+
+	return 0
+}
+
+func (c *runWrite) String() string {
+	return "write a file"
+}